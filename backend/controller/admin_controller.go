@@ -0,0 +1,360 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/repository"
+	"github.com/tiananugerah/go-BookCabin/service"
+)
+
+type AdminController struct {
+	adminService *service.AdminService
+}
+
+func NewAdminController(adminService *service.AdminService) *AdminController {
+	return &AdminController{adminService: adminService}
+}
+
+type PageQuery struct {
+	Page     int `form:"page" binding:"omitempty,min=1"`
+	PageSize int `form:"page_size" binding:"omitempty,min=1"`
+}
+
+func (q PageQuery) pagination() service.Pagination {
+	return service.Pagination{Page: q.Page, PageSize: q.PageSize}
+}
+
+type SearchBookingsQuery struct {
+	PageQuery
+	UserID    uint       `form:"user_id"`
+	FlightID  uint       `form:"flight_id"`
+	Status    string     `form:"status" binding:"omitempty,oneof=pending confirmed cancelled expired"`
+	Reference string     `form:"reference"`
+	Email     string     `form:"email"`
+	From      *time.Time `form:"from" time_format:"2006-01-02"`
+	To        *time.Time `form:"to" time_format:"2006-01-02"`
+}
+
+func (c *AdminController) SearchBookings(ctx *gin.Context) {
+	var query SearchBookingsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	filter := repository.BookingFilter{
+		UserID:     query.UserID,
+		FlightID:   query.FlightID,
+		Status:     model.BookingStatus(query.Status),
+		Reference:  query.Reference,
+		Email:      query.Email,
+		BookedFrom: query.From,
+	}
+	// Tanggal "to" bersifat inklusif
+	if query.To != nil {
+		to := query.To.AddDate(0, 0, 1)
+		filter.BookedTo = &to
+	}
+
+	page, err := c.adminService.SearchBookings(filter, query.pagination())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+type ReasonRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+func (c *AdminController) CancelBooking(ctx *gin.Context) {
+	bookingID, err := idParam(ctx, "bookingID")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var req ReasonRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	if err := c.adminService.ForceCancelBooking(ctx.GetUint("userID"), bookingID, req.Reason); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "booking cancelled successfully"})
+}
+
+type ReassignSeatRequest struct {
+	SeatID uint   `json:"seat_id" binding:"required"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+func (c *AdminController) ReassignSeat(ctx *gin.Context) {
+	bookingID, err := idParam(ctx, "bookingID")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var req ReassignSeatRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	booking, err := c.adminService.ReassignSeat(ctx.GetUint("userID"), bookingID, req.SeatID, req.Reason)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, booking)
+}
+
+type BlockSeatRequest struct {
+	Reason string `json:"reason" binding:"required"`
+	// BlockedUntil opsional; tanpa nilai, kursi diblokir sampai dicabut admin
+	BlockedUntil *time.Time `json:"blocked_until"`
+}
+
+func (c *AdminController) BlockSeat(ctx *gin.Context) {
+	seatID, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var req BlockSeatRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	seat, err := c.adminService.BlockSeat(ctx.GetUint("userID"), seatID, req.Reason, req.BlockedUntil)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, seat)
+}
+
+func (c *AdminController) UnblockSeat(ctx *gin.Context) {
+	seatID, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var req ReasonRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	seat, err := c.adminService.UnblockSeat(ctx.GetUint("userID"), seatID, req.Reason)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, seat)
+}
+
+type UpdateSeatRequest struct {
+	Price           *float64  `json:"price" binding:"omitempty,min=0"`
+	Currency        *string   `json:"currency" binding:"omitempty,len=3"`
+	Segment         *string   `json:"segment"`
+	IsWindow        *bool     `json:"is_window"`
+	IsAisle         *bool     `json:"is_aisle"`
+	Characteristics *[]string `json:"characteristics"`
+	Reason          string    `json:"reason"`
+}
+
+func (c *AdminController) UpdateSeat(ctx *gin.Context) {
+	seatID, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var req UpdateSeatRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	seat, err := c.adminService.UpdateSeat(ctx.GetUint("userID"), seatID, service.SeatUpdate{
+		Price:           req.Price,
+		Currency:        req.Currency,
+		Segment:         req.Segment,
+		IsWindow:        req.IsWindow,
+		IsAisle:         req.IsAisle,
+		Characteristics: req.Characteristics,
+	}, req.Reason)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, seat)
+}
+
+type SearchUsersQuery struct {
+	PageQuery
+	Query string `form:"q"`
+	Role  string `form:"role"`
+}
+
+func (c *AdminController) SearchUsers(ctx *gin.Context) {
+	var query SearchUsersQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	page, err := c.adminService.SearchUsers(repository.UserFilter{Query: query.Query, Role: query.Role}, query.pagination())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+func (c *AdminController) GetUser(ctx *gin.Context) {
+	userID, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	profile, err := c.adminService.GetUser(userID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, profile)
+}
+
+type AdminUpdateUserRequest struct {
+	Name          *string `json:"name" binding:"omitempty,max=255"`
+	Role          *string `json:"role" binding:"omitempty,oneof=customer support admin agent"`
+	EmailVerified *bool   `json:"email_verified"`
+	Reason        string  `json:"reason"`
+}
+
+func (c *AdminController) UpdateUser(ctx *gin.Context) {
+	userID, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var req AdminUpdateUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	profile, err := c.adminService.UpdateUser(ctx.GetUint("userID"), userID, service.UserUpdate{
+		Name:          req.Name,
+		Role:          req.Role,
+		EmailVerified: req.EmailVerified,
+	}, req.Reason)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, profile)
+}
+
+func (c *AdminController) DeleteUser(ctx *gin.Context) {
+	userID, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var req ReasonRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	if err := c.adminService.DeleteUser(ctx.GetUint("userID"), userID, req.Reason); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "user deleted successfully"})
+}
+
+type BookingLimitExemptionRequest struct {
+	Exempt *bool  `json:"exempt" binding:"required"`
+	Reason string `json:"reason"`
+}
+
+// SetBookingLimitExemption membebaskan atau mencabut pembebasan akun agent dari batas booking
+func (c *AdminController) SetBookingLimitExemption(ctx *gin.Context) {
+	userID, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var req BookingLimitExemptionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	profile, err := c.adminService.SetBookingLimitExempt(ctx.GetUint("userID"), userID, *req.Exempt, req.Reason)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, profile)
+}
+
+type AuditLogQuery struct {
+	PageQuery
+	ActorID    uint   `form:"actor_id"`
+	Action     string `form:"action"`
+	TargetType string `form:"target_type"`
+	TargetID   uint   `form:"target_id"`
+}
+
+func (c *AdminController) GetAuditLog(ctx *gin.Context) {
+	var query AuditLogQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	page, err := c.adminService.AuditLog(repository.AuditFilter{
+		ActorID:    query.ActorID,
+		Action:     query.Action,
+		TargetType: query.TargetType,
+		TargetID:   query.TargetID,
+	}, query.pagination())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tiananugerah/go-BookCabin/service"
+)
+
+type AirportController struct {
+	airportService *service.AirportService
+}
+
+func NewAirportController(airportService *service.AirportService) *AirportController {
+	return &AirportController{airportService: airportService}
+}
+
+func (c *AirportController) GetAirports(ctx *gin.Context) {
+	airports, err := c.airportService.ListAirports()
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, airports)
+}
+
+func (c *AirportController) GetAirport(ctx *gin.Context) {
+	airport, err := c.airportService.GetAirport(ctx.Param("code"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, airport)
+}
+
+type AirportRequest struct {
+	Name     string `json:"name" binding:"max=255"`
+	City     string `json:"city" binding:"max=100"`
+	Country  string `json:"country" binding:"omitempty,len=2"`
+	TimeZone string `json:"time_zone" binding:"required"`
+	Reason   string `json:"reason" binding:"required"`
+}
+
+// SaveAirport membuat atau mengganti data bandara, termasuk zona waktunya
+func (c *AirportController) SaveAirport(ctx *gin.Context) {
+	var req AirportRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	airport, err := c.airportService.SaveAirport(ctx.GetUint("userID"), service.AirportInput{
+		Code:     ctx.Param("code"),
+		Name:     req.Name,
+		City:     req.City,
+		Country:  req.Country,
+		TimeZone: req.TimeZone,
+	}, req.Reason)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, airport)
+}
@@ -18,20 +18,20 @@ func NewAuthController(authService *service.AuthService) *AuthController {
 
 type RegisterRequest struct {
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required"`
 	Name     string `json:"name" binding:"required"`
 }
 
 func (c *AuthController) Register(ctx *gin.Context) {
 	var req RegisterRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(bindingError(err))
 		return
 	}
 
 	user, err := c.authService.Register(req.Email, req.Password, req.Name)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -50,15 +50,104 @@ type LoginRequest struct {
 func (c *AuthController) Login(ctx *gin.Context) {
 	var req LoginRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(bindingError(err))
 		return
 	}
 
 	token, err := c.authService.Login(req.Email, req.Password)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"token": token})
-}
\ No newline at end of file
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+func (c *AuthController) VerifyEmail(ctx *gin.Context) {
+	var req VerifyEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	if err := c.authService.VerifyEmail(req.Token); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "email verified successfully"})
+}
+
+type EmailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+func (c *AuthController) ResendVerification(ctx *gin.Context) {
+	var req EmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	if err := c.authService.ResendVerification(req.Email); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	// Respons selalu sama agar tidak bisa dipakai untuk mengecek email terdaftar
+	ctx.JSON(http.StatusAccepted, gin.H{"message": "if the account exists and is unverified, a verification email has been sent"})
+}
+
+func (c *AuthController) ForgotPassword(ctx *gin.Context) {
+	var req EmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	if err := c.authService.ForgotPassword(req.Email); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"message": "if the account exists, a password reset email has been sent"})
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+func (c *AuthController) ResetPassword(ctx *gin.Context) {
+	var req ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	if err := c.authService.ResetPassword(req.Token, req.Password); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "password has been reset"})
+}
+
+func (c *AuthController) ConfirmEmailChange(ctx *gin.Context) {
+	var req VerifyEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	if err := c.authService.ConfirmEmailChange(req.Token); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "email changed successfully"})
+}
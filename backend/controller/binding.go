@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/tiananugerah/go-BookCabin/service"
+)
+
+// bindingError membedakan body yang gagal validasi (422) dari body yang tidak bisa di-parse (400)
+func bindingError(err error) error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return err
+	}
+	return service.ErrInvalidRequest.WithDetail("invalid request body: %v", err)
+}
+
+// idParam membaca path parameter numerik seperti :id atau :bookingID
+func idParam(ctx *gin.Context, name string) (uint, error) {
+	id, err := strconv.ParseUint(ctx.Param(name), 10, 32)
+	if err != nil {
+		return 0, service.ErrInvalidRequest.WithDetail("invalid %s", name)
+	}
+	return uint(id), nil
+}
@@ -2,7 +2,6 @@ package controller
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/tiananugerah/go-BookCabin/service"
@@ -18,6 +17,8 @@ func NewBookingController(bookingService *service.BookingService) *BookingContro
 
 type CreateBookingRequest struct {
 	SeatID uint `json:"seat_id" binding:"required"`
+	// PromoCode opsional; booking gagal jika kode tidak berlaku untuk kursi ini
+	PromoCode string `json:"promo_code" binding:"omitempty,max=32"`
 }
 
 func (c *BookingController) CreateBooking(ctx *gin.Context) {
@@ -25,36 +26,71 @@ func (c *BookingController) CreateBooking(ctx *gin.Context) {
 
 	var req CreateBookingRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(bindingError(err))
 		return
 	}
 
-	booking, err := c.bookingService.CreateBooking(userID, req.SeatID)
+	booking, err := c.bookingService.CreateBooking(userID, req.SeatID, req.PromoCode)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
 	ctx.JSON(http.StatusCreated, booking)
 }
 
+// HoldSeat menahan kursi sementara; hold harus dikonfirmasi sebelum kedaluwarsa
+func (c *BookingController) HoldSeat(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+
+	var req CreateBookingRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	booking, err := c.bookingService.HoldSeat(userID, req.SeatID, req.PromoCode)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, booking)
+}
+
+func (c *BookingController) ConfirmBooking(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+
+	bookingID, err := idParam(ctx, "bookingID")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	booking, err := c.bookingService.ConfirmBooking(userID, bookingID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, booking)
+}
+
 func (c *BookingController) CancelBooking(ctx *gin.Context) {
-    userID := ctx.GetUint("userID")
-    bookingIDStr := ctx.Param("bookingID")
-    
-    bookingID, err := strconv.ParseUint(bookingIDStr, 10, 32)
-    if err != nil {
-        ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking ID"})
-        return
-    }
-
-    err = c.bookingService.CancelBooking(userID, uint(bookingID))
-    if err != nil {
-        ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-        return
-    }
-
-    ctx.JSON(http.StatusOK, gin.H{"message": "booking cancelled successfully"})
+	userID := ctx.GetUint("userID")
+	bookingID, err := idParam(ctx, "bookingID")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	err = c.bookingService.CancelBooking(userID, bookingID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "booking cancelled successfully"})
 }
 
 func (c *BookingController) GetUserBookings(ctx *gin.Context) {
@@ -62,7 +98,7 @@ func (c *BookingController) GetUserBookings(ctx *gin.Context) {
 
 	bookings, err := c.bookingService.GetUserBookings(userID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
@@ -72,9 +108,41 @@ func (c *BookingController) GetUserBookings(ctx *gin.Context) {
 func (c *BookingController) GetAvailableSeats(ctx *gin.Context) {
 	seats, err := c.bookingService.GetAvailableSeats()
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, seats)
-}
\ No newline at end of file
+}
+
+type ManageBookingQuery struct {
+	Reference string `form:"ref" binding:"required"`
+	LastName  string `form:"last_name" binding:"required"`
+}
+
+// ManageBooking menampilkan booking berdasarkan record locator dan nama belakang tanpa login
+func (c *BookingController) ManageBooking(ctx *gin.Context) {
+	var query ManageBookingQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	details, err := c.bookingService.ManageBooking(query.Reference, query.LastName)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, details)
+}
+
+func (c *BookingController) FindByReference(ctx *gin.Context) {
+	details, err := c.bookingService.FindByReference(ctx.Param("reference"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, details)
+}
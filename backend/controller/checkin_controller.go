@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tiananugerah/go-BookCabin/service"
+)
+
+type CheckInController struct {
+	checkInService *service.CheckInService
+}
+
+func NewCheckInController(checkInService *service.CheckInService) *CheckInController {
+	return &CheckInController{checkInService: checkInService}
+}
+
+func (c *CheckInController) CheckIn(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+
+	bookingID, err := idParam(ctx, "bookingID")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	pass, err := c.checkInService.CheckIn(userID, bookingID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, pass)
+}
+
+func (c *CheckInController) GetBoardingPass(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+
+	bookingID, err := idParam(ctx, "bookingID")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	pass, err := c.checkInService.GetBoardingPass(userID, bookingID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, pass)
+}
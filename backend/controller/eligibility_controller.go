@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/service"
+)
+
+type EligibilityController struct {
+	eligibilityService *service.EligibilityService
+}
+
+func NewEligibilityController(eligibilityService *service.EligibilityService) *EligibilityController {
+	return &EligibilityController{eligibilityService: eligibilityService}
+}
+
+func (c *EligibilityController) GetRuleSets(ctx *gin.Context) {
+	sets, err := c.eligibilityService.RuleSets()
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sets)
+}
+
+// GetRuleSet menampilkan rule maskapai, atau rule bawaan jika maskapai belum punya rule sendiri
+func (c *EligibilityController) GetRuleSet(ctx *gin.Context) {
+	set, err := c.eligibilityService.RuleSet(ctx.Param("airline"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, set)
+}
+
+type EligibilityRulesRequest struct {
+	Rules  []model.EligibilityRule `json:"rules" binding:"required"`
+	Reason string                  `json:"reason" binding:"required"`
+}
+
+func (c *EligibilityController) SaveRuleSet(ctx *gin.Context) {
+	var req EligibilityRulesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	set, err := c.eligibilityService.SaveRuleSet(ctx.GetUint("userID"), ctx.Param("airline"), req.Rules, req.Reason)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, set)
+}
+
+func (c *EligibilityController) DeleteRuleSet(ctx *gin.Context) {
+	var req ReasonRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	if err := c.eligibilityService.DeleteRuleSet(ctx.GetUint("userID"), ctx.Param("airline"), req.Reason); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "eligibility rules reset to defaults"})
+}
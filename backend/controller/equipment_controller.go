@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/repository"
+	"github.com/tiananugerah/go-BookCabin/service"
+)
+
+type EquipmentController struct {
+	equipmentService *service.EquipmentService
+}
+
+func NewEquipmentController(equipmentService *service.EquipmentService) *EquipmentController {
+	return &EquipmentController{equipmentService: equipmentService}
+}
+
+// SwapEquipment mengganti layout kursi penerbangan dengan seat map pesawat baru dari body
+// request. Format dipilih lewat ?format= (kosong = dideteksi) dan alasan lewat ?reason=.
+func (c *EquipmentController) SwapEquipment(ctx *gin.Context) {
+	flightID, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	reason := strings.TrimSpace(ctx.Query("reason"))
+	if reason == "" {
+		ctx.Error(service.ErrValidationFailed.WithDetail("reason is required"))
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxSeatMapSize))
+	if err != nil {
+		ctx.Error(service.ErrInvalidRequest.WithDetail("seat map document could not be read: %v", err))
+		return
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		ctx.Error(service.ErrValidationFailed.WithDetail("seat map document for the new aircraft is required"))
+		return
+	}
+
+	result, err := c.equipmentService.SwapEquipment(ctx.GetUint("userID"), flightID, body, ctx.Query("format"), reason)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, result)
+}
+
+type ReaccommodationQuery struct {
+	PageQuery
+	FlightID uint   `form:"flight_id"`
+	Status   string `form:"status" binding:"omitempty,oneof=open resolved cancelled"`
+}
+
+func (c *EquipmentController) GetReaccommodations(ctx *gin.Context) {
+	var query ReaccommodationQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	page, err := c.equipmentService.Reaccommodations(repository.ReaccommodationFilter{
+		FlightID: query.FlightID,
+		Status:   model.ReaccommodationStatus(query.Status),
+	}, query.pagination())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tiananugerah/go-BookCabin/service"
+)
+
+type FlightController struct {
+	flightService *service.FlightService
+}
+
+func NewFlightController(flightService *service.FlightService) *FlightController {
+	return &FlightController{flightService: flightService}
+}
+
+type FlightSearchQuery struct {
+	PageQuery
+	Origin      string     `form:"origin"`
+	Destination string     `form:"destination"`
+	Date        *time.Time `form:"date" time_format:"2006-01-02"`
+	AirlineCode string     `form:"airline_code"`
+}
+
+type FlightRequest struct {
+	AirlineCode       string    `json:"airline_code" binding:"required"`
+	FlightNumber      int       `json:"flight_number" binding:"required"`
+	Origin            string    `json:"origin" binding:"required"`
+	Destination       string    `json:"destination" binding:"required"`
+	Departure         time.Time `json:"departure" binding:"required"`
+	Arrival           time.Time `json:"arrival" binding:"required"`
+	DepartureTerminal string    `json:"departure_terminal" binding:"max=10"`
+	ArrivalTerminal   string    `json:"arrival_terminal" binding:"max=10"`
+	Equipment         string    `json:"equipment" binding:"max=50"`
+	// Reason dicatat di audit log saat jadwal diubah
+	Reason string `json:"reason"`
+}
+
+func (r FlightRequest) input() service.FlightInput {
+	return service.FlightInput{
+		AirlineCode:       r.AirlineCode,
+		FlightNumber:      r.FlightNumber,
+		Origin:            r.Origin,
+		Destination:       r.Destination,
+		Departure:         r.Departure,
+		Arrival:           r.Arrival,
+		DepartureTerminal: r.DepartureTerminal,
+		ArrivalTerminal:   r.ArrivalTerminal,
+		Equipment:         r.Equipment,
+	}
+}
+
+// SearchFlights mencari jadwal penerbangan berdasarkan rute dan tanggal berangkat
+func (c *FlightController) SearchFlights(ctx *gin.Context) {
+	var query FlightSearchQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	page, err := c.flightService.SearchFlights(service.FlightSearch{
+		Origin:      query.Origin,
+		Destination: query.Destination,
+		Date:        query.Date,
+		AirlineCode: query.AirlineCode,
+	}, query.pagination())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+func (c *FlightController) GetFlight(ctx *gin.Context) {
+	id, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	flight, err := c.flightService.GetFlight(id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, flight)
+}
+
+func (c *FlightController) CreateFlight(ctx *gin.Context) {
+	var req FlightRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	flight, err := c.flightService.CreateFlight(ctx.GetUint("userID"), req.input())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, flight)
+}
+
+// UpdateFlight mengganti seluruh data jadwal penerbangan
+func (c *FlightController) UpdateFlight(ctx *gin.Context) {
+	id, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var req FlightRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	flight, err := c.flightService.UpdateFlight(ctx.GetUint("userID"), id, req.input(), req.Reason)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, flight)
+}
+
+func (c *FlightController) DeleteFlight(ctx *gin.Context) {
+	id, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var req ReasonRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	if err := c.flightService.DeleteFlight(ctx.GetUint("userID"), id, req.Reason); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "flight deleted successfully"})
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tiananugerah/go-BookCabin/service"
+)
+
+type NotificationController struct {
+	notificationService *service.NotificationService
+}
+
+func NewNotificationController(notificationService *service.NotificationService) *NotificationController {
+	return &NotificationController{notificationService: notificationService}
+}
+
+type NotificationQuery struct {
+	PageQuery
+	Unread bool `form:"unread"`
+}
+
+func (c *NotificationController) GetNotifications(ctx *gin.Context) {
+	var query NotificationQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	page, err := c.notificationService.Inbox(ctx.GetUint("userID"), query.Unread, query.pagination())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+func (c *NotificationController) MarkRead(ctx *gin.Context) {
+	notificationID, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if err := c.notificationService.MarkRead(ctx.GetUint("userID"), notificationID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "notification marked as read"})
+}
+
+func (c *NotificationController) MarkAllRead(ctx *gin.Context) {
+	marked, err := c.notificationService.MarkAllRead(ctx.GetUint("userID"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"marked": marked})
+}
@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/service"
+)
+
+type PricingController struct {
+	pricingService *service.PricingService
+}
+
+func NewPricingController(pricingService *service.PricingService) *PricingController {
+	return &PricingController{pricingService: pricingService}
+}
+
+// QuoteSeat menampilkan harga kursi saat ini untuk user yang login; harga final dikunci saat kursi di-hold atau dibooking
+func (c *PricingController) QuoteSeat(ctx *gin.Context) {
+	seatID, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	quote, err := c.pricingService.QuoteSeat(ctx.GetUint("userID"), seatID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quote)
+}
+
+func (c *PricingController) GetRuleSets(ctx *gin.Context) {
+	sets, err := c.pricingService.RuleSets()
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sets)
+}
+
+func (c *PricingController) GetActiveRuleSet(ctx *gin.Context) {
+	set, err := c.pricingService.ActiveRuleSet()
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, set)
+}
+
+type CreatePriceRulesRequest struct {
+	Rules        []model.PriceRule       `json:"rules" binding:"required"`
+	Entitlements []model.EntitlementRule `json:"entitlements"`
+	Note         string                  `json:"note"`
+}
+
+func (c *PricingController) CreateRuleSet(ctx *gin.Context) {
+	var req CreatePriceRulesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	set, err := c.pricingService.CreateRuleSet(ctx.GetUint("userID"), req.Rules, req.Entitlements, req.Note)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, set)
+}
+
+func (c *PricingController) ActivateRuleSet(ctx *gin.Context) {
+	version, err := idParam(ctx, "version")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var req ReasonRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	set, err := c.pricingService.ActivateRuleSet(ctx.GetUint("userID"), int(version), req.Reason)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, set)
+}
+
+type PricePreviewRequest struct {
+	FlightID uint `json:"flight_id" binding:"required"`
+	// Version versi tersimpan yang ingin dibandingkan; jika kosong, Rules dipakai
+	Version int               `json:"version" binding:"omitempty,min=1"`
+	Rules   []model.PriceRule `json:"rules"`
+	At      *time.Time        `json:"at"`
+}
+
+// PreviewRuleSet menampilkan harga seat map penerbangan di bawah rule aktif dan rule usulan
+func (c *PricingController) PreviewRuleSet(ctx *gin.Context) {
+	var req PricePreviewRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	preview, err := c.pricingService.Preview(req.FlightID, service.PricePreviewInput{
+		Version: req.Version,
+		Rules:   req.Rules,
+		At:      req.At,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, preview)
+}
@@ -0,0 +1,158 @@
+package controller
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/service"
+)
+
+type PromoController struct {
+	promoService *service.PromoService
+}
+
+func NewPromoController(promoService *service.PromoService) *PromoController {
+	return &PromoController{promoService: promoService}
+}
+
+type PromoCodeRequest struct {
+	Code           string                `json:"code" binding:"required"`
+	Description    string                `json:"description" binding:"max=255"`
+	DiscountType   model.DiscountType    `json:"discount_type" binding:"required"`
+	Percent        float64               `json:"percent"`
+	Amounts        model.CurrencyAmounts `json:"amounts"`
+	MaxDiscount    model.CurrencyAmounts `json:"max_discount"`
+	MaxRedemptions int                   `json:"max_redemptions" binding:"min=0"`
+	ValidFrom      *time.Time            `json:"valid_from"`
+	ValidUntil     *time.Time            `json:"valid_until"`
+	Cabins         []string              `json:"cabins"`
+	SeatTypes      []string              `json:"seat_types"`
+	// Active default true jika tidak diisi
+	Active *bool `json:"active"`
+	// Reason dicatat di audit log saat promo diubah
+	Reason string `json:"reason"`
+}
+
+func (r PromoCodeRequest) input() service.PromoCodeInput {
+	active := r.Active == nil || *r.Active
+	return service.PromoCodeInput{
+		Code:           r.Code,
+		Description:    r.Description,
+		DiscountType:   r.DiscountType,
+		Percent:        r.Percent,
+		Amounts:        r.Amounts,
+		MaxDiscount:    r.MaxDiscount,
+		MaxRedemptions: r.MaxRedemptions,
+		ValidFrom:      r.ValidFrom,
+		ValidUntil:     r.ValidUntil,
+		Cabins:         r.Cabins,
+		SeatTypes:      r.SeatTypes,
+		Active:         active,
+	}
+}
+
+func (c *PromoController) GetPromoCodes(ctx *gin.Context) {
+	promos, err := c.promoService.ListPromoCodes()
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, promos)
+}
+
+func (c *PromoController) GetPromoCode(ctx *gin.Context) {
+	id, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	promo, err := c.promoService.GetPromoCode(id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, promo)
+}
+
+func (c *PromoController) CreatePromoCode(ctx *gin.Context) {
+	var req PromoCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	promo, err := c.promoService.CreatePromoCode(ctx.GetUint("userID"), req.input())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, promo)
+}
+
+// UpdatePromoCode mengganti seluruh pengaturan promo
+func (c *PromoController) UpdatePromoCode(ctx *gin.Context) {
+	id, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var req PromoCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	promo, err := c.promoService.UpdatePromoCode(ctx.GetUint("userID"), id, req.input(), req.Reason)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, promo)
+}
+
+func (c *PromoController) DeletePromoCode(ctx *gin.Context) {
+	id, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var req ReasonRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	if err := c.promoService.DeletePromoCode(ctx.GetUint("userID"), id, req.Reason); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "promo code deleted successfully"})
+}
+
+// GetRedemptions menampilkan laporan pemakaian promo
+func (c *PromoController) GetRedemptions(ctx *gin.Context) {
+	id, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	report, err := c.promoService.RedemptionReport(id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
@@ -1,10 +1,16 @@
 package controller
 
 import (
+	"bytes"
+	"io"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/tiananugerah/go-BookCabin/event"
+	"github.com/tiananugerah/go-BookCabin/model"
 	"github.com/tiananugerah/go-BookCabin/service"
 )
 
@@ -16,10 +22,33 @@ func NewSeatController(seatService *service.SeatService) *SeatController {
 	return &SeatController{seatService: seatService}
 }
 
+// maxSeatMapSize membatasi ukuran dokumen seat map yang diunggah ke endpoint import
+const maxSeatMapSize = 20 << 20
+
+// ImportSeats mengimpor dokumen seat map dari body request atas nama admin. Formatnya dideteksi
+// otomatis atau dipilih lewat query ?format= dan alasan lewat ?reason=; tanpa body, file seat map
+// bawaan yang diimpor.
 func (c *SeatController) ImportSeats(ctx *gin.Context) {
-	seats, err := c.seatService.ImportSeatMapFromFile("/app/data/SeatMapResponse.json")
+	reason := strings.TrimSpace(ctx.Query("reason"))
+	if reason == "" {
+		ctx.Error(service.ErrValidationFailed.WithDetail("reason is required"))
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxSeatMapSize))
+	if err != nil {
+		ctx.Error(service.ErrInvalidRequest.WithDetail("seat map document could not be read: %v", err))
+		return
+	}
+
+	var seats []model.Seat
+	actorID := ctx.GetUint("userID")
+	if len(bytes.TrimSpace(body)) == 0 {
+		seats, err = c.seatService.ImportSeatMapFromFile(actorID, "/app/data/SeatMapResponse.json", reason)
+	} else {
+		seats, err = c.seatService.ImportSeatMap(actorID, body, ctx.Query("format"), reason)
+	}
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 	ctx.JSON(http.StatusOK, seats)
@@ -28,8 +57,80 @@ func (c *SeatController) ImportSeats(ctx *gin.Context) {
 func (c *SeatController) GetSeats(ctx *gin.Context) {
 	seats, err := c.seatService.GetAllSeats()
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 	ctx.JSON(http.StatusOK, seats)
-}
\ No newline at end of file
+}
+
+// ExportSeatMap mengembalikan seat map penerbangan dalam format SeatMapResponse dengan
+// ketersediaan dan harga saat ini
+func (c *SeatController) ExportSeatMap(ctx *gin.Context) {
+	flightID, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	seatMap, err := c.seatService.ExportSeatMap(flightID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, seatMap)
+}
+
+// streamHeartbeat menjaga koneksi SSE tetap hidup di balik proxy
+const streamHeartbeat = 15 * time.Second
+
+// StreamSeats mengirim snapshot kursi penerbangan lalu setiap perubahan
+// ketersediaannya sebagai Server-Sent Events.
+func (c *SeatController) StreamSeats(ctx *gin.Context) {
+	flightID, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	// Subscribe sebelum snapshot agar tidak ada perubahan yang terlewat di antaranya
+	events, unsubscribe := c.seatService.SubscribeSeatChanges(flightID)
+	defer unsubscribe()
+
+	seats, err := c.seatService.GetFlightSeats(flightID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.SSEvent("snapshot", seats)
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return false
+			}
+			// Setelah import ulang layout bisa berubah; kirim snapshot baru sebagai gantinya
+			if e.Type == event.SeatMapImported {
+				if seats, err := c.seatService.GetFlightSeats(flightID); err == nil {
+					ctx.SSEvent("snapshot", seats)
+				}
+				return true
+			}
+			ctx.SSEvent("seat", e)
+			return true
+		case <-heartbeat.C:
+			ctx.SSEvent("ping", gin.H{"time": time.Now()})
+			return true
+		case <-ctx.Request.Context().Done():
+			return false
+		}
+	})
+}
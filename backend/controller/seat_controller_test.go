@@ -0,0 +1,141 @@
+package controller
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tiananugerah/go-BookCabin/event"
+	"github.com/tiananugerah/go-BookCabin/middleware"
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/repository"
+	"github.com/tiananugerah/go-BookCabin/service"
+)
+
+// sseMessage adalah satu event Server-Sent Events yang dibaca dari response
+type sseMessage struct {
+	event string
+	data  string
+}
+
+// readSSE membaca event berikutnya dari stream atau gagal setelah batas waktu
+func readSSE(t *testing.T, r *bufio.Reader) sseMessage {
+	t.Helper()
+	done := make(chan sseMessage, 1)
+	go func() {
+		var msg sseMessage
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				close(done)
+				return
+			}
+			line = strings.TrimRight(line, "\n")
+			switch {
+			case strings.HasPrefix(line, "event:"):
+				msg.event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+			case strings.HasPrefix(line, "data:"):
+				msg.data += strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			case line == "" && msg.event != "":
+				done <- msg
+				return
+			}
+		}
+	}()
+	select {
+	case msg, ok := <-done:
+		if !ok {
+			t.Fatal("stream closed before the next event")
+		}
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("no server-sent event received")
+	}
+	return sseMessage{}
+}
+
+func newStreamServer(t *testing.T) (*httptest.Server, *event.MemoryBus) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	store := repository.NewMemoryStore()
+	bus := event.NewMemoryBus()
+	if _, err := service.NewAirportService(store).LoadAirports("../data/airports.json"); err != nil {
+		t.Fatalf("load airports: %v", err)
+	}
+	seats := service.NewSeatService(store, bus)
+	if _, err := seats.ImportSeatMapFromFile(0, "../data/SeatMapResponse.json", "test fixture"); err != nil {
+		t.Fatalf("import seat map: %v", err)
+	}
+
+	r := gin.New()
+	r.Use(middleware.ErrorHandler())
+	r.GET("/flights/:id/seats/stream", NewSeatController(seats).StreamSeats)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server, bus
+}
+
+func TestStreamSeatsSendsSnapshotThenChanges(t *testing.T) {
+	server, bus := newStreamServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/flights/1/seats/stream", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Errorf("content type = %q, want text/event-stream", ct)
+	}
+	body := bufio.NewReader(resp.Body)
+
+	snapshot := readSSE(t, body)
+	if snapshot.event != "snapshot" {
+		t.Fatalf("first event = %q, want snapshot", snapshot.event)
+	}
+	var seats []model.Seat
+	if err := json.Unmarshal([]byte(snapshot.data), &seats); err != nil {
+		t.Fatalf("decode snapshot: %v", err)
+	}
+	if len(seats) != 150 {
+		t.Errorf("snapshot has %d seats, want 150", len(seats))
+	}
+
+	// Event penerbangan lain tidak diteruskan ke stream ini
+	bus.Publish(event.Event{Type: event.SeatHeld, FlightID: 99, SeatID: 1})
+	bus.Publish(event.Event{Type: event.SeatHeld, FlightID: 1, SeatID: seats[0].ID, SeatCode: seats[0].SeatCode})
+
+	change := readSSE(t, body)
+	if change.event != "seat" {
+		t.Fatalf("second event = %q, want seat", change.event)
+	}
+	var e event.Event
+	if err := json.Unmarshal([]byte(change.data), &e); err != nil {
+		t.Fatalf("decode seat event: %v", err)
+	}
+	if e.Type != event.SeatHeld || e.FlightID != 1 || e.SeatID != seats[0].ID || e.Available {
+		t.Errorf("seat event = %+v, want seat.held for seat %d on flight 1", e, seats[0].ID)
+	}
+}
+
+func TestStreamSeatsUnknownFlight(t *testing.T) {
+	server, _ := newStreamServer(t)
+
+	resp, err := http.Get(server.URL + "/flights/404/seats/stream")
+	if err != nil {
+		t.Fatalf("GET stream: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", resp.StatusCode)
+	}
+}
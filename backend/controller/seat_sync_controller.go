@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/service"
+)
+
+type SeatMapSyncController struct {
+	syncService *service.SeatMapSyncService
+}
+
+func NewSeatMapSyncController(syncService *service.SeatMapSyncService) *SeatMapSyncController {
+	return &SeatMapSyncController{syncService: syncService}
+}
+
+func (c *SeatMapSyncController) GetSyncRuns(ctx *gin.Context) {
+	var query PageQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	page, err := c.syncService.SyncRuns(query.pagination())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+// TriggerSync menjalankan sync seat map sekarang dan mengembalikan hasilnya. Sync tetap
+// diselesaikan walaupun client memutus koneksi.
+func (c *SeatMapSyncController) TriggerSync(ctx *gin.Context) {
+	actorID := ctx.GetUint("userID")
+	run, err := c.syncService.Sync(context.WithoutCancel(ctx.Request.Context()), model.SyncTriggerManual, &actorID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, run)
+}
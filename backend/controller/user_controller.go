@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tiananugerah/go-BookCabin/service"
+)
+
+type UserController struct {
+	userService *service.UserService
+	authService *service.AuthService
+}
+
+func NewUserController(userService *service.UserService, authService *service.AuthService) *UserController {
+	return &UserController{userService: userService, authService: authService}
+}
+
+func (c *UserController) GetMe(ctx *gin.Context) {
+	profile, err := c.userService.GetProfile(ctx.GetUint("userID"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, profile)
+}
+
+type UpdateProfileRequest struct {
+	Name   *string `json:"name" binding:"omitempty,max=255"`
+	Locale *string `json:"locale"`
+}
+
+func (c *UserController) UpdateMe(ctx *gin.Context) {
+	var req UpdateProfileRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	profile, err := c.userService.UpdateProfile(ctx.GetUint("userID"), service.ProfileUpdate{Name: req.Name, Locale: req.Locale})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, profile)
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+func (c *UserController) ChangePassword(ctx *gin.Context) {
+	var req ChangePasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	if err := c.authService.ChangePassword(ctx.GetUint("userID"), req.CurrentPassword, req.NewPassword); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "password changed successfully"})
+}
+
+type ChangeEmailRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewEmail        string `json:"new_email" binding:"required,email"`
+}
+
+// ChangeEmail mengirim link konfirmasi ke email baru; email akun belum berubah sampai link dibuka
+func (c *UserController) ChangeEmail(ctx *gin.Context) {
+	var req ChangeEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	if err := c.authService.RequestEmailChange(ctx.GetUint("userID"), req.CurrentPassword, req.NewEmail); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"message": "a confirmation link has been sent to the new email address"})
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+func (c *UserController) DeleteMe(ctx *gin.Context) {
+	var req DeleteAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	if err := c.userService.DeleteAccount(ctx.GetUint("userID"), req.Password); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "account deleted successfully"})
+}
@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/service"
+)
+
+type WaitlistController struct {
+	waitlistService *service.WaitlistService
+}
+
+func NewWaitlistController(waitlistService *service.WaitlistService) *WaitlistController {
+	return &WaitlistController{waitlistService: waitlistService}
+}
+
+type JoinWaitlistRequest struct {
+	FlightID uint   `json:"flight_id" binding:"required"`
+	SeatID   *uint  `json:"seat_id"`
+	SeatType string `json:"seat_type" binding:"omitempty,oneof=window aisle"`
+	Cabin    string `json:"cabin"`
+}
+
+func (c *WaitlistController) JoinWaitlist(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+
+	var req JoinWaitlistRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	entry, err := c.waitlistService.JoinWaitlist(userID, model.WaitlistEntry{
+		FlightID: req.FlightID,
+		SeatID:   req.SeatID,
+		SeatType: req.SeatType,
+		Cabin:    req.Cabin,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, entry)
+}
+
+func (c *WaitlistController) GetUserWaitlist(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+
+	entries, err := c.waitlistService.GetUserWaitlist(userID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
+
+func (c *WaitlistController) LeaveWaitlist(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+
+	entryID, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if err := c.waitlistService.LeaveWaitlist(userID, entryID); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "left waitlist successfully"})
+}
@@ -0,0 +1,229 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/repository"
+	"github.com/tiananugerah/go-BookCabin/service"
+)
+
+type WebhookController struct {
+	webhookService *service.WebhookService
+}
+
+func NewWebhookController(webhookService *service.WebhookService) *WebhookController {
+	return &WebhookController{webhookService: webhookService}
+}
+
+type WebhookRequest struct {
+	Name   string   `json:"name" binding:"required,max=255"`
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events" binding:"required"`
+	// Active default true saat endpoint dibuat; tidak diubah jika tidak diisi saat update
+	Active *bool `json:"active"`
+	// Reason dicatat di audit log saat endpoint diubah
+	Reason string `json:"reason"`
+}
+
+func (r WebhookRequest) input() service.WebhookInput {
+	return service.WebhookInput{Name: r.Name, URL: r.URL, Events: r.Events, Active: r.Active}
+}
+
+func (c *WebhookController) GetWebhooks(ctx *gin.Context) {
+	endpoints, err := c.webhookService.ListWebhooks()
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, endpoints)
+}
+
+func (c *WebhookController) GetWebhook(ctx *gin.Context) {
+	id, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	endpoint, err := c.webhookService.GetWebhook(id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, endpoint)
+}
+
+// CreateWebhook mendaftarkan endpoint; secret untuk verifikasi signature hanya ada di respons ini
+func (c *WebhookController) CreateWebhook(ctx *gin.Context) {
+	var req WebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	endpoint, err := c.webhookService.CreateWebhook(ctx.GetUint("userID"), req.input())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, endpoint)
+}
+
+func (c *WebhookController) UpdateWebhook(ctx *gin.Context) {
+	id, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var req WebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	endpoint, err := c.webhookService.UpdateWebhook(ctx.GetUint("userID"), id, req.input(), req.Reason)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, endpoint)
+}
+
+func (c *WebhookController) DeleteWebhook(ctx *gin.Context) {
+	id, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var req ReasonRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	if err := c.webhookService.DeleteWebhook(ctx.GetUint("userID"), id, req.Reason); err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "webhook deleted successfully"})
+}
+
+func (c *WebhookController) RotateSecret(ctx *gin.Context) {
+	id, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var req ReasonRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	endpoint, err := c.webhookService.RotateWebhookSecret(ctx.GetUint("userID"), id, req.Reason)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, endpoint)
+}
+
+// ReplayDeadDeliveries mengirim ulang semua delivery endpoint yang ada di dead-letter queue
+func (c *WebhookController) ReplayDeadDeliveries(ctx *gin.Context) {
+	id, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var req ReasonRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	replayed, err := c.webhookService.ReplayDeadDeliveries(ctx.GetUint("userID"), id, req.Reason)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"replayed": replayed})
+}
+
+type WebhookDeliveryQuery struct {
+	PageQuery
+	EndpointID uint   `form:"endpoint_id"`
+	Event      string `form:"event"`
+	Status     string `form:"status" binding:"omitempty,oneof=pending delivered dead"`
+}
+
+func (c *WebhookController) GetDeliveries(ctx *gin.Context) {
+	var query WebhookDeliveryQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	page, err := c.webhookService.ListDeliveries(repository.WebhookDeliveryFilter{
+		EndpointID: query.EndpointID,
+		Event:      query.Event,
+		Status:     model.WebhookDeliveryStatus(query.Status),
+	}, query.pagination())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, page)
+}
+
+func (c *WebhookController) GetDelivery(ctx *gin.Context) {
+	id, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	delivery, err := c.webhookService.GetDelivery(id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, delivery)
+}
+
+func (c *WebhookController) ReplayDelivery(ctx *gin.Context) {
+	id, err := idParam(ctx, "id")
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	var req ReasonRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.Error(bindingError(err))
+		return
+	}
+
+	delivery, err := c.webhookService.ReplayDelivery(ctx.GetUint("userID"), id, req.Reason)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, delivery)
+}
@@ -0,0 +1,112 @@
+package event
+
+import (
+	"sync"
+	"time"
+)
+
+type Type string
+
+const (
+	BookingCreated   Type = "booking.created"
+	BookingConfirmed Type = "booking.confirmed"
+	BookingCancelled Type = "booking.cancelled"
+	SeatHeld         Type = "seat.held"
+	SeatReleased     Type = "seat.released"
+	// BookingSeatChanged dipublikasikan untuk kursi baru saat booking dipindahkan oleh admin
+	BookingSeatChanged Type = "booking.seat_changed"
+	SeatBlocked        Type = "seat.blocked"
+	SeatUnblocked      Type = "seat.unblocked"
+	// HoldExpiring dipublikasikan sekali per hold saat batas konfirmasinya sudah dekat
+	HoldExpiring Type = "booking.hold_expiring"
+	// SeatMapImported dipublikasikan per penerbangan setelah seat map-nya diimpor atau diganti
+	SeatMapImported Type = "seatmap.imported"
+)
+
+// Event adalah perubahan domain yang dipublikasikan ke subscriber
+type Event struct {
+	Type       Type      `json:"type"`
+	FlightID   uint      `json:"flight_id,omitempty"`
+	SeatID     uint      `json:"seat_id,omitempty"`
+	SeatCode   string    `json:"seat_code,omitempty"`
+	Available  bool      `json:"available"`
+	BookingID  uint      `json:"booking_id,omitempty"`
+	UserID     uint      `json:"user_id,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// Bus adalah pub/sub untuk event domain. Implementasi bawaan adalah MemoryBus
+// (satu proses); PostgresBus menyebarkan event ke semua replika lewat LISTEN/NOTIFY.
+type Bus interface {
+	Publish(e Event) error
+	// Subscribe mengembalikan channel event yang lolos filter (nil = semua event)
+	// dan fungsi untuk berhenti berlangganan.
+	Subscribe(filter func(Event) bool) (<-chan Event, func())
+}
+
+// subscriberBuffer adalah jumlah event yang ditahan untuk subscriber yang lambat
+// sebelum event berikutnya dibuang.
+const subscriberBuffer = 64
+
+type subscriber struct {
+	ch     chan Event
+	filter func(Event) bool
+}
+
+type MemoryBus struct {
+	mu          sync.RWMutex
+	nextID      int
+	subscribers map[int]*subscriber
+}
+
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{subscribers: map[int]*subscriber{}}
+}
+
+func (b *MemoryBus) Publish(e Event) error {
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, sub := range b.subscribers {
+		if sub.filter != nil && !sub.filter(e) {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			// Subscriber terlalu lambat; event dibuang agar publisher tidak ikut tertahan
+		}
+	}
+	return nil
+}
+
+func (b *MemoryBus) Subscribe(filter func(Event) bool) (<-chan Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	sub := &subscriber{ch: make(chan Event, subscriberBuffer), filter: filter}
+	b.subscribers[id] = sub
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, id)
+			b.mu.Unlock()
+			close(sub.ch)
+		})
+	}
+}
+
+// ForFlight adalah filter Subscribe untuk event milik satu penerbangan
+func ForFlight(flightID uint) func(Event) bool {
+	return func(e Event) bool {
+		return e.FlightID == flightID
+	}
+}
@@ -0,0 +1,87 @@
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+// receive menunggu satu event dari ch atau gagal setelah batas waktu
+func receive(t *testing.T, ch <-chan Event) Event {
+	t.Helper()
+	select {
+	case e, ok := <-ch:
+		if !ok {
+			t.Fatal("subscription channel was closed")
+		}
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+	}
+	return Event{}
+}
+
+func TestMemoryBusPublishSubscribe(t *testing.T) {
+	bus := NewMemoryBus()
+	all, unsubscribeAll := bus.Subscribe(nil)
+	defer unsubscribeAll()
+	flight, unsubscribeFlight := bus.Subscribe(ForFlight(7))
+	defer unsubscribeFlight()
+
+	if err := bus.Publish(Event{Type: SeatHeld, FlightID: 3, SeatID: 1}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if err := bus.Publish(Event{Type: SeatReleased, FlightID: 7, SeatID: 2, Available: true}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	if e := receive(t, all); e.Type != SeatHeld || e.OccurredAt.IsZero() {
+		t.Errorf("first event = %+v, want seat.held with a timestamp", e)
+	}
+	if e := receive(t, all); e.Type != SeatReleased {
+		t.Errorf("second event = %s, want seat.released", e.Type)
+	}
+
+	// Filter ForFlight hanya meneruskan event milik penerbangan 7
+	if e := receive(t, flight); e.FlightID != 7 || e.SeatID != 2 || !e.Available {
+		t.Errorf("flight event = %+v, want seat 2 on flight 7", e)
+	}
+	select {
+	case e := <-flight:
+		t.Errorf("unexpected event for flight subscriber: %+v", e)
+	default:
+	}
+}
+
+func TestMemoryBusUnsubscribe(t *testing.T) {
+	bus := NewMemoryBus()
+	events, unsubscribe := bus.Subscribe(nil)
+
+	unsubscribe()
+	// Unsubscribe boleh dipanggil lebih dari sekali
+	unsubscribe()
+
+	if _, ok := <-events; ok {
+		t.Error("channel still open after unsubscribe")
+	}
+	if err := bus.Publish(Event{Type: SeatHeld}); err != nil {
+		t.Fatalf("Publish after unsubscribe: %v", err)
+	}
+	if n := len(bus.subscribers); n != 0 {
+		t.Errorf("bus still has %d subscribers", n)
+	}
+}
+
+func TestMemoryBusDropsEventsForSlowSubscriber(t *testing.T) {
+	bus := NewMemoryBus()
+	events, unsubscribe := bus.Subscribe(nil)
+	defer unsubscribe()
+
+	for i := 0; i < subscriberBuffer+10; i++ {
+		if err := bus.Publish(Event{Type: SeatHeld, SeatID: uint(i)}); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+	if n := len(events); n != subscriberBuffer {
+		t.Errorf("buffered %d events, want %d", n, subscriberBuffer)
+	}
+}
@@ -0,0 +1,87 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+// PostgresChannel adalah channel LISTEN/NOTIFY yang dipakai PostgresBus
+const PostgresChannel = "bookcabin_events"
+
+// PostgresBus mengirim event lewat pg_notify sehingga setiap replika yang
+// LISTEN pada channel yang sama menerima event tersebut, termasuk replika
+// pengirim sendiri. Subscriber lokal dilayani oleh MemoryBus.
+type PostgresBus struct {
+	db    *gorm.DB
+	dsn   string
+	local *MemoryBus
+}
+
+func NewPostgresBus(db *gorm.DB, dsn string) *PostgresBus {
+	return &PostgresBus{db: db, dsn: dsn, local: NewMemoryBus()}
+}
+
+func (b *PostgresBus) Publish(e Event) error {
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now()
+	}
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return b.db.Exec("SELECT pg_notify(?, ?)", PostgresChannel, string(payload)).Error
+}
+
+func (b *PostgresBus) Subscribe(filter func(Event) bool) (<-chan Event, func()) {
+	return b.local.Subscribe(filter)
+}
+
+// Listen menerima notifikasi dari Postgres dan meneruskannya ke subscriber lokal.
+// Koneksi dibuka ulang dengan jeda jika terputus, sampai ctx dibatalkan.
+func (b *PostgresBus) Listen(ctx context.Context) {
+	backoff := time.Second
+	for ctx.Err() == nil {
+		if err := b.listen(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("event bus: listen failed, retrying in %s: %v", backoff, err)
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+			}
+			if backoff < 30*time.Second {
+				backoff *= 2
+			}
+		}
+	}
+}
+
+func (b *PostgresBus) listen(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, b.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+PostgresChannel); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var e Event
+		if err := json.Unmarshal([]byte(notification.Payload), &e); err != nil {
+			log.Printf("event bus: dropping malformed notification: %v", err)
+			continue
+		}
+		b.local.Publish(e)
+	}
+}
@@ -1,15 +1,25 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+	// Database zona IANA ikut dalam binary; image alpine tidak membawa tzdata
+	_ "time/tzdata"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
+	"github.com/tiananugerah/go-BookCabin/event"
+	"github.com/tiananugerah/go-BookCabin/mail"
 	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/ratelimit"
+	"github.com/tiananugerah/go-BookCabin/repository"
 	"github.com/tiananugerah/go-BookCabin/router"
 	"github.com/tiananugerah/go-BookCabin/service"
 )
@@ -26,36 +36,197 @@ func main() {
 		log.Fatal("DATABASE_URL environment variable is required")
 	}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	// Akun yang sudah ada sebelum verifikasi email diperkenalkan dianggap terverifikasi
+	backfillVerified := !db.Migrator().HasColumn(&model.User{}, "EmailVerifiedAt")
+	// Kursi kosong yang tidak available sebelum ada state block berasal dari data sumber
+	backfillBlocks := db.Migrator().HasTable(&model.Seat{}) && !db.Migrator().HasColumn(&model.Seat{}, "BlockReason")
+	// Booking lama tidak punya diskon sehingga harga awalnya sama dengan harga final
+	backfillOriginalPrice := db.Migrator().HasTable(&model.Booking{}) && !db.Migrator().HasColumn(&model.Booking{}, "OriginalPrice")
+
+	// Kode kursi sekarang unik per penerbangan, bukan lagi unik di seluruh tabel
+	if db.Migrator().HasTable(&model.Seat{}) {
+		for _, constraint := range []string{"seats_seat_code_key", "uni_seats_seat_code"} {
+			if err := db.Exec("ALTER TABLE seats DROP CONSTRAINT IF EXISTS " + constraint).Error; err != nil {
+				log.Fatalf("Failed to drop seat code constraint: %v", err)
+			}
+		}
+	}
+
 	// Auto migrate database
-	if err := db.AutoMigrate(&model.User{}, &model.Seat{}, &model.Booking{}); err != nil {
+	if err := db.AutoMigrate(&model.User{}, &model.UserToken{}, &model.Flight{}, &model.Seat{}, &model.Booking{}, &model.WaitlistEntry{}, &model.AuditLog{}, &model.PriceRuleSet{}, &model.PromoCode{}, &model.PromoRedemption{}, &model.FlightPassenger{}, &model.EligibilityRuleSet{}, &model.SeatOffer{}, &model.SeatAssignment{}, &model.SeatMapSync{}, &model.Reaccommodation{}, &model.Airport{}, &model.Notification{}, &model.WebhookEndpoint{}, &model.WebhookDelivery{}); err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 
+	if backfillVerified {
+		if err := db.Model(&model.User{}).Where("email_verified_at IS NULL").Update("email_verified_at", gorm.Expr("created_at")).Error; err != nil {
+			log.Fatalf("Failed to backfill email verification: %v", err)
+		}
+	}
+	if backfillBlocks {
+		err := db.Model(&model.Seat{}).
+			Where("available = ? AND id NOT IN (SELECT seat_id FROM bookings WHERE status IN ? AND deleted_at IS NULL)", false, model.ActiveStatuses).
+			Updates(map[string]interface{}{"block_reason": "UNAVAILABLE_IN_SOURCE", "block_source": model.SeatBlockImport}).Error
+		if err != nil {
+			log.Fatalf("Failed to backfill seat blocks: %v", err)
+		}
+	}
+	if backfillOriginalPrice {
+		if err := db.Model(&model.Booking{}).Where("original_price = 0").Update("original_price", gorm.Expr("price")).Error; err != nil {
+			log.Fatalf("Failed to backfill booking original prices: %v", err)
+		}
+	}
+
 	// Initialize services
 	jwtKey := []byte(os.Getenv("JWT_SECRET"))
 	if len(jwtKey) == 0 {
 		log.Fatal("JWT_SECRET environment variable is required")
 	}
 
-	authService := service.NewAuthService(db, jwtKey)
-	bookingService := service.NewBookingService(db)
-	seatService := service.NewSeatService(db)
+	// Event bus: in-process secara default, Postgres LISTEN/NOTIFY untuk banyak replika
+	var bus event.Bus = event.NewMemoryBus()
+	if os.Getenv("EVENT_BUS") == "postgres" {
+		pgBus := event.NewPostgresBus(db, dsn)
+		go pgBus.Listen(context.Background())
+		bus = pgBus
+	}
+
+	store := repository.NewGormStore(db)
+	mailer, err := newMailer()
+	if err != nil {
+		log.Fatalf("Failed to initialize mailer: %v", err)
+	}
+
+	// Tabel bandara menyediakan zona waktu untuk jadwal; penerbangan lama yang jamnya masih
+	// jam lokal tanpa zona dibaca ulang setelah bandaranya dikenal
+	airportService := service.NewAirportService(store)
+	airportsFile := os.Getenv("AIRPORTS_FILE")
+	if airportsFile == "" {
+		airportsFile = "data/airports.json"
+	}
+	if added, err := airportService.LoadAirports(airportsFile); err != nil {
+		log.Printf("Warning: airports not loaded from %s: %v", airportsFile, err)
+	} else if added > 0 {
+		log.Printf("Loaded %d airports from %s", added, airportsFile)
+	}
+	if fixed, err := airportService.LocalizeLegacyFlights(); err != nil {
+		log.Fatalf("Failed to localize flight times: %v", err)
+	} else if fixed > 0 {
+		log.Printf("Localized times of %d flights", fixed)
+	}
+
+	authConfig := service.DefaultAuthConfig()
+	authConfig.VerificationTTL = durationEnv("EMAIL_VERIFICATION_TTL", authConfig.VerificationTTL)
+	authConfig.ResetTTL = durationEnv("PASSWORD_RESET_TTL", authConfig.ResetTTL)
+	if baseURL := os.Getenv("APP_BASE_URL"); baseURL != "" {
+		authConfig.AppBaseURL = baseURL
+	}
+	authConfig.PasswordPolicy.MinLength = intEnv("PASSWORD_MIN_LENGTH", authConfig.PasswordPolicy.MinLength)
+	authConfig.PasswordPolicy.RequireUpper = boolEnv("PASSWORD_REQUIRE_UPPER", authConfig.PasswordPolicy.RequireUpper)
+	authConfig.PasswordPolicy.RequireLower = boolEnv("PASSWORD_REQUIRE_LOWER", authConfig.PasswordPolicy.RequireLower)
+	authConfig.PasswordPolicy.RequireDigit = boolEnv("PASSWORD_REQUIRE_DIGIT", authConfig.PasswordPolicy.RequireDigit)
+	authConfig.PasswordPolicy.RequireSymbol = boolEnv("PASSWORD_REQUIRE_SYMBOL", authConfig.PasswordPolicy.RequireSymbol)
+
+	// Rate limit dan lockout login: in-process secara default, Postgres agar counter dibagi antar replika
+	var limitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if os.Getenv("RATE_LIMIT_STORE") == "postgres" {
+		pgStore, err := ratelimit.NewPostgresStore(db)
+		if err != nil {
+			log.Fatalf("Failed to initialize rate limit store: %v", err)
+		}
+		go pgStore.RunPruner(context.Background(), 5*time.Minute)
+		limitStore = pgStore
+	}
+
+	lockoutPolicy := ratelimit.DefaultLockoutPolicy()
+	lockoutPolicy.MaxFailures = intEnv("LOGIN_MAX_FAILURES", lockoutPolicy.MaxFailures)
+	lockoutPolicy.FailureWindow = durationEnv("LOGIN_FAILURE_WINDOW", lockoutPolicy.FailureWindow)
+	lockoutPolicy.BaseDelay = durationEnv("LOGIN_LOCKOUT_BASE", lockoutPolicy.BaseDelay)
+	lockoutPolicy.MaxDelay = durationEnv("LOGIN_LOCKOUT_MAX", lockoutPolicy.MaxDelay)
+
+	authService := service.NewAuthService(store, jwtKey, mailer, ratelimit.NewLockout(limitStore, lockoutPolicy), authConfig)
+	bookingConfig := service.DefaultBookingConfig()
+	bookingConfig.HoldDuration = durationEnv("HOLD_DURATION", bookingConfig.HoldDuration)
+	bookingConfig.HoldReminderBefore = durationEnv("HOLD_REMINDER_BEFORE", bookingConfig.HoldReminderBefore)
+	bookingConfig.HoldCutoff = durationEnv("HOLD_CUTOFF", bookingConfig.HoldCutoff)
+	bookingConfig.CancelCutoff = durationEnv("CANCEL_CUTOFF", bookingConfig.CancelCutoff)
+	bookingConfig.Limits.MaxPerFlight = intEnv("BOOKING_MAX_PER_FLIGHT", bookingConfig.Limits.MaxPerFlight)
+	bookingConfig.Limits.MaxOpenHolds = intEnv("HOLD_MAX_OPEN", bookingConfig.Limits.MaxOpenHolds)
+	bookingConfig.Limits.ExpiryThreshold = intEnv("HOLD_EXPIRY_THRESHOLD", bookingConfig.Limits.ExpiryThreshold)
+	bookingConfig.Limits.ExpiryWindow = durationEnv("HOLD_EXPIRY_WINDOW", bookingConfig.Limits.ExpiryWindow)
+	bookingConfig.Limits.Cooldown = durationEnv("HOLD_COOLDOWN", bookingConfig.Limits.Cooldown)
+	pricingService := service.NewPricingService(store)
+	bookingService := service.NewBookingService(store, bus, pricingService, bookingConfig)
+	seatService := service.NewSeatService(store, bus)
+	waitlistService := service.NewWaitlistService(store)
+
+	if err := bookingService.BackfillReferences(); err != nil {
+		log.Fatalf("Failed to backfill booking references: %v", err)
+	}
+
+	checkInConfig := service.DefaultCheckInConfig()
+	checkInConfig.OpensBefore = durationEnv("CHECKIN_OPENS_BEFORE", checkInConfig.OpensBefore)
+	checkInConfig.ClosesBefore = durationEnv("CHECKIN_CLOSES_BEFORE", checkInConfig.ClosesBefore)
+	checkInService := service.NewCheckInService(store, checkInConfig)
+
+	// Lepas hold yang kedaluwarsa dan tawarkan kursinya ke waitlist
+	go bookingService.RunHoldSweeper(context.Background(), durationEnv("HOLD_SWEEP_INTERVAL", 30*time.Second))
+
+	// Notifikasi booking lewat email, inbox in-app, dan webhook bertanda tangan jika
+	// NOTIFY_WEBHOOK_URL diisi
+	notificationConfig := service.DefaultNotificationConfig()
+	notificationConfig.RetryBase = durationEnv("NOTIFICATION_RETRY_BASE", notificationConfig.RetryBase)
+	notificationConfig.RetryMax = durationEnv("NOTIFICATION_RETRY_MAX", notificationConfig.RetryMax)
+	notificationConfig.MaxAttempts = intEnv("NOTIFICATION_MAX_ATTEMPTS", notificationConfig.MaxAttempts)
+	notificationChannels := []service.NotificationChannel{service.NewEmailChannel(mailer), service.NewInAppChannel()}
+	if webhookURL := os.Getenv("NOTIFY_WEBHOOK_URL"); webhookURL != "" {
+		webhookSecret := os.Getenv("NOTIFY_WEBHOOK_SECRET")
+		if webhookSecret == "" {
+			log.Fatal("NOTIFY_WEBHOOK_SECRET environment variable is required when NOTIFY_WEBHOOK_URL is set")
+		}
+		notificationChannels = append(notificationChannels, &service.WebhookChannel{URL: webhookURL, Secret: webhookSecret})
+	}
+	notificationService := service.NewNotificationService(store, notificationConfig, notificationChannels...)
+	go notificationService.Run(context.Background(), bus)
+	go notificationService.RunDelivery(context.Background(), durationEnv("NOTIFICATION_DELIVERY_INTERVAL", 15*time.Second))
+
+	// Webhook partner yang didaftarkan admin lewat /admin/webhooks
+	webhookConfig := service.DefaultWebhookConfig()
+	webhookConfig.RetryBase = durationEnv("WEBHOOK_RETRY_BASE", webhookConfig.RetryBase)
+	webhookConfig.RetryMax = durationEnv("WEBHOOK_RETRY_MAX", webhookConfig.RetryMax)
+	webhookConfig.MaxAttempts = intEnv("WEBHOOK_MAX_ATTEMPTS", webhookConfig.MaxAttempts)
+	webhookConfig.Timeout = durationEnv("WEBHOOK_TIMEOUT", webhookConfig.Timeout)
+	webhookService := service.NewWebhookService(store, webhookConfig)
+	go webhookService.Run(context.Background(), bus)
+	go webhookService.RunDelivery(context.Background(), durationEnv("WEBHOOK_DELIVERY_INTERVAL", 15*time.Second))
+
+	// Tarik seat map dari upstream secara berkala jika SEATMAP_SYNC_URL atau SEATMAP_SYNC_DIR diisi
+	seatMapSyncService := service.NewSeatMapSyncService(store, seatService, newSeatMapUpstream())
+	if interval := durationEnv("SEATMAP_SYNC_INTERVAL", 15*time.Minute); seatMapSyncService.Enabled() && interval > 0 {
+		go seatMapSyncService.RunScheduler(context.Background(), interval)
+	}
 
 	// Initialize Gin router
 	r := gin.Default()
 
+	// Rate limit per IP memakai ClientIP; hanya percayai X-Forwarded-For dari proxy yang dikenal
+	if proxies := os.Getenv("TRUSTED_PROXIES"); proxies != "" {
+		if err := r.SetTrustedProxies(strings.Split(proxies, ",")); err != nil {
+			log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+		}
+	}
+
 	// Setup CORS
 	r.Use(gin.Recovery())
 	r.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -66,7 +237,33 @@ func main() {
 	})
 
 	// Setup routes
-	router.SetupRoutes(r, authService, bookingService, seatService)
+	rateLimits := router.DefaultRateLimits()
+	rateLimits.Auth = limitEnv("RATE_LIMIT_AUTH", rateLimits.Auth)
+	rateLimits.Public = limitEnv("RATE_LIMIT_PUBLIC", rateLimits.Public)
+	rateLimits.API = limitEnv("RATE_LIMIT_API", rateLimits.API)
+	rateLimits.Booking = limitEnv("RATE_LIMIT_BOOKING", rateLimits.Booking)
+	rateLimits.Admin = limitEnv("RATE_LIMIT_ADMIN", rateLimits.Admin)
+
+	router.SetupRoutes(r, router.Dependencies{
+		AuthService:         authService,
+		BookingService:      bookingService,
+		SeatService:         seatService,
+		WaitlistService:     waitlistService,
+		CheckInService:      checkInService,
+		UserService:         service.NewUserService(store, authService, bookingService),
+		AdminService:        service.NewAdminService(store, bus, bookingService),
+		PricingService:      pricingService,
+		PromoService:        service.NewPromoService(store),
+		EligibilityService:  service.NewEligibilityService(store),
+		SeatMapSyncService:  seatMapSyncService,
+		EquipmentService:    service.NewEquipmentService(store, seatService, bookingService, mailer),
+		FlightService:       service.NewFlightService(store),
+		AirportService:      airportService,
+		NotificationService: notificationService,
+		WebhookService:      webhookService,
+		Limiter:             ratelimit.NewLimiter(limitStore),
+		RateLimits:          rateLimits,
+	})
 
 	// Start server
 	port := os.Getenv("APP_PORT")
@@ -77,4 +274,103 @@ func main() {
 	if err := r.Run(":" + port); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
-}
\ No newline at end of file
+}
+
+// durationEnv membaca durasi (format time.ParseDuration) dari environment variable
+func durationEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Warning: invalid %s %q, using %s", key, value, fallback)
+		return fallback
+	}
+	return d
+}
+
+// newMailer memilih implementasi Mailer dari MAILER: smtp, file, atau log (default)
+func newMailer() (mail.Mailer, error) {
+	switch os.Getenv("MAILER") {
+	case "smtp":
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		return mail.NewSMTPMailer(mail.SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("SMTP_FROM"),
+		}), nil
+	case "file":
+		dir := os.Getenv("MAIL_DIR")
+		if dir == "" {
+			dir = "mail-outbox"
+		}
+		return mail.NewFileMailer(dir)
+	}
+	return mail.NewLogMailer(), nil
+}
+
+// newSeatMapUpstream memilih upstream seat map: SEATMAP_SYNC_URL (dipisah koma) atau
+// SEATMAP_SYNC_DIR. nil berarti sync seat map dinonaktifkan.
+func newSeatMapUpstream() service.SeatMapUpstream {
+	if urls := os.Getenv("SEATMAP_SYNC_URL"); urls != "" {
+		return &service.HTTPSeatMapUpstream{
+			URLs:   strings.Split(urls, ","),
+			Format: os.Getenv("SEATMAP_SYNC_FORMAT"),
+			Token:  os.Getenv("SEATMAP_SYNC_TOKEN"),
+		}
+	}
+	if dir := os.Getenv("SEATMAP_SYNC_DIR"); dir != "" {
+		return &service.DirSeatMapUpstream{Dir: dir}
+	}
+	return nil
+}
+
+func intEnv(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Warning: invalid %s %q, using %d", key, value, fallback)
+		return fallback
+	}
+	return n
+}
+
+func boolEnv(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Warning: invalid %s %q, using %t", key, value, fallback)
+		return fallback
+	}
+	return b
+}
+
+// limitEnv membaca rate limit dengan format "<requests>/<durasi>", misalnya "10/1m"
+func limitEnv(key string, fallback ratelimit.Limit) ratelimit.Limit {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	limit, err := ratelimit.ParseLimit(value)
+	if err != nil {
+		log.Printf("Warning: invalid %s %q, using %s", key, value, fallback)
+		return fallback
+	}
+	return limit
+}
@@ -1,7 +1,6 @@
 package middleware
 
 import (
-	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -13,21 +12,28 @@ func AuthMiddleware(authService *service.AuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authorization := c.GetHeader("Authorization")
 		if authorization == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "authorization header is required"})
+			c.Error(service.ErrAuthorizationRequired)
 			c.Abort()
 			return
 		}
 
 		parts := strings.Split(authorization, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization header format"})
+			c.Error(service.ErrAuthorizationRequired.WithDetail("invalid authorization header format"))
 			c.Abort()
 			return
 		}
 
 		claims, err := authService.ValidateToken(parts[1])
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		// Token milik akun yang sudah dihapus tidak berlaku lagi meskipun belum expired
+		if _, err := authService.GetUser(claims.UserID); err != nil {
+			c.Error(err)
 			c.Abort()
 			return
 		}
@@ -35,4 +41,28 @@ func AuthMiddleware(authService *service.AuthService) gin.HandlerFunc {
 		c.Set("userID", claims.UserID)
 		c.Next()
 	}
-}
\ No newline at end of file
+}
+
+// RequireRole membatasi route untuk user dengan salah satu role yang diberikan.
+// Harus dipasang setelah AuthMiddleware.
+func RequireRole(authService *service.AuthService, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := authService.GetUser(c.GetUint("userID"))
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		for _, role := range roles {
+			if user.Role == role {
+				c.Set("userRole", user.Role)
+				c.Next()
+				return
+			}
+		}
+
+		c.Error(service.ErrForbidden)
+		c.Abort()
+	}
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/tiananugerah/go-BookCabin/service"
+)
+
+// Problem adalah body error sesuai RFC 7807 (application/problem+json)
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+	// Error dipertahankan agar client lama yang membaca field "error" tetap berjalan
+	Error string `json:"error"`
+}
+
+type FieldError struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
+}
+
+var kindStatus = map[service.ErrorKind]int{
+	service.KindBadRequest:      http.StatusBadRequest,
+	service.KindUnauthorized:    http.StatusUnauthorized,
+	service.KindForbidden:       http.StatusForbidden,
+	service.KindNotFound:        http.StatusNotFound,
+	service.KindConflict:        http.StatusConflict,
+	service.KindUnprocessable:   http.StatusUnprocessableEntity,
+	service.KindTooManyRequests: http.StatusTooManyRequests,
+}
+
+// ErrorHandler menerjemahkan error yang dicatat handler lewat ctx.Error
+// menjadi response problem+json dengan status code yang sesuai.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		problem := NewProblem(c.Errors.Last().Err)
+		problem.Instance = c.Request.URL.Path
+		if problem.Status == http.StatusInternalServerError {
+			log.Printf("internal error on %s %s: %v", c.Request.Method, c.Request.URL.Path, c.Errors.Last().Err)
+		}
+
+		var domainErr *service.Error
+		if errors.As(c.Errors.Last().Err, &domainErr) && domainErr.RetryAfter > 0 {
+			c.Header("Retry-After", retryAfterSeconds(domainErr.RetryAfter))
+		}
+
+		c.Header("Content-Type", "application/problem+json")
+		c.JSON(problem.Status, problem)
+	}
+}
+
+// NewProblem memetakan error menjadi Problem. Error yang tidak dikenal
+// disembunyikan di balik pesan generik agar detail database tidak bocor.
+func NewProblem(err error) Problem {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		problem := problemFor(service.ErrValidationFailed)
+		for _, fe := range validationErrs {
+			problem.Errors = append(problem.Errors, FieldError{Field: fe.Field(), Rule: fe.Tag()})
+		}
+		return problem
+	}
+
+	var domainErr *service.Error
+	if errors.As(err, &domainErr) {
+		return problemFor(domainErr)
+	}
+
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(http.StatusInternalServerError),
+		Status: http.StatusInternalServerError,
+		Detail: "internal server error",
+		Code:   "internal_error",
+		Error:  "internal server error",
+	}
+}
+
+func problemFor(err *service.Error) Problem {
+	status, ok := kindStatus[err.Kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	return Problem{
+		Type:   "/problems/" + err.Code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: err.Message,
+		Code:   err.Code,
+		Error:  err.Message,
+	}
+}
+
+// retryAfterSeconds membulatkan durasi ke atas dalam detik (minimal 1) untuk header Retry-After
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(int(max(1, math.Ceil(d.Seconds()))))
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/tiananugerah/go-BookCabin/ratelimit"
+	"github.com/tiananugerah/go-BookCabin/service"
+)
+
+// KeyFunc menentukan identitas yang dibatasi untuk sebuah request
+type KeyFunc func(c *gin.Context) string
+
+// ByIP membatasi request per alamat IP client
+func ByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// ByUser membatasi request per user yang login, dengan fallback ke IP.
+// Harus dipasang setelah AuthMiddleware.
+func ByUser(c *gin.Context) string {
+	if userID := c.GetUint("userID"); userID != 0 {
+		return fmt.Sprintf("user:%d", userID)
+	}
+	return ByIP(c)
+}
+
+// RateLimit menolak request dengan 429 dan header Retry-After jika key melewati
+// limit. name memisahkan counter antar route group. Jika store bermasalah,
+// request tetap diteruskan agar gangguan limiter tidak mematikan API.
+func RateLimit(limiter *ratelimit.Limiter, name string, limit ratelimit.Limit, key KeyFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		decision, err := limiter.Allow(name+":"+key(c), limit)
+		if err != nil {
+			log.Printf("rate limit: %s check failed, allowing request: %v", name, err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(limit.Requests))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(decision.ResetAt.Unix(), 10))
+
+		if !decision.Allowed {
+			c.Error(service.ErrRateLimited.WithRetryAfter(decision.RetryAfter))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
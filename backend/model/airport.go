@@ -0,0 +1,46 @@
+package model
+
+import (
+	"sync"
+	"time"
+)
+
+// Airport adalah data referensi bandara. TimeZone adalah nama zona IANA (misalnya "Asia/Jakarta")
+// yang dipakai untuk membaca jam lokal pada seat map dan menampilkan jadwal.
+type Airport struct {
+	Code      string    `json:"code" gorm:"primaryKey;type:varchar(3)"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Name      string    `json:"name"`
+	City      string    `json:"city"`
+	Country   string    `json:"country" gorm:"type:varchar(2)"`
+	TimeZone  string    `json:"time_zone" gorm:"not null"`
+}
+
+var zoneCache sync.Map
+
+// LoadZone mengembalikan lokasi untuk nama zona IANA; hasilnya di-cache karena
+// time.LoadLocation membaca database zona setiap kali dipanggil
+func LoadZone(name string) (*time.Location, error) {
+	if loc, ok := zoneCache.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	zoneCache.Store(name, loc)
+	return loc, nil
+}
+
+// InZone mengubah t ke zona IANA; zona kosong atau tidak dikenal menghasilkan UTC
+func InZone(t time.Time, zone string) time.Time {
+	if zone == "" {
+		return t.UTC()
+	}
+	loc, err := LoadZone(zone)
+	if err != nil {
+		return t.UTC()
+	}
+	return t.In(loc)
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// JSONMap adalah objek JSON bebas yang disimpan sebagai jsonb
+type JSONMap map[string]interface{}
+
+func (m JSONMap) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return json.Marshal(m)
+}
+
+func (m *JSONMap) Scan(value interface{}) error {
+	if value == nil {
+		*m = nil
+		return nil
+	}
+	return json.Unmarshal(value.([]byte), m)
+}
+
+// AuditLog mencatat satu aksi admin beserta alasan dan detail perubahannya
+type AuditLog struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt  time.Time `json:"created_at" gorm:"index"`
+	ActorID    uint      `json:"actor_id" gorm:"not null;index"`
+	Action     string    `json:"action" gorm:"type:varchar(50);not null;index"`
+	TargetType string    `json:"target_type" gorm:"type:varchar(30);not null;index:idx_audit_target"`
+	TargetID   uint      `json:"target_id" gorm:"not null;index:idx_audit_target"`
+	Reason     string    `json:"reason,omitempty"`
+	Details    JSONMap   `json:"details,omitempty" gorm:"type:jsonb"`
+}
@@ -12,19 +12,48 @@ const (
 	StatusPending   BookingStatus = "pending"
 	StatusConfirmed BookingStatus = "confirmed"
 	StatusCancelled BookingStatus = "cancelled"
+	// StatusExpired dipakai untuk hold yang tidak dikonfirmasi sebelum HoldExpiresAt
+	StatusExpired BookingStatus = "expired"
 )
 
+// ActiveStatuses adalah status booking yang masih menempati kursi
+var ActiveStatuses = []BookingStatus{StatusPending, StatusConfirmed}
+
+// Booking menempati satu kursi. Index idx_bookings_active_seat menjamin paling banyak satu booking
+// aktif per kursi walaupun dua request lolos pengecekan kursi kosong bersamaan.
 type Booking struct {
-	ID        uint           `gorm:"primaryKey"`
+	ID        uint `gorm:"primaryKey"`
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt `gorm:"index"`
-	UserID    uint           `gorm:"not null"`
-	User      User           `gorm:"foreignKey:UserID"`
-	SeatID    uint           `gorm:"not null"`
-	Seat      Seat           `gorm:"foreignKey:SeatID"`
-	Status    BookingStatus  `gorm:"type:varchar(20);not null;default:'pending'"`
-	BookedAt  time.Time      `gorm:"not null"`
-	Price     float64        `gorm:"not null"`
-	Currency  string         `gorm:"not null"`
-}
\ No newline at end of file
+	// Reference adalah record locator 6 karakter (gaya PNR) yang ditunjukkan ke penumpang
+	Reference string        `gorm:"size:6;uniqueIndex"`
+	UserID    uint          `gorm:"not null"`
+	User      User          `gorm:"foreignKey:UserID"`
+	SeatID    uint          `gorm:"not null;uniqueIndex:idx_bookings_active_seat,where:status IN ('pending'\\,'confirmed') AND deleted_at IS NULL"`
+	Seat      Seat          `gorm:"foreignKey:SeatID"`
+	Status    BookingStatus `gorm:"type:varchar(20);not null;default:'pending'"`
+	BookedAt  time.Time     `gorm:"not null"`
+	// Price adalah harga akhir setelah entitlement dan diskon promo; OriginalPrice adalah
+	// harga kursi sebelum keduanya
+	Price         float64 `gorm:"not null"`
+	OriginalPrice float64
+	// EntitlementRuleID adalah rule yang membebaskan atau memotong biaya kursi untuk penumpang
+	EntitlementRuleID   string
+	EntitlementDiscount float64
+	Discount            float64
+	PromoCode           string `gorm:"size:32"`
+	Currency            string `gorm:"not null"`
+	// HoldExpiresAt diisi untuk booking pending (hold); setelah lewat, hold dilepas
+	HoldExpiresAt *time.Time `gorm:"index"`
+	CheckedInAt   *time.Time
+	// BoardingSequence adalah nomor urut check-in per penerbangan, dimulai dari 1
+	BoardingSequence int
+	// HoldRemindedAt diisi saat pengingat hold yang hampir kedaluwarsa sudah dipublikasikan
+	HoldRemindedAt *time.Time
+}
+
+// IsHoldExpired melaporkan apakah hold sudah melewati batas konfirmasi pada waktu now
+func (b *Booking) IsHoldExpired(now time.Time) bool {
+	return b.Status == StatusPending && b.HoldExpiresAt != nil && !now.Before(*b.HoldExpiresAt)
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// Tipe penumpang yang dipakai rule kelayakan kursi
+const (
+	PassengerAdult  = "ADT"
+	PassengerChild  = "CHD"
+	PassengerInfant = "INF"
+	// PassengerUnaccompaniedMinor adalah anak yang terbang tanpa pendamping (SSR UMNR)
+	PassengerUnaccompaniedMinor = "UMNR"
+)
+
+type EligibilityRuleKind string
+
+const (
+	// EligibilityDeny melarang penumpang duduk di kursi yang cocok dengan kondisi rule
+	EligibilityDeny EligibilityRuleKind = "deny"
+	// EligibilityOnly hanya mengizinkan penumpang duduk di kursi yang cocok dengan kondisi rule
+	EligibilityOnly EligibilityRuleKind = "only"
+	// EligibilityMaxPerRow membatasi jumlah penumpang dengan tipe tersebut dalam satu baris
+	EligibilityMaxPerRow EligibilityRuleKind = "max_per_row"
+	// EligibilityMaxPerGroup membatasi jumlah penumpang dengan tipe tersebut dalam satu
+	// kelompok kursi di antara lorong (satu blok masker oksigen)
+	EligibilityMaxPerGroup EligibilityRuleKind = "max_per_group"
+)
+
+// EligibilityRule adalah satu aturan kelayakan kursi untuk tipe penumpang tertentu.
+// Kondisi kursi yang kosong tidak dipakai; untuk rule max_* kondisi membatasi kursi yang dihitung.
+type EligibilityRule struct {
+	ID             string              `json:"id"`
+	Kind           EligibilityRuleKind `json:"kind"`
+	PassengerTypes []string            `json:"passenger_types"`
+	// Attributes cocok jika kursi memiliki salah satunya di characteristics, designations atau limitations
+	Attributes []string `json:"attributes,omitempty"`
+	Cabins     []string `json:"cabins,omitempty"`
+	MinRow     *int     `json:"min_row,omitempty"`
+	MaxRow     *int     `json:"max_row,omitempty"`
+	Max        int      `json:"max,omitempty"`
+	// Message menggantikan pesan error bawaan jika diisi
+	Message string `json:"message,omitempty"`
+}
+
+// EligibilityRules adalah daftar rule kelayakan yang disimpan sebagai jsonb
+type EligibilityRules []EligibilityRule
+
+func (r EligibilityRules) Value() (driver.Value, error) {
+	if r == nil {
+		return json.Marshal([]EligibilityRule{})
+	}
+	return json.Marshal(r)
+}
+
+func (r *EligibilityRules) Scan(value interface{}) error {
+	if value == nil {
+		*r = EligibilityRules{}
+		return nil
+	}
+	return json.Unmarshal(value.([]byte), r)
+}
+
+// EligibilityRuleSet adalah rule kelayakan kursi milik satu maskapai. Maskapai tanpa
+// rule set memakai rule bawaan.
+type EligibilityRuleSet struct {
+	ID          uint             `json:"id" gorm:"primaryKey"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+	AirlineCode string           `json:"airline_code" gorm:"size:3;not null;uniqueIndex"`
+	Rules       EligibilityRules `json:"rules" gorm:"type:jsonb;not null"`
+	UpdatedBy   uint             `json:"updated_by"`
+}
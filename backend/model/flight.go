@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Flight struct {
+	ID           uint           `json:"id" gorm:"primaryKey"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
+	AirlineCode  string         `json:"airline_code" gorm:"not null;uniqueIndex:idx_flight_designator"`
+	FlightNumber int            `json:"flight_number" gorm:"not null;uniqueIndex:idx_flight_designator"`
+	// Departure dan Arrival disimpan sebagai waktu UTC; DepartureZone dan ArrivalZone adalah
+	// zona IANA bandara asal dan tujuan untuk menampilkan jam lokal
+	Departure         time.Time `json:"departure" gorm:"not null;uniqueIndex:idx_flight_designator"`
+	Arrival           time.Time `json:"arrival"`
+	DepartureZone     string    `json:"departure_zone"`
+	ArrivalZone       string    `json:"arrival_zone"`
+	Origin            string    `json:"origin" gorm:"not null"`
+	Destination       string    `json:"destination" gorm:"not null"`
+	DepartureTerminal string    `json:"departure_terminal"`
+	ArrivalTerminal   string    `json:"arrival_terminal"`
+	Equipment         string    `json:"equipment"`
+	Seats             []Seat    `json:"-" gorm:"foreignKey:FlightID"`
+}
+
+// LocalDeparture adalah jam berangkat menurut zona bandara asal
+func (f Flight) LocalDeparture() time.Time {
+	return InZone(f.Departure, f.DepartureZone)
+}
+
+// LocalArrival adalah jam tiba menurut zona bandara tujuan
+func (f Flight) LocalArrival() time.Time {
+	return InZone(f.Arrival, f.ArrivalZone)
+}
@@ -0,0 +1,46 @@
+package model
+
+import "time"
+
+type NotificationStatus string
+
+const (
+	NotificationPending NotificationStatus = "pending"
+	NotificationSent    NotificationStatus = "sent"
+	// NotificationFailed berarti pengiriman tetap gagal setelah batas percobaan
+	NotificationFailed NotificationStatus = "failed"
+	// NotificationCancelled berarti notifikasi tidak dikirim karena akun user sudah dihapus
+	NotificationCancelled NotificationStatus = "cancelled"
+)
+
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelInApp   = "in_app"
+)
+
+// Notification adalah satu pesan untuk user lewat satu channel. Notifikasi in_app sekaligus
+// menjadi isi inbox user. DedupKey + Channel unik agar event yang diterima beberapa replika
+// hanya menghasilkan satu notifikasi.
+type Notification struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"-"`
+	UserID    uint      `json:"-" gorm:"not null;index"`
+	BookingID uint      `json:"booking_id,omitempty" gorm:"index"`
+	// Event adalah tipe event domain pemicu, misalnya "booking.created"
+	Event    string `json:"event" gorm:"not null"`
+	Channel  string `json:"-" gorm:"type:varchar(20);not null;uniqueIndex:idx_notification_dedup"`
+	DedupKey string `json:"-" gorm:"not null;uniqueIndex:idx_notification_dedup"`
+	Locale   string `json:"locale" gorm:"type:varchar(10)"`
+	Subject  string `json:"subject"`
+	Body     string `json:"body"`
+	// Recipient adalah alamat email atau URL webhook tujuan; kosong untuk in_app
+	Recipient     string             `json:"-"`
+	Status        NotificationStatus `json:"-" gorm:"type:varchar(20);not null;default:'pending';index"`
+	Attempts      int                `json:"-" gorm:"not null;default:0"`
+	NextAttemptAt *time.Time         `json:"-" gorm:"index"`
+	LastError     string             `json:"-"`
+	SentAt        *time.Time         `json:"-"`
+	ReadAt        *time.Time         `json:"read_at,omitempty"`
+}
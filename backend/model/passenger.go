@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// FlightPassenger adalah penumpang yang tercantum pada dokumen seat map sebuah penerbangan.
+// Data frequent flyer dan kelas tarifnya dipakai untuk menentukan entitlement kursi, sedangkan
+// tipe penumpang, tanggal lahir dan SSR dipakai untuk kelayakan kursi;
+// penumpang dicocokkan dengan user berdasarkan email.
+type FlightPassenger struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	CreatedAt      time.Time  `json:"created_at"`
+	FlightID       uint       `json:"flight_id" gorm:"not null;uniqueIndex:idx_flight_passenger"`
+	PassengerIndex int        `json:"passenger_index" gorm:"not null;uniqueIndex:idx_flight_passenger"`
+	NameNumber     string     `json:"name_number"`
+	FirstName      string     `json:"first_name"`
+	LastName       string     `json:"last_name"`
+	Email          string     `json:"email" gorm:"index"`
+	Type           string     `json:"type" gorm:"type:varchar(10)"`
+	DateOfBirth    *time.Time `json:"date_of_birth,omitempty" gorm:"type:date"`
+	// SpecialRequests adalah kode SSR penumpang, misalnya UMNR
+	SpecialRequests      StringArray `json:"special_requests" gorm:"type:jsonb"`
+	FrequentFlyerAirline string      `json:"frequent_flyer_airline,omitempty"`
+	FrequentFlyerNumber  string      `json:"frequent_flyer_number,omitempty"`
+	FrequentFlyerTier    int         `json:"frequent_flyer_tier"`
+	BookingClass         string      `json:"booking_class" gorm:"type:varchar(2)"`
+	FareBasis            string      `json:"fare_basis"`
+}
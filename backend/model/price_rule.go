@@ -0,0 +1,91 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// PriceRule menyesuaikan harga kursi yang cocok dengan semua kondisinya.
+// Kondisi yang kosong tidak dipakai; harga baru = harga * Multiplier + Amount.
+type PriceRule struct {
+	Name string `json:"name"`
+	// Cabins dicocokkan dengan Seat.Segment, misalnya ECONOMY
+	Cabins []string `json:"cabins,omitempty"`
+	// Attributes harus dimiliki semua oleh kursi, dicari di characteristics dan designations
+	// (misalnya W untuk window, L untuk extra legroom, FRONT_OF_CABIN)
+	Attributes []string `json:"attributes,omitempty"`
+	// Load factor penerbangan antara 0 dan 1 (booking aktif dibagi jumlah kursi)
+	MinLoadFactor *float64 `json:"min_load_factor,omitempty"`
+	MaxLoadFactor *float64 `json:"max_load_factor,omitempty"`
+	// Jam tersisa sampai keberangkatan
+	MinHoursToDeparture *float64 `json:"min_hours_to_departure,omitempty"`
+	MaxHoursToDeparture *float64 `json:"max_hours_to_departure,omitempty"`
+	// Multiplier 0 dianggap 1
+	Multiplier float64 `json:"multiplier,omitempty"`
+	Amount     float64 `json:"amount,omitempty"`
+}
+
+// EntitlementRule membebaskan atau memotong biaya kursi untuk penumpang yang cocok dengan
+// semua kondisinya. Kondisi penumpang diambil dari data FlightPassenger pada penerbangan.
+type EntitlementRule struct {
+	// ID dicatat di booking sebagai rule entitlement yang dipakai
+	ID string `json:"id"`
+	// MinTier adalah tier frequent flyer minimum
+	MinTier *int `json:"min_tier,omitempty"`
+	// BookingClasses dicocokkan persis, FareBases dicocokkan sebagai awalan fare basis
+	BookingClasses []string `json:"booking_classes,omitempty"`
+	FareBases      []string `json:"fare_bases,omitempty"`
+	Cabins         []string `json:"cabins,omitempty"`
+	Attributes     []string `json:"attributes,omitempty"`
+	// Percent adalah potongan dari harga kursi; 0 dianggap 100 (gratis)
+	Percent float64 `json:"percent,omitempty"`
+}
+
+// EntitlementRules adalah daftar rule entitlement yang disimpan sebagai jsonb
+type EntitlementRules []EntitlementRule
+
+func (r EntitlementRules) Value() (driver.Value, error) {
+	if r == nil {
+		return json.Marshal([]EntitlementRule{})
+	}
+	return json.Marshal(r)
+}
+
+func (r *EntitlementRules) Scan(value interface{}) error {
+	if value == nil {
+		*r = EntitlementRules{}
+		return nil
+	}
+	return json.Unmarshal(value.([]byte), r)
+}
+
+// PriceRules adalah daftar rule berurutan yang disimpan sebagai jsonb
+type PriceRules []PriceRule
+
+func (r PriceRules) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+func (r *PriceRules) Scan(value interface{}) error {
+	if value == nil {
+		*r = PriceRules{}
+		return nil
+	}
+	return json.Unmarshal(value.([]byte), r)
+}
+
+// PriceRuleSet adalah satu versi rule harga. Versi tidak pernah diubah setelah dibuat;
+// perubahan rule dilakukan dengan membuat versi baru lalu mengaktifkannya.
+type PriceRuleSet struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+	Version   int       `json:"version" gorm:"not null;uniqueIndex"`
+	CreatedBy uint      `json:"created_by" gorm:"not null"`
+	Note      string    `json:"note,omitempty"`
+	// ActivatedAt terbaru menentukan versi yang sedang berlaku
+	ActivatedAt *time.Time `json:"activated_at,omitempty" gorm:"index"`
+	Rules       PriceRules `json:"rules" gorm:"type:jsonb;not null"`
+	// Entitlements dievaluasi per penumpang setelah rule harga
+	Entitlements EntitlementRules `json:"entitlements" gorm:"type:jsonb"`
+}
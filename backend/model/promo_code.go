@@ -0,0 +1,74 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DiscountType string
+
+const (
+	DiscountPercent DiscountType = "percent"
+	DiscountFixed   DiscountType = "fixed"
+)
+
+// CurrencyAmounts adalah nominal per mata uang (misalnya {"MYR": 20}) yang disimpan sebagai jsonb
+type CurrencyAmounts map[string]float64
+
+func (a CurrencyAmounts) Value() (driver.Value, error) {
+	if a == nil {
+		return json.Marshal(map[string]float64{})
+	}
+	return json.Marshal(a)
+}
+
+func (a *CurrencyAmounts) Scan(value interface{}) error {
+	if value == nil {
+		*a = CurrencyAmounts{}
+		return nil
+	}
+	return json.Unmarshal(value.([]byte), a)
+}
+
+// PromoCode adalah kode diskon untuk biaya pemilihan kursi.
+// Diskon percent memakai Percent dan dibatasi MaxDiscount per mata uang (jika ada);
+// diskon fixed memakai Amounts dan hanya berlaku untuk mata uang yang terdaftar.
+type PromoCode struct {
+	ID           uint            `json:"id" gorm:"primaryKey"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt  `json:"-" gorm:"index"`
+	Code         string          `json:"code" gorm:"size:32;not null;uniqueIndex:idx_promo_codes_code,where:deleted_at IS NULL"`
+	Description  string          `json:"description,omitempty"`
+	DiscountType DiscountType    `json:"discount_type" gorm:"type:varchar(10);not null"`
+	Percent      float64         `json:"percent,omitempty"`
+	Amounts      CurrencyAmounts `json:"amounts,omitempty" gorm:"type:jsonb"`
+	MaxDiscount  CurrencyAmounts `json:"max_discount,omitempty" gorm:"type:jsonb"`
+	// MaxRedemptions 0 berarti tanpa batas; Redemptions hanya menghitung pemakaian yang masih berlaku
+	MaxRedemptions int        `json:"max_redemptions"`
+	Redemptions    int        `json:"redemptions"`
+	ValidFrom      *time.Time `json:"valid_from,omitempty"`
+	ValidUntil     *time.Time `json:"valid_until,omitempty"`
+	// Cabins dan SeatTypes kosong berarti berlaku untuk semua kursi
+	Cabins    StringArray `json:"cabins" gorm:"type:jsonb"`
+	SeatTypes StringArray `json:"seat_types" gorm:"type:jsonb"`
+	Active    bool        `json:"active" gorm:"not null"`
+}
+
+// PromoRedemption mencatat pemakaian kode promo pada satu booking. Pemakaian dilepas (ReleasedAt)
+// jika booking dibatalkan atau hold kedaluwarsa, sehingga user bisa memakai kodenya lagi.
+type PromoRedemption struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	CreatedAt     time.Time  `json:"created_at"`
+	PromoCodeID   uint       `json:"promo_code_id" gorm:"not null;uniqueIndex:idx_promo_user,where:released_at IS NULL"`
+	UserID        uint       `json:"user_id" gorm:"not null;uniqueIndex:idx_promo_user,where:released_at IS NULL"`
+	BookingID     uint       `json:"booking_id" gorm:"not null;index"`
+	Currency      string     `json:"currency" gorm:"not null"`
+	OriginalPrice float64    `json:"original_price"`
+	Discount      float64    `json:"discount"`
+	FinalPrice    float64    `json:"final_price"`
+	ReleasedAt    *time.Time `json:"released_at,omitempty"`
+}
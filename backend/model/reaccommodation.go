@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+type ReaccommodationStatus string
+
+const (
+	ReaccommodationOpen ReaccommodationStatus = "open"
+	// ReaccommodationResolved berarti booking sudah dipindahkan ke kursi baru
+	ReaccommodationResolved ReaccommodationStatus = "resolved"
+	// ReaccommodationCancelled berarti booking dibatalkan atau hold-nya kedaluwarsa
+	ReaccommodationCancelled ReaccommodationStatus = "cancelled"
+)
+
+// Reaccommodation adalah booking yang tidak bisa dipetakan otomatis ke kursi pada layout baru
+// setelah pergantian pesawat. Booking tetap aktif di kursi lamanya sampai dipindahkan admin.
+type Reaccommodation struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	FlightID  uint      `json:"flight_id" gorm:"not null;index"`
+	BookingID uint      `json:"booking_id" gorm:"not null;index"`
+	UserID    uint      `json:"user_id" gorm:"not null"`
+	// SeatCode dan Cabin adalah kursi booking sebelum pergantian pesawat
+	SeatCode       string                `json:"seat_code" gorm:"not null"`
+	Cabin          string                `json:"cabin"`
+	Reason         string                `json:"reason"`
+	Status         ReaccommodationStatus `json:"status" gorm:"type:varchar(20);not null;default:'open';index"`
+	ResolvedAt     *time.Time            `json:"resolved_at,omitempty"`
+	ResolvedSeatID *uint                 `json:"resolved_seat_id,omitempty"`
+}
@@ -25,20 +25,63 @@ func (a *StringArray) Scan(value interface{}) error {
 	return json.Unmarshal(value.([]byte), a)
 }
 
+const (
+	// SeatBlockImport dipakai untuk kursi yang tidak dijual menurut data seat map sumber
+	SeatBlockImport = "import"
+	// SeatBlockOps dipakai untuk kursi yang dikeluarkan dari penjualan oleh admin
+	SeatBlockOps = "ops"
+)
+
+// SeatBlock menandai kursi yang tidak boleh dijual, terpisah dari status booking.
+// Block dengan BlockedUntil yang sudah lewat dianggap tidak berlaku.
+type SeatBlock struct {
+	BlockReason  string     `json:"block_reason,omitempty"`
+	BlockSource  string     `json:"block_source,omitempty" gorm:"type:varchar(20)"`
+	BlockedBy    *uint      `json:"blocked_by,omitempty"`
+	BlockedAt    *time.Time `json:"blocked_at,omitempty"`
+	BlockedUntil *time.Time `json:"blocked_until,omitempty" gorm:"index"`
+}
+
+// IsBlocked melaporkan apakah block masih berlaku pada waktu now
+func (b SeatBlock) IsBlocked(now time.Time) bool {
+	return b.BlockReason != "" && (b.BlockedUntil == nil || now.Before(*b.BlockedUntil))
+}
+
+// SeatEntitlement adalah penanda biaya kursi dari dokumen seat map sumber. FeeWaived dan
+// Entitled dengan rule ID berlaku untuk penumpang yang tercantum pada dokumen tersebut,
+// sedangkan FreeOfCharge berarti kursi gratis untuk semua penumpang.
+type SeatEntitlement struct {
+	Entitled        bool   `json:"entitled"`
+	EntitledRuleID  string `json:"entitled_rule_id,omitempty"`
+	FeeWaived       bool   `json:"fee_waived"`
+	FeeWaivedRuleID string `json:"fee_waived_rule_id,omitempty"`
+	FreeOfCharge    bool   `json:"free_of_charge"`
+}
+
+// Seat adalah kursi pada satu penerbangan. Available berarti kursi bisa dijual saat ini:
+// tidak punya booking aktif dan tidak diblokir.
 type Seat struct {
-	ID        uint           `gorm:"primaryKey"`
+	ID        uint `gorm:"primaryKey"`
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt `gorm:"index"`
-	SeatCode  string         `json:"code" gorm:"unique;not null"`
-	Available bool           `json:"available" gorm:"default:true"`
+	FlightID  uint           `json:"flight_id" gorm:"index;uniqueIndex:idx_flight_seat_code"`
+	SeatCode  string         `json:"code" gorm:"not null;uniqueIndex:idx_flight_seat_code"`
+	Available bool           `json:"available" gorm:"not null"`
 	Price     float64        `json:"price" gorm:"not null"`
 	Currency  string         `json:"currency" gorm:"not null"`
 	RowNumber int            `json:"row" gorm:"not null"`
-	Segment   string         `json:"segment" gorm:"not null"`
-	IsWindow  bool           `json:"is_window" gorm:"default:false"`
-	IsAisle   bool           `json:"is_aisle" gorm:"default:false"`
-	Aircraft  string         `json:"aircraft" gorm:"not null"`
+	// ColumnGroup adalah urutan kelompok kursi di antara lorong pada baris (0 = paling kiri)
+	ColumnGroup     int         `json:"column_group"`
+	Segment         string      `json:"segment" gorm:"not null"`
+	IsWindow        bool        `json:"is_window" gorm:"default:false"`
+	IsAisle         bool        `json:"is_aisle" gorm:"default:false"`
+	Aircraft        string      `json:"aircraft" gorm:"not null"`
 	Characteristics StringArray `json:"characteristics" gorm:"type:jsonb"`
-	Bookings  []Booking      `gorm:"foreignKey:SeatID"`
+	Designations    StringArray `json:"designations" gorm:"type:jsonb"`
+	Limitations     StringArray `json:"limitations" gorm:"type:jsonb"`
+	Bookings        []Booking   `gorm:"foreignKey:SeatID"`
+
+	SeatBlock       `gorm:"embedded"`
+	SeatEntitlement `gorm:"embedded"`
 }
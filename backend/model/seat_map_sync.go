@@ -0,0 +1,54 @@
+package model
+
+import "time"
+
+type SeatMapSyncStatus string
+
+const (
+	SyncRunning   SeatMapSyncStatus = "running"
+	SyncSucceeded SeatMapSyncStatus = "succeeded"
+	// SyncPartial berarti sebagian dokumen gagal diimpor; dokumen lain tetap tersimpan
+	SyncPartial SeatMapSyncStatus = "partial"
+	SyncFailed  SeatMapSyncStatus = "failed"
+)
+
+const (
+	SyncTriggerScheduled = "scheduled"
+	SyncTriggerManual    = "manual"
+)
+
+// SeatMapDiff menghitung perubahan inventory kursi akibat import seat map
+type SeatMapDiff struct {
+	Added     int `json:"added"`
+	Updated   int `json:"updated"`
+	Removed   int `json:"removed"`
+	Unchanged int `json:"unchanged"`
+}
+
+// Add menjumlahkan diff lain ke d
+func (d *SeatMapDiff) Add(other SeatMapDiff) {
+	d.Added += other.Added
+	d.Updated += other.Updated
+	d.Removed += other.Removed
+	d.Unchanged += other.Unchanged
+}
+
+// SeatMapSync adalah catatan satu kali sinkronisasi seat map dari upstream provider
+type SeatMapSync struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	StartedAt  time.Time  `json:"started_at" gorm:"not null;index"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Trigger    string     `json:"trigger" gorm:"type:varchar(20);not null"`
+	// TriggeredBy adalah admin yang menjalankan sync manual
+	TriggeredBy *uint             `json:"triggered_by,omitempty"`
+	Source      string            `json:"source" gorm:"not null"`
+	Status      SeatMapSyncStatus `json:"status" gorm:"type:varchar(20);not null"`
+	// Documents adalah jumlah dokumen yang diambil; Failed adalah yang gagal diimpor
+	Documents int `json:"documents"`
+	Failed    int `json:"failed"`
+	Flights   int `json:"flights"`
+	// Error berisi pesan kegagalan per dokumen, satu per baris
+	Error string `json:"error,omitempty" gorm:"type:text"`
+
+	SeatMapDiff `gorm:"embedded"`
+}
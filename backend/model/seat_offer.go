@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// SeatOffer adalah ketersediaan dan harga satu kursi untuk satu penumpang menurut seat map
+// penumpang tersebut pada dokumen sumber. Harga dan entitlement bisa berbeda antar penumpang.
+type SeatOffer struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt      time.Time `json:"created_at"`
+	FlightID       uint      `json:"flight_id" gorm:"not null;index"`
+	SeatID         uint      `json:"seat_id" gorm:"not null;uniqueIndex:idx_seat_offer"`
+	PassengerIndex int       `json:"passenger_index" gorm:"not null;uniqueIndex:idx_seat_offer"`
+	SeatCode       string    `json:"seat_code" gorm:"not null"`
+	Available      bool      `json:"available"`
+	Price          float64   `json:"price"`
+	Currency       string    `json:"currency"`
+
+	SeatEntitlement `gorm:"embedded"`
+}
+
+// SeatAssignment adalah kursi yang sudah dipilih penumpang di sistem sumber (selectedSeats).
+// Kursi yang punya assignment tidak dijual walaupun tidak ada booking di kursi tersebut.
+type SeatAssignment struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt      time.Time `json:"created_at"`
+	FlightID       uint      `json:"flight_id" gorm:"not null;uniqueIndex:idx_seat_assignment_passenger"`
+	PassengerIndex int       `json:"passenger_index" gorm:"not null;uniqueIndex:idx_seat_assignment_passenger"`
+	SeatID         uint      `json:"seat_id" gorm:"not null;uniqueIndex"`
+	SeatCode       string    `json:"seat_code" gorm:"not null"`
+}
@@ -6,13 +6,28 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	RoleCustomer = "customer"
+	RoleSupport  = "support"
+	RoleAdmin    = "admin"
+	// RoleAgent adalah akun travel agent yang memesan atas nama banyak penumpang
+	RoleAgent = "agent"
+)
+
 type User struct {
-	ID        uint           `gorm:"primaryKey"`
+	ID        uint `gorm:"primaryKey"`
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt `gorm:"index"`
 	Email     string         `gorm:"unique;not null"`
 	Password  string         `gorm:"not null"`
 	Name      string         `gorm:"not null"`
-	Bookings  []Booking      `gorm:"foreignKey:UserID"`
-}
\ No newline at end of file
+	Role      string         `gorm:"type:varchar(20);not null;default:'customer'"`
+	// EmailVerifiedAt nil berarti email belum diverifikasi dan user belum bisa login
+	EmailVerifiedAt *time.Time
+	// BookingLimitExempt membebaskan akun agent dari batas booking dan hold; diatur oleh admin
+	BookingLimitExempt bool `gorm:"not null;default:false"`
+	// Locale adalah bahasa notifikasi untuk user, misalnya "en" atau "id"
+	Locale   string    `gorm:"type:varchar(10);not null;default:'en'"`
+	Bookings []Booking `gorm:"foreignKey:UserID"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+const (
+	TokenEmailVerification = "email_verification"
+	TokenPasswordReset     = "password_reset"
+	TokenEmailChange       = "email_change"
+)
+
+// UserToken adalah token sekali pakai yang dikirim lewat email. Yang disimpan
+// hanya hash SHA-256 dari token sehingga isi tabel tidak bisa dipakai langsung.
+type UserToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time  `json:"created_at"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	Purpose   string     `json:"purpose" gorm:"type:varchar(30);not null"`
+	TokenHash string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	// NewEmail adalah alamat tujuan untuk token email_change
+	NewEmail string `json:"new_email,omitempty"`
+}
+
+// IsUsable mengecek apakah token belum dipakai dan belum kedaluwarsa
+func (t *UserToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type WaitlistStatus string
+
+const (
+	WaitlistWaiting   WaitlistStatus = "waiting"
+	WaitlistOffered   WaitlistStatus = "offered"
+	WaitlistFulfilled WaitlistStatus = "fulfilled"
+	WaitlistExpired   WaitlistStatus = "expired"
+	WaitlistCancelled WaitlistStatus = "cancelled"
+)
+
+const (
+	SeatTypeWindow = "window"
+	SeatTypeAisle  = "aisle"
+)
+
+// WaitlistEntry adalah antrean user untuk kursi tertentu, tipe kursi, atau kabin
+// pada satu penerbangan. Kriteria yang kosong tidak dipakai saat mencocokkan kursi.
+type WaitlistEntry struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+	UserID        uint           `json:"user_id" gorm:"not null;index"`
+	FlightID      uint           `json:"flight_id" gorm:"not null;index"`
+	SeatID        *uint          `json:"seat_id,omitempty"`
+	SeatType      string         `json:"seat_type,omitempty"`
+	Cabin         string         `json:"cabin,omitempty"`
+	Status        WaitlistStatus `json:"status" gorm:"type:varchar(20);not null;default:'waiting'"`
+	HoldBookingID *uint          `json:"hold_booking_id,omitempty"`
+	OfferedAt     *time.Time     `json:"offered_at,omitempty"`
+	Position      int            `json:"position,omitempty" gorm:"-"`
+}
+
+// SameCriteria melaporkan apakah dua entry mengantre untuk hal yang sama
+func (w *WaitlistEntry) SameCriteria(other *WaitlistEntry) bool {
+	return w.FlightID == other.FlightID &&
+		equalSeatID(w.SeatID, other.SeatID) &&
+		w.SeatType == other.SeatType &&
+		w.Cabin == other.Cabin
+}
+
+// Matches melaporkan apakah kursi memenuhi kriteria entry
+func (w *WaitlistEntry) Matches(seat *Seat) bool {
+	if seat.FlightID != w.FlightID {
+		return false
+	}
+	if w.SeatID != nil && *w.SeatID != seat.ID {
+		return false
+	}
+	if w.Cabin != "" && w.Cabin != seat.Segment {
+		return false
+	}
+	switch w.SeatType {
+	case SeatTypeWindow:
+		return seat.IsWindow
+	case SeatTypeAisle:
+		return seat.IsAisle
+	}
+	return true
+}
+
+func equalSeatID(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// WebhookEndpoint adalah endpoint sistem partner yang menerima event terpilih. Setiap payload
+// ditandatangani HMAC-SHA256 dengan Secret milik endpoint.
+type WebhookEndpoint struct {
+	ID        uint        `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	Name      string      `json:"name" gorm:"not null"`
+	URL       string      `json:"url" gorm:"not null"`
+	Events    StringArray `json:"events" gorm:"type:jsonb"`
+	// Secret hanya ditampilkan sekali saat endpoint dibuat atau secret-nya diganti
+	Secret string `json:"-" gorm:"not null"`
+	// Active false menghentikan pembuatan delivery baru; delivery yang sudah ada tetap dikirim
+	Active    bool `json:"active" gorm:"not null"`
+	CreatedBy uint `json:"created_by"`
+}
+
+// Subscribes melaporkan apakah endpoint aktif dan berlangganan tipe event
+func (e *WebhookEndpoint) Subscribes(eventType string) bool {
+	return e.Active && slices.Contains(e.Events, eventType)
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookPending   WebhookDeliveryStatus = "pending"
+	WebhookDelivered WebhookDeliveryStatus = "delivered"
+	// WebhookDead berarti delivery masuk dead-letter queue setelah batas percobaan dan hanya
+	// dikirim ulang lewat replay oleh admin
+	WebhookDead WebhookDeliveryStatus = "dead"
+)
+
+// WebhookDelivery adalah satu event untuk satu endpoint beserta status pengirimannya.
+// EndpointID + EventKey unik agar event yang diterima beberapa replika hanya dikirim sekali.
+type WebhookDelivery struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	EndpointID uint      `json:"endpoint_id" gorm:"not null;uniqueIndex:idx_webhook_delivery_event"`
+	EventKey   string    `json:"-" gorm:"not null;uniqueIndex:idx_webhook_delivery_event"`
+	Event      string    `json:"event" gorm:"not null;index"`
+	// Payload adalah body JSON yang dikirim; sama untuk setiap percobaan dan replay
+	Payload       string                `json:"payload" gorm:"type:text;not null"`
+	Status        WebhookDeliveryStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending';index"`
+	Attempts      int                   `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt *time.Time            `json:"next_attempt_at,omitempty" gorm:"index"`
+	// ResponseStatus dan LastError berasal dari percobaan terakhir
+	ResponseStatus int        `json:"response_status,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	DeadAt         *time.Time `json:"dead_at,omitempty"`
+}
@@ -0,0 +1,74 @@
+package ratelimit
+
+import "time"
+
+// LockoutPolicy mengatur penguncian setelah percobaan gagal berulang.
+// Setelah MaxFailures kegagalan dalam FailureWindow, key dikunci selama
+// BaseDelay, dan durasinya berlipat dua untuk setiap kegagalan berikutnya
+// hingga MaxDelay.
+type LockoutPolicy struct {
+	MaxFailures   int
+	FailureWindow time.Duration
+	BaseDelay     time.Duration
+	MaxDelay      time.Duration
+}
+
+func DefaultLockoutPolicy() LockoutPolicy {
+	return LockoutPolicy{
+		MaxFailures:   5,
+		FailureWindow: time.Hour,
+		BaseDelay:     time.Minute,
+		MaxDelay:      time.Hour,
+	}
+}
+
+type Lockout struct {
+	store  Store
+	policy LockoutPolicy
+}
+
+func NewLockout(store Store, policy LockoutPolicy) *Lockout {
+	return &Lockout{store: store, policy: policy}
+}
+
+// Check mengembalikan sisa waktu penguncian key, atau 0 jika tidak terkunci
+func (l *Lockout) Check(key string) (time.Duration, error) {
+	lock, err := l.store.Get("lock:" + key)
+	if err != nil || lock.Count == 0 {
+		return 0, err
+	}
+	return lock.TTL, nil
+}
+
+// Fail mencatat satu kegagalan dan mengembalikan durasi penguncian jika key sekarang terkunci
+func (l *Lockout) Fail(key string) (time.Duration, error) {
+	failures, err := l.store.Increment("fail:"+key, l.policy.FailureWindow)
+	if err != nil {
+		return 0, err
+	}
+	if failures.Count < l.policy.MaxFailures {
+		return 0, nil
+	}
+
+	delay := l.policy.BaseDelay
+	for i := l.policy.MaxFailures; i < failures.Count && delay < l.policy.MaxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, l.policy.MaxDelay)
+
+	if err := l.store.Reset("lock:" + key); err != nil {
+		return 0, err
+	}
+	if _, err := l.store.Increment("lock:"+key, delay); err != nil {
+		return 0, err
+	}
+	return delay, nil
+}
+
+// Succeed menghapus riwayat kegagalan key setelah percobaan berhasil
+func (l *Lockout) Succeed(key string) error {
+	if err := l.store.Reset("fail:" + key); err != nil {
+		return err
+	}
+	return l.store.Reset("lock:" + key)
+}
@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryStore menyimpan counter di memori proses
+type MemoryStore struct {
+	mu        sync.Mutex
+	counters  map[string]Counter
+	lastPrune time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{counters: map[string]Counter{}, lastPrune: time.Now()}
+}
+
+func (s *MemoryStore) Increment(key string, window time.Duration) (Counter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.prune(now)
+
+	counter, ok := s.counters[key]
+	if !ok || !now.Before(counter.ResetAt) {
+		counter = Counter{ResetAt: now.Add(window)}
+	}
+	counter.Count++
+	s.counters[key] = counter
+	counter.TTL = counter.ResetAt.Sub(now)
+	return counter, nil
+}
+
+func (s *MemoryStore) Get(key string) (Counter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	counter, ok := s.counters[key]
+	if !ok || !now.Before(counter.ResetAt) {
+		return Counter{}, nil
+	}
+	counter.TTL = counter.ResetAt.Sub(now)
+	return counter, nil
+}
+
+func (s *MemoryStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.counters, key)
+	return nil
+}
+
+// prune membuang counter yang window-nya sudah lewat, paling sering sekali per menit
+func (s *MemoryStore) prune(now time.Time) {
+	if now.Sub(s.lastPrune) < time.Minute {
+		return
+	}
+	for key, counter := range s.counters {
+		if !now.Before(counter.ResetAt) {
+			delete(s.counters, key)
+		}
+	}
+	s.lastPrune = now
+}
@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// counterRow adalah baris tabel rate_limit_counters
+type counterRow struct {
+	Key     string    `gorm:"primaryKey"`
+	Count   int       `gorm:"not null"`
+	ResetAt time.Time `gorm:"not null;index"`
+}
+
+func (counterRow) TableName() string {
+	return "rate_limit_counters"
+}
+
+// counterResult adalah counter yang dibaca bersama sisa window-nya menurut now() database
+type counterResult struct {
+	Count      int
+	ResetAt    time.Time
+	TTLSeconds float64
+}
+
+func (r counterResult) counter() Counter {
+	return Counter{Count: r.Count, ResetAt: r.ResetAt, TTL: time.Duration(r.TTLSeconds * float64(time.Second))}
+}
+
+// PostgresStore menyimpan counter di Postgres sehingga limit berlaku
+// gabungan untuk semua replika. Awal window dan sisa waktunya (Counter.TTL)
+// dihitung dengan now() database agar tidak terpengaruh perbedaan jam antar replika.
+type PostgresStore struct {
+	db *gorm.DB
+}
+
+func NewPostgresStore(db *gorm.DB) (*PostgresStore, error) {
+	if err := db.AutoMigrate(&counterRow{}); err != nil {
+		return nil, err
+	}
+	return &PostgresStore{db: db}, nil
+}
+
+func (s *PostgresStore) Increment(key string, window time.Duration) (Counter, error) {
+	var result counterResult
+	err := s.db.Raw(`
+		INSERT INTO rate_limit_counters (key, count, reset_at)
+		VALUES (?, 1, now() + make_interval(secs => ?))
+		ON CONFLICT (key) DO UPDATE SET
+			count = CASE WHEN rate_limit_counters.reset_at <= now() THEN 1 ELSE rate_limit_counters.count + 1 END,
+			reset_at = CASE WHEN rate_limit_counters.reset_at <= now() THEN EXCLUDED.reset_at ELSE rate_limit_counters.reset_at END
+		RETURNING count, reset_at, EXTRACT(EPOCH FROM reset_at - now()) AS ttl_seconds`, key, window.Seconds()).Scan(&result).Error
+	if err != nil {
+		return Counter{}, err
+	}
+	return result.counter(), nil
+}
+
+func (s *PostgresStore) Get(key string) (Counter, error) {
+	var result counterResult
+	query := s.db.Raw(`
+		SELECT count, reset_at, EXTRACT(EPOCH FROM reset_at - now()) AS ttl_seconds
+		FROM rate_limit_counters
+		WHERE key = ? AND reset_at > now()`, key).Scan(&result)
+	if query.Error != nil {
+		return Counter{}, query.Error
+	}
+	if query.RowsAffected == 0 {
+		return Counter{}, nil
+	}
+	return result.counter(), nil
+}
+
+func (s *PostgresStore) Reset(key string) error {
+	return s.db.Where("key = ?", key).Delete(&counterRow{}).Error
+}
+
+// RunPruner menghapus counter kedaluwarsa secara berkala sampai ctx dibatalkan
+func (s *PostgresStore) RunPruner(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.db.Where("reset_at <= now()").Delete(&counterRow{}).Error; err != nil {
+				log.Printf("rate limit: failed to prune counters: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Counter adalah nilai counter fixed window beserta waktu window berakhir
+type Counter struct {
+	Count   int
+	ResetAt time.Time
+	// TTL adalah sisa waktu window menurut jam store, sehingga selisih jam replika
+	// tidak memengaruhi Retry-After
+	TTL time.Duration
+}
+
+// Store menyimpan counter rate limit. MemoryStore cukup untuk satu proses,
+// PostgresStore dipakai agar semua replika berbagi counter yang sama.
+type Store interface {
+	// Increment menambah counter key; window baru dimulai jika window lama sudah lewat
+	Increment(key string, window time.Duration) (Counter, error)
+	// Get mengembalikan counter yang masih berlaku, atau Counter kosong
+	Get(key string) (Counter, error)
+	Reset(key string) error
+}
+
+// Limit adalah jumlah request maksimum dalam satu window
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// ParseLimit membaca limit dengan format "<requests>/<durasi>", misalnya "10/1m"
+func ParseLimit(s string) (Limit, error) {
+	requests, window, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: expected <requests>/<duration>", s)
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(requests))
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: requests must be a positive number", s)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(window))
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: window must be a positive duration", s)
+	}
+	return Limit{Requests: n, Window: d}, nil
+}
+
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.Requests, l.Window)
+}
+
+// Decision adalah hasil pengecekan satu request
+type Decision struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter diisi saat request ditolak: sisa waktu sampai window berikutnya
+	RetryAfter time.Duration
+	ResetAt    time.Time
+}
+
+type Limiter struct {
+	store Store
+}
+
+func NewLimiter(store Store) *Limiter {
+	return &Limiter{store: store}
+}
+
+// Allow mencatat satu request untuk key dan memutuskan apakah masih dalam limit
+func (l *Limiter) Allow(key string, limit Limit) (Decision, error) {
+	counter, err := l.store.Increment(key, limit.Window)
+	if err != nil {
+		return Decision{}, err
+	}
+
+	decision := Decision{
+		Allowed:   counter.Count <= limit.Requests,
+		Remaining: max(0, limit.Requests-counter.Count),
+		ResetAt:   counter.ResetAt,
+	}
+	if !decision.Allowed {
+		decision.RetryAfter = counter.TTL
+	}
+	return decision, nil
+}
@@ -0,0 +1,141 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Limit
+		ok   bool
+	}{
+		{"10/1m", Limit{Requests: 10, Window: time.Minute}, true},
+		{" 5 / 30s ", Limit{Requests: 5, Window: 30 * time.Second}, true},
+		{"10", Limit{}, false},
+		{"0/1m", Limit{}, false},
+		{"-1/1m", Limit{}, false},
+		{"10/soon", Limit{}, false},
+		{"10/0s", Limit{}, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.in)
+		if tt.ok != (err == nil) {
+			t.Errorf("ParseLimit(%q) err = %v, want ok=%v", tt.in, err, tt.ok)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLimit(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLimiterAllowsUpToLimit(t *testing.T) {
+	limiter := NewLimiter(NewMemoryStore())
+	limit := Limit{Requests: 3, Window: time.Minute}
+
+	for i := 1; i <= limit.Requests; i++ {
+		decision, err := limiter.Allow("user:1", limit)
+		if err != nil {
+			t.Fatalf("Allow: %v", err)
+		}
+		if !decision.Allowed || decision.Remaining != limit.Requests-i || decision.RetryAfter != 0 {
+			t.Errorf("request %d = %+v, want allowed with %d remaining", i, decision, limit.Requests-i)
+		}
+	}
+
+	decision, err := limiter.Allow("user:1", limit)
+	if err != nil {
+		t.Fatalf("Allow: %v", err)
+	}
+	if decision.Allowed || decision.Remaining != 0 {
+		t.Errorf("request over limit = %+v, want denied", decision)
+	}
+	if decision.RetryAfter <= 0 || decision.RetryAfter > limit.Window {
+		t.Errorf("RetryAfter = %v, want within the %v window", decision.RetryAfter, limit.Window)
+	}
+
+	// Key lain punya counter sendiri
+	if decision, _ := limiter.Allow("user:2", limit); !decision.Allowed {
+		t.Error("another key was limited by user:1")
+	}
+}
+
+func TestLimiterResetsAfterWindow(t *testing.T) {
+	store := NewMemoryStore()
+	limiter := NewLimiter(store)
+	limit := Limit{Requests: 1, Window: 20 * time.Millisecond}
+
+	if decision, _ := limiter.Allow("ip:1", limit); !decision.Allowed {
+		t.Fatal("first request was denied")
+	}
+	if decision, _ := limiter.Allow("ip:1", limit); decision.Allowed {
+		t.Fatal("second request in the same window was allowed")
+	}
+
+	time.Sleep(limit.Window + 10*time.Millisecond)
+	if counter, _ := store.Get("ip:1"); counter.Count != 0 {
+		t.Errorf("expired counter = %+v, want empty", counter)
+	}
+	if decision, _ := limiter.Allow("ip:1", limit); !decision.Allowed {
+		t.Error("request in a new window was denied")
+	}
+
+	if err := store.Reset("ip:1"); err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	if counter, _ := store.Get("ip:1"); counter.Count != 0 {
+		t.Errorf("counter after reset = %+v, want empty", counter)
+	}
+}
+
+func TestLockoutEscalates(t *testing.T) {
+	policy := LockoutPolicy{MaxFailures: 3, FailureWindow: time.Hour, BaseDelay: time.Minute, MaxDelay: 4 * time.Minute}
+	lockout := NewLockout(NewMemoryStore(), policy)
+
+	for i := 1; i < policy.MaxFailures; i++ {
+		locked, err := lockout.Fail("login:budi")
+		if err != nil {
+			t.Fatalf("Fail: %v", err)
+		}
+		if locked != 0 {
+			t.Errorf("failure %d locked for %v, want not locked", i, locked)
+		}
+	}
+	if locked, _ := lockout.Check("login:budi"); locked != 0 {
+		t.Errorf("Check before lockout = %v, want 0", locked)
+	}
+
+	// Penguncian berlipat dua setiap kegagalan berikutnya sampai MaxDelay
+	for _, want := range []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 4 * time.Minute} {
+		locked, err := lockout.Fail("login:budi")
+		if err != nil {
+			t.Fatalf("Fail: %v", err)
+		}
+		if locked != want {
+			t.Errorf("lockout = %v, want %v", locked, want)
+		}
+		remaining, err := lockout.Check("login:budi")
+		if err != nil {
+			t.Fatalf("Check: %v", err)
+		}
+		if remaining <= want-time.Second || remaining > want {
+			t.Errorf("Check = %v, want about %v", remaining, want)
+		}
+	}
+
+	if locked, _ := lockout.Check("login:sari"); locked != 0 {
+		t.Errorf("unrelated key locked for %v", locked)
+	}
+
+	if err := lockout.Succeed("login:budi"); err != nil {
+		t.Fatalf("Succeed: %v", err)
+	}
+	if locked, _ := lockout.Check("login:budi"); locked != 0 {
+		t.Errorf("Check after success = %v, want 0", locked)
+	}
+	if locked, _ := lockout.Fail("login:budi"); locked != 0 {
+		t.Errorf("first failure after success locked for %v, want failures to start over", locked)
+	}
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+type AirportRepository interface {
+	FindAll() ([]model.Airport, error)
+	FindByCode(code string) (*model.Airport, error)
+	// Save membuat atau mengganti data bandara airport.Code
+	Save(airport *model.Airport) error
+}
+
+type gormAirportRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormAirportRepository) FindAll() ([]model.Airport, error) {
+	var airports []model.Airport
+	err := r.db.Order("code").Find(&airports).Error
+	return airports, err
+}
+
+func (r *gormAirportRepository) FindByCode(code string) (*model.Airport, error) {
+	var airport model.Airport
+	if err := r.db.Where("code = ?", code).First(&airport).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &airport, nil
+}
+
+func (r *gormAirportRepository) Save(airport *model.Airport) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "city", "country", "time_zone", "updated_at"}),
+	}).Create(airport).Error
+	if err != nil {
+		return translateError(err)
+	}
+	return r.db.Where("code = ?", airport.Code).First(airport).Error
+}
+
+type memoryAirportRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryAirportRepository) FindAll() ([]model.Airport, error) {
+	defer r.store.lock()()
+
+	airports := make([]model.Airport, 0, len(r.store.data.airports))
+	for _, airport := range r.store.data.airports {
+		airports = append(airports, airport)
+	}
+	sort.Slice(airports, func(i, j int) bool { return airports[i].Code < airports[j].Code })
+	return airports, nil
+}
+
+func (r *memoryAirportRepository) FindByCode(code string) (*model.Airport, error) {
+	defer r.store.lock()()
+
+	airport, ok := r.store.data.airports[code]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &airport, nil
+}
+
+func (r *memoryAirportRepository) Save(airport *model.Airport) error {
+	defer r.store.lock()()
+
+	if existing, ok := r.store.data.airports[airport.Code]; ok {
+		airport.CreatedAt = existing.CreatedAt
+	}
+	touch(&airport.CreatedAt, &airport.UpdatedAt)
+	r.store.data.airports[airport.Code] = *airport
+	return nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+type AuditFilter struct {
+	ActorID    uint
+	Action     string
+	TargetType string
+	TargetID   uint
+	Offset     int
+	Limit      int
+}
+
+type AuditRepository interface {
+	Create(entry *model.AuditLog) error
+	// Search mengembalikan entry yang cocok dengan filter (terbaru dulu) beserta jumlah totalnya
+	Search(filter AuditFilter) ([]model.AuditLog, int64, error)
+}
+
+type gormAuditRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormAuditRepository) Create(entry *model.AuditLog) error {
+	return translateError(r.db.Create(entry).Error)
+}
+
+func (r *gormAuditRepository) Search(filter AuditFilter) ([]model.AuditLog, int64, error) {
+	query := r.db.Model(&model.AuditLog{})
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != 0 {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []model.AuditLog
+	err := query.Order("id DESC").Offset(filter.Offset).Limit(filter.Limit).Find(&entries).Error
+	return entries, total, err
+}
+
+type memoryAuditRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryAuditRepository) Create(entry *model.AuditLog) error {
+	defer r.store.lock()()
+
+	entry.ID = r.store.data.newID("audit_logs")
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	r.store.data.audit[entry.ID] = *entry
+	return nil
+}
+
+func (r *memoryAuditRepository) Search(filter AuditFilter) ([]model.AuditLog, int64, error) {
+	defer r.store.lock()()
+
+	var matched []model.AuditLog
+	all := sortedByID(r.store.data.audit)
+	for i := len(all) - 1; i >= 0; i-- {
+		entry := all[i]
+		if (filter.ActorID == 0 || entry.ActorID == filter.ActorID) &&
+			(filter.Action == "" || entry.Action == filter.Action) &&
+			(filter.TargetType == "" || entry.TargetType == filter.TargetType) &&
+			(filter.TargetID == 0 || entry.TargetID == filter.TargetID) {
+			matched = append(matched, entry)
+		}
+	}
+	return paginate(matched, filter.Offset, filter.Limit), int64(len(matched)), nil
+}
@@ -0,0 +1,540 @@
+package repository
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+// BookingFilter adalah kriteria pencarian booking lintas user; field kosong tidak dipakai
+type BookingFilter struct {
+	UserID    uint
+	FlightID  uint
+	Status    model.BookingStatus
+	Reference string
+	// Email dicocokkan sebagian (case-insensitive) dengan email pemilik booking
+	Email      string
+	BookedFrom *time.Time
+	BookedTo   *time.Time
+	Offset     int
+	Limit      int
+}
+
+type BookingRepository interface {
+	Create(booking *model.Booking) error
+	// FindByID mengembalikan booking beserta data kursinya
+	FindByID(id uint) (*model.Booking, error)
+	// Lock mengunci baris booking sampai transaksi selesai (SELECT ... FOR UPDATE) sehingga
+	// perubahan status yang bersaing menunggu. Hanya berarti di dalam Transaction.
+	Lock(id uint) error
+	FindByReference(reference string) (*model.Booking, error)
+	// FindWithoutReference mengembalikan booking lama yang dibuat sebelum record locator ada
+	FindWithoutReference() ([]model.Booking, error)
+	// FindActiveBySeat mengembalikan booking confirmed atau hold yang menempati kursi
+	FindActiveBySeat(seatID uint) (*model.Booking, error)
+	FindByUser(userID uint) ([]model.Booking, error)
+	// Search mengembalikan booking yang cocok dengan filter (terbaru dulu) beserta jumlah totalnya
+	Search(filter BookingFilter) ([]model.Booking, int64, error)
+	// FindExpiredHolds mengembalikan hold yang batas konfirmasinya sudah lewat pada waktu now
+	FindExpiredHolds(now time.Time) ([]model.Booking, error)
+	// FindHoldsToRemind mengembalikan hold yang kedaluwarsa sebelum until dan belum diingatkan
+	FindHoldsToRemind(until time.Time) ([]model.Booking, error)
+	// MarkHoldReminded mencatat pengingat hold; false jika hold sudah diingatkan atau bukan hold lagi
+	MarkHoldReminded(id uint, at time.Time) (bool, error)
+	// CountActiveByUserOnFlight menghitung booking confirmed dan hold milik user pada satu penerbangan
+	CountActiveByUserOnFlight(userID, flightID uint) (int, error)
+	// CountActiveOnFlight menghitung booking confirmed dan hold pada satu penerbangan
+	CountActiveOnFlight(flightID uint) (int, error)
+	// FindByFlight mengembalikan semua booking pada penerbangan, termasuk yang sudah selesai
+	FindByFlight(flightID uint) ([]model.Booking, error)
+	// FindActiveOnFlight mengembalikan booking confirmed dan hold pada penerbangan beserta kursi dan pemiliknya
+	FindActiveOnFlight(flightID uint) ([]model.Booking, error)
+	// CountOpenHolds menghitung hold milik user yang belum lewat batas konfirmasi pada waktu now
+	CountOpenHolds(userID uint, now time.Time) (int, error)
+	// FindExpiredByUser mengembalikan hold user yang kedaluwarsa sejak since, urut dari yang paling awal
+	FindExpiredByUser(userID uint, since time.Time) ([]model.Booking, error)
+	UpdateStatus(id uint, status model.BookingStatus) error
+	// Update menyimpan kolom booking tanpa menyentuh relasinya (User, Seat)
+	Update(booking *model.Booking) error
+	// MaxBoardingSequence mengembalikan nomor urut check-in tertinggi pada penerbangan
+	MaxBoardingSequence(flightID uint) (int, error)
+}
+
+type gormBookingRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormBookingRepository) Create(booking *model.Booking) error {
+	return translateError(r.db.Create(booking).Error)
+}
+
+func (r *gormBookingRepository) FindByID(id uint) (*model.Booking, error) {
+	var booking model.Booking
+	if err := r.db.Preload("Seat").First(&booking, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &booking, nil
+}
+
+func (r *gormBookingRepository) FindByReference(reference string) (*model.Booking, error) {
+	var booking model.Booking
+	if err := r.db.Preload("Seat").Where("reference = ?", reference).First(&booking).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &booking, nil
+}
+
+func (r *gormBookingRepository) FindWithoutReference() ([]model.Booking, error) {
+	var bookings []model.Booking
+	err := r.db.Where("reference IS NULL OR reference = ''").Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *gormBookingRepository) FindActiveBySeat(seatID uint) (*model.Booking, error) {
+	var booking model.Booking
+	err := r.db.Where("seat_id = ? AND status IN ?", seatID, model.ActiveStatuses).First(&booking).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &booking, nil
+}
+
+func (r *gormBookingRepository) FindByUser(userID uint) ([]model.Booking, error) {
+	var bookings []model.Booking
+	err := r.db.Where("user_id = ?", userID).Preload("Seat").Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *gormBookingRepository) Search(filter BookingFilter) ([]model.Booking, int64, error) {
+	query := r.db.Model(&model.Booking{})
+	if filter.UserID != 0 {
+		query = query.Where("bookings.user_id = ?", filter.UserID)
+	}
+	if filter.FlightID != 0 {
+		query = query.Where("bookings.seat_id IN (SELECT id FROM seats WHERE flight_id = ?)", filter.FlightID)
+	}
+	if filter.Status != "" {
+		query = query.Where("bookings.status = ?", filter.Status)
+	}
+	if filter.Reference != "" {
+		query = query.Where("bookings.reference = ?", filter.Reference)
+	}
+	if filter.Email != "" {
+		query = query.Where("bookings.user_id IN (SELECT id FROM users WHERE email ILIKE ?)", "%"+filter.Email+"%")
+	}
+	if filter.BookedFrom != nil {
+		query = query.Where("bookings.booked_at >= ?", *filter.BookedFrom)
+	}
+	if filter.BookedTo != nil {
+		query = query.Where("bookings.booked_at < ?", *filter.BookedTo)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var bookings []model.Booking
+	err := query.Preload("Seat").Order("bookings.id DESC").Offset(filter.Offset).Limit(filter.Limit).Find(&bookings).Error
+	return bookings, total, err
+}
+
+func (r *gormBookingRepository) FindExpiredHolds(now time.Time) ([]model.Booking, error) {
+	var bookings []model.Booking
+	err := r.db.Where("status = ? AND hold_expires_at <= ?", model.StatusPending, now).
+		Preload("Seat").Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *gormBookingRepository) CountActiveByUserOnFlight(userID, flightID uint) (int, error) {
+	var count int64
+	err := r.db.Model(&model.Booking{}).
+		Joins("JOIN seats ON seats.id = bookings.seat_id").
+		Where("bookings.user_id = ? AND seats.flight_id = ? AND bookings.status IN ?", userID, flightID, model.ActiveStatuses).
+		Count(&count).Error
+	return int(count), err
+}
+
+func (r *gormBookingRepository) CountActiveOnFlight(flightID uint) (int, error) {
+	var count int64
+	err := r.db.Model(&model.Booking{}).
+		Joins("JOIN seats ON seats.id = bookings.seat_id").
+		Where("seats.flight_id = ? AND bookings.status IN ?", flightID, model.ActiveStatuses).
+		Count(&count).Error
+	return int(count), err
+}
+
+func (r *gormBookingRepository) FindByFlight(flightID uint) ([]model.Booking, error) {
+	var bookings []model.Booking
+	err := r.db.Joins("JOIN seats ON seats.id = bookings.seat_id").
+		Where("seats.flight_id = ?", flightID).
+		Preload("Seat").Order("bookings.id").Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *gormBookingRepository) FindActiveOnFlight(flightID uint) ([]model.Booking, error) {
+	var bookings []model.Booking
+	err := r.db.Preload("Seat").Preload("User").
+		Joins("JOIN seats ON seats.id = bookings.seat_id").
+		Where("seats.flight_id = ? AND bookings.status IN ?", flightID, model.ActiveStatuses).
+		Order("bookings.id").Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *gormBookingRepository) CountOpenHolds(userID uint, now time.Time) (int, error) {
+	var count int64
+	err := r.db.Model(&model.Booking{}).
+		Where("user_id = ? AND status = ? AND hold_expires_at > ?", userID, model.StatusPending, now).
+		Count(&count).Error
+	return int(count), err
+}
+
+func (r *gormBookingRepository) FindExpiredByUser(userID uint, since time.Time) ([]model.Booking, error) {
+	var bookings []model.Booking
+	err := r.db.Where("user_id = ? AND status = ? AND hold_expires_at >= ?", userID, model.StatusExpired, since).
+		Order("hold_expires_at").Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *gormBookingRepository) FindHoldsToRemind(until time.Time) ([]model.Booking, error) {
+	var bookings []model.Booking
+	err := r.db.Where("status = ? AND hold_expires_at < ? AND hold_reminded_at IS NULL", model.StatusPending, until).
+		Preload("Seat").Find(&bookings).Error
+	return bookings, err
+}
+
+func (r *gormBookingRepository) MarkHoldReminded(id uint, at time.Time) (bool, error) {
+	result := r.db.Model(&model.Booking{}).
+		Where("id = ? AND status = ? AND hold_reminded_at IS NULL", id, model.StatusPending).
+		Update("hold_reminded_at", at)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *gormBookingRepository) Lock(id uint) error {
+	var booking model.Booking
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&booking, id).Error
+	return translateError(err)
+}
+
+func (r *gormBookingRepository) UpdateStatus(id uint, status model.BookingStatus) error {
+	return translateError(r.db.Model(&model.Booking{}).Where("id = ?", id).Update("status", status).Error)
+}
+
+func (r *gormBookingRepository) Update(booking *model.Booking) error {
+	return translateError(r.db.Omit(clause.Associations).Save(booking).Error)
+}
+
+func (r *gormBookingRepository) MaxBoardingSequence(flightID uint) (int, error) {
+	var max int
+	err := r.db.Model(&model.Booking{}).
+		Joins("JOIN seats ON seats.id = bookings.seat_id").
+		Where("seats.flight_id = ?", flightID).
+		Select("COALESCE(MAX(bookings.boarding_sequence), 0)").
+		Scan(&max).Error
+	return max, err
+}
+
+type memoryBookingRepository struct {
+	store *memoryStore
+}
+
+// Lock hanya memeriksa keberadaan booking; transaksi memory store sudah berjalan berurutan
+func (r *memoryBookingRepository) Lock(id uint) error {
+	defer r.store.lock()()
+
+	if _, ok := r.store.data.bookings[id]; !ok {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *memoryBookingRepository) Create(booking *model.Booking) error {
+	defer r.store.lock()()
+
+	for _, existing := range r.store.data.bookings {
+		if booking.Reference != "" && existing.Reference == booking.Reference {
+			return ErrDuplicate
+		}
+	}
+	if r.seatTaken(booking) {
+		return ErrDuplicate
+	}
+
+	booking.ID = r.store.data.newID("bookings")
+	touch(&booking.CreatedAt, &booking.UpdatedAt)
+	r.store.data.bookings[booking.ID] = *booking
+	return nil
+}
+
+// seatTaken meniru index idx_bookings_active_seat: booking aktif lain sudah menempati kursi yang sama
+func (r *memoryBookingRepository) seatTaken(booking *model.Booking) bool {
+	if !slices.Contains(model.ActiveStatuses, booking.Status) {
+		return false
+	}
+	for _, existing := range r.store.data.bookings {
+		if existing.ID != booking.ID && existing.SeatID == booking.SeatID && slices.Contains(model.ActiveStatuses, existing.Status) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *memoryBookingRepository) FindByID(id uint) (*model.Booking, error) {
+	defer r.store.lock()()
+
+	booking, ok := r.store.data.bookings[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	booking.Seat = r.store.data.seats[booking.SeatID]
+	return &booking, nil
+}
+
+func (r *memoryBookingRepository) FindByReference(reference string) (*model.Booking, error) {
+	defer r.store.lock()()
+
+	for _, booking := range r.store.data.bookings {
+		if booking.Reference == reference {
+			booking.Seat = r.store.data.seats[booking.SeatID]
+			return &booking, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryBookingRepository) FindWithoutReference() ([]model.Booking, error) {
+	defer r.store.lock()()
+
+	var bookings []model.Booking
+	for _, booking := range sortedByID(r.store.data.bookings) {
+		if booking.Reference == "" {
+			bookings = append(bookings, booking)
+		}
+	}
+	return bookings, nil
+}
+
+func (r *memoryBookingRepository) FindActiveBySeat(seatID uint) (*model.Booking, error) {
+	defer r.store.lock()()
+
+	for _, booking := range sortedByID(r.store.data.bookings) {
+		if booking.SeatID == seatID && isActive(booking.Status) {
+			return &booking, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryBookingRepository) FindByUser(userID uint) ([]model.Booking, error) {
+	defer r.store.lock()()
+
+	var bookings []model.Booking
+	for _, booking := range sortedByID(r.store.data.bookings) {
+		if booking.UserID == userID {
+			booking.Seat = r.store.data.seats[booking.SeatID]
+			bookings = append(bookings, booking)
+		}
+	}
+	return bookings, nil
+}
+
+func (r *memoryBookingRepository) Search(filter BookingFilter) ([]model.Booking, int64, error) {
+	defer r.store.lock()()
+
+	var matched []model.Booking
+	all := sortedByID(r.store.data.bookings)
+	for i := len(all) - 1; i >= 0; i-- {
+		booking := all[i]
+		booking.Seat = r.store.data.seats[booking.SeatID]
+		user := r.store.data.users[booking.UserID]
+
+		if (filter.UserID != 0 && booking.UserID != filter.UserID) ||
+			(filter.FlightID != 0 && booking.Seat.FlightID != filter.FlightID) ||
+			(filter.Status != "" && booking.Status != filter.Status) ||
+			(filter.Reference != "" && booking.Reference != filter.Reference) ||
+			(filter.Email != "" && !strings.Contains(strings.ToLower(user.Email), strings.ToLower(filter.Email))) ||
+			(filter.BookedFrom != nil && booking.BookedAt.Before(*filter.BookedFrom)) ||
+			(filter.BookedTo != nil && !booking.BookedAt.Before(*filter.BookedTo)) {
+			continue
+		}
+		matched = append(matched, booking)
+	}
+	return paginate(matched, filter.Offset, filter.Limit), int64(len(matched)), nil
+}
+
+func (r *memoryBookingRepository) FindExpiredHolds(now time.Time) ([]model.Booking, error) {
+	defer r.store.lock()()
+
+	var bookings []model.Booking
+	for _, booking := range sortedByID(r.store.data.bookings) {
+		if booking.IsHoldExpired(now) {
+			booking.Seat = r.store.data.seats[booking.SeatID]
+			bookings = append(bookings, booking)
+		}
+	}
+	return bookings, nil
+}
+
+func (r *memoryBookingRepository) FindHoldsToRemind(until time.Time) ([]model.Booking, error) {
+	defer r.store.lock()()
+
+	var bookings []model.Booking
+	for _, booking := range sortedByID(r.store.data.bookings) {
+		if booking.Status == model.StatusPending && booking.HoldExpiresAt != nil &&
+			booking.HoldExpiresAt.Before(until) && booking.HoldRemindedAt == nil {
+			booking.Seat = r.store.data.seats[booking.SeatID]
+			bookings = append(bookings, booking)
+		}
+	}
+	return bookings, nil
+}
+
+func (r *memoryBookingRepository) MarkHoldReminded(id uint, at time.Time) (bool, error) {
+	defer r.store.lock()()
+
+	booking, ok := r.store.data.bookings[id]
+	if !ok || booking.Status != model.StatusPending || booking.HoldRemindedAt != nil {
+		return false, nil
+	}
+	booking.HoldRemindedAt = &at
+	r.store.data.bookings[id] = booking
+	return true, nil
+}
+
+func (r *memoryBookingRepository) CountActiveByUserOnFlight(userID, flightID uint) (int, error) {
+	defer r.store.lock()()
+
+	count := 0
+	for _, booking := range r.store.data.bookings {
+		if booking.UserID == userID && isActive(booking.Status) && r.store.data.seats[booking.SeatID].FlightID == flightID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryBookingRepository) CountActiveOnFlight(flightID uint) (int, error) {
+	defer r.store.lock()()
+
+	count := 0
+	for _, booking := range r.store.data.bookings {
+		if isActive(booking.Status) && r.store.data.seats[booking.SeatID].FlightID == flightID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryBookingRepository) FindByFlight(flightID uint) ([]model.Booking, error) {
+	defer r.store.lock()()
+
+	var bookings []model.Booking
+	for _, booking := range sortedByID(r.store.data.bookings) {
+		seat := r.store.data.seats[booking.SeatID]
+		if seat.FlightID == flightID {
+			booking.Seat = seat
+			bookings = append(bookings, booking)
+		}
+	}
+	return bookings, nil
+}
+
+func (r *memoryBookingRepository) FindActiveOnFlight(flightID uint) ([]model.Booking, error) {
+	defer r.store.lock()()
+
+	var bookings []model.Booking
+	for _, booking := range sortedByID(r.store.data.bookings) {
+		seat := r.store.data.seats[booking.SeatID]
+		if isActive(booking.Status) && seat.FlightID == flightID {
+			booking.Seat = seat
+			booking.User = r.store.data.users[booking.UserID]
+			bookings = append(bookings, booking)
+		}
+	}
+	return bookings, nil
+}
+
+func (r *memoryBookingRepository) CountOpenHolds(userID uint, now time.Time) (int, error) {
+	defer r.store.lock()()
+
+	count := 0
+	for _, booking := range r.store.data.bookings {
+		if booking.UserID == userID && booking.Status == model.StatusPending && booking.HoldExpiresAt != nil && booking.HoldExpiresAt.After(now) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryBookingRepository) FindExpiredByUser(userID uint, since time.Time) ([]model.Booking, error) {
+	defer r.store.lock()()
+
+	var bookings []model.Booking
+	for _, booking := range r.store.data.bookings {
+		if booking.UserID == userID && booking.Status == model.StatusExpired && booking.HoldExpiresAt != nil && !booking.HoldExpiresAt.Before(since) {
+			bookings = append(bookings, booking)
+		}
+	}
+	sort.Slice(bookings, func(i, j int) bool { return bookings[i].HoldExpiresAt.Before(*bookings[j].HoldExpiresAt) })
+	return bookings, nil
+}
+
+func (r *memoryBookingRepository) UpdateStatus(id uint, status model.BookingStatus) error {
+	defer r.store.lock()()
+
+	booking, ok := r.store.data.bookings[id]
+	if !ok {
+		return nil
+	}
+	booking.Status = status
+	if r.seatTaken(&booking) {
+		return ErrDuplicate
+	}
+	touch(&booking.CreatedAt, &booking.UpdatedAt)
+	r.store.data.bookings[id] = booking
+	return nil
+}
+
+func (r *memoryBookingRepository) Update(booking *model.Booking) error {
+	defer r.store.lock()()
+
+	if _, ok := r.store.data.bookings[booking.ID]; !ok {
+		return ErrNotFound
+	}
+	if r.seatTaken(booking) {
+		return ErrDuplicate
+	}
+	stored := *booking
+	stored.User = model.User{}
+	stored.Seat = model.Seat{}
+	touch(&stored.CreatedAt, &stored.UpdatedAt)
+	r.store.data.bookings[booking.ID] = stored
+	return nil
+}
+
+func (r *memoryBookingRepository) MaxBoardingSequence(flightID uint) (int, error) {
+	defer r.store.lock()()
+
+	max := 0
+	for _, booking := range r.store.data.bookings {
+		if r.store.data.seats[booking.SeatID].FlightID == flightID && booking.BoardingSequence > max {
+			max = booking.BoardingSequence
+		}
+	}
+	return max, nil
+}
+
+func isActive(status model.BookingStatus) bool {
+	for _, active := range model.ActiveStatuses {
+		if status == active {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+type EligibilityRepository interface {
+	FindAll() ([]model.EligibilityRuleSet, error)
+	FindByAirline(airlineCode string) (*model.EligibilityRuleSet, error)
+	// Save membuat atau mengganti rule set milik maskapai set.AirlineCode
+	Save(set *model.EligibilityRuleSet) error
+	Delete(airlineCode string) error
+}
+
+type gormEligibilityRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormEligibilityRepository) FindAll() ([]model.EligibilityRuleSet, error) {
+	var sets []model.EligibilityRuleSet
+	err := r.db.Order("airline_code").Find(&sets).Error
+	return sets, err
+}
+
+func (r *gormEligibilityRepository) FindByAirline(airlineCode string) (*model.EligibilityRuleSet, error) {
+	var set model.EligibilityRuleSet
+	if err := r.db.Where("airline_code = ?", airlineCode).First(&set).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &set, nil
+}
+
+func (r *gormEligibilityRepository) Save(set *model.EligibilityRuleSet) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "airline_code"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rules", "updated_by", "updated_at"}),
+	}).Create(set).Error
+	if err != nil {
+		return translateError(err)
+	}
+	return r.db.Where("airline_code = ?", set.AirlineCode).First(set).Error
+}
+
+func (r *gormEligibilityRepository) Delete(airlineCode string) error {
+	return r.db.Where("airline_code = ?", airlineCode).Delete(&model.EligibilityRuleSet{}).Error
+}
+
+type memoryEligibilityRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryEligibilityRepository) FindAll() ([]model.EligibilityRuleSet, error) {
+	defer r.store.lock()()
+
+	sets := sortedByID(r.store.data.eligibility)
+	sortByAirline(sets)
+	return sets, nil
+}
+
+func (r *memoryEligibilityRepository) FindByAirline(airlineCode string) (*model.EligibilityRuleSet, error) {
+	defer r.store.lock()()
+
+	for _, set := range r.store.data.eligibility {
+		if set.AirlineCode == airlineCode {
+			return &set, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryEligibilityRepository) Save(set *model.EligibilityRuleSet) error {
+	defer r.store.lock()()
+
+	for id, existing := range r.store.data.eligibility {
+		if existing.AirlineCode == set.AirlineCode {
+			set.ID = id
+			set.CreatedAt = existing.CreatedAt
+		}
+	}
+	if set.ID == 0 {
+		set.ID = r.store.data.newID("eligibility_rule_sets")
+	}
+	touch(&set.CreatedAt, &set.UpdatedAt)
+	r.store.data.eligibility[set.ID] = *set
+	return nil
+}
+
+func (r *memoryEligibilityRepository) Delete(airlineCode string) error {
+	defer r.store.lock()()
+
+	for id, set := range r.store.data.eligibility {
+		if set.AirlineCode == airlineCode {
+			delete(r.store.data.eligibility, id)
+		}
+	}
+	return nil
+}
+
+func sortByAirline(sets []model.EligibilityRuleSet) {
+	sort.Slice(sets, func(i, j int) bool { return sets[i].AirlineCode < sets[j].AirlineCode })
+}
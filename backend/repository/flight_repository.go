@@ -0,0 +1,220 @@
+package repository
+
+import (
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+// FlightFilter adalah kriteria pencarian jadwal penerbangan; field kosong tidak dipakai.
+// DepartureFrom inklusif, DepartureTo eksklusif.
+type FlightFilter struct {
+	AirlineCode   string
+	FlightNumber  int
+	Origin        string
+	Destination   string
+	DepartureFrom *time.Time
+	DepartureTo   *time.Time
+	Offset        int
+	Limit         int
+}
+
+type FlightRepository interface {
+	Create(flight *model.Flight) error
+	Update(flight *model.Flight) error
+	// Delete menghapus penerbangan secara permanen agar designator-nya bisa dipakai lagi
+	Delete(id uint) error
+	FindByID(id uint) (*model.Flight, error)
+	// Lock mengunci baris penerbangan sampai transaksi selesai (SELECT ... FOR UPDATE) sehingga
+	// transaksi lain yang mengunci penerbangan yang sama menunggu. Hanya berarti di dalam Transaction.
+	Lock(id uint) error
+	// FindByDesignator mencari penerbangan berdasarkan kode maskapai, nomor dan waktu berangkat
+	FindByDesignator(airlineCode string, flightNumber int, departure time.Time) (*model.Flight, error)
+	// Search mengembalikan penerbangan yang cocok dengan filter, urut waktu berangkat, beserta jumlah totalnya
+	Search(filter FlightFilter) ([]model.Flight, int64, error)
+	// FindWithoutZone mengembalikan penerbangan yang disimpan sebelum zona waktunya dicatat
+	FindWithoutZone() ([]model.Flight, error)
+}
+
+type gormFlightRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormFlightRepository) Create(flight *model.Flight) error {
+	return translateError(r.db.Create(flight).Error)
+}
+
+func (r *gormFlightRepository) Update(flight *model.Flight) error {
+	return translateError(r.db.Save(flight).Error)
+}
+
+func (r *gormFlightRepository) Delete(id uint) error {
+	return r.db.Unscoped().Delete(&model.Flight{}, id).Error
+}
+
+func (r *gormFlightRepository) FindByID(id uint) (*model.Flight, error) {
+	var flight model.Flight
+	if err := r.db.First(&flight, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &flight, nil
+}
+
+func (r *gormFlightRepository) Lock(id uint) error {
+	var flight model.Flight
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&flight, id).Error
+	return translateError(err)
+}
+
+func (r *gormFlightRepository) FindByDesignator(airlineCode string, flightNumber int, departure time.Time) (*model.Flight, error) {
+	var flight model.Flight
+	err := r.db.Where("airline_code = ? AND flight_number = ? AND departure = ?", airlineCode, flightNumber, departure).
+		First(&flight).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &flight, nil
+}
+
+func (r *gormFlightRepository) Search(filter FlightFilter) ([]model.Flight, int64, error) {
+	query := r.db.Model(&model.Flight{})
+	if filter.AirlineCode != "" {
+		query = query.Where("airline_code = ?", filter.AirlineCode)
+	}
+	if filter.FlightNumber != 0 {
+		query = query.Where("flight_number = ?", filter.FlightNumber)
+	}
+	if filter.Origin != "" {
+		query = query.Where("origin = ?", filter.Origin)
+	}
+	if filter.Destination != "" {
+		query = query.Where("destination = ?", filter.Destination)
+	}
+	if filter.DepartureFrom != nil {
+		query = query.Where("departure >= ?", *filter.DepartureFrom)
+	}
+	if filter.DepartureTo != nil {
+		query = query.Where("departure < ?", *filter.DepartureTo)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var flights []model.Flight
+	err := query.Order("departure, id").Offset(filter.Offset).Limit(filter.Limit).Find(&flights).Error
+	return flights, total, err
+}
+
+func (r *gormFlightRepository) FindWithoutZone() ([]model.Flight, error) {
+	var flights []model.Flight
+	err := r.db.Where("departure_zone = '' OR departure_zone IS NULL").Order("id").Find(&flights).Error
+	return flights, err
+}
+
+type memoryFlightRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryFlightRepository) Create(flight *model.Flight) error {
+	defer r.store.lock()()
+
+	for _, existing := range r.store.data.flights {
+		if sameDesignator(existing, flight.AirlineCode, flight.FlightNumber, flight.Departure) {
+			return ErrDuplicate
+		}
+	}
+
+	flight.ID = r.store.data.newID("flights")
+	touch(&flight.CreatedAt, &flight.UpdatedAt)
+	r.store.data.flights[flight.ID] = *flight
+	return nil
+}
+
+func (r *memoryFlightRepository) Update(flight *model.Flight) error {
+	defer r.store.lock()()
+
+	if _, ok := r.store.data.flights[flight.ID]; !ok {
+		return ErrNotFound
+	}
+	touch(&flight.CreatedAt, &flight.UpdatedAt)
+	r.store.data.flights[flight.ID] = *flight
+	return nil
+}
+
+func (r *memoryFlightRepository) Delete(id uint) error {
+	defer r.store.lock()()
+
+	delete(r.store.data.flights, id)
+	return nil
+}
+
+func (r *memoryFlightRepository) FindByID(id uint) (*model.Flight, error) {
+	defer r.store.lock()()
+
+	flight, ok := r.store.data.flights[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &flight, nil
+}
+
+// Lock hanya memeriksa keberadaan penerbangan; transaksi memory store sudah berjalan berurutan
+func (r *memoryFlightRepository) Lock(id uint) error {
+	defer r.store.lock()()
+
+	if _, ok := r.store.data.flights[id]; !ok {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *memoryFlightRepository) FindByDesignator(airlineCode string, flightNumber int, departure time.Time) (*model.Flight, error) {
+	defer r.store.lock()()
+
+	for _, flight := range sortedByID(r.store.data.flights) {
+		if sameDesignator(flight, airlineCode, flightNumber, departure) {
+			return &flight, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryFlightRepository) Search(filter FlightFilter) ([]model.Flight, int64, error) {
+	defer r.store.lock()()
+
+	var matched []model.Flight
+	for _, flight := range sortedByID(r.store.data.flights) {
+		if (filter.AirlineCode == "" || flight.AirlineCode == filter.AirlineCode) &&
+			(filter.FlightNumber == 0 || flight.FlightNumber == filter.FlightNumber) &&
+			(filter.Origin == "" || flight.Origin == filter.Origin) &&
+			(filter.Destination == "" || flight.Destination == filter.Destination) &&
+			(filter.DepartureFrom == nil || !flight.Departure.Before(*filter.DepartureFrom)) &&
+			(filter.DepartureTo == nil || flight.Departure.Before(*filter.DepartureTo)) {
+			matched = append(matched, flight)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool { return matched[i].Departure.Before(matched[j].Departure) })
+	return paginate(matched, filter.Offset, filter.Limit), int64(len(matched)), nil
+}
+
+func sameDesignator(flight model.Flight, airlineCode string, flightNumber int, departure time.Time) bool {
+	return flight.AirlineCode == airlineCode && flight.FlightNumber == flightNumber && flight.Departure.Equal(departure)
+}
+
+func (r *memoryFlightRepository) FindWithoutZone() ([]model.Flight, error) {
+	defer r.store.lock()()
+
+	var flights []model.Flight
+	for _, flight := range sortedByID(r.store.data.flights) {
+		if flight.DepartureZone == "" {
+			flights = append(flights, flight)
+		}
+	}
+	return flights, nil
+}
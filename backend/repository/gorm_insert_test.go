@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunStore membuat gormStore yang tidak terhubung ke Postgres. Query hanya dibangun,
+// lalu setiap INSERT dicatat agar nilai kolomnya bisa diperiksa.
+func dryRunStore(t *testing.T) (Store, *[]*gorm.Statement) {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost dbname=bookcabin_test"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatalf("open dry-run db: %v", err)
+	}
+
+	var inserts []*gorm.Statement
+	err = db.Callback().Create().After("gorm:create").Register("test:record_insert", func(tx *gorm.DB) {
+		inserts = append(inserts, tx.Statement)
+	})
+	if err != nil {
+		t.Fatalf("register callback: %v", err)
+	}
+	return NewGormStore(db), &inserts
+}
+
+// insertedValues mengembalikan nilai column untuk setiap baris pada statement INSERT
+func insertedValues(t *testing.T, stmt *gorm.Statement, column string) []interface{} {
+	t.Helper()
+	sql := stmt.SQL.String()
+	start, end := strings.Index(sql, "("), strings.Index(sql, ") VALUES")
+	if start < 0 || end < start {
+		t.Fatalf("not an INSERT statement: %s", sql)
+	}
+
+	columns := strings.Split(sql[start+1:end], ",")
+	index := -1
+	for i, c := range columns {
+		if strings.Trim(c, `" `) == column {
+			index = i
+		}
+	}
+	if index < 0 {
+		t.Fatalf("column %s is not written by %s", column, sql)
+	}
+
+	var values []interface{}
+	for row := 0; row+len(columns) <= len(stmt.Vars); row += len(columns) {
+		values = append(values, stmt.Vars[row+index])
+	}
+	return values
+}
@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+// memoryData menampung semua tabel in-memory
+type memoryData struct {
+	users            map[uint]model.User
+	seats            map[uint]model.Seat
+	bookings         map[uint]model.Booking
+	flights          map[uint]model.Flight
+	waitlist         map[uint]model.WaitlistEntry
+	tokens           map[uint]model.UserToken
+	audit            map[uint]model.AuditLog
+	priceRules       map[uint]model.PriceRuleSet
+	promos           map[uint]model.PromoCode
+	redemptions      map[uint]model.PromoRedemption
+	passengers       map[uint]model.FlightPassenger
+	eligibility      map[uint]model.EligibilityRuleSet
+	offers           map[uint]model.SeatOffer
+	assignments      map[uint]model.SeatAssignment
+	syncs            map[uint]model.SeatMapSync
+	reaccommodations map[uint]model.Reaccommodation
+	airports         map[string]model.Airport
+	notifications    map[uint]model.Notification
+	webhooks         map[uint]model.WebhookEndpoint
+	deliveries       map[uint]model.WebhookDelivery
+	nextID           map[string]uint
+}
+
+func newMemoryData() *memoryData {
+	return &memoryData{
+		users:            map[uint]model.User{},
+		seats:            map[uint]model.Seat{},
+		bookings:         map[uint]model.Booking{},
+		flights:          map[uint]model.Flight{},
+		waitlist:         map[uint]model.WaitlistEntry{},
+		tokens:           map[uint]model.UserToken{},
+		audit:            map[uint]model.AuditLog{},
+		priceRules:       map[uint]model.PriceRuleSet{},
+		promos:           map[uint]model.PromoCode{},
+		redemptions:      map[uint]model.PromoRedemption{},
+		passengers:       map[uint]model.FlightPassenger{},
+		eligibility:      map[uint]model.EligibilityRuleSet{},
+		offers:           map[uint]model.SeatOffer{},
+		assignments:      map[uint]model.SeatAssignment{},
+		syncs:            map[uint]model.SeatMapSync{},
+		reaccommodations: map[uint]model.Reaccommodation{},
+		airports:         map[string]model.Airport{},
+		notifications:    map[uint]model.Notification{},
+		webhooks:         map[uint]model.WebhookEndpoint{},
+		deliveries:       map[uint]model.WebhookDelivery{},
+		nextID:           map[string]uint{},
+	}
+}
+
+func (d *memoryData) clone() *memoryData {
+	return &memoryData{
+		users:            cloneMap(d.users),
+		seats:            cloneMap(d.seats),
+		bookings:         cloneMap(d.bookings),
+		flights:          cloneMap(d.flights),
+		waitlist:         cloneMap(d.waitlist),
+		tokens:           cloneMap(d.tokens),
+		audit:            cloneMap(d.audit),
+		priceRules:       cloneMap(d.priceRules),
+		promos:           cloneMap(d.promos),
+		redemptions:      cloneMap(d.redemptions),
+		passengers:       cloneMap(d.passengers),
+		eligibility:      cloneMap(d.eligibility),
+		offers:           cloneMap(d.offers),
+		assignments:      cloneMap(d.assignments),
+		syncs:            cloneMap(d.syncs),
+		reaccommodations: cloneMap(d.reaccommodations),
+		airports:         cloneMap(d.airports),
+		notifications:    cloneMap(d.notifications),
+		webhooks:         cloneMap(d.webhooks),
+		deliveries:       cloneMap(d.deliveries),
+		nextID:           cloneMap(d.nextID),
+	}
+}
+
+func (d *memoryData) newID(table string) uint {
+	d.nextID[table]++
+	return d.nextID[table]
+}
+
+func cloneMap[K comparable, V any](m map[K]V) map[K]V {
+	out := make(map[K]V, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+// sortedByID mengembalikan isi tabel terurut berdasarkan primary key
+func sortedByID[T any](m map[uint]T) []T {
+	ids := make([]uint, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	out := make([]T, 0, len(ids))
+	for _, id := range ids {
+		out = append(out, m[id])
+	}
+	return out
+}
+
+// paginate memotong hasil pencarian in-memory sesuai offset dan limit (0 = tanpa batas)
+func paginate[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// memoryStore adalah implementasi Store in-memory untuk unit test.
+// Transaksi dijalankan secara serial dan di-rollback jika fn mengembalikan error.
+type memoryStore struct {
+	mu   *sync.Mutex
+	data *memoryData
+	inTx bool
+}
+
+// NewMemoryStore membuat Store in-memory yang kosong
+func NewMemoryStore() Store {
+	return &memoryStore{mu: &sync.Mutex{}, data: newMemoryData()}
+}
+
+func (s *memoryStore) lock() func() {
+	if s.inTx {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+func (s *memoryStore) Users() UserRepository {
+	return &memoryUserRepository{store: s}
+}
+
+func (s *memoryStore) Seats() SeatRepository {
+	return &memorySeatRepository{store: s}
+}
+
+func (s *memoryStore) Bookings() BookingRepository {
+	return &memoryBookingRepository{store: s}
+}
+
+func (s *memoryStore) Flights() FlightRepository {
+	return &memoryFlightRepository{store: s}
+}
+
+func (s *memoryStore) Waitlist() WaitlistRepository {
+	return &memoryWaitlistRepository{store: s}
+}
+
+func (s *memoryStore) Tokens() TokenRepository {
+	return &memoryTokenRepository{store: s}
+}
+
+func (s *memoryStore) Audit() AuditRepository {
+	return &memoryAuditRepository{store: s}
+}
+
+func (s *memoryStore) PriceRules() PriceRuleRepository {
+	return &memoryPriceRuleRepository{store: s}
+}
+
+func (s *memoryStore) Promos() PromoRepository {
+	return &memoryPromoRepository{store: s}
+}
+
+func (s *memoryStore) Passengers() PassengerRepository {
+	return &memoryPassengerRepository{store: s}
+}
+
+func (s *memoryStore) Eligibility() EligibilityRepository {
+	return &memoryEligibilityRepository{store: s}
+}
+
+func (s *memoryStore) SeatOffers() SeatOfferRepository {
+	return &memorySeatOfferRepository{store: s}
+}
+
+func (s *memoryStore) SeatAssignments() SeatAssignmentRepository {
+	return &memorySeatAssignmentRepository{store: s}
+}
+
+func (s *memoryStore) SeatMapSyncs() SeatMapSyncRepository {
+	return &memorySeatMapSyncRepository{store: s}
+}
+
+func (s *memoryStore) Reaccommodations() ReaccommodationRepository {
+	return &memoryReaccommodationRepository{store: s}
+}
+
+func (s *memoryStore) Airports() AirportRepository {
+	return &memoryAirportRepository{store: s}
+}
+
+func (s *memoryStore) Notifications() NotificationRepository {
+	return &memoryNotificationRepository{store: s}
+}
+
+func (s *memoryStore) WebhookEndpoints() WebhookEndpointRepository {
+	return &memoryWebhookEndpointRepository{store: s}
+}
+
+func (s *memoryStore) WebhookDeliveries() WebhookDeliveryRepository {
+	return &memoryWebhookDeliveryRepository{store: s}
+}
+
+func (s *memoryStore) Transaction(fn func(tx Store) error) error {
+	if s.inTx {
+		return fn(s)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := s.data.clone()
+	if err := fn(&memoryStore{mu: s.mu, data: s.data, inTx: true}); err != nil {
+		*s.data = *snapshot
+		return err
+	}
+	return nil
+}
+
+func touch(createdAt, updatedAt *time.Time) {
+	now := time.Now()
+	if createdAt.IsZero() {
+		*createdAt = now
+	}
+	*updatedAt = now
+}
@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+// NotificationFilter adalah kriteria inbox notifikasi satu user
+type NotificationFilter struct {
+	UserID     uint
+	Channel    string
+	UnreadOnly bool
+	Offset     int
+	Limit      int
+}
+
+type NotificationRepository interface {
+	// Create mengembalikan ErrDuplicate jika DedupKey sudah dipakai pada channel yang sama
+	Create(notification *model.Notification) error
+	// UpdateDelivery menyimpan hasil percobaan kirim tanpa mengubah ReadAt. Notifikasi yang
+	// sudah tidak pending, misalnya dibatalkan saat pengiriman berjalan, tidak diubah.
+	UpdateDelivery(notification *model.Notification) error
+	// FindDue mengembalikan notifikasi pending yang jadwal percobaannya sudah tiba pada now
+	FindDue(now time.Time, limit int) ([]model.Notification, error)
+	// Claim mengambil notifikasi untuk satu percobaan kirim: Attempts dinaikkan dan percobaan
+	// berikutnya ditunda sampai leaseUntil. False jika notifikasi sudah diambil proses lain.
+	Claim(notification *model.Notification, leaseUntil time.Time) (bool, error)
+	// Search mengembalikan notifikasi yang cocok dengan filter (terbaru dulu) beserta jumlah totalnya
+	Search(filter NotificationFilter) ([]model.Notification, int64, error)
+	// MarkRead menandai notifikasi in_app milik user sebagai dibaca dan mengembalikan jumlah
+	// notifikasi yang cocok. id 0 berarti semua notifikasi yang belum dibaca. ReadAt yang sudah
+	// terisi tidak diubah.
+	MarkRead(userID, id uint, at time.Time) (int64, error)
+	// AnonymizeForUser mengosongkan Recipient, Subject dan Body semua notifikasi user dan
+	// membatalkan notifikasi yang masih pending
+	AnonymizeForUser(userID uint) error
+}
+
+type gormNotificationRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormNotificationRepository) Create(notification *model.Notification) error {
+	return translateError(r.db.Create(notification).Error)
+}
+
+func (r *gormNotificationRepository) UpdateDelivery(notification *model.Notification) error {
+	return r.db.Model(notification).Where("status = ?", model.NotificationPending).
+		Select("status", "attempts", "next_attempt_at", "last_error", "sent_at", "updated_at").
+		Updates(notification).Error
+}
+
+func (r *gormNotificationRepository) FindDue(now time.Time, limit int) ([]model.Notification, error) {
+	var notifications []model.Notification
+	err := r.db.Where("status = ? AND next_attempt_at <= ?", model.NotificationPending, now).
+		Order("next_attempt_at, id").Limit(limit).Find(&notifications).Error
+	return notifications, err
+}
+
+func (r *gormNotificationRepository) Claim(notification *model.Notification, leaseUntil time.Time) (bool, error) {
+	result := r.db.Model(&model.Notification{}).
+		Where("id = ? AND status = ? AND attempts = ?", notification.ID, model.NotificationPending, notification.Attempts).
+		Updates(map[string]interface{}{"attempts": notification.Attempts + 1, "next_attempt_at": leaseUntil})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	notification.Attempts++
+	notification.NextAttemptAt = &leaseUntil
+	return true, nil
+}
+
+func (r *gormNotificationRepository) Search(filter NotificationFilter) ([]model.Notification, int64, error) {
+	query := r.db.Model(&model.Notification{}).Where("user_id = ?", filter.UserID)
+	if filter.Channel != "" {
+		query = query.Where("channel = ?", filter.Channel)
+	}
+	if filter.UnreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var notifications []model.Notification
+	err := query.Order("id DESC").Offset(filter.Offset).Limit(filter.Limit).Find(&notifications).Error
+	return notifications, total, err
+}
+
+func (r *gormNotificationRepository) MarkRead(userID, id uint, at time.Time) (int64, error) {
+	query := r.db.Model(&model.Notification{}).Where("user_id = ? AND channel = ?", userID, model.ChannelInApp)
+	if id != 0 {
+		query = query.Where("id = ?", id)
+	} else {
+		query = query.Where("read_at IS NULL")
+	}
+	result := query.Update("read_at", gorm.Expr("COALESCE(read_at, ?)", at))
+	return result.RowsAffected, result.Error
+}
+
+func (r *gormNotificationRepository) AnonymizeForUser(userID uint) error {
+	err := r.db.Model(&model.Notification{}).
+		Where("user_id = ? AND status = ?", userID, model.NotificationPending).
+		Updates(map[string]interface{}{"status": model.NotificationCancelled, "next_attempt_at": nil}).Error
+	if err != nil {
+		return err
+	}
+	return r.db.Model(&model.Notification{}).Where("user_id = ?", userID).
+		Updates(map[string]interface{}{"recipient": "", "subject": "", "body": ""}).Error
+}
+
+type memoryNotificationRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryNotificationRepository) Create(notification *model.Notification) error {
+	defer r.store.lock()()
+
+	for _, existing := range r.store.data.notifications {
+		if existing.Channel == notification.Channel && existing.DedupKey == notification.DedupKey {
+			return ErrDuplicate
+		}
+	}
+	notification.ID = r.store.data.newID("notifications")
+	touch(&notification.CreatedAt, &notification.UpdatedAt)
+	if notification.Status == "" {
+		notification.Status = model.NotificationPending
+	}
+	r.store.data.notifications[notification.ID] = *notification
+	return nil
+}
+
+func (r *memoryNotificationRepository) UpdateDelivery(notification *model.Notification) error {
+	defer r.store.lock()()
+
+	current, ok := r.store.data.notifications[notification.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if current.Status != model.NotificationPending {
+		return nil
+	}
+	current.Status = notification.Status
+	current.Attempts = notification.Attempts
+	current.NextAttemptAt = notification.NextAttemptAt
+	current.LastError = notification.LastError
+	current.SentAt = notification.SentAt
+	touch(&current.CreatedAt, &current.UpdatedAt)
+	r.store.data.notifications[current.ID] = current
+	return nil
+}
+
+func (r *memoryNotificationRepository) FindDue(now time.Time, limit int) ([]model.Notification, error) {
+	defer r.store.lock()()
+
+	var due []model.Notification
+	for _, notification := range sortedByID(r.store.data.notifications) {
+		if notification.Status == model.NotificationPending && notification.NextAttemptAt != nil &&
+			!notification.NextAttemptAt.After(now) {
+			due = append(due, notification)
+		}
+	}
+	return paginate(due, 0, limit), nil
+}
+
+func (r *memoryNotificationRepository) Claim(notification *model.Notification, leaseUntil time.Time) (bool, error) {
+	defer r.store.lock()()
+
+	current, ok := r.store.data.notifications[notification.ID]
+	if !ok || current.Status != model.NotificationPending || current.Attempts != notification.Attempts {
+		return false, nil
+	}
+	current.Attempts++
+	current.NextAttemptAt = &leaseUntil
+	r.store.data.notifications[current.ID] = current
+	*notification = current
+	return true, nil
+}
+
+func (r *memoryNotificationRepository) Search(filter NotificationFilter) ([]model.Notification, int64, error) {
+	defer r.store.lock()()
+
+	var matched []model.Notification
+	notifications := sortedByID(r.store.data.notifications)
+	for i := len(notifications) - 1; i >= 0; i-- {
+		notification := notifications[i]
+		if notification.UserID == filter.UserID &&
+			(filter.Channel == "" || notification.Channel == filter.Channel) &&
+			(!filter.UnreadOnly || notification.ReadAt == nil) {
+			matched = append(matched, notification)
+		}
+	}
+	return paginate(matched, filter.Offset, filter.Limit), int64(len(matched)), nil
+}
+
+func (r *memoryNotificationRepository) MarkRead(userID, id uint, at time.Time) (int64, error) {
+	defer r.store.lock()()
+
+	var updated int64
+	for key, notification := range r.store.data.notifications {
+		if notification.UserID != userID || notification.Channel != model.ChannelInApp ||
+			(id == 0 && notification.ReadAt != nil) || (id != 0 && notification.ID != id) {
+			continue
+		}
+		if notification.ReadAt == nil {
+			notification.ReadAt = &at
+			r.store.data.notifications[key] = notification
+		}
+		updated++
+	}
+	return updated, nil
+}
+
+func (r *memoryNotificationRepository) AnonymizeForUser(userID uint) error {
+	defer r.store.lock()()
+
+	for id, notification := range r.store.data.notifications {
+		if notification.UserID != userID {
+			continue
+		}
+		if notification.Status == model.NotificationPending {
+			notification.Status = model.NotificationCancelled
+			notification.NextAttemptAt = nil
+		}
+		notification.Recipient, notification.Subject, notification.Body = "", "", ""
+		touch(&notification.CreatedAt, &notification.UpdatedAt)
+		r.store.data.notifications[id] = notification
+	}
+	return nil
+}
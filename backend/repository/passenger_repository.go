@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+type PassengerRepository interface {
+	// ReplaceForFlight mengganti daftar penumpang penerbangan dengan hasil import terbaru
+	ReplaceForFlight(flightID uint, passengers []model.FlightPassenger) error
+	FindByFlight(flightID uint) ([]model.FlightPassenger, error)
+	// FindByEmail mencari penumpang penerbangan berdasarkan email (tidak case-sensitive)
+	FindByEmail(flightID uint, email string) (*model.FlightPassenger, error)
+	// AnonymizeByEmail mengosongkan nama, email, tanggal lahir, SSR dan data frequent flyer semua
+	// penumpang dengan email tersebut di semua penerbangan, sehingga tidak lagi cocok dengan user mana pun
+	AnonymizeByEmail(email string) error
+}
+
+type gormPassengerRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormPassengerRepository) ReplaceForFlight(flightID uint, passengers []model.FlightPassenger) error {
+	if err := r.db.Where("flight_id = ?", flightID).Delete(&model.FlightPassenger{}).Error; err != nil {
+		return err
+	}
+	if len(passengers) == 0 {
+		return nil
+	}
+	for i := range passengers {
+		passengers[i].FlightID = flightID
+	}
+	return translateError(r.db.Create(&passengers).Error)
+}
+
+func (r *gormPassengerRepository) FindByFlight(flightID uint) ([]model.FlightPassenger, error) {
+	var passengers []model.FlightPassenger
+	err := r.db.Where("flight_id = ?", flightID).Order("passenger_index").Find(&passengers).Error
+	return passengers, err
+}
+
+func (r *gormPassengerRepository) FindByEmail(flightID uint, email string) (*model.FlightPassenger, error) {
+	var passenger model.FlightPassenger
+	err := r.db.Where("flight_id = ? AND LOWER(email) = LOWER(?)", flightID, email).
+		Order("passenger_index").First(&passenger).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &passenger, nil
+}
+
+func (r *gormPassengerRepository) AnonymizeByEmail(email string) error {
+	return r.db.Model(&model.FlightPassenger{}).Where("LOWER(email) = LOWER(?)", email).
+		Updates(map[string]interface{}{
+			"first_name":             "",
+			"last_name":              "",
+			"email":                  "",
+			"date_of_birth":          nil,
+			"special_requests":       model.StringArray{},
+			"frequent_flyer_airline": "",
+			"frequent_flyer_number":  "",
+			"frequent_flyer_tier":    0,
+		}).Error
+}
+
+type memoryPassengerRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryPassengerRepository) ReplaceForFlight(flightID uint, passengers []model.FlightPassenger) error {
+	defer r.store.lock()()
+
+	for id, passenger := range r.store.data.passengers {
+		if passenger.FlightID == flightID {
+			delete(r.store.data.passengers, id)
+		}
+	}
+
+	indexes := map[int]bool{}
+	for i := range passengers {
+		if indexes[passengers[i].PassengerIndex] {
+			return ErrDuplicate
+		}
+		indexes[passengers[i].PassengerIndex] = true
+	}
+	for i := range passengers {
+		passengers[i].ID = r.store.data.newID("flight_passengers")
+		passengers[i].FlightID = flightID
+		if passengers[i].CreatedAt.IsZero() {
+			passengers[i].CreatedAt = time.Now()
+		}
+		r.store.data.passengers[passengers[i].ID] = passengers[i]
+	}
+	return nil
+}
+
+func (r *memoryPassengerRepository) FindByFlight(flightID uint) ([]model.FlightPassenger, error) {
+	defer r.store.lock()()
+
+	var passengers []model.FlightPassenger
+	for _, passenger := range sortedByID(r.store.data.passengers) {
+		if passenger.FlightID == flightID {
+			passengers = append(passengers, passenger)
+		}
+	}
+	return passengers, nil
+}
+
+func (r *memoryPassengerRepository) FindByEmail(flightID uint, email string) (*model.FlightPassenger, error) {
+	defer r.store.lock()()
+
+	for _, passenger := range sortedByID(r.store.data.passengers) {
+		if passenger.FlightID == flightID && strings.EqualFold(passenger.Email, email) {
+			return &passenger, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryPassengerRepository) AnonymizeByEmail(email string) error {
+	defer r.store.lock()()
+
+	for id, passenger := range r.store.data.passengers {
+		if !strings.EqualFold(passenger.Email, email) {
+			continue
+		}
+		passenger.FirstName, passenger.LastName, passenger.Email = "", "", ""
+		passenger.DateOfBirth = nil
+		passenger.SpecialRequests = model.StringArray{}
+		passenger.FrequentFlyerAirline, passenger.FrequentFlyerNumber, passenger.FrequentFlyerTier = "", "", 0
+		r.store.data.passengers[id] = passenger
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+type PriceRuleRepository interface {
+	Create(set *model.PriceRuleSet) error
+	FindByVersion(version int) (*model.PriceRuleSet, error)
+	// FindActive mengembalikan versi yang paling akhir diaktifkan
+	FindActive() (*model.PriceRuleSet, error)
+	// FindAll mengembalikan semua versi, terbaru dulu
+	FindAll() ([]model.PriceRuleSet, error)
+	LatestVersion() (int, error)
+	Activate(version int, at time.Time) error
+}
+
+type gormPriceRuleRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormPriceRuleRepository) Create(set *model.PriceRuleSet) error {
+	return translateError(r.db.Create(set).Error)
+}
+
+func (r *gormPriceRuleRepository) FindByVersion(version int) (*model.PriceRuleSet, error) {
+	var set model.PriceRuleSet
+	if err := r.db.Where("version = ?", version).First(&set).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &set, nil
+}
+
+func (r *gormPriceRuleRepository) FindActive() (*model.PriceRuleSet, error) {
+	var set model.PriceRuleSet
+	err := r.db.Where("activated_at IS NOT NULL").Order("activated_at DESC, version DESC").First(&set).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &set, nil
+}
+
+func (r *gormPriceRuleRepository) FindAll() ([]model.PriceRuleSet, error) {
+	var sets []model.PriceRuleSet
+	err := r.db.Order("version DESC").Find(&sets).Error
+	return sets, err
+}
+
+func (r *gormPriceRuleRepository) LatestVersion() (int, error) {
+	var version int
+	err := r.db.Model(&model.PriceRuleSet{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	return version, err
+}
+
+func (r *gormPriceRuleRepository) Activate(version int, at time.Time) error {
+	result := r.db.Model(&model.PriceRuleSet{}).Where("version = ?", version).Update("activated_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+type memoryPriceRuleRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryPriceRuleRepository) Create(set *model.PriceRuleSet) error {
+	defer r.store.lock()()
+
+	for _, existing := range r.store.data.priceRules {
+		if existing.Version == set.Version {
+			return ErrDuplicate
+		}
+	}
+	set.ID = r.store.data.newID("price_rule_sets")
+	if set.CreatedAt.IsZero() {
+		set.CreatedAt = time.Now()
+	}
+	r.store.data.priceRules[set.ID] = *set
+	return nil
+}
+
+func (r *memoryPriceRuleRepository) FindByVersion(version int) (*model.PriceRuleSet, error) {
+	defer r.store.lock()()
+
+	for _, set := range r.store.data.priceRules {
+		if set.Version == version {
+			return &set, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryPriceRuleRepository) FindActive() (*model.PriceRuleSet, error) {
+	defer r.store.lock()()
+
+	var active *model.PriceRuleSet
+	for _, set := range sortedByID(r.store.data.priceRules) {
+		if set.ActivatedAt == nil {
+			continue
+		}
+		if active == nil || set.ActivatedAt.After(*active.ActivatedAt) ||
+			(set.ActivatedAt.Equal(*active.ActivatedAt) && set.Version > active.Version) {
+			set := set
+			active = &set
+		}
+	}
+	if active == nil {
+		return nil, ErrNotFound
+	}
+	return active, nil
+}
+
+func (r *memoryPriceRuleRepository) FindAll() ([]model.PriceRuleSet, error) {
+	defer r.store.lock()()
+
+	all := sortedByID(r.store.data.priceRules)
+	sets := make([]model.PriceRuleSet, 0, len(all))
+	for i := len(all) - 1; i >= 0; i-- {
+		sets = append(sets, all[i])
+	}
+	return sets, nil
+}
+
+func (r *memoryPriceRuleRepository) LatestVersion() (int, error) {
+	defer r.store.lock()()
+
+	latest := 0
+	for _, set := range r.store.data.priceRules {
+		if set.Version > latest {
+			latest = set.Version
+		}
+	}
+	return latest, nil
+}
+
+func (r *memoryPriceRuleRepository) Activate(version int, at time.Time) error {
+	defer r.store.lock()()
+
+	for id, set := range r.store.data.priceRules {
+		if set.Version == version {
+			set.ActivatedAt = &at
+			r.store.data.priceRules[id] = set
+			return nil
+		}
+	}
+	return ErrNotFound
+}
@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+type PromoRepository interface {
+	Create(promo *model.PromoCode) error
+	// Update menyimpan pengaturan promo; Redemptions hanya diubah lewat Redeem dan Unredeem
+	Update(promo *model.PromoCode) error
+	Delete(id uint) error
+	FindByID(id uint) (*model.PromoCode, error)
+	FindByCode(code string) (*model.PromoCode, error)
+	FindAll() ([]model.PromoCode, error)
+	// Redeem menambah hitungan pemakaian jika MaxRedemptions belum tercapai; false jika kuota habis
+	Redeem(id uint) (bool, error)
+	Unredeem(id uint) error
+
+	CreateRedemption(redemption *model.PromoRedemption) error
+	// FindActiveRedemption mengembalikan pemakaian promo oleh user yang belum dilepas
+	FindActiveRedemption(promoID, userID uint) (*model.PromoRedemption, error)
+	// FindActiveRedemptionByBooking mengembalikan pemakaian promo yang belum dilepas pada booking
+	FindActiveRedemptionByBooking(bookingID uint) (*model.PromoRedemption, error)
+	// FindRedemptions mengembalikan semua pemakaian promo, terbaru dulu
+	FindRedemptions(promoID uint) ([]model.PromoRedemption, error)
+	ReleaseRedemption(id uint, at time.Time) error
+}
+
+type gormPromoRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormPromoRepository) Create(promo *model.PromoCode) error {
+	return translateError(r.db.Create(promo).Error)
+}
+
+func (r *gormPromoRepository) Update(promo *model.PromoCode) error {
+	return translateError(r.db.Omit(clause.Associations, "Redemptions").Save(promo).Error)
+}
+
+func (r *gormPromoRepository) Delete(id uint) error {
+	return r.db.Delete(&model.PromoCode{}, id).Error
+}
+
+func (r *gormPromoRepository) FindByID(id uint) (*model.PromoCode, error) {
+	var promo model.PromoCode
+	if err := r.db.First(&promo, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &promo, nil
+}
+
+func (r *gormPromoRepository) FindByCode(code string) (*model.PromoCode, error) {
+	var promo model.PromoCode
+	if err := r.db.Where("code = ?", code).First(&promo).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &promo, nil
+}
+
+func (r *gormPromoRepository) FindAll() ([]model.PromoCode, error) {
+	var promos []model.PromoCode
+	err := r.db.Order("id DESC").Find(&promos).Error
+	return promos, err
+}
+
+func (r *gormPromoRepository) Redeem(id uint) (bool, error) {
+	result := r.db.Model(&model.PromoCode{}).
+		Where("id = ? AND (max_redemptions = 0 OR redemptions < max_redemptions)", id).
+		Update("redemptions", gorm.Expr("redemptions + 1"))
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *gormPromoRepository) Unredeem(id uint) error {
+	return r.db.Model(&model.PromoCode{}).Where("id = ? AND redemptions > 0", id).
+		Update("redemptions", gorm.Expr("redemptions - 1")).Error
+}
+
+func (r *gormPromoRepository) CreateRedemption(redemption *model.PromoRedemption) error {
+	return translateError(r.db.Create(redemption).Error)
+}
+
+func (r *gormPromoRepository) FindActiveRedemption(promoID, userID uint) (*model.PromoRedemption, error) {
+	var redemption model.PromoRedemption
+	err := r.db.Where("promo_code_id = ? AND user_id = ? AND released_at IS NULL", promoID, userID).First(&redemption).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &redemption, nil
+}
+
+func (r *gormPromoRepository) FindActiveRedemptionByBooking(bookingID uint) (*model.PromoRedemption, error) {
+	var redemption model.PromoRedemption
+	err := r.db.Where("booking_id = ? AND released_at IS NULL", bookingID).First(&redemption).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &redemption, nil
+}
+
+func (r *gormPromoRepository) FindRedemptions(promoID uint) ([]model.PromoRedemption, error) {
+	var redemptions []model.PromoRedemption
+	err := r.db.Where("promo_code_id = ?", promoID).Order("id DESC").Find(&redemptions).Error
+	return redemptions, err
+}
+
+func (r *gormPromoRepository) ReleaseRedemption(id uint, at time.Time) error {
+	return r.db.Model(&model.PromoRedemption{}).Where("id = ?", id).Update("released_at", at).Error
+}
+
+type memoryPromoRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryPromoRepository) Create(promo *model.PromoCode) error {
+	defer r.store.lock()()
+
+	for _, existing := range r.store.data.promos {
+		if !existing.DeletedAt.Valid && existing.Code == promo.Code {
+			return ErrDuplicate
+		}
+	}
+	promo.ID = r.store.data.newID("promo_codes")
+	touch(&promo.CreatedAt, &promo.UpdatedAt)
+	r.store.data.promos[promo.ID] = *promo
+	return nil
+}
+
+func (r *memoryPromoRepository) Update(promo *model.PromoCode) error {
+	defer r.store.lock()()
+
+	stored, ok := r.store.data.promos[promo.ID]
+	if !ok || stored.DeletedAt.Valid {
+		return ErrNotFound
+	}
+	for id, existing := range r.store.data.promos {
+		if id != promo.ID && !existing.DeletedAt.Valid && existing.Code == promo.Code {
+			return ErrDuplicate
+		}
+	}
+	updated := *promo
+	updated.Redemptions = stored.Redemptions
+	touch(&updated.CreatedAt, &updated.UpdatedAt)
+	r.store.data.promos[promo.ID] = updated
+	return nil
+}
+
+func (r *memoryPromoRepository) Delete(id uint) error {
+	defer r.store.lock()()
+
+	promo, ok := r.store.data.promos[id]
+	if !ok {
+		return nil
+	}
+	promo.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	r.store.data.promos[id] = promo
+	return nil
+}
+
+func (r *memoryPromoRepository) FindByID(id uint) (*model.PromoCode, error) {
+	defer r.store.lock()()
+
+	promo, ok := r.store.data.promos[id]
+	if !ok || promo.DeletedAt.Valid {
+		return nil, ErrNotFound
+	}
+	return &promo, nil
+}
+
+func (r *memoryPromoRepository) FindByCode(code string) (*model.PromoCode, error) {
+	defer r.store.lock()()
+
+	for _, promo := range r.store.data.promos {
+		if !promo.DeletedAt.Valid && promo.Code == code {
+			return &promo, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryPromoRepository) FindAll() ([]model.PromoCode, error) {
+	defer r.store.lock()()
+
+	all := sortedByID(r.store.data.promos)
+	promos := make([]model.PromoCode, 0, len(all))
+	for i := len(all) - 1; i >= 0; i-- {
+		if !all[i].DeletedAt.Valid {
+			promos = append(promos, all[i])
+		}
+	}
+	return promos, nil
+}
+
+func (r *memoryPromoRepository) Redeem(id uint) (bool, error) {
+	defer r.store.lock()()
+
+	promo, ok := r.store.data.promos[id]
+	if !ok || promo.DeletedAt.Valid {
+		return false, nil
+	}
+	if promo.MaxRedemptions > 0 && promo.Redemptions >= promo.MaxRedemptions {
+		return false, nil
+	}
+	promo.Redemptions++
+	r.store.data.promos[id] = promo
+	return true, nil
+}
+
+func (r *memoryPromoRepository) Unredeem(id uint) error {
+	defer r.store.lock()()
+
+	promo, ok := r.store.data.promos[id]
+	if ok && promo.Redemptions > 0 {
+		promo.Redemptions--
+		r.store.data.promos[id] = promo
+	}
+	return nil
+}
+
+func (r *memoryPromoRepository) CreateRedemption(redemption *model.PromoRedemption) error {
+	defer r.store.lock()()
+
+	for _, existing := range r.store.data.redemptions {
+		if existing.ReleasedAt == nil && existing.PromoCodeID == redemption.PromoCodeID && existing.UserID == redemption.UserID {
+			return ErrDuplicate
+		}
+	}
+	redemption.ID = r.store.data.newID("promo_redemptions")
+	if redemption.CreatedAt.IsZero() {
+		redemption.CreatedAt = time.Now()
+	}
+	r.store.data.redemptions[redemption.ID] = *redemption
+	return nil
+}
+
+func (r *memoryPromoRepository) FindActiveRedemption(promoID, userID uint) (*model.PromoRedemption, error) {
+	defer r.store.lock()()
+
+	for _, redemption := range sortedByID(r.store.data.redemptions) {
+		if redemption.ReleasedAt == nil && redemption.PromoCodeID == promoID && redemption.UserID == userID {
+			return &redemption, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryPromoRepository) FindActiveRedemptionByBooking(bookingID uint) (*model.PromoRedemption, error) {
+	defer r.store.lock()()
+
+	for _, redemption := range sortedByID(r.store.data.redemptions) {
+		if redemption.ReleasedAt == nil && redemption.BookingID == bookingID {
+			return &redemption, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryPromoRepository) FindRedemptions(promoID uint) ([]model.PromoRedemption, error) {
+	defer r.store.lock()()
+
+	all := sortedByID(r.store.data.redemptions)
+	var redemptions []model.PromoRedemption
+	for i := len(all) - 1; i >= 0; i-- {
+		if all[i].PromoCodeID == promoID {
+			redemptions = append(redemptions, all[i])
+		}
+	}
+	return redemptions, nil
+}
+
+func (r *memoryPromoRepository) ReleaseRedemption(id uint, at time.Time) error {
+	defer r.store.lock()()
+
+	redemption, ok := r.store.data.redemptions[id]
+	if !ok {
+		return nil
+	}
+	redemption.ReleasedAt = &at
+	r.store.data.redemptions[id] = redemption
+	return nil
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+func TestGormCreateWritesInactivePromo(t *testing.T) {
+	store, inserts := dryRunStore(t)
+
+	promo := &model.PromoCode{Code: "PAUSED", DiscountType: model.DiscountPercent, Percent: 10, Active: false}
+	if err := store.Promos().Create(promo); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(*inserts) != 1 {
+		t.Fatalf("ran %d inserts, want 1", len(*inserts))
+	}
+	if active := insertedValues(t, (*inserts)[0], "active"); len(active) != 1 || active[0] != false {
+		t.Errorf("inserted active = %v, want [false]", active)
+	}
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+// ReaccommodationFilter adalah kriteria pencarian antrean reaccommodation; field kosong tidak dipakai
+type ReaccommodationFilter struct {
+	FlightID uint
+	Status   model.ReaccommodationStatus
+	Offset   int
+	Limit    int
+}
+
+type ReaccommodationRepository interface {
+	Create(entry *model.Reaccommodation) error
+	Update(entry *model.Reaccommodation) error
+	// FindOpenByBooking mengembalikan entry yang masih open untuk booking
+	FindOpenByBooking(bookingID uint) (*model.Reaccommodation, error)
+	// Search mengembalikan entry yang cocok dengan filter (paling awal dulu) beserta jumlah totalnya
+	Search(filter ReaccommodationFilter) ([]model.Reaccommodation, int64, error)
+}
+
+type gormReaccommodationRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormReaccommodationRepository) Create(entry *model.Reaccommodation) error {
+	return translateError(r.db.Create(entry).Error)
+}
+
+func (r *gormReaccommodationRepository) Update(entry *model.Reaccommodation) error {
+	return translateError(r.db.Save(entry).Error)
+}
+
+func (r *gormReaccommodationRepository) FindOpenByBooking(bookingID uint) (*model.Reaccommodation, error) {
+	var entry model.Reaccommodation
+	err := r.db.Where("booking_id = ? AND status = ?", bookingID, model.ReaccommodationOpen).First(&entry).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &entry, nil
+}
+
+func (r *gormReaccommodationRepository) Search(filter ReaccommodationFilter) ([]model.Reaccommodation, int64, error) {
+	query := r.db.Model(&model.Reaccommodation{})
+	if filter.FlightID != 0 {
+		query = query.Where("flight_id = ?", filter.FlightID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []model.Reaccommodation
+	err := query.Order("id").Offset(filter.Offset).Limit(filter.Limit).Find(&entries).Error
+	return entries, total, err
+}
+
+type memoryReaccommodationRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryReaccommodationRepository) Create(entry *model.Reaccommodation) error {
+	defer r.store.lock()()
+
+	entry.ID = r.store.data.newID("reaccommodations")
+	touch(&entry.CreatedAt, &entry.UpdatedAt)
+	if entry.Status == "" {
+		entry.Status = model.ReaccommodationOpen
+	}
+	r.store.data.reaccommodations[entry.ID] = *entry
+	return nil
+}
+
+func (r *memoryReaccommodationRepository) Update(entry *model.Reaccommodation) error {
+	defer r.store.lock()()
+
+	if _, ok := r.store.data.reaccommodations[entry.ID]; !ok {
+		return ErrNotFound
+	}
+	touch(&entry.CreatedAt, &entry.UpdatedAt)
+	r.store.data.reaccommodations[entry.ID] = *entry
+	return nil
+}
+
+func (r *memoryReaccommodationRepository) FindOpenByBooking(bookingID uint) (*model.Reaccommodation, error) {
+	defer r.store.lock()()
+
+	for _, entry := range sortedByID(r.store.data.reaccommodations) {
+		if entry.BookingID == bookingID && entry.Status == model.ReaccommodationOpen {
+			return &entry, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryReaccommodationRepository) Search(filter ReaccommodationFilter) ([]model.Reaccommodation, int64, error) {
+	defer r.store.lock()()
+
+	var matched []model.Reaccommodation
+	for _, entry := range sortedByID(r.store.data.reaccommodations) {
+		if (filter.FlightID == 0 || entry.FlightID == filter.FlightID) &&
+			(filter.Status == "" || entry.Status == filter.Status) {
+			matched = append(matched, entry)
+		}
+	}
+	return paginate(matched, filter.Offset, filter.Limit), int64(len(matched)), nil
+}
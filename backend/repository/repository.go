@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNotFound dikembalikan oleh semua repository ketika record tidak ditemukan
+	ErrNotFound = errors.New("record not found")
+	// ErrDuplicate dikembalikan ketika unique constraint dilanggar
+	ErrDuplicate = errors.New("duplicate record")
+)
+
+// Store mengelompokkan semua repository dan menyediakan transaksi lintas repository
+type Store interface {
+	Users() UserRepository
+	Seats() SeatRepository
+	Bookings() BookingRepository
+	Flights() FlightRepository
+	Waitlist() WaitlistRepository
+	Tokens() TokenRepository
+	Audit() AuditRepository
+	PriceRules() PriceRuleRepository
+	Promos() PromoRepository
+	Passengers() PassengerRepository
+	Eligibility() EligibilityRepository
+	SeatOffers() SeatOfferRepository
+	SeatAssignments() SeatAssignmentRepository
+	SeatMapSyncs() SeatMapSyncRepository
+	Reaccommodations() ReaccommodationRepository
+	Airports() AirportRepository
+	Notifications() NotificationRepository
+	WebhookEndpoints() WebhookEndpointRepository
+	WebhookDeliveries() WebhookDeliveryRepository
+	Transaction(fn func(tx Store) error) error
+}
+
+type gormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore membuat Store yang menyimpan data di Postgres melalui GORM
+func NewGormStore(db *gorm.DB) Store {
+	return &gormStore{db: db}
+}
+
+func (s *gormStore) Users() UserRepository {
+	return &gormUserRepository{db: s.db}
+}
+
+func (s *gormStore) Seats() SeatRepository {
+	return &gormSeatRepository{db: s.db}
+}
+
+func (s *gormStore) Bookings() BookingRepository {
+	return &gormBookingRepository{db: s.db}
+}
+
+func (s *gormStore) Flights() FlightRepository {
+	return &gormFlightRepository{db: s.db}
+}
+
+func (s *gormStore) Waitlist() WaitlistRepository {
+	return &gormWaitlistRepository{db: s.db}
+}
+
+func (s *gormStore) Tokens() TokenRepository {
+	return &gormTokenRepository{db: s.db}
+}
+
+func (s *gormStore) Audit() AuditRepository {
+	return &gormAuditRepository{db: s.db}
+}
+
+func (s *gormStore) PriceRules() PriceRuleRepository {
+	return &gormPriceRuleRepository{db: s.db}
+}
+
+func (s *gormStore) Promos() PromoRepository {
+	return &gormPromoRepository{db: s.db}
+}
+
+func (s *gormStore) Passengers() PassengerRepository {
+	return &gormPassengerRepository{db: s.db}
+}
+
+func (s *gormStore) Eligibility() EligibilityRepository {
+	return &gormEligibilityRepository{db: s.db}
+}
+
+func (s *gormStore) SeatOffers() SeatOfferRepository {
+	return &gormSeatOfferRepository{db: s.db}
+}
+
+func (s *gormStore) SeatAssignments() SeatAssignmentRepository {
+	return &gormSeatAssignmentRepository{db: s.db}
+}
+
+func (s *gormStore) SeatMapSyncs() SeatMapSyncRepository {
+	return &gormSeatMapSyncRepository{db: s.db}
+}
+
+func (s *gormStore) Reaccommodations() ReaccommodationRepository {
+	return &gormReaccommodationRepository{db: s.db}
+}
+
+func (s *gormStore) Airports() AirportRepository {
+	return &gormAirportRepository{db: s.db}
+}
+
+func (s *gormStore) Notifications() NotificationRepository {
+	return &gormNotificationRepository{db: s.db}
+}
+
+func (s *gormStore) WebhookEndpoints() WebhookEndpointRepository {
+	return &gormWebhookEndpointRepository{db: s.db}
+}
+
+func (s *gormStore) WebhookDeliveries() WebhookDeliveryRepository {
+	return &gormWebhookDeliveryRepository{db: s.db}
+}
+
+func (s *gormStore) Transaction(fn func(tx Store) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&gormStore{db: tx})
+	})
+}
+
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return ErrDuplicate
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+type SeatMapSyncRepository interface {
+	Create(run *model.SeatMapSync) error
+	Update(run *model.SeatMapSync) error
+	// Search mengembalikan sync run terbaru dulu beserta jumlah totalnya
+	Search(offset, limit int) ([]model.SeatMapSync, int64, error)
+}
+
+type gormSeatMapSyncRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormSeatMapSyncRepository) Create(run *model.SeatMapSync) error {
+	return translateError(r.db.Create(run).Error)
+}
+
+func (r *gormSeatMapSyncRepository) Update(run *model.SeatMapSync) error {
+	return translateError(r.db.Save(run).Error)
+}
+
+func (r *gormSeatMapSyncRepository) Search(offset, limit int) ([]model.SeatMapSync, int64, error) {
+	var total int64
+	if err := r.db.Model(&model.SeatMapSync{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var runs []model.SeatMapSync
+	err := r.db.Order("id DESC").Offset(offset).Limit(limit).Find(&runs).Error
+	return runs, total, err
+}
+
+type memorySeatMapSyncRepository struct {
+	store *memoryStore
+}
+
+func (r *memorySeatMapSyncRepository) Create(run *model.SeatMapSync) error {
+	defer r.store.lock()()
+
+	run.ID = r.store.data.newID("seat_map_syncs")
+	r.store.data.syncs[run.ID] = *run
+	return nil
+}
+
+func (r *memorySeatMapSyncRepository) Update(run *model.SeatMapSync) error {
+	defer r.store.lock()()
+
+	if _, ok := r.store.data.syncs[run.ID]; !ok {
+		return ErrNotFound
+	}
+	r.store.data.syncs[run.ID] = *run
+	return nil
+}
+
+func (r *memorySeatMapSyncRepository) Search(offset, limit int) ([]model.SeatMapSync, int64, error) {
+	defer r.store.lock()()
+
+	all := sortedByID(r.store.data.syncs)
+	runs := make([]model.SeatMapSync, 0, len(all))
+	for i := len(all) - 1; i >= 0; i-- {
+		runs = append(runs, all[i])
+	}
+	return paginate(runs, offset, limit), int64(len(runs)), nil
+}
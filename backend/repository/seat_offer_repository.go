@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+type SeatOfferRepository interface {
+	// ReplaceForFlight mengganti semua offer penumpang pada penerbangan dengan hasil import terbaru
+	ReplaceForFlight(flightID uint, offers []model.SeatOffer) error
+	FindByFlight(flightID uint) ([]model.SeatOffer, error)
+	FindBySeatAndPassenger(seatID uint, passengerIndex int) (*model.SeatOffer, error)
+}
+
+type SeatAssignmentRepository interface {
+	// ReplaceForFlight mengganti semua assignment kursi pada penerbangan dengan hasil import terbaru
+	ReplaceForFlight(flightID uint, assignments []model.SeatAssignment) error
+	FindByFlight(flightID uint) ([]model.SeatAssignment, error)
+	FindBySeat(seatID uint) (*model.SeatAssignment, error)
+}
+
+type gormSeatOfferRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormSeatOfferRepository) ReplaceForFlight(flightID uint, offers []model.SeatOffer) error {
+	if err := r.db.Where("flight_id = ?", flightID).Delete(&model.SeatOffer{}).Error; err != nil {
+		return err
+	}
+	if len(offers) == 0 {
+		return nil
+	}
+	for i := range offers {
+		offers[i].FlightID = flightID
+	}
+	return translateError(r.db.Create(&offers).Error)
+}
+
+func (r *gormSeatOfferRepository) FindByFlight(flightID uint) ([]model.SeatOffer, error) {
+	var offers []model.SeatOffer
+	err := r.db.Where("flight_id = ?", flightID).Order("seat_id, passenger_index").Find(&offers).Error
+	return offers, err
+}
+
+func (r *gormSeatOfferRepository) FindBySeatAndPassenger(seatID uint, passengerIndex int) (*model.SeatOffer, error) {
+	var offer model.SeatOffer
+	err := r.db.Where("seat_id = ? AND passenger_index = ?", seatID, passengerIndex).First(&offer).Error
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return &offer, nil
+}
+
+type gormSeatAssignmentRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormSeatAssignmentRepository) ReplaceForFlight(flightID uint, assignments []model.SeatAssignment) error {
+	if err := r.db.Where("flight_id = ?", flightID).Delete(&model.SeatAssignment{}).Error; err != nil {
+		return err
+	}
+	if len(assignments) == 0 {
+		return nil
+	}
+	for i := range assignments {
+		assignments[i].FlightID = flightID
+	}
+	return translateError(r.db.Create(&assignments).Error)
+}
+
+func (r *gormSeatAssignmentRepository) FindByFlight(flightID uint) ([]model.SeatAssignment, error) {
+	var assignments []model.SeatAssignment
+	err := r.db.Where("flight_id = ?", flightID).Order("passenger_index").Find(&assignments).Error
+	return assignments, err
+}
+
+func (r *gormSeatAssignmentRepository) FindBySeat(seatID uint) (*model.SeatAssignment, error) {
+	var assignment model.SeatAssignment
+	if err := r.db.Where("seat_id = ?", seatID).First(&assignment).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &assignment, nil
+}
+
+type memorySeatOfferRepository struct {
+	store *memoryStore
+}
+
+func (r *memorySeatOfferRepository) ReplaceForFlight(flightID uint, offers []model.SeatOffer) error {
+	defer r.store.lock()()
+
+	for id, offer := range r.store.data.offers {
+		if offer.FlightID == flightID {
+			delete(r.store.data.offers, id)
+		}
+	}
+
+	type key struct {
+		seatID         uint
+		passengerIndex int
+	}
+	keys := map[key]bool{}
+	for _, offer := range offers {
+		k := key{offer.SeatID, offer.PassengerIndex}
+		if keys[k] {
+			return ErrDuplicate
+		}
+		keys[k] = true
+	}
+	for i := range offers {
+		offers[i].ID = r.store.data.newID("seat_offers")
+		offers[i].FlightID = flightID
+		if offers[i].CreatedAt.IsZero() {
+			offers[i].CreatedAt = time.Now()
+		}
+		r.store.data.offers[offers[i].ID] = offers[i]
+	}
+	return nil
+}
+
+func (r *memorySeatOfferRepository) FindByFlight(flightID uint) ([]model.SeatOffer, error) {
+	defer r.store.lock()()
+
+	var offers []model.SeatOffer
+	for _, offer := range sortedByID(r.store.data.offers) {
+		if offer.FlightID == flightID {
+			offers = append(offers, offer)
+		}
+	}
+	return offers, nil
+}
+
+func (r *memorySeatOfferRepository) FindBySeatAndPassenger(seatID uint, passengerIndex int) (*model.SeatOffer, error) {
+	defer r.store.lock()()
+
+	for _, offer := range r.store.data.offers {
+		if offer.SeatID == seatID && offer.PassengerIndex == passengerIndex {
+			return &offer, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+type memorySeatAssignmentRepository struct {
+	store *memoryStore
+}
+
+func (r *memorySeatAssignmentRepository) ReplaceForFlight(flightID uint, assignments []model.SeatAssignment) error {
+	defer r.store.lock()()
+
+	for id, assignment := range r.store.data.assignments {
+		if assignment.FlightID == flightID {
+			delete(r.store.data.assignments, id)
+		}
+	}
+
+	seats := map[uint]bool{}
+	passengers := map[int]bool{}
+	for _, assignment := range r.store.data.assignments {
+		seats[assignment.SeatID] = true
+	}
+	for _, assignment := range assignments {
+		if seats[assignment.SeatID] || passengers[assignment.PassengerIndex] {
+			return ErrDuplicate
+		}
+		seats[assignment.SeatID] = true
+		passengers[assignment.PassengerIndex] = true
+	}
+	for i := range assignments {
+		assignments[i].ID = r.store.data.newID("seat_assignments")
+		assignments[i].FlightID = flightID
+		if assignments[i].CreatedAt.IsZero() {
+			assignments[i].CreatedAt = time.Now()
+		}
+		r.store.data.assignments[assignments[i].ID] = assignments[i]
+	}
+	return nil
+}
+
+func (r *memorySeatAssignmentRepository) FindByFlight(flightID uint) ([]model.SeatAssignment, error) {
+	defer r.store.lock()()
+
+	var assignments []model.SeatAssignment
+	for _, assignment := range sortedByID(r.store.data.assignments) {
+		if assignment.FlightID == flightID {
+			assignments = append(assignments, assignment)
+		}
+	}
+	return assignments, nil
+}
+
+func (r *memorySeatAssignmentRepository) FindBySeat(seatID uint) (*model.SeatAssignment, error) {
+	defer r.store.lock()()
+
+	for _, assignment := range r.store.data.assignments {
+		if assignment.SeatID == seatID {
+			return &assignment, nil
+		}
+	}
+	return nil, ErrNotFound
+}
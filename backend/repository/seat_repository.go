@@ -1,18 +1,327 @@
 package repository
 
 import (
-	"github.com/tiananugerah/go-BookCabin/config"
+	"slices"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
 	"github.com/tiananugerah/go-BookCabin/model"
 )
 
-func SaveSeats(seats []model.Seat) {
+type SeatRepository interface {
+	CreateBatch(seats []model.Seat) error
+	// Delete menghapus kursi yang tidak lagi ada di seat map sumber dan tidak pernah dibooking
+	Delete(ids []uint) error
+	FindAll() ([]model.Seat, error)
+	FindByID(id uint) (*model.Seat, error)
+	FindByFlight(flightID uint) ([]model.Seat, error)
+	// FindUnbooked mengembalikan kursi yang tidak memiliki booking confirmed, hold, maupun
+	// assignment dari sistem sumber
+	FindUnbooked() ([]model.Seat, error)
+	// FindUnbookedByFlight sama dengan FindUnbooked tetapi hanya untuk satu penerbangan
+	FindUnbookedByFlight(flightID uint) ([]model.Seat, error)
+	SetAvailable(id uint, available bool) error
+	// SetBlock mengganti state block kursi; SeatBlock kosong berarti block dicabut
+	SetBlock(id uint, block model.SeatBlock) error
+	// FindExpiredBlocks mengembalikan kursi yang block-nya punya batas waktu dan sudah lewat pada now
+	FindExpiredBlocks(now time.Time) ([]model.Seat, error)
+	// Update menyimpan kolom kursi tanpa menyentuh relasi booking
+	Update(seat *model.Seat) error
+	// CountByCabin menghitung kursi per kabin pada setiap penerbangan, beserta kursi yang masih
+	// bisa dijual (available dan tanpa assignment dari sistem sumber)
+	CountByCabin(flightIDs []uint) ([]CabinSeatCount, error)
+}
+
+// CabinSeatCount adalah jumlah kursi satu kabin pada satu penerbangan
+type CabinSeatCount struct {
+	FlightID  uint
+	Cabin     string
+	Total     int
+	Available int
+}
+
+type gormSeatRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormSeatRepository) CreateBatch(seats []model.Seat) error {
+	if len(seats) == 0 {
+		return nil
+	}
+	return translateError(r.db.Create(&seats).Error)
+}
+
+func (r *gormSeatRepository) Delete(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Exec("DELETE FROM seats WHERE id IN ?", ids).Error
+}
+
+func (r *gormSeatRepository) FindAll() ([]model.Seat, error) {
+	var seats []model.Seat
+	err := r.db.Find(&seats).Error
+	return seats, err
+}
+
+func (r *gormSeatRepository) FindByID(id uint) (*model.Seat, error) {
+	var seat model.Seat
+	if err := r.db.First(&seat, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &seat, nil
+}
+
+func (r *gormSeatRepository) FindByFlight(flightID uint) ([]model.Seat, error) {
+	var seats []model.Seat
+	err := r.db.Where("flight_id = ?", flightID).Order("id").Find(&seats).Error
+	return seats, err
+}
+
+func (r *gormSeatRepository) FindUnbooked() ([]model.Seat, error) {
+	var seats []model.Seat
+	err := r.unbooked(r.db).Find(&seats).Error
+	return seats, err
+}
+
+func (r *gormSeatRepository) FindUnbookedByFlight(flightID uint) ([]model.Seat, error) {
+	var seats []model.Seat
+	err := r.unbooked(r.db.Where("flight_id = ?", flightID)).Order("id").Find(&seats).Error
+	return seats, err
+}
+
+func (r *gormSeatRepository) unbooked(query *gorm.DB) *gorm.DB {
+	return query.Where(
+		"id NOT IN (SELECT seat_id FROM bookings WHERE status IN ? AND deleted_at IS NULL) AND id NOT IN (SELECT seat_id FROM seat_assignments)",
+		model.ActiveStatuses,
+	)
+}
+
+func (r *gormSeatRepository) SetAvailable(id uint, available bool) error {
+	return r.db.Model(&model.Seat{}).Where("id = ?", id).Update("available", available).Error
+}
+
+func (r *gormSeatRepository) SetBlock(id uint, block model.SeatBlock) error {
+	return r.db.Model(&model.Seat{}).Where("id = ?", id).
+		Select("block_reason", "block_source", "blocked_by", "blocked_at", "blocked_until").
+		Updates(&model.Seat{SeatBlock: block}).Error
+}
+
+func (r *gormSeatRepository) FindExpiredBlocks(now time.Time) ([]model.Seat, error) {
+	var seats []model.Seat
+	err := r.db.Where("block_reason <> '' AND blocked_until <= ?", now).Order("id").Find(&seats).Error
+	return seats, err
+}
+
+func (r *gormSeatRepository) Update(seat *model.Seat) error {
+	return translateError(r.db.Omit(clause.Associations).Save(seat).Error)
+}
+
+func (r *gormSeatRepository) CountByCabin(flightIDs []uint) ([]CabinSeatCount, error) {
+	var counts []CabinSeatCount
+	if len(flightIDs) == 0 {
+		return counts, nil
+	}
+	err := r.db.Model(&model.Seat{}).
+		Select("flight_id, segment AS cabin, COUNT(*) AS total, "+
+			"SUM(CASE WHEN available AND id NOT IN (SELECT seat_id FROM seat_assignments) THEN 1 ELSE 0 END) AS available").
+		Where("flight_id IN ?", flightIDs).
+		Group("flight_id, segment").Order("flight_id, segment").
+		Scan(&counts).Error
+	return counts, err
+}
+
+type memorySeatRepository struct {
+	store *memoryStore
+}
+
+func (r *memorySeatRepository) CreateBatch(seats []model.Seat) error {
+	defer r.store.lock()()
+
+	type key struct {
+		flightID uint
+		code     string
+	}
+	codes := map[key]bool{}
+	for _, existing := range r.store.data.seats {
+		codes[key{existing.FlightID, existing.SeatCode}] = true
+	}
 	for _, seat := range seats {
-		config.DB.Create(&seat)
+		k := key{seat.FlightID, seat.SeatCode}
+		if codes[k] {
+			return ErrDuplicate
+		}
+		codes[k] = true
 	}
+
+	for i := range seats {
+		seats[i].ID = r.store.data.newID("seats")
+		touch(&seats[i].CreatedAt, &seats[i].UpdatedAt)
+		r.store.data.seats[seats[i].ID] = seats[i]
+	}
+	return nil
 }
 
-func GetAllSeats() []model.Seat {
+func (r *memorySeatRepository) Delete(ids []uint) error {
+	defer r.store.lock()()
+
+	for _, id := range ids {
+		delete(r.store.data.seats, id)
+	}
+	return nil
+}
+
+func (r *memorySeatRepository) FindAll() ([]model.Seat, error) {
+	defer r.store.lock()()
+
+	return sortedByID(r.store.data.seats), nil
+}
+
+func (r *memorySeatRepository) FindByID(id uint) (*model.Seat, error) {
+	defer r.store.lock()()
+
+	seat, ok := r.store.data.seats[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &seat, nil
+}
+
+func (r *memorySeatRepository) FindByFlight(flightID uint) ([]model.Seat, error) {
+	defer r.store.lock()()
+
+	var seats []model.Seat
+	for _, seat := range sortedByID(r.store.data.seats) {
+		if seat.FlightID == flightID {
+			seats = append(seats, seat)
+		}
+	}
+	return seats, nil
+}
+
+func (r *memorySeatRepository) FindUnbooked() ([]model.Seat, error) {
+	return r.unbooked(0)
+}
+
+func (r *memorySeatRepository) FindUnbookedByFlight(flightID uint) ([]model.Seat, error) {
+	return r.unbooked(flightID)
+}
+
+// unbooked mengembalikan kursi tanpa booking aktif maupun assignment; flightID 0 berarti semua penerbangan
+func (r *memorySeatRepository) unbooked(flightID uint) ([]model.Seat, error) {
+	defer r.store.lock()()
+
+	booked := map[uint]bool{}
+	for _, booking := range r.store.data.bookings {
+		if isActive(booking.Status) {
+			booked[booking.SeatID] = true
+		}
+	}
+	for _, assignment := range r.store.data.assignments {
+		booked[assignment.SeatID] = true
+	}
+
+	var seats []model.Seat
+	for _, seat := range sortedByID(r.store.data.seats) {
+		if !booked[seat.ID] && (flightID == 0 || seat.FlightID == flightID) {
+			seats = append(seats, seat)
+		}
+	}
+	return seats, nil
+}
+
+func (r *memorySeatRepository) SetAvailable(id uint, available bool) error {
+	defer r.store.lock()()
+
+	seat, ok := r.store.data.seats[id]
+	if !ok {
+		return nil
+	}
+	seat.Available = available
+	touch(&seat.CreatedAt, &seat.UpdatedAt)
+	r.store.data.seats[id] = seat
+	return nil
+}
+
+func (r *memorySeatRepository) SetBlock(id uint, block model.SeatBlock) error {
+	defer r.store.lock()()
+
+	seat, ok := r.store.data.seats[id]
+	if !ok {
+		return nil
+	}
+	seat.SeatBlock = block
+	touch(&seat.CreatedAt, &seat.UpdatedAt)
+	r.store.data.seats[id] = seat
+	return nil
+}
+
+func (r *memorySeatRepository) FindExpiredBlocks(now time.Time) ([]model.Seat, error) {
+	defer r.store.lock()()
+
 	var seats []model.Seat
-	config.DB.Find(&seats)
-	return seats
+	for _, seat := range sortedByID(r.store.data.seats) {
+		if seat.BlockReason != "" && seat.BlockedUntil != nil && !seat.BlockedUntil.After(now) {
+			seats = append(seats, seat)
+		}
+	}
+	return seats, nil
+}
+
+func (r *memorySeatRepository) Update(seat *model.Seat) error {
+	defer r.store.lock()()
+
+	if _, ok := r.store.data.seats[seat.ID]; !ok {
+		return ErrNotFound
+	}
+	stored := *seat
+	stored.Bookings = nil
+	touch(&stored.CreatedAt, &stored.UpdatedAt)
+	r.store.data.seats[seat.ID] = stored
+	return nil
+}
+
+func (r *memorySeatRepository) CountByCabin(flightIDs []uint) ([]CabinSeatCount, error) {
+	defer r.store.lock()()
+
+	assigned := map[uint]bool{}
+	for _, assignment := range r.store.data.assignments {
+		assigned[assignment.SeatID] = true
+	}
+
+	type key struct {
+		flightID uint
+		cabin    string
+	}
+	counts := map[key]*CabinSeatCount{}
+	var order []key
+	for _, seat := range sortedByID(r.store.data.seats) {
+		if !slices.Contains(flightIDs, seat.FlightID) {
+			continue
+		}
+		k := key{seat.FlightID, seat.Segment}
+		if counts[k] == nil {
+			counts[k] = &CabinSeatCount{FlightID: seat.FlightID, Cabin: seat.Segment}
+			order = append(order, k)
+		}
+		counts[k].Total++
+		if seat.Available && !assigned[seat.ID] {
+			counts[k].Available++
+		}
+	}
+
+	sort.Slice(order, func(i, j int) bool {
+		if order[i].flightID != order[j].flightID {
+			return order[i].flightID < order[j].flightID
+		}
+		return order[i].cabin < order[j].cabin
+	})
+	result := make([]CabinSeatCount, 0, len(order))
+	for _, k := range order {
+		result = append(result, *counts[k])
+	}
+	return result, nil
 }
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+func TestGormCreateBatchWritesUnavailableSeats(t *testing.T) {
+	store, inserts := dryRunStore(t)
+
+	blocked := model.SeatBlock{BlockReason: "UNAVAILABLE_IN_SOURCE", BlockSource: model.SeatBlockImport}
+	seats := []model.Seat{
+		{FlightID: 1, SeatCode: "1A", Available: true},
+		{FlightID: 1, SeatCode: "1B", Available: false, SeatBlock: blocked},
+	}
+	if err := store.Seats().CreateBatch(seats); err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+	if len(*inserts) != 1 {
+		t.Fatalf("ran %d inserts, want 1", len(*inserts))
+	}
+
+	available := insertedValues(t, (*inserts)[0], "available")
+	if len(available) != 2 || available[0] != true || available[1] != false {
+		t.Errorf("inserted available = %v, want [true false]", available)
+	}
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+type TokenRepository interface {
+	Create(token *model.UserToken) error
+	FindByHash(purpose, hash string) (*model.UserToken, error)
+	// MarkUsed menandai token terpakai; mengembalikan ErrNotFound jika token sudah dipakai lebih dulu
+	MarkUsed(id uint, at time.Time) error
+	// InvalidateForUser menandai semua token user yang belum dipakai untuk purpose tersebut
+	InvalidateForUser(userID uint, purpose string, at time.Time) error
+}
+
+type gormTokenRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormTokenRepository) Create(token *model.UserToken) error {
+	return translateError(r.db.Create(token).Error)
+}
+
+func (r *gormTokenRepository) FindByHash(purpose, hash string) (*model.UserToken, error) {
+	var token model.UserToken
+	if err := r.db.Where("purpose = ? AND token_hash = ?", purpose, hash).First(&token).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &token, nil
+}
+
+func (r *gormTokenRepository) MarkUsed(id uint, at time.Time) error {
+	result := r.db.Model(&model.UserToken{}).Where("id = ? AND used_at IS NULL", id).Update("used_at", at)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *gormTokenRepository) InvalidateForUser(userID uint, purpose string, at time.Time) error {
+	return r.db.Model(&model.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", at).Error
+}
+
+type memoryTokenRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryTokenRepository) Create(token *model.UserToken) error {
+	defer r.store.lock()()
+
+	for _, existing := range r.store.data.tokens {
+		if existing.TokenHash == token.TokenHash {
+			return ErrDuplicate
+		}
+	}
+
+	token.ID = r.store.data.newID("user_tokens")
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+	r.store.data.tokens[token.ID] = *token
+	return nil
+}
+
+func (r *memoryTokenRepository) FindByHash(purpose, hash string) (*model.UserToken, error) {
+	defer r.store.lock()()
+
+	for _, token := range r.store.data.tokens {
+		if token.Purpose == purpose && token.TokenHash == hash {
+			return &token, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *memoryTokenRepository) MarkUsed(id uint, at time.Time) error {
+	defer r.store.lock()()
+
+	token, ok := r.store.data.tokens[id]
+	if !ok || token.UsedAt != nil {
+		return ErrNotFound
+	}
+	token.UsedAt = &at
+	r.store.data.tokens[id] = token
+	return nil
+}
+
+func (r *memoryTokenRepository) InvalidateForUser(userID uint, purpose string, at time.Time) error {
+	defer r.store.lock()()
+
+	for id, token := range r.store.data.tokens {
+		if token.UserID == userID && token.Purpose == purpose && token.UsedAt == nil {
+			token.UsedAt = &at
+			r.store.data.tokens[id] = token
+		}
+	}
+	return nil
+}
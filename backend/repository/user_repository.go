@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+// UserFilter adalah kriteria pencarian user untuk admin; field kosong tidak dipakai
+type UserFilter struct {
+	// Query dicocokkan sebagian (case-insensitive) dengan email atau nama
+	Query  string
+	Role   string
+	Offset int
+	Limit  int
+}
+
+type UserRepository interface {
+	Create(user *model.User) error
+	Update(user *model.User) error
+	FindByID(id uint) (*model.User, error)
+	// Lock mengunci baris user sampai transaksi selesai (SELECT ... FOR UPDATE) sehingga transaksi
+	// lain yang mengunci user yang sama menunggu. Hanya berarti di dalam Transaction.
+	Lock(id uint) error
+	// FindByIDWithDeleted juga mengembalikan user yang sudah dihapus, untuk menampilkan data booking lama
+	FindByIDWithDeleted(id uint) (*model.User, error)
+	FindByEmail(email string) (*model.User, error)
+	// Delete melakukan soft delete
+	Delete(id uint) error
+	// Search mengembalikan user aktif yang cocok dengan filter beserta jumlah totalnya
+	Search(filter UserFilter) ([]model.User, int64, error)
+}
+
+type gormUserRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormUserRepository) Create(user *model.User) error {
+	return translateError(r.db.Create(user).Error)
+}
+
+func (r *gormUserRepository) Update(user *model.User) error {
+	return translateError(r.db.Omit(clause.Associations).Save(user).Error)
+}
+
+func (r *gormUserRepository) FindByID(id uint) (*model.User, error) {
+	var user model.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) Lock(id uint) error {
+	var user model.User
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&user, id).Error
+	return translateError(err)
+}
+
+func (r *gormUserRepository) FindByIDWithDeleted(id uint) (*model.User, error) {
+	var user model.User
+	if err := r.db.Unscoped().First(&user, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &user, nil
+}
+
+func (r *gormUserRepository) Delete(id uint) error {
+	return r.db.Delete(&model.User{}, id).Error
+}
+
+func (r *gormUserRepository) Search(filter UserFilter) ([]model.User, int64, error) {
+	query := r.db.Model(&model.User{})
+	if filter.Query != "" {
+		like := "%" + filter.Query + "%"
+		query = query.Where("email ILIKE ? OR name ILIKE ?", like, like)
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []model.User
+	err := query.Order("id").Offset(filter.Offset).Limit(filter.Limit).Find(&users).Error
+	return users, total, err
+}
+
+func (r *gormUserRepository) FindByEmail(email string) (*model.User, error) {
+	var user model.User
+	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &user, nil
+}
+
+type memoryUserRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryUserRepository) Create(user *model.User) error {
+	defer r.store.lock()()
+
+	for _, existing := range r.store.data.users {
+		if existing.Email == user.Email {
+			return ErrDuplicate
+		}
+	}
+
+	user.ID = r.store.data.newID("users")
+	touch(&user.CreatedAt, &user.UpdatedAt)
+	r.store.data.users[user.ID] = *user
+	return nil
+}
+
+func (r *memoryUserRepository) Update(user *model.User) error {
+	defer r.store.lock()()
+
+	if _, ok := r.store.data.users[user.ID]; !ok {
+		return ErrNotFound
+	}
+	for id, existing := range r.store.data.users {
+		if id != user.ID && existing.Email == user.Email {
+			return ErrDuplicate
+		}
+	}
+	touch(&user.CreatedAt, &user.UpdatedAt)
+	r.store.data.users[user.ID] = *user
+	return nil
+}
+
+func (r *memoryUserRepository) FindByID(id uint) (*model.User, error) {
+	defer r.store.lock()()
+
+	user, ok := r.store.data.users[id]
+	if !ok || user.DeletedAt.Valid {
+		return nil, ErrNotFound
+	}
+	return &user, nil
+}
+
+// Lock hanya memeriksa keberadaan user; transaksi memory store sudah berjalan berurutan
+func (r *memoryUserRepository) Lock(id uint) error {
+	defer r.store.lock()()
+
+	if user, ok := r.store.data.users[id]; !ok || user.DeletedAt.Valid {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *memoryUserRepository) FindByIDWithDeleted(id uint) (*model.User, error) {
+	defer r.store.lock()()
+
+	user, ok := r.store.data.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r *memoryUserRepository) Delete(id uint) error {
+	defer r.store.lock()()
+
+	user, ok := r.store.data.users[id]
+	if !ok {
+		return nil
+	}
+	user.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	r.store.data.users[id] = user
+	return nil
+}
+
+func (r *memoryUserRepository) Search(filter UserFilter) ([]model.User, int64, error) {
+	defer r.store.lock()()
+
+	query := strings.ToLower(filter.Query)
+	var matched []model.User
+	for _, user := range sortedByID(r.store.data.users) {
+		if user.DeletedAt.Valid ||
+			(query != "" && !strings.Contains(strings.ToLower(user.Email), query) && !strings.Contains(strings.ToLower(user.Name), query)) ||
+			(filter.Role != "" && user.Role != filter.Role) {
+			continue
+		}
+		matched = append(matched, user)
+	}
+	return paginate(matched, filter.Offset, filter.Limit), int64(len(matched)), nil
+}
+
+func (r *memoryUserRepository) FindByEmail(email string) (*model.User, error) {
+	defer r.store.lock()()
+
+	for _, user := range r.store.data.users {
+		if user.Email == email && !user.DeletedAt.Valid {
+			return &user, nil
+		}
+	}
+	return nil, ErrNotFound
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+type WaitlistRepository interface {
+	Create(entry *model.WaitlistEntry) error
+	Update(entry *model.WaitlistEntry) error
+	FindByID(id uint) (*model.WaitlistEntry, error)
+	FindByUser(userID uint) ([]model.WaitlistEntry, error)
+	// FindWaiting mengembalikan antrean penerbangan berstatus waiting, urut dari yang paling awal
+	FindWaiting(flightID uint) ([]model.WaitlistEntry, error)
+	FindByHoldBooking(bookingID uint) (*model.WaitlistEntry, error)
+}
+
+type gormWaitlistRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormWaitlistRepository) Create(entry *model.WaitlistEntry) error {
+	return translateError(r.db.Create(entry).Error)
+}
+
+func (r *gormWaitlistRepository) Update(entry *model.WaitlistEntry) error {
+	return translateError(r.db.Save(entry).Error)
+}
+
+func (r *gormWaitlistRepository) FindByID(id uint) (*model.WaitlistEntry, error) {
+	var entry model.WaitlistEntry
+	if err := r.db.First(&entry, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &entry, nil
+}
+
+func (r *gormWaitlistRepository) FindByUser(userID uint) ([]model.WaitlistEntry, error) {
+	var entries []model.WaitlistEntry
+	err := r.db.Where("user_id = ?", userID).Order("id").Find(&entries).Error
+	return entries, err
+}
+
+func (r *gormWaitlistRepository) FindWaiting(flightID uint) ([]model.WaitlistEntry, error) {
+	var entries []model.WaitlistEntry
+	err := r.db.Where("flight_id = ? AND status = ?", flightID, model.WaitlistWaiting).Order("id").Find(&entries).Error
+	return entries, err
+}
+
+func (r *gormWaitlistRepository) FindByHoldBooking(bookingID uint) (*model.WaitlistEntry, error) {
+	var entry model.WaitlistEntry
+	if err := r.db.Where("hold_booking_id = ?", bookingID).First(&entry).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &entry, nil
+}
+
+type memoryWaitlistRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryWaitlistRepository) Create(entry *model.WaitlistEntry) error {
+	defer r.store.lock()()
+
+	entry.ID = r.store.data.newID("waitlist_entries")
+	touch(&entry.CreatedAt, &entry.UpdatedAt)
+	r.store.data.waitlist[entry.ID] = *entry
+	return nil
+}
+
+func (r *memoryWaitlistRepository) Update(entry *model.WaitlistEntry) error {
+	defer r.store.lock()()
+
+	if _, ok := r.store.data.waitlist[entry.ID]; !ok {
+		return ErrNotFound
+	}
+	touch(&entry.CreatedAt, &entry.UpdatedAt)
+	r.store.data.waitlist[entry.ID] = *entry
+	return nil
+}
+
+func (r *memoryWaitlistRepository) FindByID(id uint) (*model.WaitlistEntry, error) {
+	defer r.store.lock()()
+
+	entry, ok := r.store.data.waitlist[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &entry, nil
+}
+
+func (r *memoryWaitlistRepository) FindByUser(userID uint) ([]model.WaitlistEntry, error) {
+	defer r.store.lock()()
+
+	var entries []model.WaitlistEntry
+	for _, entry := range sortedByID(r.store.data.waitlist) {
+		if entry.UserID == userID {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (r *memoryWaitlistRepository) FindWaiting(flightID uint) ([]model.WaitlistEntry, error) {
+	defer r.store.lock()()
+
+	var entries []model.WaitlistEntry
+	for _, entry := range sortedByID(r.store.data.waitlist) {
+		if entry.FlightID == flightID && entry.Status == model.WaitlistWaiting {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (r *memoryWaitlistRepository) FindByHoldBooking(bookingID uint) (*model.WaitlistEntry, error) {
+	defer r.store.lock()()
+
+	for _, entry := range sortedByID(r.store.data.waitlist) {
+		if entry.HoldBookingID != nil && *entry.HoldBookingID == bookingID {
+			return &entry, nil
+		}
+	}
+	return nil, ErrNotFound
+}
@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+type WebhookEndpointRepository interface {
+	Create(endpoint *model.WebhookEndpoint) error
+	Update(endpoint *model.WebhookEndpoint) error
+	Delete(id uint) error
+	FindByID(id uint) (*model.WebhookEndpoint, error)
+	// FindAll mengembalikan semua endpoint, urut ID
+	FindAll() ([]model.WebhookEndpoint, error)
+}
+
+// WebhookDeliveryFilter adalah kriteria pencarian delivery webhook; field kosong tidak dipakai
+type WebhookDeliveryFilter struct {
+	EndpointID uint
+	Event      string
+	Status     model.WebhookDeliveryStatus
+	Offset     int
+	Limit      int
+}
+
+type WebhookDeliveryRepository interface {
+	// Create mengembalikan ErrDuplicate jika event yang sama sudah dibuatkan delivery untuk endpoint
+	Create(delivery *model.WebhookDelivery) error
+	Update(delivery *model.WebhookDelivery) error
+	FindByID(id uint) (*model.WebhookDelivery, error)
+	// FindDue mengembalikan delivery pending yang jadwal percobaannya sudah tiba pada now
+	FindDue(now time.Time, limit int) ([]model.WebhookDelivery, error)
+	// Claim mengambil delivery untuk satu percobaan kirim: Attempts dinaikkan dan percobaan
+	// berikutnya ditunda sampai leaseUntil. False jika delivery sudah diambil proses lain.
+	Claim(delivery *model.WebhookDelivery, leaseUntil time.Time) (bool, error)
+	// Search mengembalikan delivery yang cocok dengan filter (terbaru dulu) beserta jumlah totalnya
+	Search(filter WebhookDeliveryFilter) ([]model.WebhookDelivery, int64, error)
+}
+
+type gormWebhookEndpointRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormWebhookEndpointRepository) Create(endpoint *model.WebhookEndpoint) error {
+	return translateError(r.db.Create(endpoint).Error)
+}
+
+func (r *gormWebhookEndpointRepository) Update(endpoint *model.WebhookEndpoint) error {
+	return translateError(r.db.Save(endpoint).Error)
+}
+
+func (r *gormWebhookEndpointRepository) Delete(id uint) error {
+	return r.db.Delete(&model.WebhookEndpoint{}, id).Error
+}
+
+func (r *gormWebhookEndpointRepository) FindByID(id uint) (*model.WebhookEndpoint, error) {
+	var endpoint model.WebhookEndpoint
+	if err := r.db.First(&endpoint, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &endpoint, nil
+}
+
+func (r *gormWebhookEndpointRepository) FindAll() ([]model.WebhookEndpoint, error) {
+	var endpoints []model.WebhookEndpoint
+	err := r.db.Order("id").Find(&endpoints).Error
+	return endpoints, err
+}
+
+type gormWebhookDeliveryRepository struct {
+	db *gorm.DB
+}
+
+func (r *gormWebhookDeliveryRepository) Create(delivery *model.WebhookDelivery) error {
+	return translateError(r.db.Create(delivery).Error)
+}
+
+func (r *gormWebhookDeliveryRepository) Update(delivery *model.WebhookDelivery) error {
+	return translateError(r.db.Save(delivery).Error)
+}
+
+func (r *gormWebhookDeliveryRepository) FindByID(id uint) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	if err := r.db.First(&delivery, id).Error; err != nil {
+		return nil, translateError(err)
+	}
+	return &delivery, nil
+}
+
+func (r *gormWebhookDeliveryRepository) FindDue(now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+	err := r.db.Where("status = ? AND next_attempt_at <= ?", model.WebhookPending, now).
+		Order("next_attempt_at, id").Limit(limit).Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (r *gormWebhookDeliveryRepository) Claim(delivery *model.WebhookDelivery, leaseUntil time.Time) (bool, error) {
+	result := r.db.Model(&model.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND attempts = ?", delivery.ID, model.WebhookPending, delivery.Attempts).
+		Updates(map[string]interface{}{"attempts": delivery.Attempts + 1, "next_attempt_at": leaseUntil})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	delivery.Attempts++
+	delivery.NextAttemptAt = &leaseUntil
+	return true, nil
+}
+
+func (r *gormWebhookDeliveryRepository) Search(filter WebhookDeliveryFilter) ([]model.WebhookDelivery, int64, error) {
+	query := r.db.Model(&model.WebhookDelivery{})
+	if filter.EndpointID != 0 {
+		query = query.Where("endpoint_id = ?", filter.EndpointID)
+	}
+	if filter.Event != "" {
+		query = query.Where("event = ?", filter.Event)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deliveries []model.WebhookDelivery
+	err := query.Order("id DESC").Offset(filter.Offset).Limit(filter.Limit).Find(&deliveries).Error
+	return deliveries, total, err
+}
+
+type memoryWebhookEndpointRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryWebhookEndpointRepository) Create(endpoint *model.WebhookEndpoint) error {
+	defer r.store.lock()()
+
+	endpoint.ID = r.store.data.newID("webhook_endpoints")
+	touch(&endpoint.CreatedAt, &endpoint.UpdatedAt)
+	r.store.data.webhooks[endpoint.ID] = *endpoint
+	return nil
+}
+
+func (r *memoryWebhookEndpointRepository) Update(endpoint *model.WebhookEndpoint) error {
+	defer r.store.lock()()
+
+	if _, ok := r.store.data.webhooks[endpoint.ID]; !ok {
+		return ErrNotFound
+	}
+	touch(&endpoint.CreatedAt, &endpoint.UpdatedAt)
+	r.store.data.webhooks[endpoint.ID] = *endpoint
+	return nil
+}
+
+func (r *memoryWebhookEndpointRepository) Delete(id uint) error {
+	defer r.store.lock()()
+
+	delete(r.store.data.webhooks, id)
+	return nil
+}
+
+func (r *memoryWebhookEndpointRepository) FindByID(id uint) (*model.WebhookEndpoint, error) {
+	defer r.store.lock()()
+
+	endpoint, ok := r.store.data.webhooks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &endpoint, nil
+}
+
+func (r *memoryWebhookEndpointRepository) FindAll() ([]model.WebhookEndpoint, error) {
+	defer r.store.lock()()
+
+	return sortedByID(r.store.data.webhooks), nil
+}
+
+type memoryWebhookDeliveryRepository struct {
+	store *memoryStore
+}
+
+func (r *memoryWebhookDeliveryRepository) Create(delivery *model.WebhookDelivery) error {
+	defer r.store.lock()()
+
+	for _, existing := range r.store.data.deliveries {
+		if existing.EndpointID == delivery.EndpointID && existing.EventKey == delivery.EventKey {
+			return ErrDuplicate
+		}
+	}
+	delivery.ID = r.store.data.newID("webhook_deliveries")
+	touch(&delivery.CreatedAt, &delivery.UpdatedAt)
+	if delivery.Status == "" {
+		delivery.Status = model.WebhookPending
+	}
+	r.store.data.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+func (r *memoryWebhookDeliveryRepository) Update(delivery *model.WebhookDelivery) error {
+	defer r.store.lock()()
+
+	if _, ok := r.store.data.deliveries[delivery.ID]; !ok {
+		return ErrNotFound
+	}
+	touch(&delivery.CreatedAt, &delivery.UpdatedAt)
+	r.store.data.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+func (r *memoryWebhookDeliveryRepository) FindByID(id uint) (*model.WebhookDelivery, error) {
+	defer r.store.lock()()
+
+	delivery, ok := r.store.data.deliveries[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &delivery, nil
+}
+
+func (r *memoryWebhookDeliveryRepository) FindDue(now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	defer r.store.lock()()
+
+	var due []model.WebhookDelivery
+	for _, delivery := range sortedByID(r.store.data.deliveries) {
+		if delivery.Status == model.WebhookPending && delivery.NextAttemptAt != nil &&
+			!delivery.NextAttemptAt.After(now) {
+			due = append(due, delivery)
+		}
+	}
+	return paginate(due, 0, limit), nil
+}
+
+func (r *memoryWebhookDeliveryRepository) Claim(delivery *model.WebhookDelivery, leaseUntil time.Time) (bool, error) {
+	defer r.store.lock()()
+
+	current, ok := r.store.data.deliveries[delivery.ID]
+	if !ok || current.Status != model.WebhookPending || current.Attempts != delivery.Attempts {
+		return false, nil
+	}
+	current.Attempts++
+	current.NextAttemptAt = &leaseUntil
+	r.store.data.deliveries[current.ID] = current
+	*delivery = current
+	return true, nil
+}
+
+func (r *memoryWebhookDeliveryRepository) Search(filter WebhookDeliveryFilter) ([]model.WebhookDelivery, int64, error) {
+	defer r.store.lock()()
+
+	var matched []model.WebhookDelivery
+	deliveries := sortedByID(r.store.data.deliveries)
+	for i := len(deliveries) - 1; i >= 0; i-- {
+		delivery := deliveries[i]
+		if (filter.EndpointID == 0 || delivery.EndpointID == filter.EndpointID) &&
+			(filter.Event == "" || delivery.Event == filter.Event) &&
+			(filter.Status == "" || delivery.Status == filter.Status) {
+			matched = append(matched, delivery)
+		}
+	}
+	return paginate(matched, filter.Offset, filter.Limit), int64(len(matched)), nil
+}
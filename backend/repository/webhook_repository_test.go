@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+func TestGormCreateWritesInactiveWebhook(t *testing.T) {
+	store, inserts := dryRunStore(t)
+
+	endpoint := &model.WebhookEndpoint{Name: "ops", URL: "https://partner.example.com/hook", Secret: "s3cret", Active: false}
+	if err := store.WebhookEndpoints().Create(endpoint); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if len(*inserts) != 1 {
+		t.Fatalf("ran %d inserts, want 1", len(*inserts))
+	}
+	if active := insertedValues(t, (*inserts)[0], "active"); len(active) != 1 || active[0] != false {
+		t.Errorf("inserted active = %v, want [false]", active)
+	}
+}
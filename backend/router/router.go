@@ -1,13 +1,66 @@
 package router
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/tiananugerah/go-BookCabin/controller"
 	"github.com/tiananugerah/go-BookCabin/middleware"
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/ratelimit"
 	"github.com/tiananugerah/go-BookCabin/service"
 )
 
-func SetupRoutes(r *gin.Engine, authService *service.AuthService, bookingService *service.BookingService, seatService *service.SeatService) {
+// RateLimits adalah limit request per route group
+type RateLimits struct {
+	// Auth berlaku per IP untuk semua route /auth
+	Auth ratelimit.Limit
+	// Public berlaku per IP untuk route tanpa login seperti manage-booking
+	Public ratelimit.Limit
+	// API berlaku per user untuk semua route /api
+	API ratelimit.Limit
+	// Booking berlaku per user untuk /api/bookings, di samping limit API
+	Booking ratelimit.Limit
+	// Admin berlaku per user untuk semua route /admin, terpisah dari limit API
+	Admin ratelimit.Limit
+}
+
+func DefaultRateLimits() RateLimits {
+	return RateLimits{
+		Auth:    ratelimit.Limit{Requests: 20, Window: time.Minute},
+		Public:  ratelimit.Limit{Requests: 30, Window: time.Minute},
+		API:     ratelimit.Limit{Requests: 300, Window: time.Minute},
+		Booking: ratelimit.Limit{Requests: 30, Window: time.Minute},
+		Admin:   ratelimit.Limit{Requests: 120, Window: time.Minute},
+	}
+}
+
+// Dependencies berisi service dan komponen yang dibutuhkan route
+type Dependencies struct {
+	AuthService         *service.AuthService
+	BookingService      *service.BookingService
+	SeatService         *service.SeatService
+	WaitlistService     *service.WaitlistService
+	CheckInService      *service.CheckInService
+	UserService         *service.UserService
+	AdminService        *service.AdminService
+	PricingService      *service.PricingService
+	PromoService        *service.PromoService
+	EligibilityService  *service.EligibilityService
+	SeatMapSyncService  *service.SeatMapSyncService
+	EquipmentService    *service.EquipmentService
+	FlightService       *service.FlightService
+	AirportService      *service.AirportService
+	NotificationService *service.NotificationService
+	WebhookService      *service.WebhookService
+	Limiter             *ratelimit.Limiter
+	RateLimits          RateLimits
+}
+
+func SetupRoutes(r *gin.Engine, deps Dependencies) {
+	authService := deps.AuthService
+	limiter, limits := deps.Limiter, deps.RateLimits
+
 	// ✅ CORS middleware harus paling atas
 	r.Use(func(c *gin.Context) {
 		origin := c.Request.Header.Get("Origin")
@@ -15,7 +68,7 @@ func SetupRoutes(r *gin.Engine, authService *service.AuthService, bookingService
 			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
 			c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 			c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, Accept, Cache-Control, X-Requested-With")
-			c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
+			c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, PATCH, DELETE")
 		}
 
 		if c.Request.Method == "OPTIONS" {
@@ -26,31 +79,156 @@ func SetupRoutes(r *gin.Engine, authService *service.AuthService, bookingService
 		c.Next()
 	})
 
+	// Semua error yang dicatat handler diterjemahkan menjadi problem+json
+	r.Use(middleware.ErrorHandler())
+
 	authController := controller.NewAuthController(authService)
-	bookingController := controller.NewBookingController(bookingService)
-	seatController := controller.NewSeatController(seatService)
+	bookingController := controller.NewBookingController(deps.BookingService)
+	seatController := controller.NewSeatController(deps.SeatService)
+	waitlistController := controller.NewWaitlistController(deps.WaitlistService)
+	checkInController := controller.NewCheckInController(deps.CheckInService)
+	adminController := controller.NewAdminController(deps.AdminService)
+	pricingController := controller.NewPricingController(deps.PricingService)
+	promoController := controller.NewPromoController(deps.PromoService)
+	eligibilityController := controller.NewEligibilityController(deps.EligibilityService)
+	seatMapSyncController := controller.NewSeatMapSyncController(deps.SeatMapSyncService)
+	equipmentController := controller.NewEquipmentController(deps.EquipmentService)
+	flightController := controller.NewFlightController(deps.FlightService)
+	airportController := controller.NewAirportController(deps.AirportService)
+	notificationController := controller.NewNotificationController(deps.NotificationService)
+	webhookController := controller.NewWebhookController(deps.WebhookService)
+	userController := controller.NewUserController(deps.UserService, authService)
 
 	// 🔐 Auth routes
 	auth := r.Group("/auth")
+	auth.Use(middleware.RateLimit(limiter, "auth", limits.Auth, middleware.ByIP))
 	{
 		auth.POST("/register", authController.Register)
 		auth.POST("/login", authController.Login)
+		auth.POST("/verify-email", authController.VerifyEmail)
+		auth.POST("/resend-verification", authController.ResendVerification)
+		auth.POST("/forgot-password", authController.ForgotPassword)
+		auth.POST("/reset-password", authController.ResetPassword)
+		auth.POST("/confirm-email-change", authController.ConfirmEmailChange)
 	}
 
+	// 🔎 Manage booking tanpa login (record locator + nama belakang)
+	r.GET("/api/manage-booking", middleware.RateLimit(limiter, "public", limits.Public, middleware.ByIP), bookingController.ManageBooking)
+
 	// 🔐 Protected routes
 	api := r.Group("/api")
-	api.Use(middleware.AuthMiddleware(authService))
+	api.Use(middleware.AuthMiddleware(authService), middleware.RateLimit(limiter, "api", limits.API, middleware.ByUser))
 	{
+		// 👤 Profil user yang login
+		api.GET("/me", userController.GetMe)
+		api.PATCH("/me", userController.UpdateMe)
+		api.DELETE("/me", userController.DeleteMe)
+		api.POST("/me/password", userController.ChangePassword)
+		api.POST("/me/email", userController.ChangeEmail)
+
 		api.GET("/seats", seatController.GetSeats)
-		api.POST("/seats/import", seatController.ImportSeats)
 		api.GET("/seats/available", bookingController.GetAvailableSeats)
+		api.GET("/seats/:id/quote", pricingController.QuoteSeat)
+		api.GET("/airports", airportController.GetAirports)
+		api.GET("/airports/:code", airportController.GetAirport)
+		api.GET("/flights", flightController.SearchFlights)
+		api.GET("/flights/:id", flightController.GetFlight)
+		api.GET("/flights/:id/seats/stream", seatController.StreamSeats)
+		api.GET("/flights/:id/seatmap/export", seatController.ExportSeatMap)
 
 		// 📦 Booking routes (perbaikan: tanpa trailing slash di path)
 		bookings := api.Group("/bookings")
+		bookings.Use(middleware.RateLimit(limiter, "booking", limits.Booking, middleware.ByUser))
 		{
 			bookings.POST("", bookingController.CreateBooking)
 			bookings.GET("", bookingController.GetUserBookings) // ⬅️ FIXED: hilangkan "/" supaya tidak redirect 301
+			bookings.POST("/hold", bookingController.HoldSeat)
+			bookings.POST("/:bookingID/confirm", bookingController.ConfirmBooking)
 			bookings.POST("/:bookingID/cancel", bookingController.CancelBooking)
+			bookings.POST("/:bookingID/check-in", checkInController.CheckIn)
+			bookings.GET("/:bookingID/boarding-pass", checkInController.GetBoardingPass)
 		}
+
+		// ⏳ Waitlist routes
+		waitlist := api.Group("/waitlist")
+		{
+			waitlist.POST("", waitlistController.JoinWaitlist)
+			waitlist.GET("", waitlistController.GetUserWaitlist)
+			waitlist.DELETE("/:id", waitlistController.LeaveWaitlist)
+		}
+
+		// 🔔 Inbox notifikasi
+		api.GET("/notifications", notificationController.GetNotifications)
+		api.POST("/notifications/read-all", notificationController.MarkAllRead)
+		api.POST("/notifications/:id/read", notificationController.MarkRead)
+	}
+
+	// 🎧 Customer support routes
+	support := r.Group("/support")
+	support.Use(middleware.AuthMiddleware(authService), middleware.RequireRole(authService, model.RoleSupport, model.RoleAdmin))
+	{
+		support.GET("/bookings/:reference", bookingController.FindByReference)
+	}
+
+	// 🛠️ Admin routes
+	admin := r.Group("/admin")
+	admin.Use(middleware.AuthMiddleware(authService), middleware.RequireRole(authService, model.RoleAdmin), middleware.RateLimit(limiter, "admin", limits.Admin, middleware.ByUser))
+	{
+		admin.GET("/bookings", adminController.SearchBookings)
+		admin.POST("/bookings/:bookingID/cancel", adminController.CancelBooking)
+		admin.POST("/bookings/:bookingID/reassign", adminController.ReassignSeat)
+
+		admin.POST("/seats/import", seatController.ImportSeats)
+		admin.PATCH("/seats/:id", adminController.UpdateSeat)
+		admin.POST("/seats/:id/block", adminController.BlockSeat)
+		admin.POST("/seats/:id/unblock", adminController.UnblockSeat)
+
+		admin.GET("/users", adminController.SearchUsers)
+		admin.GET("/users/:id", adminController.GetUser)
+		admin.PATCH("/users/:id", adminController.UpdateUser)
+		admin.DELETE("/users/:id", adminController.DeleteUser)
+		admin.PUT("/users/:id/booking-limit-exemption", adminController.SetBookingLimitExemption)
+
+		admin.GET("/audit-log", adminController.GetAuditLog)
+
+		admin.GET("/pricing/rules", pricingController.GetRuleSets)
+		admin.GET("/pricing/rules/active", pricingController.GetActiveRuleSet)
+		admin.POST("/pricing/rules", pricingController.CreateRuleSet)
+		admin.POST("/pricing/rules/:version/activate", pricingController.ActivateRuleSet)
+		admin.POST("/pricing/preview", pricingController.PreviewRuleSet)
+
+		admin.GET("/promo-codes", promoController.GetPromoCodes)
+		admin.POST("/promo-codes", promoController.CreatePromoCode)
+		admin.GET("/promo-codes/:id", promoController.GetPromoCode)
+		admin.PUT("/promo-codes/:id", promoController.UpdatePromoCode)
+		admin.DELETE("/promo-codes/:id", promoController.DeletePromoCode)
+		admin.GET("/promo-codes/:id/redemptions", promoController.GetRedemptions)
+
+		admin.GET("/eligibility-rules", eligibilityController.GetRuleSets)
+		admin.GET("/eligibility-rules/:airline", eligibilityController.GetRuleSet)
+		admin.PUT("/eligibility-rules/:airline", eligibilityController.SaveRuleSet)
+		admin.DELETE("/eligibility-rules/:airline", eligibilityController.DeleteRuleSet)
+
+		admin.GET("/seatmap-syncs", seatMapSyncController.GetSyncRuns)
+		admin.POST("/seatmap-syncs", seatMapSyncController.TriggerSync)
+
+		admin.PUT("/airports/:code", airportController.SaveAirport)
+
+		admin.POST("/flights", flightController.CreateFlight)
+		admin.PUT("/flights/:id", flightController.UpdateFlight)
+		admin.DELETE("/flights/:id", flightController.DeleteFlight)
+		admin.POST("/flights/:id/equipment-swap", equipmentController.SwapEquipment)
+		admin.GET("/reaccommodations", equipmentController.GetReaccommodations)
+
+		admin.GET("/webhooks", webhookController.GetWebhooks)
+		admin.POST("/webhooks", webhookController.CreateWebhook)
+		admin.GET("/webhooks/:id", webhookController.GetWebhook)
+		admin.PUT("/webhooks/:id", webhookController.UpdateWebhook)
+		admin.DELETE("/webhooks/:id", webhookController.DeleteWebhook)
+		admin.POST("/webhooks/:id/rotate-secret", webhookController.RotateSecret)
+		admin.POST("/webhooks/:id/replay-dead", webhookController.ReplayDeadDeliveries)
+		admin.GET("/webhook-deliveries", webhookController.GetDeliveries)
+		admin.GET("/webhook-deliveries/:id", webhookController.GetDelivery)
+		admin.POST("/webhook-deliveries/:id/replay", webhookController.ReplayDelivery)
 	}
 }
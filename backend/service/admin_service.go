@@ -0,0 +1,525 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/event"
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/repository"
+)
+
+// Aksi yang dicatat di audit log
+const (
+	AuditBookingCancel      = "booking.cancel"
+	AuditBookingReassign    = "booking.reassign"
+	AuditSeatBlock          = "seat.block"
+	AuditSeatUnblock        = "seat.unblock"
+	AuditSeatUpdate         = "seat.update"
+	AuditUserUpdate         = "user.update"
+	AuditUserDelete         = "user.delete"
+	AuditUserLimitExempt    = "user.booking_limit_exemption"
+	AuditPriceRulesCreate   = "pricing.rules_create"
+	AuditPriceRulesActivate = "pricing.rules_activate"
+	AuditPromoCreate        = "promo.create"
+	AuditPromoUpdate        = "promo.update"
+	AuditPromoDelete        = "promo.delete"
+	AuditEligibilityUpdate  = "eligibility.update"
+	AuditEligibilityDelete  = "eligibility.delete"
+	AuditFlightCreate       = "flight.create"
+	AuditFlightUpdate       = "flight.update"
+	AuditFlightDelete       = "flight.delete"
+	AuditEquipmentSwap      = "flight.equipment_swap"
+	AuditAirportSave        = "airport.save"
+	AuditSeatMapImport      = "seatmap.import"
+	// AuditBookingReseat dan AuditBookingReaccommodate dicatat per booking saat pergantian pesawat
+	AuditBookingReseat        = "booking.reseat"
+	AuditBookingReaccommodate = "booking.reaccommodate"
+	AuditWebhookCreate        = "webhook.create"
+	AuditWebhookUpdate        = "webhook.update"
+	AuditWebhookDelete        = "webhook.delete"
+	AuditWebhookRotateSecret  = "webhook.rotate_secret"
+	AuditWebhookReplay        = "webhook.replay"
+)
+
+const (
+	auditTargetBooking     = "booking"
+	auditTargetSeat        = "seat"
+	auditTargetUser        = "user"
+	auditTargetPriceRules  = "price_rule_set"
+	auditTargetPromo       = "promo_code"
+	auditTargetEligibility = "eligibility_rule_set"
+	auditTargetFlight      = "flight"
+	auditTargetAirport     = "airport"
+	auditTargetWebhook     = "webhook_endpoint"
+	auditTargetDelivery    = "webhook_delivery"
+)
+
+const (
+	defaultAdminPageSize = 50
+	maxAdminPageSize     = 200
+)
+
+// Pagination adalah nomor halaman (mulai dari 1) dan jumlah item per halaman
+type Pagination struct {
+	Page     int
+	PageSize int
+}
+
+func (p Pagination) normalize() Pagination {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.PageSize < 1 {
+		p.PageSize = defaultAdminPageSize
+	}
+	p.PageSize = min(p.PageSize, maxAdminPageSize)
+	return p
+}
+
+func (p Pagination) offset() int {
+	return (p.Page - 1) * p.PageSize
+}
+
+// Page adalah satu halaman hasil pencarian admin
+type Page[T any] struct {
+	Items    []T   `json:"items"`
+	Total    int64 `json:"total"`
+	Page     int   `json:"page"`
+	PageSize int   `json:"page_size"`
+}
+
+// SeatUpdate berisi atribut kursi yang bisa diubah admin; nil berarti tidak diubah
+type SeatUpdate struct {
+	Price           *float64
+	Currency        *string
+	Segment         *string
+	IsWindow        *bool
+	IsAisle         *bool
+	Characteristics *[]string
+}
+
+// UserUpdate berisi data user yang bisa diubah admin; nil berarti tidak diubah
+type UserUpdate struct {
+	Name          *string
+	Role          *string
+	EmailVerified *bool
+}
+
+// AdminService menjalankan operasi back-office. Setiap perubahan dicatat ke audit log
+// dalam transaksi yang sama dengan perubahannya.
+type AdminService struct {
+	store          repository.Store
+	bus            event.Bus
+	bookingService *BookingService
+}
+
+func NewAdminService(store repository.Store, bus event.Bus, bookingService *BookingService) *AdminService {
+	return &AdminService{store: store, bus: bus, bookingService: bookingService}
+}
+
+// SearchBookings mencari booking semua user
+func (s *AdminService) SearchBookings(filter repository.BookingFilter, pagination Pagination) (*Page[BookingDetails], error) {
+	pagination = pagination.normalize()
+	filter.Reference = NormalizeReference(filter.Reference)
+	filter.Offset, filter.Limit = pagination.offset(), pagination.PageSize
+
+	bookings, total, err := s.store.Bookings().Search(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	users := map[uint]*model.User{}
+	flights := map[uint]*model.Flight{}
+	page := &Page[BookingDetails]{Items: []BookingDetails{}, Total: total, Page: pagination.Page, PageSize: pagination.PageSize}
+	for i := range bookings {
+		booking := &bookings[i]
+
+		user, ok := users[booking.UserID]
+		if !ok {
+			if user, err = s.store.Users().FindByIDWithDeleted(booking.UserID); err != nil {
+				return nil, err
+			}
+			users[booking.UserID] = user
+		}
+		flight, ok := flights[booking.Seat.FlightID]
+		if !ok {
+			flight, err = s.store.Flights().FindByID(booking.Seat.FlightID)
+			if errors.Is(err, repository.ErrNotFound) {
+				flight = nil
+			} else if err != nil {
+				return nil, err
+			}
+			flights[booking.Seat.FlightID] = flight
+		}
+
+		details := newBookingDetails(booking, user, flight)
+		details.BookingID = booking.ID
+		details.UserID = user.ID
+		details.Email = user.Email
+		page.Items = append(page.Items, *details)
+	}
+	return page, nil
+}
+
+// ForceCancelBooking membatalkan booking user mana pun, misalnya karena fraud
+func (s *AdminService) ForceCancelBooking(actorID, bookingID uint, reason string) error {
+	booking, err := s.findBooking(bookingID)
+	if err != nil {
+		return err
+	}
+
+	return s.bookingService.cancel(booking, func(tx repository.Store) error {
+		return audit(tx, actorID, AuditBookingCancel, auditTargetBooking, booking.ID, reason, model.JSONMap{
+			"reference":       booking.Reference,
+			"user_id":         booking.UserID,
+			"seat_code":       booking.Seat.SeatCode,
+			"previous_status": booking.Status,
+		})
+	})
+}
+
+// ReassignSeat memindahkan booking ke kursi lain pada penerbangan yang sama
+func (s *AdminService) ReassignSeat(actorID, bookingID, seatID uint, reason string) (*model.Booking, error) {
+	booking, err := s.findBooking(bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	fromSeat := booking.Seat.SeatCode
+	return s.bookingService.reassignSeat(booking, seatID, func(tx repository.Store) error {
+		seat, err := tx.Seats().FindByID(seatID)
+		if err != nil {
+			return err
+		}
+		return audit(tx, actorID, AuditBookingReassign, auditTargetBooking, booking.ID, reason, model.JSONMap{
+			"reference": booking.Reference,
+			"from_seat": fromSeat,
+			"to_seat":   seat.SeatCode,
+		})
+	})
+}
+
+// BlockSeat mengeluarkan kursi dari penjualan sampai until (nil berarti sampai dicabut).
+// Booking yang sudah ada di kursi tersebut tetap berlaku, tetapi kursi tidak dijual lagi
+// setelah booking itu dibatalkan.
+func (s *AdminService) BlockSeat(actorID, seatID uint, reason string, until *time.Time) (*model.Seat, error) {
+	seat, err := s.findSeat(seatID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if until != nil && !until.After(now) {
+		return nil, ErrValidationFailed.WithDetail("blocked_until must be in the future")
+	}
+
+	block := model.SeatBlock{
+		BlockReason:  reason,
+		BlockSource:  model.SeatBlockOps,
+		BlockedBy:    &actorID,
+		BlockedAt:    &now,
+		BlockedUntil: until,
+	}
+	err = s.store.Transaction(func(tx repository.Store) error {
+		if err := tx.Seats().SetBlock(seat.ID, block); err != nil {
+			return err
+		}
+		if err := tx.Seats().SetAvailable(seat.ID, false); err != nil {
+			return err
+		}
+		return audit(tx, actorID, AuditSeatBlock, auditTargetSeat, seat.ID, reason, model.JSONMap{
+			"seat_code":     seat.SeatCode,
+			"blocked_until": until,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seat.SeatBlock = block
+	seat.Available = false
+	s.bookingService.publishSeat(event.SeatBlocked, seat)
+	return seat, nil
+}
+
+// UnblockSeat mengembalikan kursi ke penjualan; antrean waitlist yang cocok mendapat hold lebih dulu
+func (s *AdminService) UnblockSeat(actorID, seatID uint, reason string) (*model.Seat, error) {
+	seat, err := s.findSeat(seatID)
+	if err != nil {
+		return nil, err
+	}
+	if seat.BlockReason == "" {
+		return nil, ErrSeatNotBlocked
+	}
+
+	previous := seat.SeatBlock
+	var offer *model.Booking
+	err = s.store.Transaction(func(tx repository.Store) error {
+		if err := tx.Seats().SetBlock(seat.ID, model.SeatBlock{}); err != nil {
+			return err
+		}
+
+		var err error
+		offer, err = s.bookingService.releaseIfUnbooked(tx, seat)
+		if err != nil {
+			return err
+		}
+		return audit(tx, actorID, AuditSeatUnblock, auditTargetSeat, seat.ID, reason, model.JSONMap{
+			"seat_code":    seat.SeatCode,
+			"block_reason": previous.BlockReason,
+			"block_source": previous.BlockSource,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	seat.SeatBlock = model.SeatBlock{}
+	s.bookingService.publishSeat(event.SeatUnblocked, seat)
+	if offer != nil {
+		s.bookingService.publish(event.SeatHeld, offer, seat, false)
+	}
+	return seat, nil
+}
+
+// UpdateSeat mengubah harga dan atribut kursi. Booking yang sudah ada tetap memakai harga lamanya.
+func (s *AdminService) UpdateSeat(actorID, seatID uint, update SeatUpdate, reason string) (*model.Seat, error) {
+	seat, err := s.findSeat(seatID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := model.JSONMap{}
+	if update.Price != nil {
+		if *update.Price < 0 {
+			return nil, ErrValidationFailed.WithDetail("price must not be negative")
+		}
+		changes["price"] = []interface{}{seat.Price, *update.Price}
+		seat.Price = *update.Price
+	}
+	if update.Currency != nil {
+		currency := strings.ToUpper(strings.TrimSpace(*update.Currency))
+		changes["currency"] = []interface{}{seat.Currency, currency}
+		seat.Currency = currency
+	}
+	if update.Segment != nil {
+		changes["segment"] = []interface{}{seat.Segment, *update.Segment}
+		seat.Segment = *update.Segment
+	}
+	if update.IsWindow != nil {
+		changes["is_window"] = []interface{}{seat.IsWindow, *update.IsWindow}
+		seat.IsWindow = *update.IsWindow
+	}
+	if update.IsAisle != nil {
+		changes["is_aisle"] = []interface{}{seat.IsAisle, *update.IsAisle}
+		seat.IsAisle = *update.IsAisle
+	}
+	if update.Characteristics != nil {
+		changes["characteristics"] = []interface{}{seat.Characteristics, *update.Characteristics}
+		seat.Characteristics = model.StringArray(*update.Characteristics)
+	}
+
+	err = s.store.Transaction(func(tx repository.Store) error {
+		if err := tx.Seats().Update(seat); err != nil {
+			return err
+		}
+		changes["seat_code"] = seat.SeatCode
+		return audit(tx, actorID, AuditSeatUpdate, auditTargetSeat, seat.ID, reason, changes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return seat, nil
+}
+
+func (s *AdminService) SearchUsers(filter repository.UserFilter, pagination Pagination) (*Page[UserProfile], error) {
+	pagination = pagination.normalize()
+	filter.Offset, filter.Limit = pagination.offset(), pagination.PageSize
+
+	users, total, err := s.store.Users().Search(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page[UserProfile]{Items: []UserProfile{}, Total: total, Page: pagination.Page, PageSize: pagination.PageSize}
+	for i := range users {
+		page.Items = append(page.Items, *NewUserProfile(&users[i]))
+	}
+	return page, nil
+}
+
+func (s *AdminService) GetUser(userID uint) (*UserProfile, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return NewUserProfile(user), nil
+}
+
+// UpdateUser mengubah nama, role atau status verifikasi email user
+func (s *AdminService) UpdateUser(actorID, userID uint, update UserUpdate, reason string) (*UserProfile, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := model.JSONMap{}
+	if update.Name != nil {
+		name := strings.TrimSpace(*update.Name)
+		if name == "" {
+			return nil, ErrValidationFailed.WithDetail("name must not be empty")
+		}
+		changes["name"] = []interface{}{user.Name, name}
+		user.Name = name
+	}
+	if update.Role != nil && *update.Role != user.Role {
+		if !isValidRole(*update.Role) {
+			return nil, ErrValidationFailed.WithDetail("unknown role %q", *update.Role)
+		}
+		if user.ID == actorID {
+			return nil, ErrCannotModifySelf
+		}
+		changes["role"] = []interface{}{user.Role, *update.Role}
+		user.Role = *update.Role
+	}
+	if update.EmailVerified != nil && *update.EmailVerified != (user.EmailVerifiedAt != nil) {
+		changes["email_verified"] = []interface{}{user.EmailVerifiedAt != nil, *update.EmailVerified}
+		if *update.EmailVerified {
+			now := time.Now()
+			user.EmailVerifiedAt = &now
+		} else {
+			user.EmailVerifiedAt = nil
+		}
+	}
+
+	err = s.store.Transaction(func(tx repository.Store) error {
+		if err := tx.Users().Update(user); err != nil {
+			return err
+		}
+		return audit(tx, actorID, AuditUserUpdate, auditTargetUser, user.ID, reason, changes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewUserProfile(user), nil
+}
+
+// DeleteUser menganonimkan dan menghapus akun user. Hold dan booking penerbangan yang belum
+// berangkat dibatalkan; booking yang sudah lewat tetap tersimpan sebagai riwayat.
+func (s *AdminService) DeleteUser(actorID, userID uint, reason string) error {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return err
+	}
+	if user.ID == actorID {
+		return ErrCannotModifySelf
+	}
+
+	var released []releasedBooking
+	err = s.store.Transaction(func(tx repository.Store) error {
+		if err := audit(tx, actorID, AuditUserDelete, auditTargetUser, user.ID, reason, model.JSONMap{"role": user.Role}); err != nil {
+			return err
+		}
+		if err := anonymizeUser(tx, user); err != nil {
+			return err
+		}
+		released, err = s.bookingService.releaseUserBookings(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.bookingService.publishReleased(released)
+	return nil
+}
+
+// SetBookingLimitExempt membebaskan akun agent dari BookingLimits atau mencabut pembebasannya
+func (s *AdminService) SetBookingLimitExempt(actorID, userID uint, exempt bool, reason string) (*UserProfile, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if exempt && user.Role != model.RoleAgent {
+		return nil, ErrExemptionRequiresAgent
+	}
+
+	user.BookingLimitExempt = exempt
+	err = s.store.Transaction(func(tx repository.Store) error {
+		if err := tx.Users().Update(user); err != nil {
+			return err
+		}
+		return audit(tx, actorID, AuditUserLimitExempt, auditTargetUser, user.ID, reason, model.JSONMap{"exempt": exempt})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewUserProfile(user), nil
+}
+
+func (s *AdminService) AuditLog(filter repository.AuditFilter, pagination Pagination) (*Page[model.AuditLog], error) {
+	pagination = pagination.normalize()
+	filter.Offset, filter.Limit = pagination.offset(), pagination.PageSize
+
+	entries, total, err := s.store.Audit().Search(filter)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []model.AuditLog{}
+	}
+	return &Page[model.AuditLog]{Items: entries, Total: total, Page: pagination.Page, PageSize: pagination.PageSize}, nil
+}
+
+func (s *AdminService) findBooking(bookingID uint) (*model.Booking, error) {
+	booking, err := s.store.Bookings().FindByID(bookingID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrBookingNotFound
+		}
+		return nil, err
+	}
+	return booking, nil
+}
+
+func (s *AdminService) findSeat(seatID uint) (*model.Seat, error) {
+	seat, err := s.store.Seats().FindByID(seatID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrSeatNotFound
+		}
+		return nil, err
+	}
+	return seat, nil
+}
+
+func (s *AdminService) findUser(userID uint) (*model.User, error) {
+	user, err := s.store.Users().FindByID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func audit(tx repository.Store, actorID uint, action, targetType string, targetID uint, reason string, details model.JSONMap) error {
+	return tx.Audit().Create(&model.AuditLog{
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Reason:     reason,
+		Details:    details,
+	})
+}
+
+func isValidRole(role string) bool {
+	switch role {
+	case model.RoleCustomer, model.RoleSupport, model.RoleAdmin, model.RoleAgent:
+		return true
+	}
+	return false
+}
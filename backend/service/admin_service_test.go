@@ -0,0 +1,226 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/repository"
+)
+
+// auditEntries mengembalikan entry audit log untuk aksi action
+func (env *testEnv) auditEntries(t *testing.T, action string) []model.AuditLog {
+	t.Helper()
+	entries, _, err := env.store.Audit().Search(repository.AuditFilter{Action: action})
+	if err != nil {
+		t.Fatalf("search audit log: %v", err)
+	}
+	return entries
+}
+
+func TestAdminForceCancelIsAudited(t *testing.T) {
+	env := newTestEnv(t)
+	admins := NewAdminService(env.store, env.bus, env.bookings)
+	admin := env.user(t, "admin@example.com")
+	user := env.user(t, "budi@example.com")
+	seat := env.freeSeats(t)[0]
+
+	booking, err := env.bookings.CreateBooking(user.ID, seat.ID, "")
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+	if err := admins.ForceCancelBooking(admin.ID, booking.ID, "fraud"); err != nil {
+		t.Fatalf("ForceCancelBooking: %v", err)
+	}
+
+	cancelled, err := env.store.Bookings().FindByID(booking.ID)
+	if err != nil {
+		t.Fatalf("find booking: %v", err)
+	}
+	if cancelled.Status != model.StatusCancelled {
+		t.Errorf("booking status = %s, want cancelled", cancelled.Status)
+	}
+	if !env.seat(t, seat.ID).Available {
+		t.Error("seat of the cancelled booking is still unavailable")
+	}
+
+	entries := env.auditEntries(t, AuditBookingCancel)
+	if len(entries) != 1 {
+		t.Fatalf("found %d audit entries, want 1", len(entries))
+	}
+	entry := entries[0]
+	if entry.ActorID != admin.ID || entry.TargetType != auditTargetBooking || entry.TargetID != booking.ID || entry.Reason != "fraud" {
+		t.Errorf("audit entry = actor %d %s %d %q, want actor %d booking %d %q", entry.ActorID, entry.TargetType, entry.TargetID, entry.Reason, admin.ID, booking.ID, "fraud")
+	}
+	if fmt.Sprint(entry.Details["previous_status"]) != string(model.StatusConfirmed) {
+		t.Errorf("previous_status = %v, want confirmed", entry.Details["previous_status"])
+	}
+
+	// Aksi yang gagal tidak meninggalkan jejak di audit log
+	if err := admins.ForceCancelBooking(admin.ID, booking.ID, "again"); !errors.Is(err, ErrBookingAlreadyCancelled) {
+		t.Errorf("second cancel err = %v, want ErrBookingAlreadyCancelled", err)
+	}
+	if n := len(env.auditEntries(t, AuditBookingCancel)); n != 1 {
+		t.Errorf("failed cancel wrote an audit entry: %d entries", n)
+	}
+}
+
+func TestAdminReassignSeat(t *testing.T) {
+	env := newTestEnv(t)
+	admins := NewAdminService(env.store, env.bus, env.bookings)
+	admin := env.user(t, "admin@example.com")
+	user := env.user(t, "budi@example.com")
+	free := env.freeSeats(t)
+
+	booking, err := env.bookings.CreateBooking(user.ID, free[0].ID, "")
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+	moved, err := admins.ReassignSeat(admin.ID, booking.ID, free[1].ID, "broken recline")
+	if err != nil {
+		t.Fatalf("ReassignSeat: %v", err)
+	}
+	if moved.SeatID != free[1].ID || moved.Price != booking.Price {
+		t.Errorf("reassigned booking = seat %d price %v, want seat %d at the original %v", moved.SeatID, moved.Price, free[1].ID, booking.Price)
+	}
+	if !env.seat(t, free[0].ID).Available || env.seat(t, free[1].ID).Available {
+		t.Error("old seat was not released or new seat is still for sale")
+	}
+
+	entries := env.auditEntries(t, AuditBookingReassign)
+	if len(entries) != 1 {
+		t.Fatalf("found %d audit entries, want 1", len(entries))
+	}
+	if entries[0].Details["from_seat"] != free[0].SeatCode || entries[0].Details["to_seat"] != free[1].SeatCode {
+		t.Errorf("audit details = %v, want %s to %s", entries[0].Details, free[0].SeatCode, free[1].SeatCode)
+	}
+
+	if _, err := admins.ReassignSeat(admin.ID, booking.ID, free[1].ID, "same seat"); !errors.Is(err, ErrValidationFailed) {
+		t.Errorf("reassign to the current seat err = %v, want ErrValidationFailed", err)
+	}
+}
+
+func TestAdminBlockAndUnblockSeat(t *testing.T) {
+	env := newTestEnv(t)
+	admins := NewAdminService(env.store, env.bus, env.bookings)
+	admin := env.user(t, "admin@example.com")
+	user := env.user(t, "budi@example.com")
+	seat := env.freeSeats(t)[0]
+
+	past := time.Now().Add(-time.Hour)
+	if _, err := admins.BlockSeat(admin.ID, seat.ID, "broken", &past); !errors.Is(err, ErrValidationFailed) {
+		t.Errorf("block until the past err = %v, want ErrValidationFailed", err)
+	}
+
+	blocked, err := admins.BlockSeat(admin.ID, seat.ID, "broken tray table", nil)
+	if err != nil {
+		t.Fatalf("BlockSeat: %v", err)
+	}
+	if blocked.Available || blocked.BlockReason != "broken tray table" || blocked.BlockSource != model.SeatBlockOps {
+		t.Errorf("blocked seat = available %v reason %q source %q", blocked.Available, blocked.BlockReason, blocked.BlockSource)
+	}
+	if _, err := env.bookings.CreateBooking(user.ID, seat.ID, ""); err == nil {
+		t.Error("booked a blocked seat")
+	}
+
+	if _, err := admins.UnblockSeat(admin.ID, seat.ID, "fixed"); err != nil {
+		t.Fatalf("UnblockSeat: %v", err)
+	}
+	if stored := env.seat(t, seat.ID); !stored.Available || stored.BlockReason != "" {
+		t.Errorf("unblocked seat = available %v reason %q, want back on sale", stored.Available, stored.BlockReason)
+	}
+	if _, err := admins.UnblockSeat(admin.ID, seat.ID, "fixed"); !errors.Is(err, ErrSeatNotBlocked) {
+		t.Errorf("second unblock err = %v, want ErrSeatNotBlocked", err)
+	}
+
+	if n := len(env.auditEntries(t, AuditSeatBlock)); n != 1 {
+		t.Errorf("found %d seat.block entries, want 1", n)
+	}
+	unblocks := env.auditEntries(t, AuditSeatUnblock)
+	if len(unblocks) != 1 || unblocks[0].Details["block_reason"] != "broken tray table" {
+		t.Errorf("seat.unblock entries = %+v, want one with the previous block reason", unblocks)
+	}
+}
+
+func TestAdminUpdateSeatKeepsBookedPrice(t *testing.T) {
+	env := newTestEnv(t)
+	admins := NewAdminService(env.store, env.bus, env.bookings)
+	admin := env.user(t, "admin@example.com")
+	user := env.user(t, "budi@example.com")
+	seat := env.freeSeats(t)[0]
+
+	booking, err := env.bookings.CreateBooking(user.ID, seat.ID, "")
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+
+	negative := -1.0
+	if _, err := admins.UpdateSeat(admin.ID, seat.ID, SeatUpdate{Price: &negative}, "typo"); !errors.Is(err, ErrValidationFailed) {
+		t.Errorf("negative price err = %v, want ErrValidationFailed", err)
+	}
+
+	price, currency := seat.Price+25, " usd "
+	updated, err := admins.UpdateSeat(admin.ID, seat.ID, SeatUpdate{Price: &price, Currency: &currency}, "fare change")
+	if err != nil {
+		t.Fatalf("UpdateSeat: %v", err)
+	}
+	if updated.Price != price || updated.Currency != "USD" {
+		t.Errorf("updated seat = %v %s, want %v USD", updated.Price, updated.Currency, price)
+	}
+	if stored, _ := env.store.Bookings().FindByID(booking.ID); stored.Price != booking.Price {
+		t.Errorf("existing booking price = %v, want %v", stored.Price, booking.Price)
+	}
+
+	entries := env.auditEntries(t, AuditSeatUpdate)
+	if len(entries) != 1 || entries[0].Details["seat_code"] != seat.SeatCode {
+		t.Fatalf("seat.update entries = %+v, want one for %s", entries, seat.SeatCode)
+	}
+	if _, ok := entries[0].Details["price"]; !ok {
+		t.Errorf("audit details = %v, want the price change", entries[0].Details)
+	}
+}
+
+func TestAdminSearchBookings(t *testing.T) {
+	env := newTestEnv(t)
+	admins := NewAdminService(env.store, env.bus, env.bookings)
+	budi := env.user(t, "budi@example.com")
+	sari := env.user(t, "sari@example.com")
+	free := env.freeSeats(t)
+
+	first, err := env.bookings.CreateBooking(budi.ID, free[0].ID, "")
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+	if _, err := env.bookings.CreateBooking(sari.ID, free[1].ID, ""); err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+	if _, err := env.bookings.HoldSeat(sari.ID, free[2].ID, ""); err != nil {
+		t.Fatalf("HoldSeat: %v", err)
+	}
+
+	all, err := admins.SearchBookings(repository.BookingFilter{}, Pagination{PageSize: 2})
+	if err != nil {
+		t.Fatalf("SearchBookings: %v", err)
+	}
+	if all.Total != 3 || len(all.Items) != 2 || all.Page != 1 {
+		t.Errorf("first page = total %d items %d page %d, want 3, 2, 1", all.Total, len(all.Items), all.Page)
+	}
+
+	bySari, err := admins.SearchBookings(repository.BookingFilter{Email: "SARI@", Status: model.StatusPending}, Pagination{})
+	if err != nil {
+		t.Fatalf("SearchBookings: %v", err)
+	}
+	if bySari.Total != 1 || bySari.Items[0].SeatCode != free[2].SeatCode || bySari.Items[0].Email != sari.Email {
+		t.Errorf("pending bookings for sari = %+v, want the hold on %s", bySari.Items, free[2].SeatCode)
+	}
+
+	byReference, err := admins.SearchBookings(repository.BookingFilter{Reference: " " + first.Reference + " "}, Pagination{})
+	if err != nil {
+		t.Fatalf("SearchBookings: %v", err)
+	}
+	if byReference.Total != 1 || byReference.Items[0].BookingID != first.ID || byReference.Items[0].Flight == nil {
+		t.Errorf("search by reference = %+v, want booking %d with its flight", byReference.Items, first.ID)
+	}
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/repository"
+)
+
+type AirportService struct {
+	store repository.Store
+}
+
+func NewAirportService(store repository.Store) *AirportService {
+	return &AirportService{store: store}
+}
+
+// AirportInput adalah data bandara dari admin atau file referensi
+type AirportInput struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	City     string `json:"city"`
+	Country  string `json:"country"`
+	TimeZone string `json:"time_zone"`
+}
+
+func (s *AirportService) ListAirports() ([]model.Airport, error) {
+	return s.store.Airports().FindAll()
+}
+
+func (s *AirportService) GetAirport(code string) (*model.Airport, error) {
+	airport, err := s.store.Airports().FindByCode(strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrAirportNotFound
+		}
+		return nil, err
+	}
+	return airport, nil
+}
+
+// SaveAirport membuat atau mengganti data bandara. Penerbangan yang sudah tersimpan tidak
+// berubah; jadwal yang jam lokalnya salah karena zona lama perlu diimpor atau diubah ulang.
+func (s *AirportService) SaveAirport(actorID uint, input AirportInput, reason string) (*model.Airport, error) {
+	airport, err := airportFromInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.store.Transaction(func(tx repository.Store) error {
+		previous := ""
+		if existing, err := tx.Airports().FindByCode(airport.Code); err == nil {
+			previous = existing.TimeZone
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+		if err := tx.Airports().Save(airport); err != nil {
+			return err
+		}
+		return audit(tx, actorID, AuditAirportSave, auditTargetAirport, 0, reason, model.JSONMap{
+			"code":               airport.Code,
+			"time_zone":          airport.TimeZone,
+			"previous_time_zone": previous,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return airport, nil
+}
+
+// LoadAirports menambahkan bandara dari file JSON referensi yang belum ada di tabel.
+// Bandara yang sudah ada tidak ditimpa agar perubahan dari admin tetap berlaku.
+func (s *AirportService) LoadAirports(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	var inputs []AirportInput
+	if err := json.Unmarshal(data, &inputs); err != nil {
+		return 0, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	added := 0
+	err = s.store.Transaction(func(tx repository.Store) error {
+		for _, input := range inputs {
+			airport, err := airportFromInput(input)
+			if err != nil {
+				return fmt.Errorf("airport %q: %w", input.Code, err)
+			}
+			if _, err := tx.Airports().FindByCode(airport.Code); err == nil {
+				continue
+			} else if !errors.Is(err, repository.ErrNotFound) {
+				return err
+			}
+			if err := tx.Airports().Save(airport); err != nil {
+				return err
+			}
+			added++
+		}
+		return nil
+	})
+	return added, err
+}
+
+// LocalizeLegacyFlights memperbaiki penerbangan yang disimpan sebelum ada tabel bandara.
+// Jam pada penerbangan tersebut adalah jam lokal dari seat map yang tersimpan seolah-olah UTC,
+// sehingga dibaca ulang sebagai jam lokal bandara. Penerbangan dengan bandara yang belum
+// dikenal dilewati dan dicoba lagi pada start berikutnya.
+func (s *AirportService) LocalizeLegacyFlights() (int, error) {
+	flights, err := s.store.Flights().FindWithoutZone()
+	if err != nil {
+		return 0, err
+	}
+
+	fixed := 0
+	err = s.store.Transaction(func(tx repository.Store) error {
+		for i := range flights {
+			flight := &flights[i]
+			if err := localizeFlight(tx, flight, true, true); err != nil {
+				if errors.Is(err, ErrUnknownAirport) {
+					continue
+				}
+				return err
+			}
+			if err := tx.Flights().Update(flight); err != nil {
+				return err
+			}
+			fixed++
+		}
+		return nil
+	})
+	return fixed, err
+}
+
+func airportFromInput(input AirportInput) (*model.Airport, error) {
+	airport := &model.Airport{
+		Code:     strings.ToUpper(strings.TrimSpace(input.Code)),
+		Name:     strings.TrimSpace(input.Name),
+		City:     strings.TrimSpace(input.City),
+		Country:  strings.ToUpper(strings.TrimSpace(input.Country)),
+		TimeZone: strings.TrimSpace(input.TimeZone),
+	}
+	if !airportCodePattern.MatchString(airport.Code) {
+		return nil, ErrValidationFailed.WithDetail("code must be a 3-letter IATA airport code")
+	}
+	// Hanya nama zona IANA yang diterima; "Local" atau offset tetap tidak mengikuti DST
+	if airport.TimeZone == "" || airport.TimeZone == "Local" {
+		return nil, ErrValidationFailed.WithDetail("time_zone must be an IANA time zone name")
+	}
+	if _, err := model.LoadZone(airport.TimeZone); err != nil {
+		return nil, ErrValidationFailed.WithDetail("unknown time zone %q", airport.TimeZone)
+	}
+	return airport, nil
+}
+
+// airportLocation mengembalikan zona IANA bandara beserta lokasinya
+func airportLocation(tx repository.Store, code string) (string, *time.Location, error) {
+	airport, err := tx.Airports().FindByCode(code)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return "", nil, ErrUnknownAirport.WithDetail("airport %s is not in the airport table; add it with its time zone first", code)
+		}
+		return "", nil, err
+	}
+	loc, err := model.LoadZone(airport.TimeZone)
+	if err != nil {
+		return "", nil, err
+	}
+	return airport.TimeZone, loc, nil
+}
+
+// localizeFlight mengisi zona bandara asal dan tujuan lalu menyimpan jam sebagai UTC.
+// localDeparture/localArrival berarti jam tersebut adalah jam dinding lokal bandara tanpa offset
+// (seperti pada seat map) dan dibaca ulang di zona bandara. Jam yang jatuh di celah DST
+// digeser maju oleh time.Date.
+func localizeFlight(tx repository.Store, flight *model.Flight, localDeparture, localArrival bool) error {
+	departureZone, departureLoc, err := airportLocation(tx, flight.Origin)
+	if err != nil {
+		return err
+	}
+	arrivalZone, arrivalLoc, err := airportLocation(tx, flight.Destination)
+	if err != nil {
+		return err
+	}
+
+	if localDeparture {
+		flight.Departure = wallClock(flight.Departure, departureLoc)
+	}
+	if localArrival {
+		flight.Arrival = wallClock(flight.Arrival, arrivalLoc)
+	}
+	flight.Departure = flight.Departure.UTC()
+	flight.Arrival = flight.Arrival.UTC()
+	flight.DepartureZone = departureZone
+	flight.ArrivalZone = arrivalZone
+	return nil
+}
+
+// localizeSegments menerapkan localizeFlight pada semua segmen hasil ParseSeatMap
+func localizeSegments(tx repository.Store, segments []*ImportedSegment) error {
+	for _, segment := range segments {
+		if err := localizeFlight(tx, segment.Flight, segment.LocalDeparture, segment.LocalArrival); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wallClock membaca jam dinding UTC t sebagai jam di loc; jam tanpa offset dari seat map
+// diparse sebagai UTC sehingga jam dindingnya tetap sama
+func wallClock(t time.Time, loc *time.Location) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
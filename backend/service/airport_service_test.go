@@ -0,0 +1,84 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+func TestLocalizeFlightAcrossDST(t *testing.T) {
+	env := newEmptyTestEnv(t)
+	// Jam dinding dari seat map diparse sebagai UTC tanpa offset
+	wall := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2026, month, day, hour, min, 0, 0, time.UTC)
+	}
+	utc := wall
+
+	tests := []struct {
+		name               string
+		origin, dest       string
+		departure          time.Time
+		arrival            time.Time
+		wantDeparture      time.Time
+		wantArrival        time.Time
+		wantLocalDeparture string
+	}{
+		{
+			"London still on GMT, New York already on EDT", "LHR", "JFK",
+			wall(3, 28, 10, 0), wall(3, 28, 13, 0),
+			utc(3, 28, 10, 0), utc(3, 28, 17, 0), "10:00 GMT",
+		},
+		{
+			"London on BST the day after", "LHR", "JFK",
+			wall(3, 29, 10, 0), wall(3, 29, 13, 0),
+			utc(3, 29, 9, 0), utc(3, 29, 17, 0), "10:00 BST",
+		},
+		{
+			// 01:30 tidak ada di London pada 29 Maret; jamnya digeser maju satu jam
+			"departure in the spring-forward gap", "LHR", "JFK",
+			wall(3, 29, 1, 30), wall(3, 29, 5, 0),
+			utc(3, 29, 1, 30), utc(3, 29, 9, 0), "02:30 BST",
+		},
+		{
+			// 01:30 terjadi dua kali di London pada 25 Oktober; time.Date memakai yang kedua (GMT)
+			"departure in the repeated autumn hour", "LHR", "JFK",
+			wall(10, 25, 1, 30), wall(10, 25, 5, 0),
+			utc(10, 25, 1, 30), utc(10, 25, 9, 0), "01:30 GMT",
+		},
+		{
+			"Sydney leaving daylight saving, arrival the previous day in Los Angeles", "SYD", "LAX",
+			wall(4, 5, 9, 0), wall(4, 4, 6, 0),
+			utc(4, 4, 23, 0), utc(4, 4, 13, 0), "09:00 AEST",
+		},
+		{
+			"Sydney on daylight saving the day before", "SYD", "LAX",
+			wall(4, 4, 9, 0), wall(4, 3, 6, 0),
+			utc(4, 3, 22, 0), utc(4, 3, 13, 0), "09:00 AEDT",
+		},
+	}
+	for _, tt := range tests {
+		flight := &model.Flight{Origin: tt.origin, Destination: tt.dest, Departure: tt.departure, Arrival: tt.arrival}
+		if err := localizeFlight(env.store, flight, true, true); err != nil {
+			t.Fatalf("%s: localizeFlight: %v", tt.name, err)
+		}
+		if !flight.Departure.Equal(tt.wantDeparture) || !flight.Arrival.Equal(tt.wantArrival) {
+			t.Errorf("%s: stored %s to %s, want %s to %s", tt.name, flight.Departure, flight.Arrival, tt.wantDeparture, tt.wantArrival)
+		}
+		if flight.Departure.Location() != time.UTC || flight.Arrival.Location() != time.UTC {
+			t.Errorf("%s: flight times are not stored as UTC", tt.name)
+		}
+		if got := flight.LocalDeparture().Format("15:04 MST"); got != tt.wantLocalDeparture {
+			t.Errorf("%s: local departure = %s, want %s", tt.name, got, tt.wantLocalDeparture)
+		}
+	}
+
+	// Jam yang sudah punya offset tidak dibaca ulang sebagai jam dinding
+	flight := &model.Flight{Origin: "LHR", Destination: "JFK", Departure: utc(3, 29, 9, 0), Arrival: utc(3, 29, 17, 0)}
+	if err := localizeFlight(env.store, flight, false, false); err != nil {
+		t.Fatalf("localizeFlight: %v", err)
+	}
+	if !flight.Departure.Equal(utc(3, 29, 9, 0)) || flight.DepartureZone != "Europe/London" || flight.ArrivalZone != "America/New_York" {
+		t.Errorf("flight with offsets = %s %s/%s, want 09:00 UTC with airport zones", flight.Departure, flight.DepartureZone, flight.ArrivalZone)
+	}
+}
@@ -1,19 +1,51 @@
 package service
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"golang.org/x/crypto/bcrypt"
-	"gorm.io/gorm"
 
+	"github.com/tiananugerah/go-BookCabin/mail"
 	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/ratelimit"
+	"github.com/tiananugerah/go-BookCabin/repository"
 )
 
+type AuthConfig struct {
+	PasswordPolicy PasswordPolicy
+	// VerificationTTL adalah masa berlaku token verifikasi email
+	VerificationTTL time.Duration
+	// ResetTTL adalah masa berlaku token reset password
+	ResetTTL time.Duration
+	// AppBaseURL dipakai untuk menyusun link di email, misalnya https://bookcabin.example
+	AppBaseURL string
+}
+
+func DefaultAuthConfig() AuthConfig {
+	return AuthConfig{
+		PasswordPolicy:  DefaultPasswordPolicy(),
+		VerificationTTL: 48 * time.Hour,
+		ResetTTL:        time.Hour,
+		AppBaseURL:      "http://localhost:3000",
+	}
+}
+
 type AuthService struct {
-	db     *gorm.DB
-	jwtKey []byte
+	store   repository.Store
+	jwtKey  []byte
+	mailer  mail.Mailer
+	lockout *ratelimit.Lockout
+	config  AuthConfig
 }
 
 type Claims struct {
@@ -21,14 +53,22 @@ type Claims struct {
 	jwt.StandardClaims
 }
 
-func NewAuthService(db *gorm.DB, jwtKey []byte) *AuthService {
+func NewAuthService(store repository.Store, jwtKey []byte, mailer mail.Mailer, lockout *ratelimit.Lockout, config AuthConfig) *AuthService {
 	return &AuthService{
-		db:     db,
-		jwtKey: jwtKey,
+		store:   store,
+		jwtKey:  jwtKey,
+		mailer:  mailer,
+		lockout: lockout,
+		config:  config,
 	}
 }
 
+// Register membuat akun yang belum terverifikasi dan mengirim email verifikasi
 func (s *AuthService) Register(email, password, name string) (*model.User, error) {
+	if err := s.config.PasswordPolicy.Validate(password); err != nil {
+		return nil, err
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		return nil, err
@@ -38,40 +78,69 @@ func (s *AuthService) Register(email, password, name string) (*model.User, error
 		Email:    email,
 		Password: string(hashedPassword),
 		Name:     name,
+		Locale:   DefaultLocale,
 	}
 
-	result := s.db.Create(user)
-	if result.Error != nil {
-		return nil, result.Error
+	var token string
+	err = s.store.Transaction(func(tx repository.Store) error {
+		if err := tx.Users().Create(user); err != nil {
+			return err
+		}
+		token, err = s.issueToken(tx, user.ID, model.TokenEmailVerification, s.config.VerificationTTL)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrEmailExists
+		}
+		return nil, err
 	}
 
+	s.sendVerificationEmail(user, token)
 	return user, nil
 }
 
+// Login memvalidasi credentials. Kegagalan berulang untuk email yang sama mengunci
+// login sementara dengan durasi yang makin panjang, termasuk untuk email yang
+// tidak terdaftar agar respons tidak membedakan keduanya.
 func (s *AuthService) Login(email, password string) (string, error) {
-	var user model.User
-	result := s.db.Where("email = ?", email).First(&user)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return "", errors.New("invalid email or password")
+	lockKey := loginLockKey(email)
+	if locked, err := s.lockout.Check(lockKey); err != nil {
+		return "", err
+	} else if locked > 0 {
+		return "", ErrAccountLocked.WithRetryAfter(locked)
+	}
+
+	user, err := s.store.Users().FindByEmail(email)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return "", err
+	}
+	if user == nil || bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		locked, err := s.lockout.Fail(lockKey)
+		if err != nil {
+			return "", err
+		}
+		if locked > 0 {
+			return "", ErrAccountLocked.WithRetryAfter(locked)
 		}
-		return "", result.Error
+		return "", ErrInvalidCredentials
+	}
+	if err := s.lockout.Succeed(lockKey); err != nil {
+		return "", err
+	}
+	if user.EmailVerifiedAt == nil {
+		return "", ErrEmailNotVerified
 	}
 
-	err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
-	if err != nil {
-		return "", errors.New("invalid email or password")
-	}
-    
-    // Ubah waktu expired token menjadi 1 jam
-    expirationTime := time.Now().Add(1 * time.Hour)
-    claims := &Claims{
-        UserID: user.ID,
-        StandardClaims: jwt.StandardClaims{
-            ExpiresAt: expirationTime.Unix(),
-            IssuedAt:  time.Now().Unix(), // Tambahkan waktu issued
-        },
-    }
+	// Ubah waktu expired token menjadi 1 jam
+	expirationTime := time.Now().Add(1 * time.Hour)
+	claims := &Claims{
+		UserID: user.ID,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: expirationTime.Unix(),
+			IssuedAt:  time.Now().Unix(), // Tambahkan waktu issued
+		},
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString(s.jwtKey)
@@ -82,6 +151,232 @@ func (s *AuthService) Login(email, password string) (string, error) {
 	return tokenString, nil
 }
 
+// VerifyEmail memakai token verifikasi dan menandai email user sudah terverifikasi
+func (s *AuthService) VerifyEmail(token string) error {
+	return s.store.Transaction(func(tx repository.Store) error {
+		user, err := s.consumeToken(tx, model.TokenEmailVerification, token)
+		if err != nil {
+			return err
+		}
+		if user.EmailVerifiedAt != nil {
+			return nil
+		}
+
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		return tx.Users().Update(user)
+	})
+}
+
+// ResendVerification mengirim ulang email verifikasi. Email yang tidak terdaftar
+// atau sudah terverifikasi diabaikan agar endpoint tidak membocorkan data akun.
+func (s *AuthService) ResendVerification(email string) error {
+	user, err := s.store.Users().FindByEmail(email)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	var token string
+	err = s.store.Transaction(func(tx repository.Store) error {
+		if err := tx.Tokens().InvalidateForUser(user.ID, model.TokenEmailVerification, time.Now()); err != nil {
+			return err
+		}
+		token, err = s.issueToken(tx, user.ID, model.TokenEmailVerification, s.config.VerificationTTL)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.sendVerificationEmail(user, token)
+	return nil
+}
+
+// ForgotPassword mengirim link reset password. Token lama yang belum dipakai
+// dibatalkan sehingga hanya link terakhir yang berlaku.
+func (s *AuthService) ForgotPassword(email string) error {
+	user, err := s.store.Users().FindByEmail(email)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var token string
+	err = s.store.Transaction(func(tx repository.Store) error {
+		if err := tx.Tokens().InvalidateForUser(user.ID, model.TokenPasswordReset, time.Now()); err != nil {
+			return err
+		}
+		token, err = s.issueToken(tx, user.ID, model.TokenPasswordReset, s.config.ResetTTL)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.send(mail.Message{
+		To:      user.Email,
+		Subject: "Reset your BookCabin password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. The link expires in %s and can only be used once.\n\n%s\n\nIf you did not request a password reset, you can ignore this email.\n",
+			user.Name, s.config.ResetTTL, s.link("/reset-password", token)),
+	})
+	return nil
+}
+
+// ResetPassword mengganti password memakai token reset. Karena link dikirim ke
+// email user, reset yang berhasil sekaligus membuktikan email tersebut valid.
+func (s *AuthService) ResetPassword(token, password string) error {
+	if err := s.config.PasswordPolicy.Validate(password); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return s.store.Transaction(func(tx repository.Store) error {
+		user, err := s.consumeToken(tx, model.TokenPasswordReset, token)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Tokens().InvalidateForUser(user.ID, model.TokenPasswordReset, now); err != nil {
+			return err
+		}
+
+		user.Password = string(hashedPassword)
+		if user.EmailVerifiedAt == nil {
+			user.EmailVerifiedAt = &now
+		}
+		return tx.Users().Update(user)
+	})
+}
+
+// ChangePassword mengganti password user yang login setelah password lama dicek.
+// Password lama yang salah ikut dihitung oleh lockout login.
+func (s *AuthService) ChangePassword(userID uint, currentPassword, newPassword string) error {
+	user, err := s.GetUser(userID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkPassword(user, currentPassword); err != nil {
+		return err
+	}
+	if err := s.config.PasswordPolicy.Validate(newPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return s.store.Transaction(func(tx repository.Store) error {
+		if err := tx.Tokens().InvalidateForUser(user.ID, model.TokenPasswordReset, time.Now()); err != nil {
+			return err
+		}
+		user.Password = string(hashedPassword)
+		return tx.Users().Update(user)
+	})
+}
+
+// RequestEmailChange mengirim link konfirmasi ke alamat baru. Email akun baru
+// berubah setelah link tersebut dibuka, dan alamat lama diberi tahu.
+func (s *AuthService) RequestEmailChange(userID uint, currentPassword, newEmail string) error {
+	user, err := s.GetUser(userID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkPassword(user, currentPassword); err != nil {
+		return err
+	}
+	if strings.EqualFold(user.Email, newEmail) {
+		return ErrValidationFailed.WithDetail("new email is the same as the current email")
+	}
+	if _, err := s.store.Users().FindByEmail(newEmail); err == nil {
+		return ErrEmailExists
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+
+	var token string
+	err = s.store.Transaction(func(tx repository.Store) error {
+		if err := tx.Tokens().InvalidateForUser(user.ID, model.TokenEmailChange, time.Now()); err != nil {
+			return err
+		}
+		token, err = s.createToken(tx, &model.UserToken{
+			UserID:    user.ID,
+			Purpose:   model.TokenEmailChange,
+			ExpiresAt: time.Now().Add(s.config.VerificationTTL),
+			NewEmail:  newEmail,
+		})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.send(mail.Message{
+		To:      newEmail,
+		Subject: "Confirm your new BookCabin email address",
+		Body: fmt.Sprintf("Hi %s,\n\nOpen the link below to use this address for your BookCabin account. The link expires in %s.\n\n%s\n",
+			user.Name, s.config.VerificationTTL, s.link("/confirm-email-change", token)),
+	})
+	s.send(mail.Message{
+		To:      user.Email,
+		Subject: "Your BookCabin email address is being changed",
+		Body: fmt.Sprintf("Hi %s,\n\nA request was made to change the email address of your BookCabin account to %s. If this was not you, change your password immediately.\n",
+			user.Name, newEmail),
+	})
+	return nil
+}
+
+// ConfirmEmailChange memakai token email_change dan memindahkan akun ke alamat baru
+func (s *AuthService) ConfirmEmailChange(token string) error {
+	err := s.store.Transaction(func(tx repository.Store) error {
+		user, stored, err := s.consumeStoredToken(tx, model.TokenEmailChange, token)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		user.Email = stored.NewEmail
+		user.EmailVerifiedAt = &now
+		return tx.Users().Update(user)
+	})
+	if errors.Is(err, repository.ErrDuplicate) {
+		return ErrEmailExists
+	}
+	return err
+}
+
+// checkPassword mencocokkan password user yang login dan mencatat kegagalan ke lockout login
+func (s *AuthService) checkPassword(user *model.User, password string) error {
+	lockKey := loginLockKey(user.Email)
+	if locked, err := s.lockout.Check(lockKey); err != nil {
+		return err
+	} else if locked > 0 {
+		return ErrAccountLocked.WithRetryAfter(locked)
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)) != nil {
+		if locked, err := s.lockout.Fail(lockKey); err != nil {
+			return err
+		} else if locked > 0 {
+			return ErrAccountLocked.WithRetryAfter(locked)
+		}
+		return ErrIncorrectPassword
+	}
+	return nil
+}
+
 func (s *AuthService) ValidateToken(tokenStr string) (*Claims, error) {
 	claims := &Claims{}
 
@@ -89,13 +384,105 @@ func (s *AuthService) ValidateToken(tokenStr string) (*Claims, error) {
 		return s.jwtKey, nil
 	})
 
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
+
+func (s *AuthService) GetUser(userID uint) (*model.User, error) {
+	user, err := s.store.Users().FindByID(userID)
 	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidToken
+		}
 		return nil, err
 	}
+	return user, nil
+}
 
-	if !token.Valid {
-		return nil, errors.New("invalid token")
+// issueToken membuat token acak dan menyimpan hash-nya. Token mentah hanya
+// dikembalikan ke pemanggil untuk dikirim lewat email.
+func (s *AuthService) issueToken(tx repository.Store, userID uint, purpose string, ttl time.Duration) (string, error) {
+	return s.createToken(tx, &model.UserToken{UserID: userID, Purpose: purpose, ExpiresAt: time.Now().Add(ttl)})
+}
+
+func (s *AuthService) createToken(tx repository.Store, stored *model.UserToken) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
 	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
 
-	return claims, nil
-}
\ No newline at end of file
+	stored.TokenHash = hashToken(token)
+	if err := tx.Tokens().Create(stored); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// consumeToken memvalidasi token lalu menandainya terpakai dan mengembalikan pemiliknya
+func (s *AuthService) consumeToken(tx repository.Store, purpose, token string) (*model.User, error) {
+	user, _, err := s.consumeStoredToken(tx, purpose, token)
+	return user, err
+}
+
+func (s *AuthService) consumeStoredToken(tx repository.Store, purpose, token string) (*model.User, *model.UserToken, error) {
+	stored, err := tx.Tokens().FindByHash(purpose, hashToken(strings.TrimSpace(token)))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, ErrInvalidEmailToken
+		}
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if !stored.IsUsable(now) {
+		return nil, nil, ErrInvalidEmailToken
+	}
+	if err := tx.Tokens().MarkUsed(stored.ID, now); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, ErrInvalidEmailToken
+		}
+		return nil, nil, err
+	}
+
+	user, err := tx.Users().FindByID(stored.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, ErrInvalidEmailToken
+		}
+		return nil, nil, err
+	}
+	return user, stored, nil
+}
+
+func (s *AuthService) sendVerificationEmail(user *model.User, token string) {
+	s.send(mail.Message{
+		To:      user.Email,
+		Subject: "Verify your BookCabin email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below. The link expires in %s.\n\n%s\n",
+			user.Name, s.config.VerificationTTL, s.link("/verify-email", token)),
+	})
+}
+
+// send tidak menggagalkan request jika email gagal terkirim; user bisa meminta kirim ulang
+func (s *AuthService) send(msg mail.Message) {
+	if err := s.mailer.Send(msg); err != nil {
+		log.Printf("failed to send %q to %s: %v", msg.Subject, msg.To, err)
+	}
+}
+
+func (s *AuthService) link(path, token string) string {
+	return strings.TrimRight(s.config.AppBaseURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+func loginLockKey(email string) string {
+	return "login:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
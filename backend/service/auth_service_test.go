@@ -0,0 +1,205 @@
+package service
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tiananugerah/go-BookCabin/mail"
+	"github.com/tiananugerah/go-BookCabin/ratelimit"
+	"github.com/tiananugerah/go-BookCabin/repository"
+)
+
+// recordingMailer menyimpan email yang dikirim agar token di dalamnya bisa dipakai test
+type recordingMailer struct {
+	mu       sync.Mutex
+	messages []mail.Message
+}
+
+func (m *recordingMailer) Send(msg mail.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// last mengembalikan email terakhir untuk alamat to
+func (m *recordingMailer) last(t *testing.T, to string) mail.Message {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		if m.messages[i].To == to {
+			return m.messages[i]
+		}
+	}
+	t.Fatalf("no email sent to %s", to)
+	return mail.Message{}
+}
+
+func (m *recordingMailer) count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.messages)
+}
+
+// tokenFrom membaca token dari link ?token= di body email
+func tokenFrom(t *testing.T, msg mail.Message) string {
+	t.Helper()
+	_, rest, ok := strings.Cut(msg.Body, "?token=")
+	if !ok {
+		t.Fatalf("email %q has no token link", msg.Subject)
+	}
+	token, err := url.QueryUnescape(strings.Fields(rest)[0])
+	if err != nil {
+		t.Fatalf("unescape token: %v", err)
+	}
+	return token
+}
+
+func newTestAuth(t *testing.T) (*AuthService, *recordingMailer) {
+	t.Helper()
+	mailer := &recordingMailer{}
+	lockout := ratelimit.NewLockout(ratelimit.NewMemoryStore(), ratelimit.DefaultLockoutPolicy())
+	return NewAuthService(repository.NewMemoryStore(), []byte("test-key"), mailer, lockout, DefaultAuthConfig()), mailer
+}
+
+func TestRegisterRequiresVerifiedEmail(t *testing.T) {
+	auth, mailer := newTestAuth(t)
+
+	if _, err := auth.Register("budi@example.com", "password", "Budi Santoso"); !errors.Is(err, ErrWeakPassword) {
+		t.Errorf("weak password err = %v, want ErrWeakPassword", err)
+	}
+	if _, err := auth.Register("budi@example.com", "Rahasia123", "Budi Santoso"); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if _, err := auth.Register("budi@example.com", "Rahasia123", "Budi Lain"); !errors.Is(err, ErrEmailExists) {
+		t.Errorf("duplicate register err = %v, want ErrEmailExists", err)
+	}
+
+	if _, err := auth.Login("budi@example.com", "Rahasia123"); !errors.Is(err, ErrEmailNotVerified) {
+		t.Errorf("login before verification err = %v, want ErrEmailNotVerified", err)
+	}
+	token := tokenFrom(t, mailer.last(t, "budi@example.com"))
+	if err := auth.VerifyEmail("not-a-token"); !errors.Is(err, ErrInvalidEmailToken) {
+		t.Errorf("verify with unknown token err = %v, want ErrInvalidEmailToken", err)
+	}
+	if err := auth.VerifyEmail(token); err != nil {
+		t.Fatalf("VerifyEmail: %v", err)
+	}
+	if _, err := auth.Login("budi@example.com", "Rahasia123"); err != nil {
+		t.Errorf("login after verification: %v", err)
+	}
+}
+
+func TestPasswordResetIsSingleUse(t *testing.T) {
+	auth, mailer := newTestAuth(t)
+	if _, err := auth.Register("budi@example.com", "Rahasia123", "Budi Santoso"); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	// Email yang tidak terdaftar tidak dibedakan dan tidak dikirimi apa pun
+	sent := mailer.count()
+	if err := auth.ForgotPassword("nobody@example.com"); err != nil {
+		t.Errorf("ForgotPassword for unknown email: %v", err)
+	}
+	if mailer.count() != sent {
+		t.Error("reset email was sent to an unknown address")
+	}
+
+	if err := auth.ForgotPassword("budi@example.com"); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	first := tokenFrom(t, mailer.last(t, "budi@example.com"))
+	if err := auth.ForgotPassword("budi@example.com"); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	second := tokenFrom(t, mailer.last(t, "budi@example.com"))
+
+	if err := auth.ResetPassword(first, "BaruSekali456"); !errors.Is(err, ErrInvalidEmailToken) {
+		t.Errorf("superseded reset token err = %v, want ErrInvalidEmailToken", err)
+	}
+	if err := auth.ResetPassword(second, "lemah"); !errors.Is(err, ErrWeakPassword) {
+		t.Errorf("weak new password err = %v, want ErrWeakPassword", err)
+	}
+	if err := auth.ResetPassword(second, "BaruSekali456"); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+	if err := auth.ResetPassword(second, "LagiLagi789"); !errors.Is(err, ErrInvalidEmailToken) {
+		t.Errorf("reused reset token err = %v, want ErrInvalidEmailToken", err)
+	}
+
+	// Reset lewat link email sekaligus memverifikasi alamatnya
+	if _, err := auth.Login("budi@example.com", "BaruSekali456"); err != nil {
+		t.Errorf("login with new password: %v", err)
+	}
+	if _, err := auth.Login("budi@example.com", "Rahasia123"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("login with old password err = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+func TestEmailChangeNeedsPasswordAndConfirmation(t *testing.T) {
+	auth, mailer := newTestAuth(t)
+	user, err := auth.Register("budi@example.com", "Rahasia123", "Budi Santoso")
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if err := auth.VerifyEmail(tokenFrom(t, mailer.last(t, "budi@example.com"))); err != nil {
+		t.Fatalf("VerifyEmail: %v", err)
+	}
+
+	if err := auth.RequestEmailChange(user.ID, "salah", "budi.baru@example.com"); !errors.Is(err, ErrIncorrectPassword) {
+		t.Errorf("wrong password err = %v, want ErrIncorrectPassword", err)
+	}
+	if err := auth.RequestEmailChange(user.ID, "Rahasia123", "budi.baru@example.com"); err != nil {
+		t.Fatalf("RequestEmailChange: %v", err)
+	}
+	if notice := mailer.last(t, "budi@example.com"); !strings.Contains(notice.Body, "budi.baru@example.com") {
+		t.Errorf("old address notice = %q, want it to mention the new address", notice.Body)
+	}
+
+	// Email baru belum berlaku sebelum link dikonfirmasi
+	if _, err := auth.Login("budi.baru@example.com", "Rahasia123"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("login with unconfirmed email err = %v, want ErrInvalidCredentials", err)
+	}
+	if err := auth.ConfirmEmailChange(tokenFrom(t, mailer.last(t, "budi.baru@example.com"))); err != nil {
+		t.Fatalf("ConfirmEmailChange: %v", err)
+	}
+	if _, err := auth.Login("budi.baru@example.com", "Rahasia123"); err != nil {
+		t.Errorf("login with confirmed email: %v", err)
+	}
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	policy := DefaultPasswordPolicy()
+	tests := []struct {
+		password string
+		ok       bool
+	}{
+		{"Rahasia123", true},
+		{"Rah1", false},
+		{"rahasia123", false},
+		{"RAHASIA123", false},
+		{"Rahasiaaa", false},
+		{strings.Repeat("Aa1", 25), false},
+	}
+	for _, tt := range tests {
+		err := policy.Validate(tt.password)
+		if tt.ok && err != nil {
+			t.Errorf("Validate(%q) = %v, want nil", tt.password, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrWeakPassword) {
+			t.Errorf("Validate(%q) = %v, want ErrWeakPassword", tt.password, err)
+		}
+	}
+
+	symbols := PasswordPolicy{MinLength: 4, RequireSymbol: true}
+	if err := symbols.Validate("abcd!"); err != nil {
+		t.Errorf("symbol policy rejected abcd!: %v", err)
+	}
+	if err := symbols.Validate("abcd"); !errors.Is(err, ErrWeakPassword) {
+		t.Errorf("symbol policy accepted abcd: %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// BCBPData adalah field mandatory satu leg pada IATA Bar Coded Boarding Pass (Resolution 792)
+type BCBPData struct {
+	PassengerName    string
+	BookingReference string
+	From             string
+	To               string
+	CarrierCode      string
+	FlightNumber     int
+	Departure        time.Time
+	Compartment      string
+	SeatCode         string
+	CheckInSequence  int
+}
+
+// EncodeBCBP menghasilkan string BCBP format "M1" (satu leg, tanpa item conditional)
+// yang bisa langsung dirender sebagai barcode PDF417 atau QR.
+func EncodeBCBP(d BCBPData) string {
+	var b strings.Builder
+	b.WriteString("M1")
+	b.WriteString(fixed(bcbpName(d.PassengerName), 20))
+	b.WriteString("E")
+	b.WriteString(fixed(strings.ToUpper(d.BookingReference), 7))
+	b.WriteString(fixed(d.From, 3))
+	b.WriteString(fixed(d.To, 3))
+	b.WriteString(fixed(d.CarrierCode, 3))
+	b.WriteString(fixed(fmt.Sprintf("%04d", d.FlightNumber), 5))
+	b.WriteString(fmt.Sprintf("%03d", d.Departure.YearDay()))
+	b.WriteString(fixed(d.Compartment, 1))
+	b.WriteString(fixed(bcbpSeat(d.SeatCode), 4))
+	b.WriteString(fixed(fmt.Sprintf("%04d", d.CheckInSequence), 5))
+	// Passenger status 1: tiket diterbitkan dan penumpang sudah check-in
+	b.WriteString("1")
+	// Panjang field conditional (hex); tidak ada item conditional
+	b.WriteString("00")
+	return b.String()
+}
+
+// bcbpName mengubah "Given Names Surname" menjadi "SURNAME/GIVEN NAMES"
+func bcbpName(name string) string {
+	parts := strings.Fields(strings.ToUpper(name))
+	if len(parts) == 0 {
+		return ""
+	}
+	clean := func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if r >= 'A' && r <= 'Z' || r == ' ' {
+				return r
+			}
+			if unicode.IsSpace(r) {
+				return ' '
+			}
+			return -1
+		}, s)
+	}
+
+	surname := clean(strings.ToUpper(lastNameOf(name)))
+	if len(parts) == 1 {
+		return surname
+	}
+	return surname + "/" + clean(strings.Join(parts[:len(parts)-1], " "))
+}
+
+// lastNameOf mengambil kata terakhir dari nama lengkap sebagai nama belakang
+func lastNameOf(name string) string {
+	parts := strings.Fields(name)
+	if len(parts) == 0 {
+		return ""
+	}
+	return parts[len(parts)-1]
+}
+
+// bcbpSeat mengubah kode kursi seperti "4A" menjadi "004A"
+func bcbpSeat(code string) string {
+	i := strings.IndexFunc(code, func(r rune) bool { return r < '0' || r > '9' })
+	if i <= 0 {
+		return code
+	}
+	return strings.Repeat("0", max(0, 3-i)) + code
+}
+
+// fixed memotong atau menambah spasi di kanan agar panjang string tepat n karakter
+func fixed(s string, n int) string {
+	if len(s) >= n {
+		return s[:n]
+	}
+	return s + strings.Repeat(" ", n-len(s))
+}
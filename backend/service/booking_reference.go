@@ -0,0 +1,55 @@
+package service
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/tiananugerah/go-BookCabin/repository"
+)
+
+// referenceAlphabet tidak memuat karakter yang mudah tertukar (0/O, 1/I/L)
+const referenceAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+const (
+	referenceLength      = 6
+	referenceMaxAttempts = 10
+)
+
+// newBookingReference membuat record locator acak yang belum dipakai booking lain
+func newBookingReference(tx repository.Store) (string, error) {
+	for attempt := 0; attempt < referenceMaxAttempts; attempt++ {
+		reference, err := randomReference()
+		if err != nil {
+			return "", err
+		}
+
+		_, err = tx.Bookings().FindByReference(reference)
+		if errors.Is(err, repository.ErrNotFound) {
+			return reference, nil
+		} else if err != nil {
+			return "", err
+		}
+	}
+	return "", fmt.Errorf("could not generate a unique booking reference after %d attempts", referenceMaxAttempts)
+}
+
+func randomReference() (string, error) {
+	var b strings.Builder
+	max := big.NewInt(int64(len(referenceAlphabet)))
+	for i := 0; i < referenceLength; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(referenceAlphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+// NormalizeReference menyeragamkan input locator dari user (huruf besar, tanpa spasi)
+func NormalizeReference(reference string) string {
+	return strings.ToUpper(strings.TrimSpace(reference))
+}
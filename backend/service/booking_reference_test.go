@@ -0,0 +1,113 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+func TestBookingReferenceFormat(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.user(t, "budi@example.com")
+
+	seen := map[string]bool{}
+	for _, seat := range env.freeSeats(t)[:3] {
+		booking, err := env.bookings.CreateBooking(user.ID, seat.ID, "")
+		if err != nil {
+			t.Fatalf("CreateBooking: %v", err)
+		}
+		ref := booking.Reference
+		if len(ref) != referenceLength || strings.Trim(ref, referenceAlphabet) != "" {
+			t.Errorf("reference %q is not %d characters from the locator alphabet", ref, referenceLength)
+		}
+		if seen[ref] {
+			t.Errorf("reference %q was issued twice", ref)
+		}
+		seen[ref] = true
+	}
+}
+
+func TestManageBookingByReferenceAndLastName(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.user(t, "budi@example.com")
+	seat := env.freeSeats(t)[0]
+	booking, err := env.bookings.CreateBooking(user.ID, seat.ID, "")
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+
+	// Locator dan nama belakang dicocokkan tanpa memedulikan huruf besar dan spasi
+	details, err := env.bookings.ManageBooking(" "+strings.ToLower(booking.Reference)+" ", "santoso")
+	if err != nil {
+		t.Fatalf("ManageBooking: %v", err)
+	}
+	if details.Reference != booking.Reference || details.SeatCode != seat.SeatCode || details.PassengerName != user.Name {
+		t.Errorf("details = %s %s %q, want %s %s %q", details.Reference, details.SeatCode, details.PassengerName, booking.Reference, seat.SeatCode, user.Name)
+	}
+	if details.BookingID != 0 || details.Email != "" {
+		t.Errorf("public lookup exposes booking %d and email %q", details.BookingID, details.Email)
+	}
+
+	if _, err := env.bookings.ManageBooking(booking.Reference, "Wijaya"); !errors.Is(err, ErrBookingNotFound) {
+		t.Errorf("wrong last name err = %v, want ErrBookingNotFound", err)
+	}
+	if _, err := env.bookings.ManageBooking("ZZZZZZ", "Santoso"); !errors.Is(err, ErrBookingNotFound) {
+		t.Errorf("unknown locator err = %v, want ErrBookingNotFound", err)
+	}
+
+	// Support tetap bisa mencari booking milik akun yang sudah dihapus
+	if err := env.store.Users().Delete(user.ID); err != nil {
+		t.Fatalf("delete user: %v", err)
+	}
+	if _, err := env.bookings.ManageBooking(booking.Reference, "Santoso"); !errors.Is(err, ErrBookingNotFound) {
+		t.Errorf("deleted account err = %v, want ErrBookingNotFound", err)
+	}
+	support, err := env.bookings.FindByReference(booking.Reference)
+	if err != nil {
+		t.Fatalf("FindByReference: %v", err)
+	}
+	if support.BookingID != booking.ID || support.UserID != user.ID || support.Email != user.Email {
+		t.Errorf("support details = booking %d user %d %q, want booking %d user %d %q", support.BookingID, support.UserID, support.Email, booking.ID, user.ID, user.Email)
+	}
+}
+
+func TestBackfillReferences(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.user(t, "budi@example.com")
+
+	// Booking lama dibuat langsung di store, sebelum record locator ada
+	var legacy []uint
+	for _, seat := range env.freeSeats(t)[:2] {
+		booking := &model.Booking{UserID: user.ID, SeatID: seat.ID, Status: model.StatusConfirmed, Price: seat.Price, BookedAt: time.Now()}
+		if err := env.store.Bookings().Create(booking); err != nil {
+			t.Fatalf("create legacy booking: %v", err)
+		}
+		legacy = append(legacy, booking.ID)
+	}
+
+	if err := env.bookings.BackfillReferences(); err != nil {
+		t.Fatalf("BackfillReferences: %v", err)
+	}
+	remaining, err := env.store.Bookings().FindWithoutReference()
+	if err != nil {
+		t.Fatalf("FindWithoutReference: %v", err)
+	}
+	if len(remaining) != 0 {
+		t.Errorf("%d bookings still have no reference", len(remaining))
+	}
+
+	refs := map[string]bool{}
+	for _, id := range legacy {
+		booking, err := env.store.Bookings().FindByID(id)
+		if err != nil {
+			t.Fatalf("find booking: %v", err)
+		}
+		if booking.Reference == "" || refs[booking.Reference] {
+			t.Errorf("booking %d reference = %q, want a unique locator", id, booking.Reference)
+		}
+		refs[booking.Reference] = true
+	}
+}
@@ -1,107 +1,996 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"log"
+	"strings"
 	"time"
 
-	"gorm.io/gorm"
-
+	"github.com/tiananugerah/go-BookCabin/event"
 	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/repository"
 )
 
+type BookingConfig struct {
+	// HoldDuration adalah batas waktu untuk mengonfirmasi hold, termasuk hold dari waitlist
+	HoldDuration time.Duration
+	// HoldReminderBefore adalah jarak sebelum hold kedaluwarsa saat pengingat dikirim. Nilai 0
+	// mematikan pengingat.
+	HoldReminderBefore time.Duration
+	// HoldCutoff membatasi hold agar kedaluwarsa paling lambat HoldCutoff sebelum keberangkatan;
+	// setelah batas itu kursi hanya bisa langsung dibooking. Nilai 0 mematikan aturan ini.
+	HoldCutoff time.Duration
+	// CancelCutoff adalah batas pembatalan booking confirmed oleh user sebelum keberangkatan;
+	// admin tetap bisa membatalkan. Nilai 0 mematikan aturan ini.
+	CancelCutoff time.Duration
+	Limits       BookingLimits
+}
+
+// BookingLimits mencegah satu akun menimbun kursi. Nilai 0 mematikan aturan terkait.
+// Akun agent yang diberi BookingLimitExempt oleh admin tidak terkena aturan ini.
+type BookingLimits struct {
+	// MaxPerFlight adalah jumlah maksimum booking confirmed dan hold per user per penerbangan
+	MaxPerFlight int
+	// MaxOpenHolds adalah jumlah maksimum hold aktif per user di semua penerbangan
+	MaxOpenHolds int
+	// ExpiryThreshold hold yang kedaluwarsa dalam ExpiryWindow memicu Cooldown,
+	// dihitung dari hold terakhir yang kedaluwarsa
+	ExpiryThreshold int
+	ExpiryWindow    time.Duration
+	Cooldown        time.Duration
+}
+
+func DefaultBookingConfig() BookingConfig {
+	return BookingConfig{
+		HoldDuration:       15 * time.Minute,
+		HoldReminderBefore: 5 * time.Minute,
+		Limits: BookingLimits{
+			MaxPerFlight:    9,
+			MaxOpenHolds:    2,
+			ExpiryThreshold: 3,
+			ExpiryWindow:    24 * time.Hour,
+			Cooldown:        time.Hour,
+		},
+	}
+}
+
+// BookingDetails adalah ringkasan booking untuk manage-booking dan customer support
+type BookingDetails struct {
+	// BookingID hanya diisi untuk support dan admin
+	BookingID     uint                `json:"booking_id,omitempty"`
+	Reference     string              `json:"reference"`
+	Status        model.BookingStatus `json:"status"`
+	PassengerName string              `json:"passenger_name"`
+	UserID        uint                `json:"user_id,omitempty"`
+	Email         string              `json:"email,omitempty"`
+	Flight        *model.Flight       `json:"flight,omitempty"`
+	SeatCode      string              `json:"seat_code"`
+	Cabin         string              `json:"cabin"`
+	OriginalPrice float64             `json:"original_price"`
+	// EntitlementRuleID dan EntitlementDiscount diisi jika biaya kursi dibebaskan untuk penumpang
+	EntitlementRuleID   string     `json:"entitlement_rule_id,omitempty"`
+	EntitlementDiscount float64    `json:"entitlement_discount,omitempty"`
+	Discount            float64    `json:"discount,omitempty"`
+	PromoCode           string     `json:"promo_code,omitempty"`
+	Price               float64    `json:"price"`
+	Currency            string     `json:"currency"`
+	BookedAt            time.Time  `json:"booked_at"`
+	HoldExpiresAt       *time.Time `json:"hold_expires_at,omitempty"`
+	CheckedInAt         *time.Time `json:"checked_in_at,omitempty"`
+}
+
 type BookingService struct {
-	db *gorm.DB
+	store   repository.Store
+	bus     event.Bus
+	pricing *PricingService
+	config  BookingConfig
+}
+
+func NewBookingService(store repository.Store, bus event.Bus, pricing *PricingService, config BookingConfig) *BookingService {
+	return &BookingService{store: store, bus: bus, pricing: pricing, config: config}
+}
+
+// CreateBooking membooking kursi untuk user. promoCode (opsional) divalidasi dan dipakai
+// dalam transaksi yang sama; jika promo tidak berlaku, booking tidak dibuat.
+func (s *BookingService) CreateBooking(userID, seatID uint, promoCode string) (*model.Booking, error) {
+	return s.reserveSeat(userID, seatID, promoCode, model.StatusConfirmed)
+}
+
+// HoldSeat menahan kursi untuk user sampai HoldDuration (dipendekkan oleh HoldCutoff);
+// hold harus dikonfirmasi lewat ConfirmBooking
+func (s *BookingService) HoldSeat(userID, seatID uint, promoCode string) (*model.Booking, error) {
+	return s.reserveSeat(userID, seatID, promoCode, model.StatusPending)
+}
+
+func (s *BookingService) reserveSeat(userID, seatID uint, promoCode string, status model.BookingStatus) (*model.Booking, error) {
+	seat, err := s.store.Seats().FindByID(seatID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrSeatNotFound
+		}
+		return nil, err
+	}
+
+	var booking *model.Booking
+	err = s.store.Transaction(func(tx repository.Store) error {
+		if err := ensureSeatNotBlocked(tx, seatID); err != nil {
+			return err
+		}
+		if err := checkEligibility(tx, userID, seat, 0); err != nil {
+			return err
+		}
+		if err := s.checkLimits(tx, userID, seat, status == model.StatusPending); err != nil {
+			return err
+		}
+
+		// Cek apakah kursi sudah dibooking, sedang di-hold, atau sudah dipilih di sistem sumber
+		if err := ensureSeatFree(tx, seatID); err != nil {
+			return err
+		}
+
+		reference, err := newBookingReference(tx)
+		if err != nil {
+			return err
+		}
+
+		// Harga dihitung saat kursi diambil dan terkunci di booking
+		now := time.Now().UTC()
+		quote, err := s.pricing.quote(tx, seat, userID, now)
+		if err != nil {
+			return err
+		}
+
+		var holdExpiresAt *time.Time
+		if status == model.StatusPending {
+			expiresAt, err := s.holdDeadline(tx, seat, now)
+			if err != nil {
+				return err
+			}
+			holdExpiresAt = &expiresAt
+		}
+
+		booking = &model.Booking{
+			Reference:     reference,
+			UserID:        userID,
+			SeatID:        seatID,
+			Status:        status,
+			BookedAt:      now,
+			Currency:      quote.Currency,
+			HoldExpiresAt: holdExpiresAt,
+		}
+		applyQuote(booking, quote)
+
+		var promo *model.PromoCode
+		if strings.TrimSpace(promoCode) != "" {
+			var discount float64
+			promo, discount, err = redeemPromo(tx, promoCode, userID, seat, quote, now)
+			if err != nil {
+				return err
+			}
+			booking.PromoCode = promo.Code
+			booking.Discount = discount
+			booking.Price = roundPrice(quote.Price - discount)
+		}
+
+		if err := tx.Bookings().Create(booking); err != nil {
+			return seatConflict(err)
+		}
+		if promo != nil {
+			if err := recordRedemption(tx, promo, booking); err != nil {
+				return err
+			}
+		}
+
+		return tx.Seats().SetAvailable(seatID, false)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if status == model.StatusPending {
+		s.publish(event.SeatHeld, booking, seat, false)
+	} else {
+		s.publish(event.BookingCreated, booking, seat, false)
+	}
+
+	// Load seat data
+	return s.store.Bookings().FindByID(booking.ID)
 }
 
-func NewBookingService(db *gorm.DB) *BookingService {
-	return &BookingService{db: db}
+// holdDeadline menghitung batas hold: HoldDuration dari now, dipendekkan sampai HoldCutoff
+// sebelum keberangkatan. Keduanya instant UTC sehingga zona bandara dan DST tidak berpengaruh.
+func (s *BookingService) holdDeadline(tx repository.Store, seat *model.Seat, now time.Time) (time.Time, error) {
+	expiresAt := now.Add(s.config.HoldDuration)
+	if s.config.HoldCutoff <= 0 {
+		return expiresAt, nil
+	}
+	flight, err := tx.Flights().FindByID(seat.FlightID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return expiresAt, nil
+	} else if err != nil {
+		return time.Time{}, err
+	}
+
+	cutoff := flight.Departure.Add(-s.config.HoldCutoff)
+	if !now.Before(cutoff) {
+		return time.Time{}, ErrHoldClosed.WithDetail("holds closed at %s; book the seat directly",
+			model.InZone(cutoff, flight.DepartureZone).Format(time.RFC3339))
+	}
+	if cutoff.Before(expiresAt) {
+		expiresAt = cutoff
+	}
+	return expiresAt, nil
 }
 
-func (s *BookingService) CreateBooking(userID, seatID uint) (*model.Booking, error) {
-	var seat model.Seat
-	if err := s.db.First(&seat, seatID).Error; err != nil {
-		return nil, errors.New("seat not found")
+// applyQuote mengunci harga quote (termasuk entitlement penumpang) ke booking
+func applyQuote(booking *model.Booking, quote *PriceQuote) {
+	booking.Price = quote.Price
+	booking.OriginalPrice = quote.Price
+	if quote.Entitlement != nil {
+		booking.EntitlementRuleID = quote.Entitlement.RuleID
+		booking.EntitlementDiscount = quote.Entitlement.Discount
+		booking.OriginalPrice = roundPrice(quote.Price + quote.Entitlement.Discount)
+	}
+}
+
+// checkLimits menerapkan BookingLimits untuk booking atau hold baru milik user. Baris user dikunci
+// dulu supaya request paralel dari user yang sama dihitung bergantian, bukan semuanya melihat
+// jumlah lama lalu lolos bersama.
+func (s *BookingService) checkLimits(tx repository.Store, userID uint, seat *model.Seat, hold bool) error {
+	limits := s.config.Limits
+	if err := tx.Users().Lock(userID); err != nil {
+		return err
+	}
+	user, err := tx.Users().FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.Role == model.RoleAgent && user.BookingLimitExempt {
+		return nil
+	}
+
+	if limits.MaxPerFlight > 0 {
+		count, err := tx.Bookings().CountActiveByUserOnFlight(userID, seat.FlightID)
+		if err != nil {
+			return err
+		}
+		if count >= limits.MaxPerFlight {
+			return ErrFlightBookingLimit.WithDetail("at most %d active bookings per flight are allowed", limits.MaxPerFlight)
+		}
+	}
+	if !hold {
+		return nil
+	}
+
+	now := time.Now()
+	if limits.ExpiryThreshold > 0 && limits.Cooldown > 0 {
+		expired, err := tx.Bookings().FindExpiredByUser(userID, now.Add(-limits.ExpiryWindow))
+		if err != nil {
+			return err
+		}
+		if len(expired) >= limits.ExpiryThreshold {
+			until := expired[len(expired)-1].HoldExpiresAt.Add(limits.Cooldown)
+			if now.Before(until) {
+				return ErrHoldCooldown.WithDetail("%d holds expired recently; new holds are allowed after %s", len(expired), until.Format(time.RFC3339)).
+					WithRetryAfter(until.Sub(now))
+			}
+		}
+	}
+	if limits.MaxOpenHolds > 0 {
+		count, err := tx.Bookings().CountOpenHolds(userID, now)
+		if err != nil {
+			return err
+		}
+		if count >= limits.MaxOpenHolds {
+			return ErrHoldLimit.WithDetail("at most %d open holds are allowed; confirm or cancel an existing hold first", limits.MaxOpenHolds)
+		}
 	}
+	return nil
+}
 
-	// Cek apakah kursi sudah dibooking
-	var existingBooking model.Booking
-	if err := s.db.Where("seat_id = ? AND status = ? AND deleted_at IS NULL", seatID, model.StatusConfirmed).First(&existingBooking).Error; err == nil {
-		return nil, errors.New("seat is already booked")
-	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+// ConfirmBooking mengubah hold milik user menjadi booking confirmed
+func (s *BookingService) ConfirmBooking(userID, bookingID uint) (*model.Booking, error) {
+	booking, err := s.findUserBooking(userID, bookingID)
+	if err != nil {
 		return nil, err
 	}
 
-	booking := &model.Booking{
-		UserID:   userID,
-		SeatID:   seatID,
-		Status:   model.StatusConfirmed,
-		BookedAt: time.Now(),
-		Price:    seat.Price,
-		Currency: seat.Currency,
+	err = s.store.Transaction(func(tx repository.Store) error {
+		// Status dibaca ulang di bawah lock agar konfirmasi tidak bersaing dengan sweeper atau pembatalan
+		current, err := lockBooking(tx, booking.ID)
+		if err != nil {
+			return err
+		}
+		if current.Status == model.StatusExpired {
+			return ErrHoldExpired
+		}
+		if current.Status != model.StatusPending {
+			return ErrBookingNotPending
+		}
+		if current.IsHoldExpired(time.Now()) {
+			return ErrHoldExpired
+		}
+		booking = current
+
+		if err := tx.Bookings().UpdateStatus(booking.ID, model.StatusConfirmed); err != nil {
+			return err
+		}
+		return closeWaitlistOffer(tx, booking.ID, model.WaitlistFulfilled)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	err := s.db.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Create(booking).Error; err != nil {
+	s.publish(event.BookingConfirmed, booking, &booking.Seat, false)
+	return s.store.Bookings().FindByID(booking.ID)
+}
+
+func (s *BookingService) CancelBooking(userID, bookingID uint) error {
+	booking, err := s.findUserBooking(userID, bookingID)
+	if err != nil {
+		return err
+	}
+	if booking.Status == model.StatusConfirmed && s.config.CancelCutoff > 0 {
+		flight, err := s.store.Flights().FindByID(booking.Seat.FlightID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
 			return err
 		}
+		if flight != nil {
+			cutoff := flight.Departure.Add(-s.config.CancelCutoff)
+			if !time.Now().Before(cutoff) {
+				return ErrCancellationClosed.WithDetail("cancellation closed at %s", model.InZone(cutoff, flight.DepartureZone).Format(time.RFC3339))
+			}
+		}
+	}
+	return s.cancel(booking, nil)
+}
 
-		if err := tx.Model(&seat).Update("available", false).Error; err != nil {
+// cancel membatalkan booking dan melepas kursinya. fn (jika ada) dijalankan
+// dalam transaksi yang sama, misalnya untuk mencatat audit log admin.
+func (s *BookingService) cancel(booking *model.Booking, fn func(tx repository.Store) error) error {
+	var offer *model.Booking
+	err := s.store.Transaction(func(tx repository.Store) error {
+		current, err := lockBooking(tx, booking.ID)
+		if err != nil {
 			return err
 		}
+		switch current.Status {
+		case model.StatusCancelled:
+			return ErrBookingAlreadyCancelled
+		case model.StatusExpired:
+			return ErrHoldExpired
+		}
+		booking = current
 
+		offer, err = s.cancelBooking(tx, booking)
+		if err != nil {
+			return err
+		}
+		if fn != nil {
+			return fn(tx)
+		}
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	s.publish(event.BookingCancelled, booking, &booking.Seat, booking.Seat.Available)
+	if offer != nil {
+		s.publish(event.SeatHeld, offer, &booking.Seat, false)
+	}
+	return nil
+}
+
+// cancelBooking membatalkan booking yang sudah dikunci di dalam transaksi tx dan melepas
+// kursinya. Booking waitlist yang mendapat kursi itu dikembalikan untuk dipublikasikan.
+func (s *BookingService) cancelBooking(tx repository.Store, booking *model.Booking) (*model.Booking, error) {
+	// Update status booking menjadi cancelled
+	if err := tx.Bookings().UpdateStatus(booking.ID, model.StatusCancelled); err != nil {
+		return nil, err
+	}
+	if err := closeWaitlistOffer(tx, booking.ID, model.WaitlistCancelled); err != nil {
+		return nil, err
+	}
+	if err := closeReaccommodation(tx, booking.ID, model.ReaccommodationCancelled, nil); err != nil {
+		return nil, err
+	}
+	if err := releasePromo(tx, booking.ID, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.releaseSeat(tx, &booking.Seat)
+}
+
+// releasedBooking adalah booking yang dibatalkan di dalam transaksi beserta booking waitlist
+// yang mendapat kursinya; event-nya baru dipublikasikan setelah commit
+type releasedBooking struct {
+	booking *model.Booking
+	offer   *model.Booking
+}
 
+// releaseUserBookings membatalkan hold yang masih terbuka dan booking confirmed untuk penerbangan
+// yang belum berangkat milik user. Booking penerbangan yang sudah lewat dibiarkan sebagai riwayat.
+func (s *BookingService) releaseUserBookings(tx repository.Store, userID uint) ([]releasedBooking, error) {
+	bookings, err := tx.Bookings().FindByUser(userID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Load seat data
-	if err := s.db.Preload("Seat").First(booking, booking.ID).Error; err != nil {
+	now := time.Now()
+	var released []releasedBooking
+	for i := range bookings {
+		switch bookings[i].Status {
+		case model.StatusPending:
+		case model.StatusConfirmed:
+			flight, err := tx.Flights().FindByID(bookings[i].Seat.FlightID)
+			if errors.Is(err, repository.ErrNotFound) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			if !flight.Departure.After(now) {
+				continue
+			}
+		default:
+			continue
+		}
+
+		booking, err := lockBooking(tx, bookings[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		if booking.Status != model.StatusPending && booking.Status != model.StatusConfirmed {
+			continue
+		}
+		offer, err := s.cancelBooking(tx, booking)
+		if err != nil {
+			return nil, err
+		}
+		released = append(released, releasedBooking{booking: booking, offer: offer})
+	}
+	return released, nil
+}
+
+// publishReleased mempublikasikan event untuk booking yang dibatalkan releaseUserBookings
+func (s *BookingService) publishReleased(released []releasedBooking) {
+	for _, r := range released {
+		s.publish(event.BookingCancelled, r.booking, &r.booking.Seat, r.booking.Seat.Available)
+		if r.offer != nil {
+			s.publish(event.SeatHeld, r.offer, &r.booking.Seat, false)
+		}
+	}
+}
+
+// reassignSeat memindahkan booking aktif ke kursi lain pada penerbangan yang sama.
+// Harga booking tidak berubah; kursi lama dilepas seperti pada pembatalan.
+func (s *BookingService) reassignSeat(booking *model.Booking, seatID uint, fn func(tx repository.Store) error) (*model.Booking, error) {
+	if booking.Status != model.StatusConfirmed && booking.Status != model.StatusPending {
+		return nil, ErrBookingNotActive
+	}
+	if booking.SeatID == seatID {
+		return nil, ErrValidationFailed.WithDetail("booking is already assigned to seat %d", seatID)
+	}
+
+	seat, err := s.store.Seats().FindByID(seatID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrSeatNotFound
+		}
 		return nil, err
 	}
+	if seat.FlightID != booking.Seat.FlightID {
+		return nil, ErrValidationFailed.WithDetail("seat %s is on a different flight", seat.SeatCode)
+	}
 
-	return booking, nil
+	oldSeat := booking.Seat
+	var offer *model.Booking
+	err = s.store.Transaction(func(tx repository.Store) error {
+		if err := ensureSeatNotBlocked(tx, seatID); err != nil {
+			return err
+		}
+		if err := ensureSeatFree(tx, seatID); err != nil {
+			return err
+		}
+		if err := checkEligibility(tx, booking.UserID, seat, booking.ID); err != nil {
+			return err
+		}
+
+		booking.SeatID = seatID
+		if err := tx.Bookings().Update(booking); err != nil {
+			return seatConflict(err)
+		}
+		if err := tx.Seats().SetAvailable(seatID, false); err != nil {
+			return err
+		}
+		if err := closeReaccommodation(tx, booking.ID, model.ReaccommodationResolved, &seatID); err != nil {
+			return err
+		}
+
+		var err error
+		offer, err = s.releaseSeat(tx, &oldSeat)
+		if err != nil {
+			return err
+		}
+		if fn != nil {
+			return fn(tx)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.publish(event.SeatReleased, booking, &oldSeat, oldSeat.Available)
+	if offer != nil {
+		s.publish(event.SeatHeld, offer, &oldSeat, false)
+	}
+	s.publish(event.BookingSeatChanged, booking, seat, false)
+	return s.store.Bookings().FindByID(booking.ID)
 }
 
-func (s *BookingService) CancelBooking(userID, bookingID uint) error {
-    var booking model.Booking
-    if err := s.db.Where("id = ? AND user_id = ?", bookingID, userID).First(&booking).Error; err != nil {
-        return err
-    }
+// ExpireHolds melepas semua hold yang sudah melewati batas konfirmasi pada waktu now
+func (s *BookingService) ExpireHolds(now time.Time) error {
+	holds, err := s.store.Bookings().FindExpiredHolds(now)
+	if err != nil {
+		return err
+	}
+
+	for i := range holds {
+		hold := &holds[i]
+
+		var offer *model.Booking
+		err := s.store.Transaction(func(tx repository.Store) error {
+			// Hold bisa saja sudah dikonfirmasi atau dibatalkan sejak query di atas
+			current, err := tx.Bookings().FindByID(hold.ID)
+			if err != nil {
+				return err
+			}
+			if !current.IsHoldExpired(now) {
+				return nil
+			}
+
+			if err := tx.Bookings().UpdateStatus(hold.ID, model.StatusExpired); err != nil {
+				return err
+			}
+			if err := closeWaitlistOffer(tx, hold.ID, model.WaitlistExpired); err != nil {
+				return err
+			}
+			if err := closeReaccommodation(tx, hold.ID, model.ReaccommodationCancelled, nil); err != nil {
+				return err
+			}
+			if err := releasePromo(tx, hold.ID, now); err != nil {
+				return err
+			}
+
+			offer, err = s.releaseSeat(tx, &hold.Seat)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		s.publish(event.SeatReleased, hold, &hold.Seat, hold.Seat.Available)
+		if offer != nil {
+			s.publish(event.SeatHeld, offer, &hold.Seat, false)
+		}
+	}
+	return nil
+}
+
+// RemindExpiringHolds mempublikasikan HoldExpiring untuk hold yang kedaluwarsa dalam
+// HoldReminderBefore dari now. Setiap hold hanya diingatkan sekali, juga antar replika.
+func (s *BookingService) RemindExpiringHolds(now time.Time) error {
+	if s.config.HoldReminderBefore <= 0 {
+		return nil
+	}
+	holds, err := s.store.Bookings().FindHoldsToRemind(now.Add(s.config.HoldReminderBefore))
+	if err != nil {
+		return err
+	}
+
+	for i := range holds {
+		hold := &holds[i]
+		if hold.IsHoldExpired(now) {
+			continue
+		}
+		marked, err := s.store.Bookings().MarkHoldReminded(hold.ID, now)
+		if err != nil {
+			return err
+		}
+		if marked {
+			s.publish(event.HoldExpiring, hold, &hold.Seat, false)
+		}
+	}
+	return nil
+}
+
+// ExpireSeatBlocks mencabut block kursi yang batas waktunya sudah lewat pada now.
+// Kursi kosong ditawarkan ke waitlist lebih dulu, seperti kursi yang baru dilepas.
+func (s *BookingService) ExpireSeatBlocks(now time.Time) error {
+	seats, err := s.store.Seats().FindExpiredBlocks(now)
+	if err != nil {
+		return err
+	}
+
+	for i := range seats {
+		seat := &seats[i]
+
+		var offer *model.Booking
+		var lifted bool
+		err := s.store.Transaction(func(tx repository.Store) error {
+			// Block bisa saja sudah diperpanjang atau dicabut admin sejak query di atas
+			current, err := tx.Seats().FindByID(seat.ID)
+			if err != nil {
+				return err
+			}
+			if current.BlockReason == "" || current.IsBlocked(now) {
+				return nil
+			}
+
+			if err := tx.Seats().SetBlock(seat.ID, model.SeatBlock{}); err != nil {
+				return err
+			}
+			lifted = true
+			offer, err = s.releaseIfUnbooked(tx, seat)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		if !lifted {
+			continue
+		}
+
+		s.publishSeat(event.SeatUnblocked, seat)
+		if offer != nil {
+			s.publish(event.SeatHeld, offer, seat, false)
+		}
+	}
+	return nil
+}
+
+// RunHoldSweeper menjalankan ExpireHolds, RemindExpiringHolds dan ExpireSeatBlocks secara
+// berkala sampai ctx dibatalkan
+func (s *BookingService) RunHoldSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := s.ExpireHolds(now); err != nil {
+				log.Printf("failed to expire seat holds: %v", err)
+			}
+			if err := s.RemindExpiringHolds(now); err != nil {
+				log.Printf("failed to remind expiring seat holds: %v", err)
+			}
+			if err := s.ExpireSeatBlocks(now); err != nil {
+				log.Printf("failed to expire seat blocks: %v", err)
+			}
+		}
+	}
+}
+
+// releaseSeat menawarkan kursi yang baru kosong ke antrean waitlist pertama yang cocok.
+// Jika tidak ada yang cocok, kursi kembali available. Kursi yang sedang diblokir tetap
+// tidak dijual. seat diperbarui dengan state terbaru; hold baru (jika ada) dikembalikan.
+func (s *BookingService) releaseSeat(tx repository.Store, seat *model.Seat) (*model.Booking, error) {
+	current, err := tx.Seats().FindByID(seat.ID)
+	if err != nil {
+		return nil, err
+	}
+	seat.SeatBlock = current.SeatBlock
+	seat.Available = false
+	if seat.IsBlocked(time.Now()) {
+		return nil, tx.Seats().SetAvailable(seat.ID, false)
+	}
+
+	entries, err := tx.Waitlist().FindWaiting(seat.FlightID)
+	if err != nil {
+		return nil, err
+	}
+	// Terlalu dekat dengan keberangkatan untuk hold: kursi langsung dijual lagi
+	expiresAt, err := s.holdDeadline(tx, seat, time.Now().UTC())
+	if errors.Is(err, ErrHoldClosed) {
+		entries = nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		if !entry.Matches(seat) {
+			continue
+		}
+		// Penumpang yang tidak boleh menempati kursi ini tetap menunggu kursi berikutnya
+		if err := checkEligibility(tx, entry.UserID, seat, 0); errors.Is(err, ErrSeatNotEligible) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		reference, err := newBookingReference(tx)
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now().UTC()
+		quote, err := s.pricing.quote(tx, seat, entry.UserID, now)
+		if err != nil {
+			return nil, err
+		}
+
+		hold := &model.Booking{
+			Reference:     reference,
+			UserID:        entry.UserID,
+			SeatID:        seat.ID,
+			Status:        model.StatusPending,
+			BookedAt:      now,
+			Currency:      quote.Currency,
+			HoldExpiresAt: &expiresAt,
+		}
+		applyQuote(hold, quote)
+		if err := tx.Bookings().Create(hold); err != nil {
+			return nil, err
+		}
+
+		entry.Status = model.WaitlistOffered
+		entry.HoldBookingID = &hold.ID
+		entry.OfferedAt = &now
+		if err := tx.Waitlist().Update(entry); err != nil {
+			return nil, err
+		}
+		return hold, nil
+	}
+
+	// Update kursi menjadi available = true
+	seat.Available = true
+	return nil, tx.Seats().SetAvailable(seat.ID, true)
+}
+
+// releaseIfUnbooked melepas kursi lewat releaseSeat hanya jika tidak ada booking aktif maupun
+// assignment dari sistem sumber di kursi tersebut
+func (s *BookingService) releaseIfUnbooked(tx repository.Store, seat *model.Seat) (*model.Booking, error) {
+	if err := ensureSeatFree(tx, seat.ID); errors.Is(err, ErrSeatTaken) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return s.releaseSeat(tx, seat)
+}
+
+// ensureSeatFree menolak kursi yang punya booking aktif atau assignment hasil import seat map
+func ensureSeatFree(tx repository.Store, seatID uint) error {
+	if _, err := tx.Bookings().FindActiveBySeat(seatID); err == nil {
+		return ErrSeatTaken
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	if _, err := tx.SeatAssignments().FindBySeat(seatID); err == nil {
+		return ErrSeatTaken
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// seatConflict menerjemahkan pelanggaran idx_bookings_active_seat menjadi ErrSeatTaken. Index itu
+// menangkap request bersamaan yang sama-sama lolos ensureSeatFree sebelum salah satunya commit.
+func seatConflict(err error) error {
+	if errors.Is(err, repository.ErrDuplicate) {
+		return ErrSeatTaken
+	}
+	return err
+}
+
+// ensureSeatNotBlocked menolak booking baru pada kursi yang sedang diblokir
+func ensureSeatNotBlocked(tx repository.Store, seatID uint) error {
+	seat, err := tx.Seats().FindByID(seatID)
+	if err != nil {
+		return err
+	}
+	if seat.IsBlocked(time.Now()) {
+		return ErrSeatBlocked.WithDetail("seat %s is blocked: %s", seat.SeatCode, seat.BlockReason)
+	}
+	return nil
+}
+
+// closeWaitlistOffer menutup entry waitlist yang menghasilkan hold bookingID, jika ada
+func closeWaitlistOffer(tx repository.Store, bookingID uint, status model.WaitlistStatus) error {
+	entry, err := tx.Waitlist().FindByHoldBooking(bookingID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	entry.Status = status
+	return tx.Waitlist().Update(entry)
+}
+
+// BackfillReferences memberi record locator pada booking lama yang belum memilikinya
+func (s *BookingService) BackfillReferences() error {
+	bookings, err := s.store.Bookings().FindWithoutReference()
+	if err != nil {
+		return err
+	}
+
+	for i := range bookings {
+		err := s.store.Transaction(func(tx repository.Store) error {
+			reference, err := newBookingReference(tx)
+			if err != nil {
+				return err
+			}
+			bookings[i].Reference = reference
+			return tx.Bookings().Update(&bookings[i])
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ManageBooking mencari booking lewat record locator dan nama belakang penumpang, tanpa login
+func (s *BookingService) ManageBooking(reference, lastName string) (*BookingDetails, error) {
+	booking, err := s.store.Bookings().FindByReference(NormalizeReference(reference))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrBookingNotFound
+		}
+		return nil, err
+	}
+
+	user, err := s.store.Users().FindByIDWithDeleted(booking.UserID)
+	if err != nil {
+		return nil, err
+	}
+	// Nama belakang yang salah, atau akun yang sudah dihapus, diperlakukan sama dengan locator yang tidak ada
+	if user.DeletedAt.Valid || !strings.EqualFold(lastNameOf(user.Name), strings.TrimSpace(lastName)) {
+		return nil, ErrBookingNotFound
+	}
+
+	return s.bookingDetails(booking, user)
+}
+
+// FindByReference dipakai customer support untuk mencari booking lewat record locator
+func (s *BookingService) FindByReference(reference string) (*BookingDetails, error) {
+	booking, err := s.store.Bookings().FindByReference(NormalizeReference(reference))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrBookingNotFound
+		}
+		return nil, err
+	}
+
+	user, err := s.store.Users().FindByIDWithDeleted(booking.UserID)
+	if err != nil {
+		return nil, err
+	}
 
-    if booking.Status == model.StatusCancelled {
-        return errors.New("booking is already cancelled")
-    }
+	details, err := s.bookingDetails(booking, user)
+	if err != nil {
+		return nil, err
+	}
+	details.BookingID = booking.ID
+	details.UserID = user.ID
+	details.Email = user.Email
+	return details, nil
+}
 
-    err := s.db.Transaction(func(tx *gorm.DB) error {
-        // Update status booking menjadi cancelled
-        if err := tx.Model(&booking).Update("status", model.StatusCancelled).Error; err != nil {
-            return err
-        }
+func (s *BookingService) bookingDetails(booking *model.Booking, user *model.User) (*BookingDetails, error) {
+	flight, err := s.store.Flights().FindByID(booking.Seat.FlightID)
+	if errors.Is(err, repository.ErrNotFound) {
+		flight = nil
+	} else if err != nil {
+		return nil, err
+	}
+	return newBookingDetails(booking, user, flight), nil
+}
 
-        // Update kursi menjadi available = true
-        if err := tx.Model(&model.Seat{}).Where("id = ?", booking.SeatID).Update("available", true).Error; err != nil {
-            return err
-        }
+func newBookingDetails(booking *model.Booking, user *model.User, flight *model.Flight) *BookingDetails {
+	return &BookingDetails{
+		Reference:           booking.Reference,
+		Status:              booking.Status,
+		PassengerName:       user.Name,
+		Flight:              flight,
+		SeatCode:            booking.Seat.SeatCode,
+		Cabin:               booking.Seat.Segment,
+		OriginalPrice:       booking.OriginalPrice,
+		EntitlementRuleID:   booking.EntitlementRuleID,
+		EntitlementDiscount: booking.EntitlementDiscount,
+		Discount:            booking.Discount,
+		PromoCode:           booking.PromoCode,
+		Price:               booking.Price,
+		Currency:            booking.Currency,
+		BookedAt:            booking.BookedAt,
+		HoldExpiresAt:       booking.HoldExpiresAt,
+		CheckedInAt:         booking.CheckedInAt,
+	}
+}
 
-        return nil
-    })
+func (s *BookingService) findUserBooking(userID, bookingID uint) (*model.Booking, error) {
+	booking, err := s.store.Bookings().FindByID(bookingID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrBookingNotFound
+		}
+		return nil, err
+	}
+	if booking.UserID != userID {
+		return nil, ErrBookingNotFound
+	}
+	return booking, nil
+}
 
-    return err
+// lockBooking mengunci booking dalam transaksi tx lalu membacanya ulang beserta kursinya
+func lockBooking(tx repository.Store, bookingID uint) (*model.Booking, error) {
+	if err := tx.Bookings().Lock(bookingID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrBookingNotFound
+		}
+		return nil, err
+	}
+	booking, err := tx.Bookings().FindByID(bookingID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrBookingNotFound
+	}
+	return booking, err
 }
 
 func (s *BookingService) GetUserBookings(userID uint) ([]model.Booking, error) {
-	var bookings []model.Booking
-	err := s.db.Where("user_id = ?", userID).Preload("Seat").Find(&bookings).Error
-	return bookings, err
+	return s.store.Bookings().FindByUser(userID)
 }
 
 func (s *BookingService) GetAvailableSeats() ([]model.Seat, error) {
-    var seats []model.Seat
-    err := s.db.Where(
-        "id NOT IN (SELECT seat_id FROM bookings WHERE status = ? AND deleted_at IS NULL)",
-        model.StatusConfirmed,
-    ).Find(&seats).Error
-    return seats, err
-}
\ No newline at end of file
+	seats, err := s.store.Seats().FindUnbooked()
+	if err != nil {
+		return nil, err
+	}
+	return withoutBlocked(seats, time.Now()), nil
+}
+
+// withoutBlocked membuang kursi yang sedang diblokir dari seats
+func withoutBlocked(seats []model.Seat, now time.Time) []model.Seat {
+	sellable := seats[:0]
+	for _, seat := range seats {
+		if !seat.IsBlocked(now) {
+			sellable = append(sellable, seat)
+		}
+	}
+	return sellable
+}
+
+// publish mengirim perubahan ketersediaan kursi setelah transaksi commit.
+// Kegagalan publish hanya dicatat karena booking sudah tersimpan.
+func (s *BookingService) publish(eventType event.Type, booking *model.Booking, seat *model.Seat, available bool) {
+	err := s.bus.Publish(event.Event{
+		Type:      eventType,
+		FlightID:  seat.FlightID,
+		SeatID:    seat.ID,
+		SeatCode:  seat.SeatCode,
+		Available: available,
+		BookingID: booking.ID,
+		UserID:    booking.UserID,
+	})
+	if err != nil {
+		log.Printf("failed to publish %s for booking %d: %v", eventType, booking.ID, err)
+	}
+}
+
+// publishSeat mengirim perubahan ketersediaan kursi yang tidak terkait booking, misalnya block kursi
+func (s *BookingService) publishSeat(eventType event.Type, seat *model.Seat) {
+	err := s.bus.Publish(event.Event{
+		Type:      eventType,
+		FlightID:  seat.FlightID,
+		SeatID:    seat.ID,
+		SeatCode:  seat.SeatCode,
+		Available: seat.Available,
+	})
+	if err != nil {
+		log.Printf("failed to publish %s for seat %d: %v", eventType, seat.ID, err)
+	}
+}
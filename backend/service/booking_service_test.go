@@ -0,0 +1,368 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/event"
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/repository"
+)
+
+// testEnv adalah service booking di atas memory store dengan seat map contoh sudah diimpor
+type testEnv struct {
+	store    repository.Store
+	bus      *event.MemoryBus
+	seats    *SeatService
+	bookings *BookingService
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	env := newEmptyTestEnv(t)
+	data, err := os.ReadFile("../data/SeatMapResponse.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := env.seats.ImportSeatMapDocument(data, ""); err != nil {
+		t.Fatalf("import seat map: %v", err)
+	}
+	return env
+}
+
+// newEmptyTestEnv menyiapkan store dengan data bandara tetapi tanpa seat map
+func newEmptyTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	store := repository.NewMemoryStore()
+	bus := event.NewMemoryBus()
+	env := &testEnv{
+		store:    store,
+		bus:      bus,
+		seats:    NewSeatService(store, bus),
+		bookings: NewBookingService(store, bus, NewPricingService(store), DefaultBookingConfig()),
+	}
+	if _, err := NewAirportService(store).LoadAirports("../data/airports.json"); err != nil {
+		t.Fatalf("load airports: %v", err)
+	}
+	return env
+}
+
+func (env *testEnv) user(t *testing.T, email string) *model.User {
+	t.Helper()
+	now := time.Now()
+	user := &model.User{Email: email, Password: "x", Name: "Budi Santoso", Role: model.RoleCustomer, EmailVerifiedAt: &now}
+	if err := env.store.Users().Create(user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+// freeSeats mengembalikan kursi berbayar yang bisa dibooking
+func (env *testEnv) freeSeats(t *testing.T) []model.Seat {
+	t.Helper()
+	seats, err := env.bookings.GetAvailableSeats()
+	if err != nil {
+		t.Fatalf("available seats: %v", err)
+	}
+	var free []model.Seat
+	for _, seat := range seats {
+		if seat.Price > 0 {
+			free = append(free, seat)
+		}
+	}
+	if len(free) < 5 {
+		t.Fatalf("expected at least 5 free seats, got %d", len(free))
+	}
+	return free
+}
+
+func (env *testEnv) seat(t *testing.T, id uint) *model.Seat {
+	t.Helper()
+	seat, err := env.store.Seats().FindByID(id)
+	if err != nil {
+		t.Fatalf("find seat %d: %v", id, err)
+	}
+	return seat
+}
+
+func TestCreateBookingReservesSeat(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.user(t, "budi@example.com")
+	seat := env.freeSeats(t)[0]
+
+	booking, err := env.bookings.CreateBooking(user.ID, seat.ID, "")
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+	if booking.Status != model.StatusConfirmed || booking.Reference == "" {
+		t.Errorf("booking = %s %q, want confirmed with reference", booking.Status, booking.Reference)
+	}
+	if booking.Price != seat.Price {
+		t.Errorf("price = %v, want %v", booking.Price, seat.Price)
+	}
+	if env.seat(t, seat.ID).Available {
+		t.Error("seat is still available after booking")
+	}
+
+	other := env.user(t, "sari@example.com")
+	if _, err := env.bookings.CreateBooking(other.ID, seat.ID, ""); !errors.Is(err, ErrSeatTaken) {
+		t.Errorf("second booking err = %v, want ErrSeatTaken", err)
+	}
+	if _, err := env.bookings.HoldSeat(other.ID, seat.ID, ""); !errors.Is(err, ErrSeatTaken) {
+		t.Errorf("hold on booked seat err = %v, want ErrSeatTaken", err)
+	}
+	if _, err := env.bookings.CreateBooking(other.ID, 99999, ""); !errors.Is(err, ErrSeatNotFound) {
+		t.Errorf("unknown seat err = %v, want ErrSeatNotFound", err)
+	}
+}
+
+func TestHoldSeatThenConfirm(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.user(t, "budi@example.com")
+	seat := env.freeSeats(t)[0]
+
+	hold, err := env.bookings.HoldSeat(user.ID, seat.ID, "")
+	if err != nil {
+		t.Fatalf("HoldSeat: %v", err)
+	}
+	if hold.Status != model.StatusPending || hold.HoldExpiresAt == nil {
+		t.Fatalf("hold = %s expires %v, want pending with expiry", hold.Status, hold.HoldExpiresAt)
+	}
+	if d := time.Until(*hold.HoldExpiresAt); d <= 0 || d > DefaultBookingConfig().HoldDuration {
+		t.Errorf("hold expires in %v, want within %v", d, DefaultBookingConfig().HoldDuration)
+	}
+
+	other := env.user(t, "sari@example.com")
+	if _, err := env.bookings.ConfirmBooking(other.ID, hold.ID); !errors.Is(err, ErrBookingNotFound) {
+		t.Errorf("confirm by another user err = %v, want ErrBookingNotFound", err)
+	}
+
+	confirmed, err := env.bookings.ConfirmBooking(user.ID, hold.ID)
+	if err != nil {
+		t.Fatalf("ConfirmBooking: %v", err)
+	}
+	if confirmed.Status != model.StatusConfirmed {
+		t.Errorf("status = %s, want confirmed", confirmed.Status)
+	}
+	if _, err := env.bookings.ConfirmBooking(user.ID, hold.ID); !errors.Is(err, ErrBookingNotPending) {
+		t.Errorf("second confirm err = %v, want ErrBookingNotPending", err)
+	}
+}
+
+func TestConfirmExpiredHold(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.user(t, "budi@example.com")
+	seat := env.freeSeats(t)[0]
+
+	hold, err := env.bookings.HoldSeat(user.ID, seat.ID, "")
+	if err != nil {
+		t.Fatalf("HoldSeat: %v", err)
+	}
+	if err := env.bookings.ExpireHolds(hold.HoldExpiresAt.Add(time.Second)); err != nil {
+		t.Fatalf("ExpireHolds: %v", err)
+	}
+
+	if _, err := env.bookings.ConfirmBooking(user.ID, hold.ID); !errors.Is(err, ErrHoldExpired) {
+		t.Errorf("confirm err = %v, want ErrHoldExpired", err)
+	}
+	if !env.seat(t, seat.ID).Available {
+		t.Error("seat was not released after the hold expired")
+	}
+}
+
+func TestHoldLimit(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.user(t, "budi@example.com")
+	free := env.freeSeats(t)
+
+	limit := DefaultBookingConfig().Limits.MaxOpenHolds
+	for i := 0; i < limit; i++ {
+		if _, err := env.bookings.HoldSeat(user.ID, free[i].ID, ""); err != nil {
+			t.Fatalf("hold %d: %v", i, err)
+		}
+	}
+	if _, err := env.bookings.HoldSeat(user.ID, free[limit].ID, ""); !errors.Is(err, ErrHoldLimit) {
+		t.Errorf("hold over limit err = %v, want ErrHoldLimit", err)
+	}
+}
+
+func TestParallelHoldsRespectLimit(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.user(t, "budi@example.com")
+	free := env.freeSeats(t)
+
+	const requests = 5
+	var wg sync.WaitGroup
+	errs := make([]error, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = env.bookings.HoldSeat(user.ID, free[i].ID, "")
+		}(i)
+	}
+	wg.Wait()
+
+	held := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			held++
+		case !errors.Is(err, ErrHoldLimit):
+			t.Errorf("parallel hold err = %v, want nil or ErrHoldLimit", err)
+		}
+	}
+	if limit := DefaultBookingConfig().Limits.MaxOpenHolds; held != limit {
+		t.Errorf("%d parallel holds succeeded, want %d", held, limit)
+	}
+}
+
+func TestCancelBookingReleasesSeat(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.user(t, "budi@example.com")
+	seat := env.freeSeats(t)[0]
+
+	events, unsubscribe := env.bus.Subscribe(func(e event.Event) bool { return e.Type == event.BookingCancelled })
+	defer unsubscribe()
+
+	booking, err := env.bookings.CreateBooking(user.ID, seat.ID, "")
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+	if err := env.bookings.CancelBooking(env.user(t, "sari@example.com").ID, booking.ID); !errors.Is(err, ErrBookingNotFound) {
+		t.Errorf("cancel by another user err = %v, want ErrBookingNotFound", err)
+	}
+	if err := env.bookings.CancelBooking(user.ID, booking.ID); err != nil {
+		t.Fatalf("CancelBooking: %v", err)
+	}
+
+	cancelled, err := env.store.Bookings().FindByID(booking.ID)
+	if err != nil {
+		t.Fatalf("find booking: %v", err)
+	}
+	if cancelled.Status != model.StatusCancelled {
+		t.Errorf("status = %s, want cancelled", cancelled.Status)
+	}
+	if !env.seat(t, seat.ID).Available {
+		t.Error("seat is not available after cancellation")
+	}
+	select {
+	case e := <-events:
+		if e.BookingID != booking.ID || !e.Available {
+			t.Errorf("event = %+v, want booking %d with available seat", e, booking.ID)
+		}
+	default:
+		t.Error("booking.cancelled was not published")
+	}
+	if err := env.bookings.CancelBooking(user.ID, booking.ID); !errors.Is(err, ErrBookingAlreadyCancelled) {
+		t.Errorf("second cancel err = %v, want ErrBookingAlreadyCancelled", err)
+	}
+
+	// Kursi yang dilepas bisa dibooking lagi
+	if _, err := env.bookings.CreateBooking(env.user(t, "rina@example.com").ID, seat.ID, ""); err != nil {
+		t.Errorf("rebook released seat: %v", err)
+	}
+}
+
+func TestActiveBookingIsUniquePerSeat(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.user(t, "budi@example.com")
+	seat := env.freeSeats(t)[0]
+	if _, err := env.bookings.CreateBooking(user.ID, seat.ID, ""); err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+
+	// Insert yang lolos ensureSeatFree bersamaan ditolak oleh store, bukan hanya oleh pengecekan
+	racing := &model.Booking{UserID: user.ID, SeatID: seat.ID, Status: model.StatusPending, BookedAt: time.Now()}
+	err := env.store.Bookings().Create(racing)
+	if !errors.Is(err, repository.ErrDuplicate) {
+		t.Fatalf("second active booking err = %v, want ErrDuplicate", err)
+	}
+	if !errors.Is(seatConflict(err), ErrSeatTaken) {
+		t.Errorf("seatConflict(%v) = %v, want ErrSeatTaken", err, seatConflict(err))
+	}
+
+	cancelled := &model.Booking{UserID: user.ID, SeatID: seat.ID, Status: model.StatusCancelled, BookedAt: time.Now()}
+	if err := env.store.Bookings().Create(cancelled); err != nil {
+		t.Errorf("inactive booking on a taken seat: %v", err)
+	}
+
+	// Mengaktifkan kembali booking lama lewat UpdateStatus juga ditolak selama kursinya terisi
+	if err := env.store.Bookings().UpdateStatus(cancelled.ID, model.StatusConfirmed); !errors.Is(err, repository.ErrDuplicate) {
+		t.Errorf("reactivate booking on a taken seat err = %v, want ErrDuplicate", err)
+	}
+}
+
+func TestParallelConfirmSucceedsOnce(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.user(t, "budi@example.com")
+	hold, err := env.bookings.HoldSeat(user.ID, env.freeSeats(t)[0].ID, "")
+	if err != nil {
+		t.Fatalf("HoldSeat: %v", err)
+	}
+
+	const requests = 5
+	var wg sync.WaitGroup
+	errs := make([]error, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = env.bookings.ConfirmBooking(user.ID, hold.ID)
+		}(i)
+	}
+	wg.Wait()
+
+	confirmed := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			confirmed++
+		case !errors.Is(err, ErrBookingNotPending):
+			t.Errorf("parallel confirm err = %v, want nil or ErrBookingNotPending", err)
+		}
+	}
+	if confirmed != 1 {
+		t.Errorf("%d parallel confirms succeeded, want 1", confirmed)
+	}
+}
+
+func TestCancelRechecksStatusInTransaction(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.user(t, "budi@example.com")
+	seat := env.freeSeats(t)[0]
+	hold, err := env.bookings.HoldSeat(user.ID, seat.ID, "")
+	if err != nil {
+		t.Fatalf("HoldSeat: %v", err)
+	}
+
+	// Salinan yang dibaca sebelum sweeper berjalan masih berstatus pending
+	stale, err := env.store.Bookings().FindByID(hold.ID)
+	if err != nil {
+		t.Fatalf("find booking: %v", err)
+	}
+	if err := env.bookings.ExpireHolds(hold.HoldExpiresAt.Add(time.Second)); err != nil {
+		t.Fatalf("ExpireHolds: %v", err)
+	}
+	if err := env.bookings.cancel(stale, nil); !errors.Is(err, ErrHoldExpired) {
+		t.Errorf("cancel of a stale pending copy err = %v, want ErrHoldExpired", err)
+	}
+	if _, err := env.bookings.ConfirmBooking(user.ID, hold.ID); !errors.Is(err, ErrHoldExpired) {
+		t.Errorf("confirm after expiry err = %v, want ErrHoldExpired", err)
+	}
+
+	// Kursi yang dilepas sweeper tidak boleh ditahan lagi oleh pembatalan yang kalah balapan
+	rebooked, err := env.bookings.CreateBooking(env.user(t, "sari@example.com").ID, seat.ID, "")
+	if err != nil {
+		t.Fatalf("rebook released seat: %v", err)
+	}
+	if err := env.bookings.cancel(stale, nil); !errors.Is(err, ErrHoldExpired) {
+		t.Errorf("second stale cancel err = %v, want ErrHoldExpired", err)
+	}
+	if current, _ := env.store.Bookings().FindByID(rebooked.ID); current.Status != model.StatusConfirmed {
+		t.Errorf("new booking status = %s after stale cancel, want confirmed", current.Status)
+	}
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/repository"
+)
+
+type CheckInConfig struct {
+	// OpensBefore adalah jarak waktu sebelum keberangkatan saat check-in dibuka
+	OpensBefore time.Duration
+	// ClosesBefore adalah jarak waktu sebelum keberangkatan saat check-in ditutup
+	ClosesBefore time.Duration
+}
+
+func DefaultCheckInConfig() CheckInConfig {
+	return CheckInConfig{OpensBefore: 24 * time.Hour, ClosesBefore: time.Hour}
+}
+
+type BoardingPass struct {
+	BookingID         uint      `json:"booking_id"`
+	BookingReference  string    `json:"booking_reference"`
+	PassengerName     string    `json:"passenger_name"`
+	Flight            string    `json:"flight"`
+	Origin            string    `json:"origin"`
+	Destination       string    `json:"destination"`
+	Departure         time.Time `json:"departure"`
+	DepartureTerminal string    `json:"departure_terminal,omitempty"`
+	SeatCode          string    `json:"seat_code"`
+	Cabin             string    `json:"cabin"`
+	BoardingSequence  int       `json:"boarding_sequence"`
+	CheckedInAt       time.Time `json:"checked_in_at"`
+	// BCBP adalah data IATA Bar Coded Boarding Pass untuk dirender sebagai PDF417/QR
+	BCBP string `json:"bcbp"`
+}
+
+type CheckInService struct {
+	store  repository.Store
+	config CheckInConfig
+}
+
+func NewCheckInService(store repository.Store, config CheckInConfig) *CheckInService {
+	return &CheckInService{store: store, config: config}
+}
+
+// CheckIn melakukan check-in untuk booking confirmed milik user dan mengembalikan boarding pass.
+// Check-in ulang bersifat idempotent dan mengembalikan boarding pass yang sama.
+func (s *CheckInService) CheckIn(userID, bookingID uint) (*BoardingPass, error) {
+	booking, flight, err := s.loadBooking(userID, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if booking.CheckedInAt != nil {
+		return s.boardingPass(booking, flight)
+	}
+
+	// Jendela check-in dihitung dari instant keberangkatan (UTC), sehingga tetap tepat
+	// walaupun zona bandara berbeda dengan server atau ada pergantian DST di antaranya
+	now := time.Now().UTC()
+	if opens := flight.Departure.Add(-s.config.OpensBefore); now.Before(opens) {
+		return nil, ErrCheckInNotOpen.WithDetail("check-in opens at %s", model.InZone(opens, flight.DepartureZone).Format(time.RFC3339))
+	}
+	if !now.Before(flight.Departure.Add(-s.config.ClosesBefore)) {
+		return nil, ErrCheckInClosed
+	}
+
+	err = s.store.Transaction(func(tx repository.Store) error {
+		// Check-in bersamaan pada satu penerbangan harus antre agar nomor urutnya tidak kembar
+		if err := tx.Flights().Lock(flight.ID); err != nil {
+			return err
+		}
+		current, err := tx.Bookings().FindByID(booking.ID)
+		if err != nil {
+			return err
+		}
+		if current.CheckedInAt != nil {
+			booking = current
+			return nil
+		}
+		sequence, err := tx.Bookings().MaxBoardingSequence(flight.ID)
+		if err != nil {
+			return err
+		}
+
+		booking.CheckedInAt = &now
+		booking.BoardingSequence = sequence + 1
+		return tx.Bookings().Update(booking)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.boardingPass(booking, flight)
+}
+
+// GetBoardingPass mengembalikan boarding pass untuk booking yang sudah check-in
+func (s *CheckInService) GetBoardingPass(userID, bookingID uint) (*BoardingPass, error) {
+	booking, flight, err := s.loadBooking(userID, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if booking.CheckedInAt == nil {
+		return nil, ErrNotCheckedIn
+	}
+	return s.boardingPass(booking, flight)
+}
+
+func (s *CheckInService) loadBooking(userID, bookingID uint) (*model.Booking, *model.Flight, error) {
+	booking, err := s.store.Bookings().FindByID(bookingID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, ErrBookingNotFound
+		}
+		return nil, nil, err
+	}
+	if booking.UserID != userID {
+		return nil, nil, ErrBookingNotFound
+	}
+	if booking.Status != model.StatusConfirmed {
+		return nil, nil, ErrBookingNotConfirmed
+	}
+
+	flight, err := s.store.Flights().FindByID(booking.Seat.FlightID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil, ErrFlightNotFound
+		}
+		return nil, nil, err
+	}
+	return booking, flight, nil
+}
+
+func (s *CheckInService) boardingPass(booking *model.Booking, flight *model.Flight) (*BoardingPass, error) {
+	user, err := s.store.Users().FindByIDWithDeleted(booking.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	pass := &BoardingPass{
+		BookingID:         booking.ID,
+		BookingReference:  booking.Reference,
+		PassengerName:     user.Name,
+		Flight:            fmt.Sprintf("%s%d", flight.AirlineCode, flight.FlightNumber),
+		Origin:            flight.Origin,
+		Destination:       flight.Destination,
+		Departure:         flight.LocalDeparture(),
+		DepartureTerminal: flight.DepartureTerminal,
+		SeatCode:          booking.Seat.SeatCode,
+		Cabin:             booking.Seat.Segment,
+		BoardingSequence:  booking.BoardingSequence,
+		CheckedInAt:       *booking.CheckedInAt,
+	}
+	pass.BCBP = EncodeBCBP(BCBPData{
+		PassengerName:    user.Name,
+		BookingReference: booking.Reference,
+		From:             flight.Origin,
+		To:               flight.Destination,
+		CarrierCode:      flight.AirlineCode,
+		FlightNumber:     flight.FlightNumber,
+		Departure:        flight.LocalDeparture(),
+		Compartment:      compartmentCode(booking.Seat.Segment),
+		SeatCode:         booking.Seat.SeatCode,
+		CheckInSequence:  booking.BoardingSequence,
+	})
+	return pass, nil
+}
+
+// compartmentCode memetakan segmen kursi ke kode kompartemen IATA
+func compartmentCode(segment string) string {
+	switch segment {
+	case "FIRST":
+		return "F"
+	case "BUSINESS":
+		return "C"
+	}
+	return "Y"
+}
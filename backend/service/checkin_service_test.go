@@ -0,0 +1,104 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// departIn memindahkan keberangkatan penerbangan contoh ke d dari sekarang
+func (env *testEnv) departIn(t *testing.T, flightID uint, d time.Duration) {
+	t.Helper()
+	flight, err := env.store.Flights().FindByID(flightID)
+	if err != nil {
+		t.Fatalf("find flight: %v", err)
+	}
+	flight.Departure = time.Now().UTC().Add(d)
+	if err := env.store.Flights().Update(flight); err != nil {
+		t.Fatalf("update flight: %v", err)
+	}
+}
+
+func TestCheckInAssignsUniqueBoardingSequence(t *testing.T) {
+	env := newTestEnv(t)
+	checkIn := NewCheckInService(env.store, DefaultCheckInConfig())
+	free := env.freeSeats(t)
+	env.departIn(t, free[0].FlightID, 3*time.Hour)
+
+	const passengers = 3
+	bookings := make([]uint, passengers)
+	users := make([]uint, passengers)
+	for i := range bookings {
+		user := env.user(t, string(rune('a'+i))+"@example.com")
+		booking, err := env.bookings.CreateBooking(user.ID, free[i].ID, "")
+		if err != nil {
+			t.Fatalf("CreateBooking: %v", err)
+		}
+		users[i], bookings[i] = user.ID, booking.ID
+	}
+
+	var wg sync.WaitGroup
+	passes := make([]*BoardingPass, passengers)
+	errs := make([]error, passengers)
+	for i := range bookings {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			passes[i], errs[i] = checkIn.CheckIn(users[i], bookings[i])
+		}(i)
+	}
+	wg.Wait()
+
+	seen := map[int]bool{}
+	for i, pass := range passes {
+		if errs[i] != nil {
+			t.Fatalf("CheckIn %d: %v", i, errs[i])
+		}
+		if seen[pass.BoardingSequence] {
+			t.Errorf("boarding sequence %d issued twice", pass.BoardingSequence)
+		}
+		seen[pass.BoardingSequence] = true
+		if !strings.HasPrefix(pass.BCBP, "M1") {
+			t.Errorf("BCBP = %q, want M1 prefix", pass.BCBP)
+		}
+	}
+	for sequence := 1; sequence <= passengers; sequence++ {
+		if !seen[sequence] {
+			t.Errorf("boarding sequence %d was skipped", sequence)
+		}
+	}
+
+	// Check-in ulang mengembalikan boarding pass yang sama
+	again, err := checkIn.CheckIn(users[0], bookings[0])
+	if err != nil {
+		t.Fatalf("repeat CheckIn: %v", err)
+	}
+	if again.BoardingSequence != passes[0].BoardingSequence {
+		t.Errorf("repeat check-in sequence = %d, want %d", again.BoardingSequence, passes[0].BoardingSequence)
+	}
+}
+
+func TestCheckInWindow(t *testing.T) {
+	env := newTestEnv(t)
+	checkIn := NewCheckInService(env.store, DefaultCheckInConfig())
+	user := env.user(t, "budi@example.com")
+	seat := env.freeSeats(t)[0]
+	booking, err := env.bookings.CreateBooking(user.ID, seat.ID, "")
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+
+	env.departIn(t, seat.FlightID, 48*time.Hour)
+	if _, err := checkIn.CheckIn(user.ID, booking.ID); !errors.Is(err, ErrCheckInNotOpen) {
+		t.Errorf("early check-in err = %v, want ErrCheckInNotOpen", err)
+	}
+	env.departIn(t, seat.FlightID, 30*time.Minute)
+	if _, err := checkIn.CheckIn(user.ID, booking.ID); !errors.Is(err, ErrCheckInClosed) {
+		t.Errorf("late check-in err = %v, want ErrCheckInClosed", err)
+	}
+	if _, err := checkIn.GetBoardingPass(user.ID, booking.ID); !errors.Is(err, ErrNotCheckedIn) {
+		t.Errorf("boarding pass before check-in err = %v, want ErrNotCheckedIn", err)
+	}
+}
@@ -0,0 +1,325 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/repository"
+)
+
+// DefaultEligibilityRules adalah rule kelayakan kursi untuk maskapai yang belum punya rule set sendiri
+func DefaultEligibilityRules() []model.EligibilityRule {
+	return []model.EligibilityRule{
+		{
+			ID:             "EXIT_ROW",
+			Kind:           model.EligibilityDeny,
+			PassengerTypes: []string{model.PassengerChild, model.PassengerInfant, model.PassengerUnaccompaniedMinor},
+			Attributes:     []string{"NEXT_TO_EXIT_DOOR", "E"},
+		},
+		{
+			ID:             "NO_INFANT_SEAT",
+			Kind:           model.EligibilityDeny,
+			PassengerTypes: []string{model.PassengerInfant},
+			Attributes:     []string{"NOT_ALLOWED_FOR_INFANT"},
+		},
+		{
+			ID:             "INFANT_PER_MASK_BLOCK",
+			Kind:           model.EligibilityMaxPerGroup,
+			PassengerTypes: []string{model.PassengerInfant},
+			Max:            1,
+		},
+	}
+}
+
+type EligibilityService struct {
+	store repository.Store
+}
+
+func NewEligibilityService(store repository.Store) *EligibilityService {
+	return &EligibilityService{store: store}
+}
+
+// RuleSets mengembalikan rule set yang sudah diatur admin per maskapai
+func (s *EligibilityService) RuleSets() ([]model.EligibilityRuleSet, error) {
+	return s.store.Eligibility().FindAll()
+}
+
+// RuleSet mengembalikan rule set maskapai, atau rule bawaan (ID 0) jika belum diatur
+func (s *EligibilityService) RuleSet(airlineCode string) (*model.EligibilityRuleSet, error) {
+	return eligibilityRuleSet(s.store, normalizeAirline(airlineCode))
+}
+
+// SaveRuleSet mengganti seluruh rule kelayakan milik maskapai
+func (s *EligibilityService) SaveRuleSet(actorID uint, airlineCode string, rules []model.EligibilityRule, reason string) (*model.EligibilityRuleSet, error) {
+	airlineCode = normalizeAirline(airlineCode)
+	if airlineCode == "" {
+		return nil, ErrValidationFailed.WithDetail("airline code is required")
+	}
+	if err := validateEligibilityRules(rules); err != nil {
+		return nil, err
+	}
+
+	set := &model.EligibilityRuleSet{AirlineCode: airlineCode, Rules: rules, UpdatedBy: actorID}
+	err := s.store.Transaction(func(tx repository.Store) error {
+		if err := tx.Eligibility().Save(set); err != nil {
+			return err
+		}
+		return audit(tx, actorID, AuditEligibilityUpdate, auditTargetEligibility, set.ID, reason, model.JSONMap{
+			"airline_code": airlineCode,
+			"rules":        len(rules),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// DeleteRuleSet menghapus rule set maskapai sehingga rule bawaan kembali berlaku
+func (s *EligibilityService) DeleteRuleSet(actorID uint, airlineCode, reason string) error {
+	airlineCode = normalizeAirline(airlineCode)
+	set, err := s.store.Eligibility().FindByAirline(airlineCode)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrEligibilityRulesNotFound
+		}
+		return err
+	}
+
+	return s.store.Transaction(func(tx repository.Store) error {
+		if err := tx.Eligibility().Delete(airlineCode); err != nil {
+			return err
+		}
+		return audit(tx, actorID, AuditEligibilityDelete, auditTargetEligibility, set.ID, reason, model.JSONMap{
+			"airline_code": airlineCode,
+		})
+	})
+}
+
+func normalizeAirline(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func eligibilityRuleSet(tx repository.Store, airlineCode string) (*model.EligibilityRuleSet, error) {
+	set, err := tx.Eligibility().FindByAirline(airlineCode)
+	if errors.Is(err, repository.ErrNotFound) {
+		return &model.EligibilityRuleSet{AirlineCode: airlineCode, Rules: DefaultEligibilityRules()}, nil
+	}
+	return set, err
+}
+
+func validateEligibilityRules(rules []model.EligibilityRule) error {
+	ids := map[string]bool{}
+	for i, rule := range rules {
+		if strings.TrimSpace(rule.ID) == "" {
+			return ErrValidationFailed.WithDetail("rule %d: id is required", i+1)
+		}
+		if ids[rule.ID] {
+			return ErrValidationFailed.WithDetail("rule %q: id is used more than once", rule.ID)
+		}
+		ids[rule.ID] = true
+
+		switch rule.Kind {
+		case model.EligibilityDeny, model.EligibilityOnly:
+		case model.EligibilityMaxPerRow, model.EligibilityMaxPerGroup:
+			if rule.Max < 1 {
+				return ErrValidationFailed.WithDetail("rule %q: max must be at least 1", rule.ID)
+			}
+		default:
+			return ErrValidationFailed.WithDetail("rule %q: unknown kind %q", rule.ID, rule.Kind)
+		}
+
+		if len(rule.PassengerTypes) == 0 {
+			return ErrValidationFailed.WithDetail("rule %q: passenger_types is required", rule.ID)
+		}
+		for _, passengerType := range rule.PassengerTypes {
+			switch passengerType {
+			case model.PassengerAdult, model.PassengerChild, model.PassengerInfant, model.PassengerUnaccompaniedMinor:
+			default:
+				return ErrValidationFailed.WithDetail("rule %q: unknown passenger type %q", rule.ID, passengerType)
+			}
+		}
+		if rule.MinRow != nil && rule.MaxRow != nil && *rule.MinRow > *rule.MaxRow {
+			return ErrValidationFailed.WithDetail("rule %q: min_row is greater than max_row", rule.ID)
+		}
+	}
+	return nil
+}
+
+// checkEligibility memastikan penumpang milik userID boleh menempati seat menurut rule maskapai
+// penerbangan. excludeBookingID adalah booking milik penumpang yang sedang dipindahkan sehingga
+// tidak ikut dihitung pada rule max_*.
+func checkEligibility(tx repository.Store, userID uint, seat *model.Seat, excludeBookingID uint) error {
+	flight, err := tx.Flights().FindByID(seat.FlightID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	passenger, err := flightPassenger(tx, userID, seat.FlightID)
+	if err != nil {
+		return err
+	}
+	types := passengerTypes(passenger, flight.LocalDeparture())
+
+	set, err := eligibilityRuleSet(tx, flight.AirlineCode)
+	if err != nil {
+		return err
+	}
+
+	var occupants []occupant
+	for i := range set.Rules {
+		rule := &set.Rules[i]
+		if !slices.ContainsFunc(rule.PassengerTypes, func(t string) bool { return slices.Contains(types, t) }) {
+			continue
+		}
+
+		matches := eligibilitySeatMatches(rule, seat)
+		switch rule.Kind {
+		case model.EligibilityDeny:
+			if matches {
+				return eligibilityError(rule, seat, types)
+			}
+		case model.EligibilityOnly:
+			if !matches {
+				return eligibilityError(rule, seat, types)
+			}
+		case model.EligibilityMaxPerRow, model.EligibilityMaxPerGroup:
+			if !matches {
+				continue
+			}
+			if occupants == nil {
+				if occupants, err = flightOccupants(tx, flight, excludeBookingID); err != nil {
+					return err
+				}
+			}
+			count := 1
+			for _, o := range occupants {
+				if o.seat.RowNumber != seat.RowNumber || !eligibilitySeatMatches(rule, &o.seat) {
+					continue
+				}
+				if rule.Kind == model.EligibilityMaxPerGroup && o.seat.ColumnGroup != seat.ColumnGroup {
+					continue
+				}
+				if slices.ContainsFunc(rule.PassengerTypes, func(t string) bool { return slices.Contains(o.types, t) }) {
+					count++
+				}
+			}
+			if count > rule.Max {
+				return eligibilityError(rule, seat, types)
+			}
+		}
+	}
+	return nil
+}
+
+// occupant adalah penumpang yang sudah menempati kursi pada penerbangan
+type occupant struct {
+	seat  model.Seat
+	types []string
+}
+
+func flightOccupants(tx repository.Store, flight *model.Flight, excludeBookingID uint) ([]occupant, error) {
+	bookings, err := tx.Bookings().FindActiveOnFlight(flight.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	occupants := []occupant{}
+	for _, booking := range bookings {
+		if booking.ID == excludeBookingID {
+			continue
+		}
+		passenger, err := tx.Passengers().FindByEmail(flight.ID, booking.User.Email)
+		if errors.Is(err, repository.ErrNotFound) {
+			passenger = nil
+		} else if err != nil {
+			return nil, err
+		}
+		occupants = append(occupants, occupant{seat: booking.Seat, types: passengerTypes(passenger, flight.LocalDeparture())})
+	}
+	return occupants, nil
+}
+
+// passengerTypes mengembalikan tipe penumpang untuk evaluasi rule. Tipe dari dokumen seat map
+// dipakai jika ada; jika tidak, tipe dihitung dari umur saat keberangkatan. UMNR ditambahkan
+// untuk penumpang dengan SSR UMNR. User yang tidak tercantum di seat map dianggap dewasa.
+func passengerTypes(passenger *model.FlightPassenger, departure time.Time) []string {
+	if passenger == nil {
+		return []string{model.PassengerAdult}
+	}
+
+	passengerType := strings.ToUpper(passenger.Type)
+	if passengerType == "" && passenger.DateOfBirth != nil {
+		switch age := ageAt(*passenger.DateOfBirth, departure); {
+		case age < 2:
+			passengerType = model.PassengerInfant
+		case age < 12:
+			passengerType = model.PassengerChild
+		}
+	}
+	if passengerType == "" {
+		passengerType = model.PassengerAdult
+	}
+
+	types := []string{passengerType}
+	if slices.Contains(passenger.SpecialRequests, model.PassengerUnaccompaniedMinor) {
+		types = append(types, model.PassengerUnaccompaniedMinor)
+	}
+	return types
+}
+
+// ageAt menghitung umur dalam tahun penuh. Bulan dan tanggal dibandingkan langsung karena
+// YearDay bergeser satu hari setelah 29 Februari pada tahun kabisat.
+func ageAt(dateOfBirth, at time.Time) int {
+	age := at.Year() - dateOfBirth.Year()
+	if at.Month() < dateOfBirth.Month() || at.Month() == dateOfBirth.Month() && at.Day() < dateOfBirth.Day() {
+		age--
+	}
+	return age
+}
+
+func eligibilitySeatMatches(rule *model.EligibilityRule, seat *model.Seat) bool {
+	if len(rule.Attributes) > 0 && !slices.ContainsFunc(rule.Attributes, func(attribute string) bool {
+		return slices.Contains(seat.Characteristics, attribute) ||
+			slices.Contains(seat.Designations, attribute) ||
+			slices.Contains(seat.Limitations, attribute)
+	}) {
+		return false
+	}
+	if len(rule.Cabins) > 0 && !slices.ContainsFunc(rule.Cabins, func(cabin string) bool {
+		return strings.EqualFold(cabin, seat.Segment)
+	}) {
+		return false
+	}
+	if rule.MinRow != nil && seat.RowNumber < *rule.MinRow {
+		return false
+	}
+	if rule.MaxRow != nil && seat.RowNumber > *rule.MaxRow {
+		return false
+	}
+	return true
+}
+
+func eligibilityError(rule *model.EligibilityRule, seat *model.Seat, types []string) error {
+	message := rule.Message
+	if message == "" {
+		passenger := strings.Join(types, "/")
+		switch rule.Kind {
+		case model.EligibilityDeny:
+			message = fmt.Sprintf("%s passengers may not sit in seat %s", passenger, seat.SeatCode)
+		case model.EligibilityOnly:
+			message = fmt.Sprintf("seat %s is outside the zone allowed for %s passengers", seat.SeatCode, passenger)
+		case model.EligibilityMaxPerRow:
+			message = fmt.Sprintf("row %d already has the maximum of %d %s passengers", seat.RowNumber, rule.Max, strings.Join(rule.PassengerTypes, "/"))
+		case model.EligibilityMaxPerGroup:
+			message = fmt.Sprintf("the seat block of %s already has the maximum of %d %s passengers", seat.SeatCode, rule.Max, strings.Join(rule.PassengerTypes, "/"))
+		}
+	}
+	return ErrSeatNotEligible.WithDetail("%s: %s", rule.ID, message)
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+func TestPassengerTypes(t *testing.T) {
+	departure := time.Date(2026, 7, 1, 9, 0, 0, 0, time.UTC)
+	born := func(years, days int) *time.Time {
+		dob := departure.AddDate(-years, 0, days)
+		return &dob
+	}
+
+	tests := []struct {
+		name      string
+		passenger *model.FlightPassenger
+		want      []string
+	}{
+		{"not on the seat map", nil, []string{model.PassengerAdult}},
+		{"type from the document wins over age", &model.FlightPassenger{Type: "chd", DateOfBirth: born(30, 0)}, []string{model.PassengerChild}},
+		{"infant by age", &model.FlightPassenger{DateOfBirth: born(1, 0)}, []string{model.PassengerInfant}},
+		// 2024 adalah tahun kabisat, sehingga YearDay ulang tahun dan keberangkatan berbeda
+		{"second birthday on departure day", &model.FlightPassenger{DateOfBirth: born(2, 0)}, []string{model.PassengerChild}},
+		{"second birthday the day after departure", &model.FlightPassenger{DateOfBirth: born(2, 1)}, []string{model.PassengerInfant}},
+		{
+			"born on 29 February",
+			&model.FlightPassenger{DateOfBirth: ptr(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC))},
+			[]string{model.PassengerChild},
+		},
+		{"child by age", &model.FlightPassenger{DateOfBirth: born(11, 0)}, []string{model.PassengerChild}},
+		{"twelve is an adult", &model.FlightPassenger{DateOfBirth: born(12, 0)}, []string{model.PassengerAdult}},
+		{"no type and no birth date", &model.FlightPassenger{}, []string{model.PassengerAdult}},
+		{
+			"unaccompanied minor from SSR",
+			&model.FlightPassenger{DateOfBirth: born(9, 0), SpecialRequests: model.StringArray{"UMNR"}},
+			[]string{model.PassengerChild, model.PassengerUnaccompaniedMinor},
+		},
+	}
+	for _, tt := range tests {
+		if got := passengerTypes(tt.passenger, departure); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: passengerTypes = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// eligibilitySeats mengembalikan tiga kursi kosong pada satu baris: dua di kelompok kursi yang
+// sama dan satu di seberang lorong
+func eligibilitySeats(t *testing.T, env *testEnv) (model.Seat, model.Seat, model.Seat) {
+	t.Helper()
+	type key struct{ row, group int }
+	groups := map[key][]model.Seat{}
+	for _, seat := range env.freeSeats(t) {
+		k := key{seat.RowNumber, seat.ColumnGroup}
+		groups[k] = append(groups[k], seat)
+	}
+	for k, seats := range groups {
+		if len(seats) < 2 {
+			continue
+		}
+		for k2, across := range groups {
+			if k2.row == k.row && k2.group != k.group {
+				return seats[0], seats[1], across[0]
+			}
+		}
+	}
+	t.Fatal("fixture has no row with two free seats in one group and a free seat across the aisle")
+	return model.Seat{}, model.Seat{}, model.Seat{}
+}
+
+func TestCheckEligibility(t *testing.T) {
+	env := newTestEnv(t)
+	sameGroup, neighbour, across := eligibilitySeats(t, env)
+
+	exitRow := sameGroup
+	exitRow.Characteristics = append(slices.Clone(exitRow.Characteristics), "E")
+	noInfant := across
+	noInfant.Limitations = model.StringArray{"NOT_ALLOWED_FOR_INFANT"}
+
+	departure := time.Now().UTC().AddDate(0, 1, 0)
+	env.departIn(t, sameGroup.FlightID, time.Until(departure))
+	age := func(years int) *time.Time {
+		dob := departure.AddDate(-years, 0, -1)
+		return &dob
+	}
+
+	passengers := map[string]model.FlightPassenger{
+		"adult":  {Type: model.PassengerAdult},
+		"child":  {DateOfBirth: age(8)},
+		"infant": {Type: model.PassengerInfant},
+		"minor":  {Type: model.PassengerAdult, SpecialRequests: model.StringArray{"UMNR"}},
+	}
+	users := map[string]uint{}
+	var manifest []model.FlightPassenger
+	for name, passenger := range passengers {
+		user := env.user(t, name+"@example.com")
+		users[name] = user.ID
+		passenger.PassengerIndex = len(manifest) + 1
+		passenger.Email = user.Email
+		manifest = append(manifest, passenger)
+	}
+	// Penumpang bayi lain sudah duduk di kelompok kursi sameGroup
+	seated := env.user(t, "seated@example.com")
+	manifest = append(manifest, model.FlightPassenger{PassengerIndex: len(manifest) + 1, Email: seated.Email, Type: model.PassengerInfant})
+	if err := env.store.Passengers().ReplaceForFlight(sameGroup.FlightID, manifest); err != nil {
+		t.Fatalf("ReplaceForFlight: %v", err)
+	}
+	seatedBooking, err := env.bookings.CreateBooking(seated.ID, neighbour.ID, "")
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		passenger string
+		seat      model.Seat
+		exclude   uint
+		eligible  bool
+	}{
+		{"adult in the exit row", "adult", exitRow, 0, true},
+		{"child in the exit row", "child", exitRow, 0, false},
+		{"unaccompanied minor in the exit row", "minor", exitRow, 0, false},
+		{"infant in the exit row", "infant", exitRow, 0, false},
+		{"child in a normal seat", "child", across, 0, true},
+		{"infant in a seat not allowed for infants", "infant", noInfant, 0, false},
+		{"adult in a seat not allowed for infants", "adult", noInfant, 0, true},
+		{"second infant in the same oxygen mask block", "infant", sameGroup, 0, false},
+		{"infant across the aisle from another infant", "infant", across, 0, true},
+		{"infant moving within its own mask block", "infant", sameGroup, seatedBooking.ID, true},
+	}
+	for _, tt := range tests {
+		seat := tt.seat
+		err := checkEligibility(env.store, users[tt.passenger], &seat, tt.exclude)
+		if tt.eligible && err != nil {
+			t.Errorf("%s: err = %v, want eligible", tt.name, err)
+		}
+		if !tt.eligible && !errors.Is(err, ErrSeatNotEligible) {
+			t.Errorf("%s: err = %v, want ErrSeatNotEligible", tt.name, err)
+		}
+	}
+}
+
+func TestAirlineEligibilityRules(t *testing.T) {
+	env := newTestEnv(t)
+	eligibility := NewEligibilityService(env.store)
+	admin := env.user(t, "admin@example.com")
+	minor := env.user(t, "minor@example.com")
+	free := env.freeSeats(t)
+
+	flight, err := env.store.Flights().FindByID(free[0].FlightID)
+	if err != nil {
+		t.Fatalf("find flight: %v", err)
+	}
+	manifest := []model.FlightPassenger{{PassengerIndex: 1, Email: minor.Email, Type: model.PassengerChild, SpecialRequests: model.StringArray{"UMNR"}}}
+	if err := env.store.Passengers().ReplaceForFlight(flight.ID, manifest); err != nil {
+		t.Fatalf("ReplaceForFlight: %v", err)
+	}
+
+	var front, back *model.Seat
+	for i := range free {
+		if front == nil || free[i].RowNumber < front.RowNumber {
+			front = &free[i]
+		}
+		if back == nil || free[i].RowNumber > back.RowNumber {
+			back = &free[i]
+		}
+	}
+	maxRow := front.RowNumber
+	rules := []model.EligibilityRule{{ID: "UMNR_FRONT", Kind: model.EligibilityOnly, PassengerTypes: []string{model.PassengerUnaccompaniedMinor},
+		MaxRow: &maxRow, Message: "unaccompanied minors sit near the crew"}}
+
+	if _, err := eligibility.SaveRuleSet(admin.ID, "xx", []model.EligibilityRule{{ID: "BAD", Kind: model.EligibilityMaxPerRow, PassengerTypes: []string{model.PassengerInfant}}}, ""); !errors.Is(err, ErrValidationFailed) {
+		t.Errorf("max rule without max err = %v, want ErrValidationFailed", err)
+	}
+	if _, err := eligibility.SaveRuleSet(admin.ID, " "+flight.AirlineCode+" ", rules, "crew request"); err != nil {
+		t.Fatalf("SaveRuleSet: %v", err)
+	}
+
+	err = checkEligibility(env.store, minor.ID, back, 0)
+	if !errors.Is(err, ErrSeatNotEligible) || !strings.Contains(err.Error(), "unaccompanied minors sit near the crew") {
+		t.Errorf("back row err = %v, want ErrSeatNotEligible with the rule message", err)
+	}
+	if err := checkEligibility(env.store, minor.ID, front, 0); err != nil {
+		t.Errorf("front row err = %v, want eligible", err)
+	}
+
+	// Tanpa rule set maskapai, rule bawaan berlaku lagi
+	if err := eligibility.DeleteRuleSet(admin.ID, flight.AirlineCode, "revert"); err != nil {
+		t.Fatalf("DeleteRuleSet: %v", err)
+	}
+	if err := checkEligibility(env.store, minor.ID, back, 0); err != nil {
+		t.Errorf("back row after reverting to default rules err = %v, want eligible", err)
+	}
+}
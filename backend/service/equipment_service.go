@@ -0,0 +1,364 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/event"
+	"github.com/tiananugerah/go-BookCabin/mail"
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/repository"
+)
+
+// EquipmentService menangani pergantian pesawat (equipment swap) pada penerbangan yang sudah
+// punya booking: layout kursi diganti dan penumpang dipindahkan ke kursi pada layout baru
+type EquipmentService struct {
+	store    repository.Store
+	seats    *SeatService
+	bookings *BookingService
+	mailer   mail.Mailer
+}
+
+func NewEquipmentService(store repository.Store, seats *SeatService, bookings *BookingService, mailer mail.Mailer) *EquipmentService {
+	return &EquipmentService{store: store, seats: seats, bookings: bookings, mailer: mailer}
+}
+
+// ReseatedBooking adalah booking yang dipindahkan ke kursi lain karena pergantian pesawat
+type ReseatedBooking struct {
+	BookingID uint   `json:"booking_id"`
+	Reference string `json:"reference"`
+	UserID    uint   `json:"user_id"`
+	FromSeat  string `json:"from_seat"`
+	ToSeat    string `json:"to_seat"`
+}
+
+// EquipmentSwapResult merangkum hasil pergantian pesawat
+type EquipmentSwapResult struct {
+	FlightID          uint              `json:"flight_id"`
+	PreviousEquipment string            `json:"previous_equipment"`
+	Equipment         string            `json:"equipment"`
+	Seats             model.SeatMapDiff `json:"seats"`
+	// Kept adalah jumlah booking yang tetap di kursi dengan kode dan kabin yang sama
+	Kept             int                     `json:"kept"`
+	Reseated         []ReseatedBooking       `json:"reseated"`
+	Reaccommodations []model.Reaccommodation `json:"reaccommodations"`
+}
+
+// swapNotice adalah email untuk penumpang yang terdampak, dikirim setelah transaksi commit
+type swapNotice struct {
+	user     model.User
+	booking  model.Booking
+	fromSeat string
+	toSeat   string
+}
+
+// SwapEquipment mengganti layout kursi penerbangan dengan seat map pesawat baru. Segmen pada
+// dokumen yang cocok dengan penerbangan diimpor seperti import ulang, lalu setiap booking aktif
+// dipetakan ke layout baru:
+//  1. kursi dengan kode dan kabin yang sama, jika penumpang masih eligible
+//  2. kursi kosong di kabin yang sama dengan atribut paling mirip (window/aisle, kolom,
+//     karakteristik, jarak baris)
+//  3. jika tidak ada, booking masuk antrean reaccommodation dan tetap di kursi lamanya
+//
+// Setiap perpindahan dicatat di audit log booking dan penumpang diberi tahu lewat notifikasi.
+func (s *EquipmentService) SwapEquipment(actorID, flightID uint, data []byte, format, reason string) (*EquipmentSwapResult, error) {
+	flight, err := s.store.Flights().FindByID(flightID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrFlightNotFound
+		}
+		return nil, err
+	}
+
+	source, err := s.seats.source(data, format)
+	if err != nil {
+		return nil, err
+	}
+	segments, err := ParseSeatMap(source, data)
+	if err != nil {
+		return nil, err
+	}
+	var segment *ImportedSegment
+	for _, candidate := range segments {
+		if candidate.Flight.AirlineCode != flight.AirlineCode || candidate.Flight.FlightNumber != flight.FlightNumber {
+			continue
+		}
+		if err := localizeSegments(s.store, []*ImportedSegment{candidate}); err != nil {
+			return nil, err
+		}
+		if candidate.Flight.Departure.Equal(flight.Departure) {
+			segment = candidate
+			break
+		}
+	}
+	if segment == nil {
+		return nil, ErrSeatMapInvalid.WithDetail("seat map does not contain flight %s%d departing %s",
+			flight.AirlineCode, flight.FlightNumber, flight.LocalDeparture().Format(segmentTimeLayout))
+	}
+
+	result := &EquipmentSwapResult{
+		FlightID:          flight.ID,
+		PreviousEquipment: flight.Equipment,
+		Equipment:         segment.Flight.Equipment,
+		Reseated:          []ReseatedBooking{},
+		Reaccommodations:  []model.Reaccommodation{},
+	}
+	var notices []swapNotice
+	var released []model.Seat
+	// offers[i] adalah hold waitlist untuk released[i], jika ada
+	var offers []*model.Booking
+	err = s.store.Transaction(func(tx repository.Store) error {
+		bookings, err := tx.Bookings().FindActiveOnFlight(flight.ID)
+		if err != nil {
+			return err
+		}
+		result.Seats, err = importSegment(tx, segment)
+		if err != nil {
+			return err
+		}
+
+		seats, err := tx.Seats().FindByFlight(flight.ID)
+		if err != nil {
+			return err
+		}
+		assignments, err := tx.SeatAssignments().FindByFlight(flight.ID)
+		if err != nil {
+			return err
+		}
+		assigned := map[uint]bool{}
+		for _, assignment := range assignments {
+			assigned[assignment.SeatID] = true
+		}
+		current := map[uint]model.Seat{}
+		var free []model.Seat
+		now := time.Now()
+		for _, seat := range seats {
+			current[seat.ID] = seat
+			if seat.Available && !seat.IsBlocked(now) && !assigned[seat.ID] {
+				free = append(free, seat)
+			}
+		}
+
+		for i := range bookings {
+			booking := &bookings[i]
+			previous := booking.Seat
+			seat := current[booking.SeatID]
+
+			if seat.BlockReason != removedFromSourceReason && seat.Segment == previous.Segment {
+				err := checkEligibility(tx, booking.UserID, &seat, booking.ID)
+				if err == nil {
+					result.Kept++
+					continue
+				} else if !errors.Is(err, ErrSeatNotEligible) {
+					return err
+				}
+			}
+
+			target, err := reseatTarget(tx, booking, &previous, free)
+			if err != nil {
+				return err
+			}
+			if target == nil {
+				entry := &model.Reaccommodation{
+					FlightID:  flight.ID,
+					BookingID: booking.ID,
+					UserID:    booking.UserID,
+					SeatCode:  previous.SeatCode,
+					Cabin:     previous.Segment,
+					Reason:    fmt.Sprintf("no eligible free seat in cabin %s", previous.Segment),
+					Status:    model.ReaccommodationOpen,
+				}
+				if err := tx.Reaccommodations().Create(entry); err != nil {
+					return err
+				}
+				if err := audit(tx, actorID, AuditBookingReaccommodate, auditTargetBooking, booking.ID, reason, model.JSONMap{
+					"reference":          booking.Reference,
+					"seat_code":          previous.SeatCode,
+					"cabin":              previous.Segment,
+					"previous_equipment": result.PreviousEquipment,
+					"equipment":          result.Equipment,
+				}); err != nil {
+					return err
+				}
+				result.Reaccommodations = append(result.Reaccommodations, *entry)
+				notices = append(notices, swapNotice{user: booking.User, booking: *booking, fromSeat: previous.SeatCode})
+				continue
+			}
+
+			free = slices.DeleteFunc(free, func(seat model.Seat) bool { return seat.ID == target.ID })
+			booking.SeatID = target.ID
+			if err := tx.Bookings().Update(booking); err != nil {
+				return seatConflict(err)
+			}
+			if err := tx.Seats().SetAvailable(target.ID, false); err != nil {
+				return err
+			}
+			if err := audit(tx, actorID, AuditBookingReseat, auditTargetBooking, booking.ID, reason, model.JSONMap{
+				"reference":          booking.Reference,
+				"from_seat":          previous.SeatCode,
+				"to_seat":            target.SeatCode,
+				"previous_equipment": result.PreviousEquipment,
+				"equipment":          result.Equipment,
+			}); err != nil {
+				return err
+			}
+			released = append(released, seat)
+			booking.Seat = *target
+			result.Reseated = append(result.Reseated, ReseatedBooking{
+				BookingID: booking.ID,
+				Reference: booking.Reference,
+				UserID:    booking.UserID,
+				FromSeat:  previous.SeatCode,
+				ToSeat:    target.SeatCode,
+			})
+			notices = append(notices, swapNotice{user: booking.User, booking: *booking, fromSeat: previous.SeatCode, toSeat: target.SeatCode})
+		}
+
+		// Kursi lama baru dilepas setelah semua booking dipetakan, supaya waitlist tidak
+		// mengambil kursi yang masih dibutuhkan penumpang yang dipindahkan
+		for i := range released {
+			offer, err := s.bookings.releaseSeat(tx, &released[i])
+			if err != nil {
+				return err
+			}
+			offers = append(offers, offer)
+		}
+
+		return audit(tx, actorID, AuditEquipmentSwap, auditTargetFlight, flight.ID, reason, model.JSONMap{
+			"previous_equipment": result.PreviousEquipment,
+			"equipment":          result.Equipment,
+			"seats":              result.Seats,
+			"kept":               result.Kept,
+			"reseated":           len(result.Reseated),
+			"reaccommodations":   len(result.Reaccommodations),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.seats.publishImported(flight.ID)
+	for i := range released {
+		s.bookings.publishSeat(event.SeatReleased, &released[i])
+		if offers[i] != nil {
+			s.bookings.publish(event.SeatHeld, offers[i], &released[i], false)
+		}
+	}
+	for i := range notices {
+		if notices[i].toSeat != "" {
+			s.bookings.publish(event.BookingSeatChanged, &notices[i].booking, &notices[i].booking.Seat, false)
+		} else {
+			s.notify(flight, &notices[i])
+		}
+	}
+	return result, nil
+}
+
+// reseatTarget memilih kursi kosong di kabin yang sama dengan atribut paling mirip dengan kursi
+// lama yang juga lolos eligibility penumpang. nil berarti tidak ada kursi yang cocok.
+func reseatTarget(tx repository.Store, booking *model.Booking, previous *model.Seat, free []model.Seat) (*model.Seat, error) {
+	var candidates []model.Seat
+	for _, seat := range free {
+		if seat.Segment == previous.Segment {
+			candidates = append(candidates, seat)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := seatSimilarity(previous, &candidates[i]), seatSimilarity(previous, &candidates[j])
+		if a != b {
+			return a > b
+		}
+		return candidates[i].ID < candidates[j].ID
+	})
+
+	for i := range candidates {
+		err := checkEligibility(tx, booking.UserID, &candidates[i], booking.ID)
+		if err == nil {
+			return &candidates[i], nil
+		} else if !errors.Is(err, ErrSeatNotEligible) {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// seatSimilarity memberi skor kemiripan kursi baru dengan kursi lama; makin besar makin mirip.
+// Posisi window/aisle paling menentukan, disusul huruf kolom, karakteristik yang sama dan
+// jarak baris.
+func seatSimilarity(previous, seat *model.Seat) int {
+	score := 0
+	if previous.IsWindow == seat.IsWindow {
+		score += 100
+	}
+	if previous.IsAisle == seat.IsAisle {
+		score += 100
+	}
+	if seatColumn(previous.SeatCode) == seatColumn(seat.SeatCode) {
+		score += 30
+	}
+	for _, characteristic := range seat.Characteristics {
+		if slices.Contains(previous.Characteristics, characteristic) {
+			score += 10
+		}
+	}
+	distance := previous.RowNumber - seat.RowNumber
+	if distance < 0 {
+		distance = -distance
+	}
+	return score - distance
+}
+
+// closeReaccommodation menutup entry reaccommodation booking yang masih open, jika ada.
+// seatID diisi ketika booking dipindahkan ke kursi baru.
+func closeReaccommodation(tx repository.Store, bookingID uint, status model.ReaccommodationStatus, seatID *uint) error {
+	entry, err := tx.Reaccommodations().FindOpenByBooking(bookingID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	entry.Status = status
+	entry.ResolvedAt = &now
+	entry.ResolvedSeatID = seatID
+	return tx.Reaccommodations().Update(entry)
+}
+
+// Reaccommodations mengembalikan antrean reaccommodation, paling awal dulu
+func (s *EquipmentService) Reaccommodations(filter repository.ReaccommodationFilter, pagination Pagination) (*Page[model.Reaccommodation], error) {
+	pagination = pagination.normalize()
+	filter.Offset, filter.Limit = pagination.offset(), pagination.PageSize
+
+	entries, total, err := s.store.Reaccommodations().Search(filter)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []model.Reaccommodation{}
+	}
+	return &Page[model.Reaccommodation]{Items: entries, Total: total, Page: pagination.Page, PageSize: pagination.PageSize}, nil
+}
+
+// notify memberi tahu penumpang yang masuk antrean reaccommodation. Penumpang yang dipindah
+// kursinya diberi tahu NotificationService lewat event BookingSeatChanged.
+func (s *EquipmentService) notify(flight *model.Flight, notice *swapNotice) {
+	designator := fmt.Sprintf("%s%d", flight.AirlineCode, flight.FlightNumber)
+	departure := flight.LocalDeparture().Format("02 Jan 2006 15:04 MST")
+
+	msg := mail.Message{
+		To:      notice.user.Email,
+		Subject: fmt.Sprintf("Your seat on %s needs to be reassigned", designator),
+		Body: fmt.Sprintf("Hi %s,\n\nThe aircraft for flight %s departing %s has changed and seat %s is no longer available. Our team will assign you a new seat and contact you (booking %s).\n",
+			notice.user.Name, designator, departure, notice.fromSeat, notice.booking.Reference),
+	}
+
+	// Email yang gagal tidak membatalkan pergantian pesawat yang sudah tersimpan
+	if err := s.mailer.Send(msg); err != nil {
+		log.Printf("failed to send %q to %s: %v", msg.Subject, msg.To, err)
+	}
+}
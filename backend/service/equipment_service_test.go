@@ -0,0 +1,184 @@
+package service
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/repository"
+)
+
+// swapDocument membuat seat map pesawat pengganti dari fixture: equipment diganti, kursi pada
+// removed dihapus dan setiap kursi lain boleh diubah lewat edit
+func swapDocument(t *testing.T, equipment string, removed []string, edit func(seat *SeatMapSeat)) []byte {
+	t.Helper()
+	data, err := os.ReadFile("../data/SeatMapResponse.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc SeatMapResponse
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	for _, part := range doc.SeatsItineraryParts {
+		for i := range part.SegmentSeatMaps {
+			segment := &part.SegmentSeatMaps[i]
+			segment.Segment.Equipment = equipment
+			for _, pax := range segment.PassengerSeatMaps {
+				for _, cabin := range pax.SeatMap.Cabins {
+					for r := range cabin.SeatRows {
+						row := &cabin.SeatRows[r]
+						row.Seats = slices.DeleteFunc(row.Seats, func(seat SeatMapSeat) bool {
+							return seat.Code != "" && slices.Contains(removed, seat.Code)
+						})
+						row.SeatCodes = slices.DeleteFunc(row.SeatCodes, func(code string) bool { return slices.Contains(removed, code) })
+						for s := range row.Seats {
+							if row.Seats[s].Code != "" && edit != nil {
+								edit(&row.Seats[s])
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	data, err = json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestSwapEquipmentMapsBookingsToNewLayout(t *testing.T) {
+	env := newTestEnv(t)
+	mailer := &recordingMailer{}
+	equipment := NewEquipmentService(env.store, env.seats, env.bookings, mailer)
+	admin := env.user(t, "admin@example.com")
+	budi := env.user(t, "budi@example.com")
+	sari := env.user(t, "sari@example.com")
+
+	free := env.freeSeats(t)
+	var window *model.Seat
+	for i := range free[1:] {
+		if free[1+i].IsWindow {
+			window = &free[1+i]
+			break
+		}
+	}
+	if window == nil {
+		t.Fatal("fixture has no free window seat")
+	}
+	kept, err := env.bookings.CreateBooking(budi.ID, free[0].ID, "")
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+	displaced, err := env.bookings.CreateBooking(sari.ID, window.ID, "")
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+	flight, err := env.store.Flights().FindByID(window.FlightID)
+	if err != nil {
+		t.Fatalf("find flight: %v", err)
+	}
+
+	result, err := equipment.SwapEquipment(admin.ID, flight.ID, swapDocument(t, "789", []string{window.SeatCode}, nil), "", "aircraft change")
+	if err != nil {
+		t.Fatalf("SwapEquipment: %v", err)
+	}
+	if result.PreviousEquipment != flight.Equipment || result.Equipment != "789" {
+		t.Errorf("equipment = %s to %s, want %s to 789", result.PreviousEquipment, result.Equipment, flight.Equipment)
+	}
+	if result.Kept != 1 || len(result.Reseated) != 1 || len(result.Reaccommodations) != 0 {
+		t.Fatalf("result = kept %d reseated %d reaccommodated %d, want 1, 1, 0", result.Kept, len(result.Reseated), len(result.Reaccommodations))
+	}
+	if stored, _ := env.store.Flights().FindByID(flight.ID); stored.Equipment != "789" {
+		t.Errorf("flight equipment = %s, want 789", stored.Equipment)
+	}
+
+	// Booking yang kursinya masih ada tetap di kursi yang sama
+	if stored, _ := env.store.Bookings().FindByID(kept.ID); stored.SeatID != free[0].ID {
+		t.Errorf("kept booking moved to seat %d, want %d", stored.SeatID, free[0].ID)
+	}
+
+	// Booking yang kursinya hilang dipindah ke kursi window di kabin yang sama
+	moved := result.Reseated[0]
+	stored, err := env.store.Bookings().FindByID(displaced.ID)
+	if err != nil {
+		t.Fatalf("find booking: %v", err)
+	}
+	target := env.seat(t, stored.SeatID)
+	if moved.BookingID != displaced.ID || moved.FromSeat != window.SeatCode || moved.ToSeat != target.SeatCode {
+		t.Errorf("reseated = %+v, want booking %d from %s to %s", moved, displaced.ID, window.SeatCode, target.SeatCode)
+	}
+	if target.ID == window.ID || target.Segment != window.Segment || !target.IsWindow || target.Available {
+		t.Errorf("new seat %s = cabin %s window %v available %v, want an unavailable window seat in cabin %s",
+			target.SeatCode, target.Segment, target.IsWindow, target.Available, window.Segment)
+	}
+	if stored.Price != displaced.Price {
+		t.Errorf("reseated booking price = %v, want the original %v", stored.Price, displaced.Price)
+	}
+
+	reseats := env.auditEntries(t, AuditBookingReseat)
+	if len(reseats) != 1 || reseats[0].Details["from_seat"] != window.SeatCode || reseats[0].Details["to_seat"] != target.SeatCode {
+		t.Errorf("booking.reseat entries = %+v, want one from %s to %s", reseats, window.SeatCode, target.SeatCode)
+	}
+	if swaps := env.auditEntries(t, AuditEquipmentSwap); len(swaps) != 1 || swaps[0].TargetID != flight.ID || swaps[0].Reason != "aircraft change" {
+		t.Errorf("equipment swap entries = %+v, want one for flight %d", swaps, flight.ID)
+	}
+	if mailer.count() != 0 {
+		t.Errorf("sent %d reaccommodation emails, want none", mailer.count())
+	}
+}
+
+func TestSwapEquipmentReaccommodatesDisplacedPassengers(t *testing.T) {
+	env := newTestEnv(t)
+	mailer := &recordingMailer{}
+	equipment := NewEquipmentService(env.store, env.seats, env.bookings, mailer)
+	admin := env.user(t, "admin@example.com")
+	budi := env.user(t, "budi@example.com")
+	seat := env.freeSeats(t)[0]
+
+	booking, err := env.bookings.CreateBooking(budi.ID, seat.ID, "")
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+
+	// Pesawat pengganti tidak punya kursi lama dan tidak ada kursi kosong lain yang dijual
+	data := swapDocument(t, "320", []string{seat.SeatCode}, func(s *SeatMapSeat) { s.Available = false })
+	result, err := equipment.SwapEquipment(admin.ID, seat.FlightID, data, "", "aircraft change")
+	if err != nil {
+		t.Fatalf("SwapEquipment: %v", err)
+	}
+	if result.Kept != 0 || len(result.Reseated) != 0 || len(result.Reaccommodations) != 1 {
+		t.Fatalf("result = kept %d reseated %d reaccommodated %d, want 0, 0, 1", result.Kept, len(result.Reseated), len(result.Reaccommodations))
+	}
+
+	// Booking tetap di kursi lamanya sampai tim ops memindahkannya
+	stored, err := env.store.Bookings().FindByID(booking.ID)
+	if err != nil {
+		t.Fatalf("find booking: %v", err)
+	}
+	if stored.SeatID != seat.ID || stored.Status != model.StatusConfirmed {
+		t.Errorf("displaced booking = seat %d %s, want seat %d confirmed", stored.SeatID, stored.Status, seat.ID)
+	}
+	if old := env.seat(t, seat.ID); old.Available || old.BlockReason != removedFromSourceReason {
+		t.Errorf("old seat = available %v block %q, want blocked as removed from source", old.Available, old.BlockReason)
+	}
+
+	queue, err := equipment.Reaccommodations(repository.ReaccommodationFilter{FlightID: seat.FlightID, Status: model.ReaccommodationOpen}, Pagination{})
+	if err != nil {
+		t.Fatalf("Reaccommodations: %v", err)
+	}
+	if queue.Total != 1 || queue.Items[0].BookingID != booking.ID || queue.Items[0].SeatCode != seat.SeatCode || queue.Items[0].Cabin != seat.Segment {
+		t.Errorf("reaccommodation queue = %+v, want booking %d from %s", queue.Items, booking.ID, seat.SeatCode)
+	}
+	entries := env.auditEntries(t, AuditBookingReaccommodate)
+	if len(entries) != 1 || entries[0].TargetID != booking.ID || entries[0].Details["seat_code"] != seat.SeatCode {
+		t.Errorf("booking.reaccommodate entries = %+v, want one for booking %d", entries, booking.ID)
+	}
+	if msg := mailer.last(t, budi.Email); msg.Subject == "" {
+		t.Error("displaced passenger was not emailed")
+	}
+}
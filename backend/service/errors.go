@@ -0,0 +1,115 @@
+package service
+
+import (
+	"fmt"
+	"time"
+)
+
+// ErrorKind mengelompokkan error domain agar layer HTTP bisa memilih status code
+type ErrorKind int
+
+const (
+	KindInternal ErrorKind = iota
+	KindBadRequest
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindUnprocessable
+	KindTooManyRequests
+)
+
+// Error adalah error domain dengan kode stabil yang aman dikirim ke client.
+// Dua Error dianggap sama (errors.Is) jika kodenya sama, sehingga WithDetail
+// tetap cocok dengan sentinel asalnya.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	// RetryAfter diisi untuk error KindTooManyRequests dan dikirim sebagai header Retry-After
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithDetail mengembalikan salinan error dengan pesan yang lebih spesifik
+func (e *Error) WithDetail(format string, args ...interface{}) *Error {
+	return &Error{Kind: e.Kind, Code: e.Code, Message: fmt.Sprintf(format, args...), RetryAfter: e.RetryAfter}
+}
+
+// WithRetryAfter mengembalikan salinan error dengan waktu tunggu sebelum client boleh mencoba lagi
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	return &Error{Kind: e.Kind, Code: e.Code, Message: e.Message, RetryAfter: d}
+}
+
+var (
+	ErrInvalidRequest           = &Error{Kind: KindBadRequest, Code: "invalid_request", Message: "invalid request"}
+	ErrValidationFailed         = &Error{Kind: KindUnprocessable, Code: "validation_failed", Message: "request validation failed"}
+	ErrInvalidCredentials       = &Error{Kind: KindUnauthorized, Code: "invalid_credentials", Message: "invalid email or password"}
+	ErrInvalidToken             = &Error{Kind: KindUnauthorized, Code: "invalid_token", Message: "invalid token"}
+	ErrAuthorizationRequired    = &Error{Kind: KindUnauthorized, Code: "authorization_required", Message: "authorization header is required"}
+	ErrForbidden                = &Error{Kind: KindForbidden, Code: "forbidden", Message: "you do not have access to this resource"}
+	ErrWeakPassword             = &Error{Kind: KindUnprocessable, Code: "weak_password", Message: "password does not meet the password policy"}
+	ErrEmailNotVerified         = &Error{Kind: KindForbidden, Code: "email_not_verified", Message: "email address has not been verified"}
+	ErrInvalidEmailToken        = &Error{Kind: KindBadRequest, Code: "invalid_email_token", Message: "token is invalid, expired or already used"}
+	ErrIncorrectPassword        = &Error{Kind: KindForbidden, Code: "incorrect_password", Message: "current password is incorrect"}
+	ErrAccountLocked            = &Error{Kind: KindTooManyRequests, Code: "account_locked", Message: "too many failed login attempts, try again later"}
+	ErrRateLimited              = &Error{Kind: KindTooManyRequests, Code: "rate_limited", Message: "too many requests, try again later"}
+	ErrEmailExists              = &Error{Kind: KindConflict, Code: "email_exists", Message: "email is already registered"}
+	ErrSeatNotFound             = &Error{Kind: KindNotFound, Code: "seat_not_found", Message: "seat not found"}
+	ErrSeatTaken                = &Error{Kind: KindConflict, Code: "seat_taken", Message: "seat is already booked"}
+	ErrFlightBookingLimit       = &Error{Kind: KindConflict, Code: "flight_booking_limit", Message: "booking limit for this flight reached"}
+	ErrHoldLimit                = &Error{Kind: KindConflict, Code: "hold_limit", Message: "too many open seat holds"}
+	ErrHoldCooldown             = &Error{Kind: KindTooManyRequests, Code: "hold_cooldown", Message: "too many expired holds, try again later"}
+	ErrUserNotFound             = &Error{Kind: KindNotFound, Code: "user_not_found", Message: "user not found"}
+	ErrExemptionRequiresAgent   = &Error{Kind: KindUnprocessable, Code: "exemption_requires_agent", Message: "booking limit exemptions can only be granted to agent accounts"}
+	ErrBookingNotFound          = &Error{Kind: KindNotFound, Code: "booking_not_found", Message: "booking not found"}
+	ErrBookingAlreadyCancelled  = &Error{Kind: KindConflict, Code: "booking_already_cancelled", Message: "booking is already cancelled"}
+	ErrBookingNotActive         = &Error{Kind: KindConflict, Code: "booking_not_active", Message: "booking is no longer active"}
+	ErrSeatBlocked              = &Error{Kind: KindConflict, Code: "seat_blocked", Message: "seat is blocked and not for sale"}
+	ErrSeatNotBlocked           = &Error{Kind: KindConflict, Code: "seat_not_blocked", Message: "seat is not blocked"}
+	ErrPriceRulesNotFound       = &Error{Kind: KindNotFound, Code: "price_rules_not_found", Message: "price rule set not found"}
+	ErrPriceRulesConflict       = &Error{Kind: KindConflict, Code: "price_rules_conflict", Message: "another price rule version was created at the same time, please retry"}
+	ErrSeatNotEligible          = &Error{Kind: KindUnprocessable, Code: "seat_not_eligible", Message: "passenger is not eligible for this seat"}
+	ErrEligibilityRulesNotFound = &Error{Kind: KindNotFound, Code: "eligibility_rules_not_found", Message: "eligibility rule set not found"}
+	ErrPromoCodeNotFound        = &Error{Kind: KindNotFound, Code: "promo_code_not_found", Message: "promo code not found"}
+	ErrPromoCodeExists          = &Error{Kind: KindConflict, Code: "promo_code_exists", Message: "promo code already exists"}
+	ErrPromoCodeInvalid         = &Error{Kind: KindUnprocessable, Code: "promo_code_invalid", Message: "promo code is invalid"}
+	ErrPromoCodeExpired         = &Error{Kind: KindUnprocessable, Code: "promo_code_expired", Message: "promo code has expired"}
+	ErrPromoNotApplicable       = &Error{Kind: KindUnprocessable, Code: "promo_code_not_applicable", Message: "promo code does not apply to this seat"}
+	ErrPromoExhausted           = &Error{Kind: KindConflict, Code: "promo_code_exhausted", Message: "promo code has reached its usage limit"}
+	ErrPromoAlreadyUsed         = &Error{Kind: KindConflict, Code: "promo_code_already_used", Message: "promo code has already been used"}
+	ErrCannotModifySelf         = &Error{Kind: KindUnprocessable, Code: "cannot_modify_self", Message: "admins cannot remove their own admin access"}
+	ErrBookingNotPending        = &Error{Kind: KindConflict, Code: "booking_not_pending", Message: "booking is not an open hold"}
+	ErrHoldExpired              = &Error{Kind: KindConflict, Code: "hold_expired", Message: "seat hold has expired"}
+	ErrBookingNotConfirmed      = &Error{Kind: KindConflict, Code: "booking_not_confirmed", Message: "booking is not confirmed"}
+	ErrCheckInNotOpen           = &Error{Kind: KindConflict, Code: "check_in_not_open", Message: "check-in is not open yet"}
+	ErrCheckInClosed            = &Error{Kind: KindConflict, Code: "check_in_closed", Message: "check-in is closed"}
+	ErrNotCheckedIn             = &Error{Kind: KindConflict, Code: "not_checked_in", Message: "booking has not been checked in"}
+	ErrWaitlistEntryNotFound    = &Error{Kind: KindNotFound, Code: "waitlist_entry_not_found", Message: "waitlist entry not found"}
+	ErrWaitlistEntryClosed      = &Error{Kind: KindConflict, Code: "waitlist_entry_closed", Message: "waitlist entry is no longer waiting"}
+	ErrAlreadyWaitlisted        = &Error{Kind: KindConflict, Code: "already_waitlisted", Message: "already on the waitlist for these criteria"}
+	ErrMatchingSeatAvailable    = &Error{Kind: KindConflict, Code: "matching_seat_available", Message: "a matching seat is still available"}
+	ErrFlightNotFound           = &Error{Kind: KindNotFound, Code: "flight_not_found", Message: "flight not found"}
+	ErrSeatMapInvalid           = &Error{Kind: KindUnprocessable, Code: "seat_map_invalid", Message: "seat map document is invalid"}
+	ErrSeatMapFormat            = &Error{Kind: KindUnprocessable, Code: "seat_map_format_unsupported", Message: "seat map format is not supported"}
+	ErrFlightExists             = &Error{Kind: KindConflict, Code: "flight_exists", Message: "a flight with this designator and departure already exists"}
+	ErrFlightHasBookings        = &Error{Kind: KindConflict, Code: "flight_has_bookings", Message: "flight has bookings and cannot be deleted"}
+	ErrSeatMapSyncDisabled      = &Error{Kind: KindConflict, Code: "seat_map_sync_disabled", Message: "no seat map upstream is configured"}
+	ErrSeatMapSyncRunning       = &Error{Kind: KindConflict, Code: "seat_map_sync_running", Message: "a seat map sync is already running"}
+	ErrAirportNotFound          = &Error{Kind: KindNotFound, Code: "airport_not_found", Message: "airport not found"}
+	ErrUnknownAirport           = &Error{Kind: KindUnprocessable, Code: "unknown_airport", Message: "airport is not in the airport table"}
+	ErrCancellationClosed       = &Error{Kind: KindConflict, Code: "cancellation_closed", Message: "booking can no longer be cancelled this close to departure"}
+	ErrHoldClosed               = &Error{Kind: KindConflict, Code: "hold_closed", Message: "seats can no longer be held this close to departure"}
+	ErrNotificationNotFound     = &Error{Kind: KindNotFound, Code: "notification_not_found", Message: "notification not found"}
+	ErrWebhookNotFound          = &Error{Kind: KindNotFound, Code: "webhook_not_found", Message: "webhook endpoint not found"}
+	ErrWebhookDeliveryNotFound  = &Error{Kind: KindNotFound, Code: "webhook_delivery_not_found", Message: "webhook delivery not found"}
+	ErrWebhookDeliveryPending   = &Error{Kind: KindConflict, Code: "webhook_delivery_pending", Message: "webhook delivery is still being retried"}
+)
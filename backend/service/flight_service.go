@@ -0,0 +1,340 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/repository"
+)
+
+var (
+	airlineCodePattern = regexp.MustCompile(`^[A-Z0-9]{2}$`)
+	airportCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// cabinOrder mengurutkan kabin dari kelas tertinggi pada hasil pencarian
+var cabinOrder = map[string]int{"FIRST": 0, "BUSINESS": 1, "PREMIUM_ECONOMY": 2, "ECONOMY": 3}
+
+type FlightService struct {
+	store repository.Store
+}
+
+func NewFlightService(store repository.Store) *FlightService {
+	return &FlightService{store: store}
+}
+
+// FlightSearch adalah kriteria pencarian jadwal; Date adalah tanggal berangkat lokal di bandara
+// asal (jam diabaikan). Tanpa Date, hanya penerbangan yang belum berangkat yang dikembalikan.
+type FlightSearch struct {
+	Origin      string
+	Destination string
+	Date        *time.Time
+	AirlineCode string
+}
+
+// CabinAvailability adalah jumlah kursi satu kabin dan kursi yang masih bisa dijual
+type CabinAvailability struct {
+	Cabin     string `json:"cabin"`
+	Total     int    `json:"total"`
+	Available int    `json:"available"`
+}
+
+// FlightSummary adalah penerbangan pada jadwal beserta jam lokal dan sisa kursi per kabin
+type FlightSummary struct {
+	model.Flight
+	DepartureLocal time.Time           `json:"departure_local"`
+	ArrivalLocal   time.Time           `json:"arrival_local"`
+	Cabins         []CabinAvailability `json:"cabins"`
+	SeatsAvailable int                 `json:"seats_available"`
+}
+
+// FlightInput adalah data penerbangan dari admin, dipakai untuk membuat maupun mengganti jadwal
+type FlightInput struct {
+	AirlineCode       string
+	FlightNumber      int
+	Origin            string
+	Destination       string
+	Departure         time.Time
+	Arrival           time.Time
+	DepartureTerminal string
+	ArrivalTerminal   string
+	Equipment         string
+}
+
+// SearchFlights mencari penerbangan pada jadwal, urut waktu berangkat
+func (s *FlightService) SearchFlights(search FlightSearch, pagination Pagination) (*Page[FlightSummary], error) {
+	pagination = pagination.normalize()
+	filter := repository.FlightFilter{
+		AirlineCode: normalizeAirline(search.AirlineCode),
+		Origin:      strings.ToUpper(strings.TrimSpace(search.Origin)),
+		Destination: strings.ToUpper(strings.TrimSpace(search.Destination)),
+		Offset:      pagination.offset(),
+		Limit:       pagination.PageSize,
+	}
+	if search.Date != nil {
+		// Hari dihitung di zona bandara asal; tanpa origin (atau bandara tidak dikenal) memakai UTC.
+		// AddDate menjaga panjang hari 23/25 jam saat pergantian DST.
+		loc := time.UTC
+		if filter.Origin != "" {
+			if _, originLoc, err := airportLocation(s.store, filter.Origin); err == nil {
+				loc = originLoc
+			} else if !errors.Is(err, ErrUnknownAirport) {
+				return nil, err
+			}
+		}
+		from := time.Date(search.Date.Year(), search.Date.Month(), search.Date.Day(), 0, 0, 0, 0, loc)
+		to := from.AddDate(0, 0, 1)
+		filter.DepartureFrom, filter.DepartureTo = &from, &to
+	} else {
+		now := time.Now()
+		filter.DepartureFrom = &now
+	}
+
+	flights, total, err := s.store.Flights().Search(filter)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := s.summaries(flights)
+	if err != nil {
+		return nil, err
+	}
+	return &Page[FlightSummary]{Items: summaries, Total: total, Page: pagination.Page, PageSize: pagination.PageSize}, nil
+}
+
+// GetFlight mengembalikan satu penerbangan beserta sisa kursinya
+func (s *FlightService) GetFlight(id uint) (*FlightSummary, error) {
+	flight, err := s.findFlight(id)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := s.summaries([]model.Flight{*flight})
+	if err != nil {
+		return nil, err
+	}
+	return &summaries[0], nil
+}
+
+// CreateFlight menambahkan penerbangan ke jadwal. Zona waktu diambil dari tabel bandara dan
+// jam disimpan sebagai UTC. Seat map yang diimpor kemudian dengan designator dan waktu
+// berangkat yang sama akan terpasang ke penerbangan ini.
+func (s *FlightService) CreateFlight(actorID uint, input FlightInput) (*model.Flight, error) {
+	flight := &model.Flight{}
+	if err := applyFlightInput(flight, input); err != nil {
+		return nil, err
+	}
+
+	err := s.store.Transaction(func(tx repository.Store) error {
+		if err := localizeFlight(tx, flight, false, false); err != nil {
+			return err
+		}
+		if err := tx.Flights().Create(flight); err != nil {
+			if errors.Is(err, repository.ErrDuplicate) {
+				return ErrFlightExists
+			}
+			return err
+		}
+		return audit(tx, actorID, AuditFlightCreate, auditTargetFlight, flight.ID, "", flightDetails(flight))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return flight, nil
+}
+
+// UpdateFlight mengganti data jadwal penerbangan. Kursi dan booking tidak berubah.
+func (s *FlightService) UpdateFlight(actorID, id uint, input FlightInput, reason string) (*model.Flight, error) {
+	flight, err := s.findFlight(id)
+	if err != nil {
+		return nil, err
+	}
+	previous := flightDetails(flight)
+	if err := applyFlightInput(flight, input); err != nil {
+		return nil, err
+	}
+
+	err = s.store.Transaction(func(tx repository.Store) error {
+		if err := localizeFlight(tx, flight, false, false); err != nil {
+			return err
+		}
+		if err := tx.Flights().Update(flight); err != nil {
+			if errors.Is(err, repository.ErrDuplicate) {
+				return ErrFlightExists
+			}
+			return err
+		}
+		details := flightDetails(flight)
+		details["previous"] = previous
+		return audit(tx, actorID, AuditFlightUpdate, auditTargetFlight, flight.ID, reason, details)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return flight, nil
+}
+
+// DeleteFlight menghapus penerbangan beserta inventory kursinya. Penerbangan yang pernah
+// dibooking tidak bisa dihapus agar riwayat booking tetap utuh. Antrean waitlist penerbangan
+// dibatalkan dalam transaksi yang sama.
+func (s *FlightService) DeleteFlight(actorID, id uint, reason string) error {
+	flight, err := s.findFlight(id)
+	if err != nil {
+		return err
+	}
+
+	return s.store.Transaction(func(tx repository.Store) error {
+		bookings, err := tx.Bookings().FindByFlight(flight.ID)
+		if err != nil {
+			return err
+		}
+		if len(bookings) > 0 {
+			return ErrFlightHasBookings.WithDetail("flight has %d bookings and cannot be deleted", len(bookings))
+		}
+
+		seats, err := tx.Seats().FindByFlight(flight.ID)
+		if err != nil {
+			return err
+		}
+		seatIDs := make([]uint, 0, len(seats))
+		for _, seat := range seats {
+			seatIDs = append(seatIDs, seat.ID)
+		}
+		if err := tx.SeatAssignments().ReplaceForFlight(flight.ID, nil); err != nil {
+			return err
+		}
+		if err := tx.SeatOffers().ReplaceForFlight(flight.ID, nil); err != nil {
+			return err
+		}
+		if err := tx.Passengers().ReplaceForFlight(flight.ID, nil); err != nil {
+			return err
+		}
+		// Entry offered selalu punya hold booking sehingga sudah ditolak di atas; tinggal yang waiting
+		waiting, err := tx.Waitlist().FindWaiting(flight.ID)
+		if err != nil {
+			return err
+		}
+		for i := range waiting {
+			waiting[i].Status = model.WaitlistCancelled
+			if err := tx.Waitlist().Update(&waiting[i]); err != nil {
+				return err
+			}
+		}
+		if err := tx.Seats().Delete(seatIDs); err != nil {
+			return err
+		}
+		if err := tx.Flights().Delete(flight.ID); err != nil {
+			return err
+		}
+		details := flightDetails(flight)
+		details["waitlist_cancelled"] = len(waiting)
+		return audit(tx, actorID, AuditFlightDelete, auditTargetFlight, flight.ID, reason, details)
+	})
+}
+
+func (s *FlightService) findFlight(id uint) (*model.Flight, error) {
+	flight, err := s.store.Flights().FindByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrFlightNotFound
+		}
+		return nil, err
+	}
+	return flight, nil
+}
+
+// summaries melengkapi penerbangan dengan jumlah kursi per kabin dalam satu query
+func (s *FlightService) summaries(flights []model.Flight) ([]FlightSummary, error) {
+	ids := make([]uint, 0, len(flights))
+	for _, flight := range flights {
+		ids = append(ids, flight.ID)
+	}
+	counts, err := s.store.Seats().CountByCabin(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	cabins := map[uint][]CabinAvailability{}
+	for _, count := range counts {
+		cabins[count.FlightID] = append(cabins[count.FlightID], CabinAvailability{
+			Cabin:     count.Cabin,
+			Total:     count.Total,
+			Available: count.Available,
+		})
+	}
+
+	summaries := make([]FlightSummary, 0, len(flights))
+	for _, flight := range flights {
+		summary := FlightSummary{
+			Flight:         flight,
+			DepartureLocal: flight.LocalDeparture(),
+			ArrivalLocal:   flight.LocalArrival(),
+			Cabins:         cabins[flight.ID],
+		}
+		if summary.Cabins == nil {
+			summary.Cabins = []CabinAvailability{}
+		}
+		sort.SliceStable(summary.Cabins, func(i, j int) bool {
+			return cabinRank(summary.Cabins[i].Cabin) < cabinRank(summary.Cabins[j].Cabin)
+		})
+		for _, cabin := range summary.Cabins {
+			summary.SeatsAvailable += cabin.Available
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+func cabinRank(cabin string) int {
+	if rank, ok := cabinOrder[cabin]; ok {
+		return rank
+	}
+	return len(cabinOrder)
+}
+
+// applyFlightInput memvalidasi input admin lalu menyalinnya ke flight
+func applyFlightInput(flight *model.Flight, input FlightInput) error {
+	airlineCode := normalizeAirline(input.AirlineCode)
+	origin := strings.ToUpper(strings.TrimSpace(input.Origin))
+	destination := strings.ToUpper(strings.TrimSpace(input.Destination))
+
+	switch {
+	case !airlineCodePattern.MatchString(airlineCode):
+		return ErrValidationFailed.WithDetail("airline_code must be a 2-character IATA airline code")
+	case input.FlightNumber < 1 || input.FlightNumber > 9999:
+		return ErrValidationFailed.WithDetail("flight_number must be between 1 and 9999")
+	case !airportCodePattern.MatchString(origin) || !airportCodePattern.MatchString(destination):
+		return ErrValidationFailed.WithDetail("origin and destination must be 3-letter IATA airport codes")
+	case origin == destination:
+		return ErrValidationFailed.WithDetail("origin and destination must be different")
+	case !input.Arrival.After(input.Departure):
+		return ErrValidationFailed.WithDetail("arrival must be after departure")
+	}
+
+	flight.AirlineCode = airlineCode
+	flight.FlightNumber = input.FlightNumber
+	flight.Origin = origin
+	flight.Destination = destination
+	flight.Departure = input.Departure
+	flight.Arrival = input.Arrival
+	flight.DepartureTerminal = strings.TrimSpace(input.DepartureTerminal)
+	flight.ArrivalTerminal = strings.TrimSpace(input.ArrivalTerminal)
+	flight.Equipment = strings.TrimSpace(input.Equipment)
+	return nil
+}
+
+func flightDetails(flight *model.Flight) model.JSONMap {
+	return model.JSONMap{
+		"airline_code":   flight.AirlineCode,
+		"flight_number":  flight.FlightNumber,
+		"origin":         flight.Origin,
+		"destination":    flight.Destination,
+		"departure":      flight.Departure,
+		"arrival":        flight.Arrival,
+		"departure_zone": flight.DepartureZone,
+		"arrival_zone":   flight.ArrivalZone,
+		"equipment":      flight.Equipment,
+	}
+}
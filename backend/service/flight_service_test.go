@@ -0,0 +1,72 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/repository"
+)
+
+// waitingEntry menulis entry waiting langsung karena JoinWaitlist menolak kursi yang masih kosong
+func (env *testEnv) waitingEntry(t *testing.T, userID uint, seat model.Seat) *model.WaitlistEntry {
+	t.Helper()
+	entry := &model.WaitlistEntry{UserID: userID, FlightID: seat.FlightID, SeatID: &seat.ID, Status: model.WaitlistWaiting}
+	if err := env.store.Waitlist().Create(entry); err != nil {
+		t.Fatalf("create waitlist entry: %v", err)
+	}
+	return entry
+}
+
+func TestDeleteFlightCancelsWaitlist(t *testing.T) {
+	env := newTestEnv(t)
+	flights := NewFlightService(env.store)
+	admin := env.user(t, "admin@example.com")
+	seat := env.freeSeats(t)[0]
+	entry := env.waitingEntry(t, env.user(t, "sari@example.com").ID, seat)
+
+	if err := flights.DeleteFlight(admin.ID, seat.FlightID, "cancelled route"); err != nil {
+		t.Fatalf("DeleteFlight: %v", err)
+	}
+
+	current, err := env.store.Waitlist().FindByID(entry.ID)
+	if err != nil {
+		t.Fatalf("find waitlist entry: %v", err)
+	}
+	if current.Status != model.WaitlistCancelled {
+		t.Errorf("entry after delete = %s, want cancelled", current.Status)
+	}
+	if waiting, _ := env.store.Waitlist().FindWaiting(seat.FlightID); len(waiting) != 0 {
+		t.Errorf("%d entries still waiting for the deleted flight", len(waiting))
+	}
+
+	logs, _, err := env.store.Audit().Search(repository.AuditFilter{Action: AuditFlightDelete})
+	if err != nil {
+		t.Fatalf("search audit log: %v", err)
+	}
+	if len(logs) != 1 || logs[0].Details["waitlist_cancelled"] != 1 {
+		t.Errorf("audit entries = %+v, want one flight.delete with waitlist_cancelled 1", logs)
+	}
+}
+
+func TestRefusedDeleteFlightKeepsWaitlist(t *testing.T) {
+	env := newTestEnv(t)
+	flights := NewFlightService(env.store)
+	admin := env.user(t, "admin@example.com")
+	free := env.freeSeats(t)
+	entry := env.waitingEntry(t, env.user(t, "sari@example.com").ID, free[0])
+	if _, err := env.bookings.CreateBooking(env.user(t, "budi@example.com").ID, free[1].ID, ""); err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+
+	if err := flights.DeleteFlight(admin.ID, free[0].FlightID, "cancelled route"); !errors.Is(err, ErrFlightHasBookings) {
+		t.Fatalf("delete booked flight err = %v, want ErrFlightHasBookings", err)
+	}
+	current, err := env.store.Waitlist().FindByID(entry.ID)
+	if err != nil {
+		t.Fatalf("find waitlist entry: %v", err)
+	}
+	if current.Status != model.WaitlistWaiting {
+		t.Errorf("entry after refused delete = %s, want waiting", current.Status)
+	}
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/mail"
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+// NotificationChannel adalah jalur pengiriman notifikasi yang dipakai NotificationService
+type NotificationChannel interface {
+	// Name adalah nama channel yang disimpan pada notifikasi, misalnya model.ChannelEmail
+	Name() string
+	// Recipient mengembalikan tujuan pengiriman untuk user; false berarti user tidak
+	// dikirimi lewat channel ini
+	Recipient(user *model.User) (string, bool)
+	// Deliver mengirim satu notifikasi; error berarti pengiriman dicoba lagi kemudian
+	Deliver(ctx context.Context, notification *model.Notification) error
+}
+
+// EmailChannel mengirim notifikasi sebagai email lewat Mailer
+type EmailChannel struct {
+	mailer mail.Mailer
+}
+
+func NewEmailChannel(mailer mail.Mailer) *EmailChannel {
+	return &EmailChannel{mailer: mailer}
+}
+
+func (c *EmailChannel) Name() string {
+	return model.ChannelEmail
+}
+
+func (c *EmailChannel) Recipient(user *model.User) (string, bool) {
+	return user.Email, user.Email != ""
+}
+
+func (c *EmailChannel) Deliver(_ context.Context, notification *model.Notification) error {
+	return c.mailer.Send(mail.Message{
+		To:      notification.Recipient,
+		Subject: notification.Subject,
+		Body:    notification.Body,
+	})
+}
+
+// WebhookChannel mengirim setiap notifikasi sebagai POST JSON ke satu URL. Request ditandatangani
+// dengan Secret memakai header yang sama dengan webhook partner (lihat SignWebhook). Respons
+// selain 2xx dianggap gagal.
+type WebhookChannel struct {
+	URL    string
+	Secret string
+	Client *http.Client
+}
+
+// webhookNotification adalah body JSON yang dikirim WebhookChannel
+type webhookNotification struct {
+	ID        uint      `json:"id"`
+	Event     string    `json:"event"`
+	UserID    uint      `json:"user_id"`
+	BookingID uint      `json:"booking_id,omitempty"`
+	Locale    string    `json:"locale"`
+	Subject   string    `json:"subject"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (c *WebhookChannel) Name() string {
+	return model.ChannelWebhook
+}
+
+func (c *WebhookChannel) Recipient(*model.User) (string, bool) {
+	return c.URL, c.URL != ""
+}
+
+func (c *WebhookChannel) Deliver(ctx context.Context, notification *model.Notification) error {
+	client := c.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	payload, err := json.Marshal(webhookNotification{
+		ID:        notification.ID,
+		Event:     notification.Event,
+		UserID:    notification.UserID,
+		BookingID: notification.BookingID,
+		Locale:    notification.Locale,
+		Subject:   notification.Subject,
+		Body:      notification.Body,
+		CreatedAt: notification.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, notification.Recipient, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, notification.Event)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(uint64(notification.ID), 10))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(c.Secret, timestamp, payload))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s returned %s", notification.Recipient, resp.Status)
+	}
+	return nil
+}
+
+// InAppChannel menyimpan notifikasi ke inbox user; notifikasi sudah tersimpan sehingga
+// Deliver hanya menandainya terkirim
+type InAppChannel struct{}
+
+func NewInAppChannel() *InAppChannel {
+	return &InAppChannel{}
+}
+
+func (c *InAppChannel) Name() string {
+	return model.ChannelInApp
+}
+
+func (c *InAppChannel) Recipient(*model.User) (string, bool) {
+	return "", true
+}
+
+func (c *InAppChannel) Deliver(context.Context, *model.Notification) error {
+	return nil
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+func TestWebhookChannelSignsRequests(t *testing.T) {
+	var received webhookNotification
+	var signed bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(WebhookTimestampHeader), 10, 64)
+		signed = r.Header.Get(WebhookSignatureHeader) == SignWebhook("s3cret", timestamp, body)
+		if !signed || r.Header.Get(WebhookEventHeader) != "booking.created" || r.Header.Get(WebhookDeliveryHeader) != "7" {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		if err := json.Unmarshal(body, &received); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	channel := &WebhookChannel{URL: server.URL, Secret: "s3cret"}
+	if recipient, ok := channel.Recipient(&model.User{}); !ok || recipient != server.URL {
+		t.Fatalf("recipient = %q %v, want the configured URL", recipient, ok)
+	}
+	notification := &model.Notification{ID: 7, Event: "booking.created", UserID: 3, BookingID: 9, Subject: "Booking created", Recipient: server.URL}
+	if err := channel.Deliver(context.Background(), notification); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	if !signed || received.ID != 7 || received.BookingID != 9 || received.Subject != "Booking created" {
+		t.Errorf("received = %+v signed %v, want notification 7 with a valid signature", received, signed)
+	}
+
+	// Secret yang salah ditolak penerima sehingga pengiriman dicoba lagi kemudian
+	wrong := &WebhookChannel{URL: server.URL, Secret: "other"}
+	if err := wrong.Deliver(context.Background(), notification); err == nil {
+		t.Error("delivery signed with the wrong secret succeeded")
+	}
+}
@@ -0,0 +1,305 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/event"
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/repository"
+)
+
+// notificationEvents adalah event booking yang dikirim ke user sebagai notifikasi
+var notificationEvents = map[event.Type]bool{
+	event.BookingCreated:     true,
+	event.BookingConfirmed:   true,
+	event.BookingCancelled:   true,
+	event.BookingSeatChanged: true,
+	event.HoldExpiring:       true,
+}
+
+// notificationBatchSize adalah jumlah notifikasi jatuh tempo yang diproses per putaran
+const notificationBatchSize = 100
+
+type NotificationConfig struct {
+	// RetryBase adalah jeda setelah percobaan pertama gagal; jeda berikutnya berlipat dua
+	// sampai RetryMax
+	RetryBase time.Duration
+	RetryMax  time.Duration
+	// MaxAttempts adalah jumlah percobaan sebelum notifikasi ditandai gagal
+	MaxAttempts int
+	// DeliveryTimeout membatasi satu percobaan kirim
+	DeliveryTimeout time.Duration
+}
+
+func DefaultNotificationConfig() NotificationConfig {
+	return NotificationConfig{
+		RetryBase:       30 * time.Second,
+		RetryMax:        time.Hour,
+		MaxAttempts:     6,
+		DeliveryTimeout: 30 * time.Second,
+	}
+}
+
+// NotificationService mengubah event booking menjadi notifikasi per channel lalu mengirimnya.
+// Notifikasi disimpan dulu sebelum dikirim sehingga pengiriman yang gagal dicoba lagi dengan
+// backoff, juga setelah restart.
+type NotificationService struct {
+	store    repository.Store
+	channels map[string]NotificationChannel
+	config   NotificationConfig
+	// wake membangunkan RunDelivery saat ada notifikasi baru
+	wake chan struct{}
+}
+
+func NewNotificationService(store repository.Store, config NotificationConfig, channels ...NotificationChannel) *NotificationService {
+	s := &NotificationService{
+		store:    store,
+		channels: map[string]NotificationChannel{},
+		config:   config,
+		wake:     make(chan struct{}, 1),
+	}
+	for _, channel := range channels {
+		s.channels[channel.Name()] = channel
+	}
+	return s
+}
+
+// Run berlangganan event booking dan membuat notifikasinya sampai ctx dibatalkan. Dengan
+// PostgresBus setiap replika menerima event yang sama; DedupKey memastikan hanya satu
+// notifikasi yang tersimpan per channel.
+func (s *NotificationService) Run(ctx context.Context, bus event.Bus) {
+	events, unsubscribe := bus.Subscribe(func(e event.Event) bool {
+		return notificationEvents[e.Type] && e.BookingID != 0
+	})
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := s.Notify(e); err != nil {
+				log.Printf("failed to create notifications for %s on booking %d: %v", e.Type, e.BookingID, err)
+			}
+		}
+	}
+}
+
+// Notify membuat satu notifikasi per channel untuk event booking. Event untuk booking yang
+// sudah tidak ada diabaikan.
+func (s *NotificationService) Notify(e event.Event) error {
+	booking, err := s.store.Bookings().FindByID(e.BookingID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	user, err := s.store.Users().FindByID(booking.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	data, err := s.notificationData(e, booking, user)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	created := false
+	for _, channel := range s.channels {
+		recipient, ok := channel.Recipient(user)
+		if !ok {
+			continue
+		}
+		notification := &model.Notification{
+			UserID:        user.ID,
+			BookingID:     booking.ID,
+			Event:         string(e.Type),
+			Channel:       channel.Name(),
+			DedupKey:      fmt.Sprintf("%s:%d:%d", e.Type, e.BookingID, e.OccurredAt.UnixNano()),
+			Locale:        user.Locale,
+			Recipient:     recipient,
+			Status:        model.NotificationPending,
+			NextAttemptAt: &now,
+		}
+		if ok, err := renderNotification(notification, data); err != nil || !ok {
+			return err
+		}
+		if err := s.store.Notifications().Create(notification); err != nil {
+			if errors.Is(err, repository.ErrDuplicate) {
+				continue
+			}
+			return err
+		}
+		created = true
+	}
+
+	if created {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// notificationData menyusun data template. Kursi diambil dari event karena booking bisa sudah
+// dipindahkan lagi saat event diproses.
+func (s *NotificationService) notificationData(e event.Event, booking *model.Booking, user *model.User) (notificationData, error) {
+	data := notificationData{
+		Name:      user.Name,
+		Reference: booking.Reference,
+		Seat:      e.SeatCode,
+		Price:     fmt.Sprintf("%.2f %s", booking.Price, booking.Currency),
+	}
+	if data.Seat == "" {
+		data.Seat = booking.Seat.SeatCode
+	}
+
+	flight, err := s.store.Flights().FindByID(booking.Seat.FlightID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return data, nil
+	} else if err != nil {
+		return data, err
+	}
+	data.Flight = fmt.Sprintf("%s%d", flight.AirlineCode, flight.FlightNumber)
+	data.Origin = flight.Origin
+	data.Destination = flight.Destination
+	data.Departure = flight.LocalDeparture().Format("02 Jan 2006 15:04 MST")
+	if booking.HoldExpiresAt != nil {
+		data.HoldExpiresAt = model.InZone(*booking.HoldExpiresAt, flight.DepartureZone).Format("15:04 MST")
+	}
+	return data, nil
+}
+
+// DeliverDue mengirim notifikasi yang jadwal percobaannya sudah tiba. Notifikasi diklaim dulu
+// agar replika lain tidak mengirimnya bersamaan.
+func (s *NotificationService) DeliverDue(ctx context.Context, now time.Time) error {
+	due, err := s.store.Notifications().FindDue(now, notificationBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for i := range due {
+		notification := &due[i]
+		// Klaim berlaku sedikit lebih lama dari timeout kirim; jika proses mati di tengah
+		// pengiriman, notifikasi dicoba lagi setelah klaim habis
+		claimed, err := s.store.Notifications().Claim(notification, time.Now().Add(2*s.config.DeliveryTimeout))
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+		s.deliver(ctx, notification)
+		if err := s.store.Notifications().UpdateDelivery(notification); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliver menjalankan satu percobaan kirim dan mencatat hasilnya pada notification
+func (s *NotificationService) deliver(ctx context.Context, notification *model.Notification) {
+	var err error
+	if channel, ok := s.channels[notification.Channel]; ok {
+		deliverCtx, cancel := context.WithTimeout(ctx, s.config.DeliveryTimeout)
+		err = channel.Deliver(deliverCtx, notification)
+		cancel()
+	} else {
+		err = fmt.Errorf("channel %q is not configured", notification.Channel)
+	}
+
+	now := time.Now()
+	if err == nil {
+		notification.Status = model.NotificationSent
+		notification.SentAt = &now
+		notification.NextAttemptAt = nil
+		notification.LastError = ""
+		return
+	}
+
+	notification.LastError = err.Error()
+	if notification.Attempts >= s.config.MaxAttempts {
+		notification.Status = model.NotificationFailed
+		notification.NextAttemptAt = nil
+		log.Printf("notification %d via %s failed after %d attempts: %v", notification.ID, notification.Channel, notification.Attempts, err)
+		return
+	}
+	next := now.Add(retryDelay(s.config.RetryBase, s.config.RetryMax, notification.Attempts))
+	notification.NextAttemptAt = &next
+}
+
+// retryDelay adalah jeda sebelum percobaan berikutnya setelah attempts kali gagal: base yang
+// berlipat dua setiap kegagalan, paling lama limit
+func retryDelay(base, limit time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 1; i < attempts && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
+// RunDelivery mengirim notifikasi jatuh tempo setiap interval, dan segera setelah Notify
+// membuat notifikasi baru, sampai ctx dibatalkan
+func (s *NotificationService) RunDelivery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+		if err := s.DeliverDue(ctx, time.Now()); err != nil {
+			log.Printf("failed to deliver notifications: %v", err)
+		}
+	}
+}
+
+// Inbox mengembalikan notifikasi in-app milik user, terbaru dulu
+func (s *NotificationService) Inbox(userID uint, unreadOnly bool, pagination Pagination) (*Page[model.Notification], error) {
+	pagination = pagination.normalize()
+
+	notifications, total, err := s.store.Notifications().Search(repository.NotificationFilter{
+		UserID:     userID,
+		Channel:    model.ChannelInApp,
+		UnreadOnly: unreadOnly,
+		Offset:     pagination.offset(),
+		Limit:      pagination.PageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if notifications == nil {
+		notifications = []model.Notification{}
+	}
+	return &Page[model.Notification]{Items: notifications, Total: total, Page: pagination.Page, PageSize: pagination.PageSize}, nil
+}
+
+// MarkRead menandai satu notifikasi inbox sebagai dibaca
+func (s *NotificationService) MarkRead(userID, id uint) error {
+	marked, err := s.store.Notifications().MarkRead(userID, id, time.Now())
+	if err != nil {
+		return err
+	}
+	if marked == 0 {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+// MarkAllRead menandai semua notifikasi inbox user sebagai dibaca dan mengembalikan jumlahnya
+func (s *NotificationService) MarkAllRead(userID uint) (int64, error) {
+	return s.store.Notifications().MarkRead(userID, 0, time.Now())
+}
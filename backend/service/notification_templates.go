@@ -0,0 +1,129 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/tiananugerah/go-BookCabin/event"
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+// DefaultLocale dipakai untuk user tanpa locale atau dengan locale yang belum punya template
+const DefaultLocale = "en"
+
+// notificationTemplate adalah subject dan body satu event dalam satu bahasa
+type notificationTemplate struct {
+	Subject string
+	Body    string
+}
+
+// notificationTemplates adalah template notifikasi per locale lalu per tipe event. Locale baru
+// cukup ditambahkan di sini; event yang tidak punya template pada locale user memakai DefaultLocale.
+var notificationTemplates = map[string]map[event.Type]notificationTemplate{
+	"en": {
+		event.BookingCreated: {
+			Subject: "Booking {{.Reference}} confirmed: {{.Flight}} seat {{.Seat}}",
+			Body:    "Hi {{.Name}},\n\nYour booking {{.Reference}} is confirmed. Flight {{.Flight}} from {{.Origin}} to {{.Destination}} departs {{.Departure}}. Seat: {{.Seat}}. Price: {{.Price}}.\n",
+		},
+		event.BookingConfirmed: {
+			Subject: "Booking {{.Reference}} confirmed: {{.Flight}} seat {{.Seat}}",
+			Body:    "Hi {{.Name}},\n\nYour seat hold {{.Reference}} has been confirmed. Flight {{.Flight}} from {{.Origin}} to {{.Destination}} departs {{.Departure}}. Seat: {{.Seat}}. Price: {{.Price}}.\n",
+		},
+		event.BookingCancelled: {
+			Subject: "Booking {{.Reference}} cancelled",
+			Body:    "Hi {{.Name}},\n\nYour booking {{.Reference}} for seat {{.Seat}} on flight {{.Flight}} departing {{.Departure}} has been cancelled.\n",
+		},
+		event.BookingSeatChanged: {
+			Subject: "Your seat on {{.Flight}} has changed",
+			Body:    "Hi {{.Name}},\n\nYour seat on flight {{.Flight}} departing {{.Departure}} is now {{.Seat}} (booking {{.Reference}}).\n",
+		},
+		event.HoldExpiring: {
+			Subject: "Your hold on seat {{.Seat}} expires soon",
+			Body:    "Hi {{.Name}},\n\nYour hold {{.Reference}} on seat {{.Seat}} for flight {{.Flight}} departing {{.Departure}} expires at {{.HoldExpiresAt}}. Confirm it before then to keep the seat.\n",
+		},
+	},
+	"id": {
+		event.BookingCreated: {
+			Subject: "Booking {{.Reference}} terkonfirmasi: {{.Flight}} kursi {{.Seat}}",
+			Body:    "Halo {{.Name}},\n\nBooking {{.Reference}} Anda terkonfirmasi. Penerbangan {{.Flight}} dari {{.Origin}} ke {{.Destination}} berangkat {{.Departure}}. Kursi: {{.Seat}}. Harga: {{.Price}}.\n",
+		},
+		event.BookingConfirmed: {
+			Subject: "Booking {{.Reference}} terkonfirmasi: {{.Flight}} kursi {{.Seat}}",
+			Body:    "Halo {{.Name}},\n\nHold kursi {{.Reference}} Anda sudah dikonfirmasi. Penerbangan {{.Flight}} dari {{.Origin}} ke {{.Destination}} berangkat {{.Departure}}. Kursi: {{.Seat}}. Harga: {{.Price}}.\n",
+		},
+		event.BookingCancelled: {
+			Subject: "Booking {{.Reference}} dibatalkan",
+			Body:    "Halo {{.Name}},\n\nBooking {{.Reference}} untuk kursi {{.Seat}} pada penerbangan {{.Flight}} yang berangkat {{.Departure}} telah dibatalkan.\n",
+		},
+		event.BookingSeatChanged: {
+			Subject: "Kursi Anda pada {{.Flight}} berubah",
+			Body:    "Halo {{.Name}},\n\nKursi Anda pada penerbangan {{.Flight}} yang berangkat {{.Departure}} sekarang {{.Seat}} (booking {{.Reference}}).\n",
+		},
+		event.HoldExpiring: {
+			Subject: "Hold kursi {{.Seat}} segera berakhir",
+			Body:    "Halo {{.Name}},\n\nHold {{.Reference}} untuk kursi {{.Seat}} pada penerbangan {{.Flight}} yang berangkat {{.Departure}} berakhir pukul {{.HoldExpiresAt}}. Konfirmasi sebelum itu agar kursi tetap milik Anda.\n",
+		},
+	},
+}
+
+// parsedTemplates adalah notificationTemplates yang sudah diparse, dengan key "locale/event"
+var parsedTemplates = parseNotificationTemplates()
+
+// notificationData adalah data yang tersedia untuk template notifikasi. Jam ditulis dalam
+// zona bandara keberangkatan.
+type notificationData struct {
+	Name          string
+	Reference     string
+	Flight        string
+	Origin        string
+	Destination   string
+	Departure     string
+	Seat          string
+	Price         string
+	HoldExpiresAt string
+}
+
+func parseNotificationTemplates() map[string][2]*template.Template {
+	parsed := map[string][2]*template.Template{}
+	for locale, templates := range notificationTemplates {
+		for eventType, t := range templates {
+			key := locale + "/" + string(eventType)
+			parsed[key] = [2]*template.Template{
+				template.Must(template.New(key + "/subject").Parse(t.Subject)),
+				template.Must(template.New(key + "/body").Parse(t.Body)),
+			}
+		}
+	}
+	return parsed
+}
+
+// SupportedLocale melaporkan apakah ada template notifikasi untuk locale
+func SupportedLocale(locale string) bool {
+	_, ok := notificationTemplates[locale]
+	return ok
+}
+
+// renderNotification mengisi Subject dan Body dari template notification.Event dalam
+// notification.Locale; locale tanpa template diganti DefaultLocale. False berarti event tidak
+// punya template.
+func renderNotification(notification *model.Notification, data notificationData) (bool, error) {
+	templates, ok := parsedTemplates[notification.Locale+"/"+notification.Event]
+	if !ok {
+		notification.Locale = DefaultLocale
+		if templates, ok = parsedTemplates[DefaultLocale+"/"+notification.Event]; !ok {
+			return false, nil
+		}
+	}
+
+	var subject, body strings.Builder
+	if err := templates[0].Execute(&subject, data); err != nil {
+		return false, fmt.Errorf("render %s: %w", templates[0].Name(), err)
+	}
+	if err := templates[1].Execute(&body, data); err != nil {
+		return false, fmt.Errorf("render %s: %w", templates[1].Name(), err)
+	}
+	notification.Subject = subject.String()
+	notification.Body = body.String()
+	return true, nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// bcrypt hanya memakai 72 byte pertama password
+const maxPasswordBytes = 72
+
+// PasswordPolicy menentukan syarat kekuatan password saat register dan reset password
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+}
+
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{MinLength: 8, RequireUpper: true, RequireLower: true, RequireDigit: true}
+}
+
+// Validate mengembalikan ErrWeakPassword berisi semua syarat yang belum terpenuhi
+func (p PasswordPolicy) Validate(password string) error {
+	var problems []string
+	if len([]rune(password)) < p.MinLength {
+		problems = append(problems, fmt.Sprintf("at least %d characters", p.MinLength))
+	}
+	if len(password) > maxPasswordBytes {
+		problems = append(problems, fmt.Sprintf("at most %d bytes", maxPasswordBytes))
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+	if p.RequireUpper && !upper {
+		problems = append(problems, "an uppercase letter")
+	}
+	if p.RequireLower && !lower {
+		problems = append(problems, "a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		problems = append(problems, "a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		problems = append(problems, "a symbol")
+	}
+
+	if len(problems) > 0 {
+		return ErrWeakPassword.WithDetail("password must contain %s", strings.Join(problems, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,560 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/repository"
+)
+
+// PriceQuote adalah harga kursi untuk satu penumpang pada satu waktu. Harga ini yang disimpan
+// ke Booking.Price saat kursi di-hold atau dibooking.
+type PriceQuote struct {
+	SeatID       uint      `json:"seat_id"`
+	SeatCode     string    `json:"seat_code"`
+	BasePrice    float64   `json:"base_price"`
+	Price        float64   `json:"price"`
+	Currency     string    `json:"currency"`
+	RuleVersion  int       `json:"rule_version,omitempty"`
+	AppliedRules []string  `json:"applied_rules"`
+	LoadFactor   float64   `json:"load_factor"`
+	QuotedAt     time.Time `json:"quoted_at"`
+	// Entitlement diisi jika biaya kursi dibebaskan atau dipotong untuk penumpang; Price sudah
+	// dikurangi potongannya
+	Entitlement *Entitlement `json:"entitlement,omitempty"`
+}
+
+const (
+	// EntitlementSourceSeatMap dipakai untuk penanda feeWaived/entitled/freeOfCharge dari seat map sumber
+	EntitlementSourceSeatMap = "seat_map"
+	// EntitlementSourceRule dipakai untuk rule entitlement pada versi rule harga yang aktif
+	EntitlementSourceRule = "rule"
+)
+
+// Entitlement adalah pembebasan atau potongan biaya kursi untuk penumpang
+type Entitlement struct {
+	RuleID   string  `json:"rule_id,omitempty"`
+	Source   string  `json:"source"`
+	Discount float64 `json:"discount"`
+}
+
+// PricePreviewInput adalah rule yang ingin dibandingkan dengan versi aktif: Version yang sudah
+// tersimpan, atau Rules yang belum disimpan. At mengganti waktu perhitungan (default sekarang).
+type PricePreviewInput struct {
+	Version int
+	Rules   []model.PriceRule
+	At      *time.Time
+}
+
+type SeatPricePreview struct {
+	SeatID       uint     `json:"seat_id"`
+	SeatCode     string   `json:"seat_code"`
+	Cabin        string   `json:"cabin"`
+	Sold         bool     `json:"sold"`
+	BasePrice    float64  `json:"base_price"`
+	CurrentPrice float64  `json:"current_price"`
+	NewPrice     float64  `json:"new_price"`
+	Change       float64  `json:"change"`
+	CurrentRules []string `json:"current_rules"`
+	NewRules     []string `json:"new_rules"`
+}
+
+// PricePreview membandingkan harga seat map satu penerbangan di bawah rule aktif dan rule usulan
+type PricePreview struct {
+	FlightID         uint               `json:"flight_id"`
+	CurrentVersion   int                `json:"current_version,omitempty"`
+	ProposedVersion  int                `json:"proposed_version,omitempty"`
+	At               time.Time          `json:"at"`
+	LoadFactor       float64            `json:"load_factor"`
+	HoursToDeparture float64            `json:"hours_to_departure"`
+	ChangedSeats     int                `json:"changed_seats"`
+	UnsoldCurrent    float64            `json:"unsold_current_total"`
+	UnsoldNew        float64            `json:"unsold_new_total"`
+	Seats            []SeatPricePreview `json:"seats"`
+}
+
+type PricingService struct {
+	store repository.Store
+}
+
+func NewPricingService(store repository.Store) *PricingService {
+	return &PricingService{store: store}
+}
+
+// pricingContext adalah kondisi penerbangan yang dipakai rule harga
+type pricingContext struct {
+	loadFactor float64
+	// hoursToDeparture nil jika jadwal penerbangan tidak diketahui; rule berbasis waktu tidak berlaku
+	hoursToDeparture *float64
+}
+
+// QuoteSeat menghitung harga kursi saat ini untuk user dengan rule yang aktif
+func (s *PricingService) QuoteSeat(userID, seatID uint) (*PriceQuote, error) {
+	seat, err := s.store.Seats().FindByID(seatID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrSeatNotFound
+		}
+		return nil, err
+	}
+	return s.quote(s.store, seat, userID, time.Now())
+}
+
+func (s *PricingService) quote(tx repository.Store, seat *model.Seat, userID uint, now time.Time) (*PriceQuote, error) {
+	set, err := activeRuleSet(tx)
+	if err != nil {
+		return nil, err
+	}
+	pc, err := flightPricingContext(tx, seat.FlightID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	passenger, err := flightPassenger(tx, userID, seat.FlightID)
+	if err != nil {
+		return nil, err
+	}
+	seat, err = passengerSeat(tx, seat, passenger)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := &PriceQuote{
+		SeatID:     seat.ID,
+		SeatCode:   seat.SeatCode,
+		BasePrice:  seat.Price,
+		Currency:   seat.Currency,
+		LoadFactor: pc.loadFactor,
+		QuotedAt:   now,
+	}
+	var rules []model.PriceRule
+	var entitlements []model.EntitlementRule
+	if set != nil {
+		quote.RuleVersion = set.Version
+		rules = set.Rules
+		entitlements = set.Entitlements
+	}
+	quote.Price, quote.AppliedRules = applyPriceRules(rules, seat, pc)
+
+	if entitlement := bestEntitlement(entitlements, seat, passenger, quote.Price); entitlement != nil {
+		quote.Entitlement = entitlement
+		quote.Price = roundPrice(quote.Price - entitlement.Discount)
+	}
+	return quote, nil
+}
+
+// flightPassenger mengembalikan data penumpang milik user pada penerbangan, atau nil jika
+// email user tidak tercantum pada seat map penerbangan tersebut
+func flightPassenger(tx repository.Store, userID, flightID uint) (*model.FlightPassenger, error) {
+	user, err := tx.Users().FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	passenger, err := tx.Passengers().FindByEmail(flightID, user.Email)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	return passenger, err
+}
+
+// passengerSeat mengembalikan salinan kursi dengan harga dan entitlement dari offer penumpang
+// pada seat map sumber. Tanpa offer untuk penumpang tersebut, kursi dikembalikan apa adanya.
+func passengerSeat(tx repository.Store, seat *model.Seat, passenger *model.FlightPassenger) (*model.Seat, error) {
+	if passenger == nil {
+		return seat, nil
+	}
+	offer, err := tx.SeatOffers().FindBySeatAndPassenger(seat.ID, passenger.PassengerIndex)
+	if errors.Is(err, repository.ErrNotFound) {
+		return seat, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	priced := *seat
+	priced.Price = offer.Price
+	priced.Currency = offer.Currency
+	priced.SeatEntitlement = offer.SeatEntitlement
+	return &priced, nil
+}
+
+// bestEntitlement memilih entitlement dengan potongan terbesar untuk penumpang pada kursi.
+// Penanda fee waiver dan entitlement dari seat map hanya berlaku untuk penumpang yang tercantum
+// pada seat map; freeOfCharge berlaku untuk semua penumpang.
+func bestEntitlement(rules []model.EntitlementRule, seat *model.Seat, passenger *model.FlightPassenger, price float64) *Entitlement {
+	if price <= 0 {
+		return nil
+	}
+
+	var candidates []Entitlement
+	if seat.FreeOfCharge {
+		candidates = append(candidates, Entitlement{Source: EntitlementSourceSeatMap, Discount: price})
+	}
+	if passenger != nil {
+		if seat.FeeWaived {
+			candidates = append(candidates, Entitlement{RuleID: seat.FeeWaivedRuleID, Source: EntitlementSourceSeatMap, Discount: price})
+		}
+		if seat.Entitled && seat.EntitledRuleID != "" {
+			candidates = append(candidates, Entitlement{RuleID: seat.EntitledRuleID, Source: EntitlementSourceSeatMap, Discount: price})
+		}
+		for i := range rules {
+			rule := &rules[i]
+			if !entitlementRuleMatches(rule, seat, passenger) {
+				continue
+			}
+			percent := rule.Percent
+			if percent == 0 {
+				percent = 100
+			}
+			candidates = append(candidates, Entitlement{
+				RuleID:   rule.ID,
+				Source:   EntitlementSourceRule,
+				Discount: roundPrice(price * percent / 100),
+			})
+		}
+	}
+
+	var best *Entitlement
+	for i := range candidates {
+		if candidates[i].Discount > 0 && (best == nil || candidates[i].Discount > best.Discount) {
+			best = &candidates[i]
+		}
+	}
+	return best
+}
+
+func entitlementRuleMatches(rule *model.EntitlementRule, seat *model.Seat, passenger *model.FlightPassenger) bool {
+	if rule.MinTier != nil && passenger.FrequentFlyerTier < *rule.MinTier {
+		return false
+	}
+	if len(rule.BookingClasses) > 0 && !slices.ContainsFunc(rule.BookingClasses, func(class string) bool {
+		return strings.EqualFold(class, passenger.BookingClass)
+	}) {
+		return false
+	}
+	if len(rule.FareBases) > 0 && !slices.ContainsFunc(rule.FareBases, func(prefix string) bool {
+		return strings.HasPrefix(strings.ToUpper(passenger.FareBasis), strings.ToUpper(prefix))
+	}) {
+		return false
+	}
+	return seatMatches(rule.Cabins, rule.Attributes, seat)
+}
+
+func (s *PricingService) RuleSets() ([]model.PriceRuleSet, error) {
+	return s.store.PriceRules().FindAll()
+}
+
+func (s *PricingService) ActiveRuleSet() (*model.PriceRuleSet, error) {
+	set, err := activeRuleSet(s.store)
+	if err != nil {
+		return nil, err
+	}
+	if set == nil {
+		return nil, ErrPriceRulesNotFound
+	}
+	return set, nil
+}
+
+// CreateRuleSet menyimpan rule harga dan rule entitlement sebagai versi baru yang belum aktif
+func (s *PricingService) CreateRuleSet(actorID uint, rules []model.PriceRule, entitlements []model.EntitlementRule, note string) (*model.PriceRuleSet, error) {
+	if err := validatePriceRules(rules); err != nil {
+		return nil, err
+	}
+	if err := validateEntitlementRules(entitlements); err != nil {
+		return nil, err
+	}
+
+	set := &model.PriceRuleSet{CreatedBy: actorID, Note: note, Rules: rules, Entitlements: entitlements}
+	err := s.store.Transaction(func(tx repository.Store) error {
+		latest, err := tx.PriceRules().LatestVersion()
+		if err != nil {
+			return err
+		}
+		set.Version = latest + 1
+		if err := tx.PriceRules().Create(set); err != nil {
+			if errors.Is(err, repository.ErrDuplicate) {
+				return ErrPriceRulesConflict
+			}
+			return err
+		}
+		return audit(tx, actorID, AuditPriceRulesCreate, auditTargetPriceRules, set.ID, note, model.JSONMap{
+			"version":      set.Version,
+			"rules":        len(rules),
+			"entitlements": len(entitlements),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// ActivateRuleSet memberlakukan versi untuk quote berikutnya. Booking yang sudah ada tetap
+// memakai harga yang terkunci saat booking dibuat.
+func (s *PricingService) ActivateRuleSet(actorID uint, version int, reason string) (*model.PriceRuleSet, error) {
+	var set *model.PriceRuleSet
+	err := s.store.Transaction(func(tx repository.Store) error {
+		previous, err := activeRuleSet(tx)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.PriceRules().Activate(version, now); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return ErrPriceRulesNotFound
+			}
+			return err
+		}
+		set, err = tx.PriceRules().FindByVersion(version)
+		if err != nil {
+			return err
+		}
+
+		details := model.JSONMap{"version": version}
+		if previous != nil {
+			details["previous_version"] = previous.Version
+		}
+		return audit(tx, actorID, AuditPriceRulesActivate, auditTargetPriceRules, set.ID, reason, details)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// Preview menghitung ulang seat map penerbangan dengan rule usulan tanpa mengubah apa pun
+func (s *PricingService) Preview(flightID uint, input PricePreviewInput) (*PricePreview, error) {
+	if _, err := s.store.Flights().FindByID(flightID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrFlightNotFound
+		}
+		return nil, err
+	}
+
+	preview := &PricePreview{FlightID: flightID, At: time.Now(), Seats: []SeatPricePreview{}}
+	if input.At != nil {
+		preview.At = *input.At
+	}
+
+	proposed := input.Rules
+	switch {
+	case input.Version != 0:
+		set, err := s.store.PriceRules().FindByVersion(input.Version)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, ErrPriceRulesNotFound
+			}
+			return nil, err
+		}
+		preview.ProposedVersion = set.Version
+		proposed = set.Rules
+	case proposed != nil:
+		if err := validatePriceRules(proposed); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrValidationFailed.WithDetail("either version or rules is required")
+	}
+
+	var current []model.PriceRule
+	active, err := activeRuleSet(s.store)
+	if err != nil {
+		return nil, err
+	}
+	if active != nil {
+		preview.CurrentVersion = active.Version
+		current = active.Rules
+	}
+
+	pc, err := flightPricingContext(s.store, flightID, preview.At)
+	if err != nil {
+		return nil, err
+	}
+	preview.LoadFactor = pc.loadFactor
+	if pc.hoursToDeparture != nil {
+		preview.HoursToDeparture = *pc.hoursToDeparture
+	}
+
+	seats, err := s.store.Seats().FindByFlight(flightID)
+	if err != nil {
+		return nil, err
+	}
+	unbooked, err := s.store.Seats().FindUnbookedByFlight(flightID)
+	if err != nil {
+		return nil, err
+	}
+	unsold := map[uint]bool{}
+	for _, seat := range unbooked {
+		unsold[seat.ID] = true
+	}
+
+	for i := range seats {
+		seat := &seats[i]
+		currentPrice, currentRules := applyPriceRules(current, seat, pc)
+		newPrice, newRules := applyPriceRules(proposed, seat, pc)
+
+		item := SeatPricePreview{
+			SeatID:       seat.ID,
+			SeatCode:     seat.SeatCode,
+			Cabin:        seat.Segment,
+			Sold:         !unsold[seat.ID],
+			BasePrice:    seat.Price,
+			CurrentPrice: currentPrice,
+			NewPrice:     newPrice,
+			Change:       roundPrice(newPrice - currentPrice),
+			CurrentRules: currentRules,
+			NewRules:     newRules,
+		}
+		if item.Change != 0 {
+			preview.ChangedSeats++
+		}
+		if !item.Sold {
+			preview.UnsoldCurrent += currentPrice
+			preview.UnsoldNew += newPrice
+		}
+		preview.Seats = append(preview.Seats, item)
+	}
+	preview.UnsoldCurrent = roundPrice(preview.UnsoldCurrent)
+	preview.UnsoldNew = roundPrice(preview.UnsoldNew)
+	return preview, nil
+}
+
+// activeRuleSet mengembalikan versi rule yang aktif, atau nil jika belum ada
+func activeRuleSet(tx repository.Store) (*model.PriceRuleSet, error) {
+	set, err := tx.PriceRules().FindActive()
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	return set, err
+}
+
+func flightPricingContext(tx repository.Store, flightID uint, now time.Time) (pricingContext, error) {
+	var pc pricingContext
+
+	seats, err := tx.Seats().FindByFlight(flightID)
+	if err != nil {
+		return pc, err
+	}
+	if len(seats) > 0 {
+		booked, err := tx.Bookings().CountActiveOnFlight(flightID)
+		if err != nil {
+			return pc, err
+		}
+		pc.loadFactor = math.Round(float64(booked)/float64(len(seats))*1000) / 1000
+	}
+
+	flight, err := tx.Flights().FindByID(flightID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return pc, nil
+	} else if err != nil {
+		return pc, err
+	}
+	hours := math.Round(flight.Departure.Sub(now).Hours()*100) / 100
+	pc.hoursToDeparture = &hours
+	return pc, nil
+}
+
+// applyPriceRules menjalankan rule secara berurutan dan mengembalikan harga akhir beserta nama rule yang cocok
+func applyPriceRules(rules []model.PriceRule, seat *model.Seat, pc pricingContext) (float64, []string) {
+	price := seat.Price
+	applied := []string{}
+	for i := range rules {
+		rule := &rules[i]
+		if !priceRuleMatches(rule, seat, pc) {
+			continue
+		}
+
+		multiplier := rule.Multiplier
+		if multiplier == 0 {
+			multiplier = 1
+		}
+		price = price*multiplier + rule.Amount
+		applied = append(applied, rule.Name)
+	}
+	return roundPrice(math.Max(price, 0)), applied
+}
+
+func priceRuleMatches(rule *model.PriceRule, seat *model.Seat, pc pricingContext) bool {
+	if !seatMatches(rule.Cabins, rule.Attributes, seat) {
+		return false
+	}
+	if !inRange(pc.loadFactor, rule.MinLoadFactor, rule.MaxLoadFactor) {
+		return false
+	}
+	if rule.MinHoursToDeparture != nil || rule.MaxHoursToDeparture != nil {
+		if pc.hoursToDeparture == nil || !inRange(*pc.hoursToDeparture, rule.MinHoursToDeparture, rule.MaxHoursToDeparture) {
+			return false
+		}
+	}
+	return true
+}
+
+// seatMatches melaporkan apakah kursi berada di salah satu cabins (jika ada) dan memiliki semua attributes
+func seatMatches(cabins, attributes []string, seat *model.Seat) bool {
+	if len(cabins) > 0 && !slices.ContainsFunc(cabins, func(cabin string) bool {
+		return strings.EqualFold(cabin, seat.Segment)
+	}) {
+		return false
+	}
+	for _, attribute := range attributes {
+		if !slices.Contains(seat.Characteristics, attribute) && !slices.Contains(seat.Designations, attribute) {
+			return false
+		}
+	}
+	return true
+}
+
+func inRange(value float64, min, max *float64) bool {
+	return (min == nil || value >= *min) && (max == nil || value <= *max)
+}
+
+func validatePriceRules(rules []model.PriceRule) error {
+	for i, rule := range rules {
+		if strings.TrimSpace(rule.Name) == "" {
+			return ErrValidationFailed.WithDetail("rule %d: name is required", i+1)
+		}
+		if rule.Multiplier < 0 {
+			return ErrValidationFailed.WithDetail("rule %q: multiplier must not be negative", rule.Name)
+		}
+		for _, factor := range []*float64{rule.MinLoadFactor, rule.MaxLoadFactor} {
+			if factor != nil && (*factor < 0 || *factor > 1) {
+				return ErrValidationFailed.WithDetail("rule %q: load factor must be between 0 and 1", rule.Name)
+			}
+		}
+		if rule.MinLoadFactor != nil && rule.MaxLoadFactor != nil && *rule.MinLoadFactor > *rule.MaxLoadFactor {
+			return ErrValidationFailed.WithDetail("rule %q: min_load_factor is greater than max_load_factor", rule.Name)
+		}
+		if rule.MinHoursToDeparture != nil && rule.MaxHoursToDeparture != nil && *rule.MinHoursToDeparture > *rule.MaxHoursToDeparture {
+			return ErrValidationFailed.WithDetail("rule %q: min_hours_to_departure is greater than max_hours_to_departure", rule.Name)
+		}
+	}
+	return nil
+}
+
+func validateEntitlementRules(rules []model.EntitlementRule) error {
+	ids := map[string]bool{}
+	for i, rule := range rules {
+		if strings.TrimSpace(rule.ID) == "" {
+			return ErrValidationFailed.WithDetail("entitlement %d: id is required", i+1)
+		}
+		if ids[rule.ID] {
+			return ErrValidationFailed.WithDetail("entitlement %q: id is used more than once", rule.ID)
+		}
+		ids[rule.ID] = true
+		if rule.Percent < 0 || rule.Percent > 100 {
+			return ErrValidationFailed.WithDetail("entitlement %q: percent must be between 0 and 100", rule.ID)
+		}
+		if rule.MinTier != nil && *rule.MinTier < 0 {
+			return ErrValidationFailed.WithDetail("entitlement %q: min_tier must not be negative", rule.ID)
+		}
+	}
+	return nil
+}
+
+func roundPrice(price float64) float64 {
+	return math.Round(price*100) / 100
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+// ptr mengembalikan pointer ke salinan v, untuk field opsional pada rule
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestApplyPriceRules(t *testing.T) {
+	window := &model.Seat{Price: 100, Segment: "ECONOMY", Characteristics: model.StringArray{"W"}}
+	aisle := &model.Seat{Price: 19.99, Segment: "ECONOMY", Characteristics: model.StringArray{"A"}}
+	busy := pricingContext{loadFactor: 0.8, hoursToDeparture: ptr(12.0)}
+	unscheduled := pricingContext{loadFactor: 0.2}
+
+	tests := []struct {
+		name        string
+		rules       []model.PriceRule
+		seat        *model.Seat
+		pc          pricingContext
+		wantPrice   float64
+		wantApplied []string
+	}{
+		{"no rules keeps the seat price", nil, window, busy, 100, []string{}},
+		{
+			"rules apply in order: multiply then add",
+			[]model.PriceRule{{Name: "peak", Multiplier: 1.5}, {Name: "fee", Amount: 10}},
+			window, busy, 160, []string{"peak", "fee"},
+		},
+		{
+			"rules apply in order: add then multiply",
+			[]model.PriceRule{{Name: "fee", Amount: 10}, {Name: "peak", Multiplier: 1.5}},
+			window, busy, 165, []string{"fee", "peak"},
+		},
+		{
+			"multiplier and amount in one rule",
+			[]model.PriceRule{{Name: "combo", Multiplier: 2, Amount: -15}},
+			window, busy, 185, []string{"combo"},
+		},
+		{
+			"cabin matches case-insensitively, attributes must all match",
+			[]model.PriceRule{
+				{Name: "economy window", Cabins: []string{"economy"}, Attributes: []string{"W"}, Amount: 5},
+				{Name: "business", Cabins: []string{"BUSINESS"}, Amount: 50},
+				{Name: "window legroom", Attributes: []string{"W", "L"}, Amount: 30},
+			},
+			window, busy, 105, []string{"economy window"},
+		},
+		{
+			"load factor bounds are inclusive",
+			[]model.PriceRule{
+				{Name: "high load", MinLoadFactor: ptr(0.8), Multiplier: 1.2},
+				{Name: "low load", MaxLoadFactor: ptr(0.5), Multiplier: 0.5},
+			},
+			window, busy, 120, []string{"high load"},
+		},
+		{
+			"time rules need a known departure",
+			[]model.PriceRule{{Name: "last minute", MaxHoursToDeparture: ptr(24.0), Amount: 20}},
+			window, unscheduled, 100, []string{},
+		},
+		{
+			"time rules match hours to departure",
+			[]model.PriceRule{
+				{Name: "last minute", MaxHoursToDeparture: ptr(24.0), Amount: 20},
+				{Name: "early bird", MinHoursToDeparture: ptr(720.0), Amount: -20},
+			},
+			window, busy, 120, []string{"last minute"},
+		},
+		{
+			"price never goes below zero",
+			[]model.PriceRule{{Name: "clearance", Amount: -150}, {Name: "fee", Amount: 10}},
+			window, busy, 0, []string{"clearance", "fee"},
+		},
+		{
+			"result is rounded to cents",
+			[]model.PriceRule{{Name: "markup", Multiplier: 1.15}},
+			aisle, busy, 22.99, []string{"markup"},
+		},
+		{
+			"zero multiplier counts as one",
+			[]model.PriceRule{{Name: "flat", Amount: 0.004}},
+			aisle, busy, 19.99, []string{"flat"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, applied := applyPriceRules(tt.rules, tt.seat, tt.pc)
+			if price != tt.wantPrice {
+				t.Errorf("price = %v, want %v", price, tt.wantPrice)
+			}
+			if !slices.Equal(applied, tt.wantApplied) {
+				t.Errorf("applied = %v, want %v", applied, tt.wantApplied)
+			}
+		})
+	}
+}
+
+func TestValidatePriceRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []model.PriceRule
+		ok    bool
+	}{
+		{"valid", []model.PriceRule{{Name: "peak", MinLoadFactor: ptr(0.5), MaxLoadFactor: ptr(1.0), Multiplier: 1.2}}, true},
+		{"missing name", []model.PriceRule{{Name: " ", Amount: 5}}, false},
+		{"negative multiplier", []model.PriceRule{{Name: "bad", Multiplier: -1}}, false},
+		{"load factor above one", []model.PriceRule{{Name: "bad", MaxLoadFactor: ptr(1.5)}}, false},
+		{"inverted load factor range", []model.PriceRule{{Name: "bad", MinLoadFactor: ptr(0.8), MaxLoadFactor: ptr(0.2)}}, false},
+		{"inverted hours range", []model.PriceRule{{Name: "bad", MinHoursToDeparture: ptr(48.0), MaxHoursToDeparture: ptr(24.0)}}, false},
+	}
+	for _, tt := range tests {
+		err := validatePriceRules(tt.rules)
+		if tt.ok && err != nil {
+			t.Errorf("%s: err = %v, want nil", tt.name, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrValidationFailed) {
+			t.Errorf("%s: err = %v, want ErrValidationFailed", tt.name, err)
+		}
+	}
+}
+
+func TestPricePreviewSeparatesSoldSeats(t *testing.T) {
+	env := newTestEnv(t)
+	pricing := NewPricingService(env.store)
+	admin := env.user(t, "admin@example.com")
+	user := env.user(t, "budi@example.com")
+	free := env.freeSeats(t)
+	env.departIn(t, free[0].FlightID, 10*24*time.Hour)
+
+	if _, err := pricing.CreateRuleSet(admin.ID, []model.PriceRule{{Name: "fee", Amount: 5}}, nil, "v1"); err != nil {
+		t.Fatalf("CreateRuleSet: %v", err)
+	}
+	if _, err := pricing.ActivateRuleSet(admin.ID, 1, "launch"); err != nil {
+		t.Fatalf("ActivateRuleSet: %v", err)
+	}
+	sold, err := env.bookings.CreateBooking(user.ID, free[0].ID, "")
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+
+	preview, err := pricing.Preview(free[0].FlightID, PricePreviewInput{Rules: []model.PriceRule{{Name: "peak", Multiplier: 2}}})
+	if err != nil {
+		t.Fatalf("Preview: %v", err)
+	}
+	if preview.CurrentVersion != 1 || preview.HoursToDeparture < 239 || preview.LoadFactor == 0 {
+		t.Errorf("preview = version %d, %v hours, load %v; want version 1, ~240 hours and a load factor",
+			preview.CurrentVersion, preview.HoursToDeparture, preview.LoadFactor)
+	}
+
+	var unsoldCurrent, unsoldNew float64
+	for _, seat := range preview.Seats {
+		if (seat.SeatID == sold.SeatID || seat.SeatID == free[1].ID) && seat.Sold != (seat.SeatID == sold.SeatID) {
+			t.Errorf("seat %s sold = %v", seat.SeatCode, seat.Sold)
+		}
+		if seat.CurrentPrice != roundPrice(seat.BasePrice+5) || seat.NewPrice != roundPrice(seat.BasePrice*2) {
+			t.Errorf("seat %s = %v -> %v, want %v -> %v", seat.SeatCode, seat.CurrentPrice, seat.NewPrice, seat.BasePrice+5, seat.BasePrice*2)
+		}
+		if !seat.Sold {
+			unsoldCurrent += seat.CurrentPrice
+			unsoldNew += seat.NewPrice
+		}
+	}
+	if preview.UnsoldCurrent != roundPrice(unsoldCurrent) || preview.UnsoldNew != roundPrice(unsoldNew) {
+		t.Errorf("unsold totals = %v / %v, want %v / %v", preview.UnsoldCurrent, preview.UnsoldNew, roundPrice(unsoldCurrent), roundPrice(unsoldNew))
+	}
+
+	if _, err := pricing.Preview(404, PricePreviewInput{Version: 1}); !errors.Is(err, ErrFlightNotFound) {
+		t.Errorf("unknown flight err = %v, want ErrFlightNotFound", err)
+	}
+	if _, err := pricing.Preview(free[0].FlightID, PricePreviewInput{}); !errors.Is(err, ErrValidationFailed) {
+		t.Errorf("preview without rules err = %v, want ErrValidationFailed", err)
+	}
+}
+
+func TestBestEntitlement(t *testing.T) {
+	economy := &model.Seat{Segment: "ECONOMY", Characteristics: model.StringArray{"W"}}
+	waived := &model.Seat{Segment: "ECONOMY", SeatEntitlement: model.SeatEntitlement{FeeWaived: true, FeeWaivedRuleID: "FW1"}}
+	entitled := &model.Seat{Segment: "ECONOMY", SeatEntitlement: model.SeatEntitlement{Entitled: true}}
+	free := &model.Seat{Segment: "ECONOMY", SeatEntitlement: model.SeatEntitlement{FreeOfCharge: true}}
+	gold := &model.FlightPassenger{FrequentFlyerTier: 3, BookingClass: "y", FareBasis: "YFLEX1"}
+	basic := &model.FlightPassenger{FrequentFlyerTier: 0, BookingClass: "Q", FareBasis: "QSAVER"}
+
+	rules := []model.EntitlementRule{
+		{ID: "GOLD", MinTier: ptr(2), Percent: 50},
+		{ID: "FLEX", FareBases: []string{"yflex"}},
+		{ID: "FULL-Y", BookingClasses: []string{"Y"}, Cabins: []string{"BUSINESS"}},
+	}
+
+	tests := []struct {
+		name      string
+		rules     []model.EntitlementRule
+		seat      *model.Seat
+		passenger *model.FlightPassenger
+		price     float64
+		want      *Entitlement
+	}{
+		{"no entitlement", rules, economy, basic, 40, nil},
+		{"free of charge applies without a passenger record", nil, free, nil, 40, &Entitlement{Source: EntitlementSourceSeatMap, Discount: 40}},
+		{"fee waiver needs a passenger record", nil, waived, nil, 40, nil},
+		{"fee waiver from the seat map", nil, waived, basic, 40, &Entitlement{RuleID: "FW1", Source: EntitlementSourceSeatMap, Discount: 40}},
+		{"entitled flag without a rule id is ignored", nil, entitled, basic, 40, nil},
+		{"tier rule gives a percentage", rules[:1], economy, gold, 45.55, &Entitlement{RuleID: "GOLD", Source: EntitlementSourceRule, Discount: 22.78}},
+		{"largest discount wins over rule order", rules, economy, gold, 40, &Entitlement{RuleID: "FLEX", Source: EntitlementSourceRule, Discount: 40}},
+		{"rule cabins must match the seat", rules[2:], economy, gold, 40, nil},
+		{"seat map waiver beats a partial rule", rules[:1], waived, gold, 40, &Entitlement{RuleID: "FW1", Source: EntitlementSourceSeatMap, Discount: 40}},
+		{"free seats need no entitlement", rules, free, gold, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bestEntitlement(tt.rules, tt.seat, tt.passenger, tt.price)
+			if (got == nil) != (tt.want == nil) || got != nil && *got != *tt.want {
+				t.Errorf("bestEntitlement = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBookingRecordsEntitlement(t *testing.T) {
+	env := newTestEnv(t)
+	pricing := NewPricingService(env.store)
+	admin := env.user(t, "admin@example.com")
+	budi := env.user(t, "budi@example.com")
+	sari := env.user(t, "sari@example.com")
+	free := env.freeSeats(t)
+
+	passengers := []model.FlightPassenger{
+		{PassengerIndex: 1, FirstName: "Budi", LastName: "Santoso", Email: budi.Email, FrequentFlyerTier: 3, BookingClass: "Y"},
+		{PassengerIndex: 2, FirstName: "Sari", LastName: "Wijaya", Email: sari.Email, BookingClass: "Q"},
+	}
+	if err := env.store.Passengers().ReplaceForFlight(free[0].FlightID, passengers); err != nil {
+		t.Fatalf("ReplaceForFlight: %v", err)
+	}
+	// Offer seat map sumber membebaskan biaya kursi free[1] untuk Sari saja
+	offer := model.SeatOffer{FlightID: free[1].FlightID, SeatID: free[1].ID, PassengerIndex: 2, SeatCode: free[1].SeatCode,
+		Available: true, Price: free[1].Price, Currency: free[1].Currency,
+		SeatEntitlement: model.SeatEntitlement{FeeWaived: true, FeeWaivedRuleID: "CORP-WAIVER"}}
+	if err := env.store.SeatOffers().ReplaceForFlight(free[1].FlightID, []model.SeatOffer{offer}); err != nil {
+		t.Fatalf("replace seat offers: %v", err)
+	}
+
+	entitlements := []model.EntitlementRule{{ID: "GOLD", MinTier: ptr(2), Percent: 50}}
+	if _, err := pricing.CreateRuleSet(admin.ID, nil, entitlements, "elite"); err != nil {
+		t.Fatalf("CreateRuleSet: %v", err)
+	}
+	if _, err := pricing.ActivateRuleSet(admin.ID, 1, "launch"); err != nil {
+		t.Fatalf("ActivateRuleSet: %v", err)
+	}
+
+	elite, err := env.bookings.CreateBooking(budi.ID, free[0].ID, "")
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+	half := roundPrice(free[0].Price * 50 / 100)
+	if elite.EntitlementRuleID != "GOLD" || elite.EntitlementDiscount != half || elite.Price != roundPrice(free[0].Price-half) || elite.OriginalPrice != free[0].Price {
+		t.Errorf("elite booking = rule %q discount %v price %v original %v, want GOLD at half of %v",
+			elite.EntitlementRuleID, elite.EntitlementDiscount, elite.Price, elite.OriginalPrice, free[0].Price)
+	}
+
+	waivedBooking, err := env.bookings.CreateBooking(sari.ID, free[1].ID, "")
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+	if waivedBooking.EntitlementRuleID != "CORP-WAIVER" || waivedBooking.Price != 0 {
+		t.Errorf("waived booking = rule %q price %v, want CORP-WAIVER for free", waivedBooking.EntitlementRuleID, waivedBooking.Price)
+	}
+
+	// Tanpa data penumpang tidak ada entitlement
+	other := env.user(t, "other@example.com")
+	plain, err := env.bookings.CreateBooking(other.ID, free[2].ID, "")
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+	if plain.EntitlementRuleID != "" || plain.Price != free[2].Price {
+		t.Errorf("booking without a passenger record = rule %q price %v, want full price %v", plain.EntitlementRuleID, plain.Price, free[2].Price)
+	}
+}
@@ -0,0 +1,337 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/repository"
+)
+
+var (
+	promoCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+	currencyPattern  = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// PromoCodeInput adalah pengaturan kode promo dari admin, dipakai untuk membuat maupun mengganti promo
+type PromoCodeInput struct {
+	Code           string
+	Description    string
+	DiscountType   model.DiscountType
+	Percent        float64
+	Amounts        model.CurrencyAmounts
+	MaxDiscount    model.CurrencyAmounts
+	MaxRedemptions int
+	ValidFrom      *time.Time
+	ValidUntil     *time.Time
+	Cabins         []string
+	SeatTypes      []string
+	Active         bool
+}
+
+// PromoReport merangkum pemakaian satu kode promo
+type PromoReport struct {
+	Promo *model.PromoCode `json:"promo"`
+	// Redemptions dan DiscountTotals hanya menghitung pemakaian yang belum dilepas
+	Redemptions    int                     `json:"redemptions"`
+	Released       int                     `json:"released"`
+	DiscountTotals model.CurrencyAmounts   `json:"discount_totals"`
+	Items          []model.PromoRedemption `json:"items"`
+}
+
+type PromoService struct {
+	store repository.Store
+}
+
+func NewPromoService(store repository.Store) *PromoService {
+	return &PromoService{store: store}
+}
+
+func (s *PromoService) ListPromoCodes() ([]model.PromoCode, error) {
+	return s.store.Promos().FindAll()
+}
+
+func (s *PromoService) GetPromoCode(id uint) (*model.PromoCode, error) {
+	return s.findPromo(id)
+}
+
+func (s *PromoService) CreatePromoCode(actorID uint, input PromoCodeInput) (*model.PromoCode, error) {
+	promo := &model.PromoCode{}
+	if err := applyPromoInput(promo, input); err != nil {
+		return nil, err
+	}
+
+	err := s.store.Transaction(func(tx repository.Store) error {
+		if err := tx.Promos().Create(promo); err != nil {
+			if errors.Is(err, repository.ErrDuplicate) {
+				return ErrPromoCodeExists
+			}
+			return err
+		}
+		return audit(tx, actorID, AuditPromoCreate, auditTargetPromo, promo.ID, "", model.JSONMap{"code": promo.Code})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return promo, nil
+}
+
+// UpdatePromoCode mengganti seluruh pengaturan promo. Booking yang sudah memakai promo tidak berubah.
+func (s *PromoService) UpdatePromoCode(actorID, id uint, input PromoCodeInput, reason string) (*model.PromoCode, error) {
+	promo, err := s.findPromo(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyPromoInput(promo, input); err != nil {
+		return nil, err
+	}
+
+	err = s.store.Transaction(func(tx repository.Store) error {
+		if err := tx.Promos().Update(promo); err != nil {
+			if errors.Is(err, repository.ErrDuplicate) {
+				return ErrPromoCodeExists
+			}
+			return err
+		}
+		return audit(tx, actorID, AuditPromoUpdate, auditTargetPromo, promo.ID, reason, model.JSONMap{"code": promo.Code})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.findPromo(id)
+}
+
+// DeletePromoCode menghapus promo sehingga tidak bisa dipakai lagi; riwayat pemakaian tetap tersimpan
+func (s *PromoService) DeletePromoCode(actorID, id uint, reason string) error {
+	promo, err := s.findPromo(id)
+	if err != nil {
+		return err
+	}
+
+	return s.store.Transaction(func(tx repository.Store) error {
+		if err := tx.Promos().Delete(promo.ID); err != nil {
+			return err
+		}
+		return audit(tx, actorID, AuditPromoDelete, auditTargetPromo, promo.ID, reason, model.JSONMap{
+			"code":        promo.Code,
+			"redemptions": promo.Redemptions,
+		})
+	})
+}
+
+func (s *PromoService) RedemptionReport(id uint) (*PromoReport, error) {
+	promo, err := s.findPromo(id)
+	if err != nil {
+		return nil, err
+	}
+	redemptions, err := s.store.Promos().FindRedemptions(promo.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &PromoReport{
+		Promo:          promo,
+		DiscountTotals: model.CurrencyAmounts{},
+		Items:          []model.PromoRedemption{},
+	}
+	for _, redemption := range redemptions {
+		if redemption.ReleasedAt != nil {
+			report.Released++
+		} else {
+			report.Redemptions++
+			report.DiscountTotals[redemption.Currency] = roundPrice(report.DiscountTotals[redemption.Currency] + redemption.Discount)
+		}
+		report.Items = append(report.Items, redemption)
+	}
+	return report, nil
+}
+
+func (s *PromoService) findPromo(id uint) (*model.PromoCode, error) {
+	promo, err := s.store.Promos().FindByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrPromoCodeNotFound
+		}
+		return nil, err
+	}
+	return promo, nil
+}
+
+// applyPromoInput memvalidasi input lalu menyalinnya ke promo
+func applyPromoInput(promo *model.PromoCode, input PromoCodeInput) error {
+	code := normalizePromoCode(input.Code)
+	if !promoCodePattern.MatchString(code) {
+		return ErrValidationFailed.WithDetail("code must be 3-32 letters, digits, '-' or '_'")
+	}
+
+	amounts, err := normalizeAmounts(input.Amounts)
+	if err != nil {
+		return err
+	}
+	maxDiscount, err := normalizeAmounts(input.MaxDiscount)
+	if err != nil {
+		return err
+	}
+
+	switch input.DiscountType {
+	case model.DiscountPercent:
+		if input.Percent <= 0 || input.Percent > 100 {
+			return ErrValidationFailed.WithDetail("percent must be greater than 0 and at most 100")
+		}
+		amounts = model.CurrencyAmounts{}
+	case model.DiscountFixed:
+		if len(amounts) == 0 {
+			return ErrValidationFailed.WithDetail("amounts is required for fixed discounts")
+		}
+		input.Percent = 0
+		maxDiscount = model.CurrencyAmounts{}
+	default:
+		return ErrValidationFailed.WithDetail("discount_type must be %q or %q", model.DiscountPercent, model.DiscountFixed)
+	}
+
+	if input.MaxRedemptions < 0 {
+		return ErrValidationFailed.WithDetail("max_redemptions must not be negative")
+	}
+	if input.ValidFrom != nil && input.ValidUntil != nil && !input.ValidUntil.After(*input.ValidFrom) {
+		return ErrValidationFailed.WithDetail("valid_until must be after valid_from")
+	}
+	for _, seatType := range input.SeatTypes {
+		if seatType != model.SeatTypeWindow && seatType != model.SeatTypeAisle {
+			return ErrValidationFailed.WithDetail("seat_types may only contain %q or %q", model.SeatTypeWindow, model.SeatTypeAisle)
+		}
+	}
+
+	promo.Code = code
+	promo.Description = strings.TrimSpace(input.Description)
+	promo.DiscountType = input.DiscountType
+	promo.Percent = input.Percent
+	promo.Amounts = amounts
+	promo.MaxDiscount = maxDiscount
+	promo.MaxRedemptions = input.MaxRedemptions
+	promo.ValidFrom = input.ValidFrom
+	promo.ValidUntil = input.ValidUntil
+	promo.Cabins = model.StringArray(input.Cabins)
+	promo.SeatTypes = model.StringArray(input.SeatTypes)
+	promo.Active = input.Active
+	return nil
+}
+
+func normalizeAmounts(amounts model.CurrencyAmounts) (model.CurrencyAmounts, error) {
+	normalized := model.CurrencyAmounts{}
+	for currency, amount := range amounts {
+		currency = strings.ToUpper(strings.TrimSpace(currency))
+		if !currencyPattern.MatchString(currency) {
+			return nil, ErrValidationFailed.WithDetail("invalid currency %q", currency)
+		}
+		if amount <= 0 {
+			return nil, ErrValidationFailed.WithDetail("amount for %s must be positive", currency)
+		}
+		normalized[currency] = amount
+	}
+	return normalized, nil
+}
+
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// redeemPromo memvalidasi kode promo untuk kursi dan user, lalu memakai satu kuota.
+// Harus dijalankan dalam transaksi yang sama dengan pembuatan booking; pemakaian dicatat
+// dengan recordRedemption setelah booking tersimpan.
+func redeemPromo(tx repository.Store, code string, userID uint, seat *model.Seat, quote *PriceQuote, now time.Time) (*model.PromoCode, float64, error) {
+	promo, err := tx.Promos().FindByCode(normalizePromoCode(code))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, 0, ErrPromoCodeInvalid
+	} else if err != nil {
+		return nil, 0, err
+	}
+	if !promo.Active {
+		return nil, 0, ErrPromoCodeInvalid
+	}
+	if promo.ValidFrom != nil && now.Before(*promo.ValidFrom) {
+		return nil, 0, ErrPromoNotApplicable.WithDetail("promo code is not valid until %s", promo.ValidFrom.Format(time.RFC3339))
+	}
+	if promo.ValidUntil != nil && !now.Before(*promo.ValidUntil) {
+		return nil, 0, ErrPromoCodeExpired
+	}
+	if len(promo.Cabins) > 0 && !slices.ContainsFunc(promo.Cabins, func(cabin string) bool {
+		return strings.EqualFold(cabin, seat.Segment)
+	}) {
+		return nil, 0, ErrPromoNotApplicable.WithDetail("promo code is not valid for %s seats", seat.Segment)
+	}
+	if len(promo.SeatTypes) > 0 && !slices.ContainsFunc(promo.SeatTypes, func(seatType string) bool {
+		return (seatType == model.SeatTypeWindow && seat.IsWindow) || (seatType == model.SeatTypeAisle && seat.IsAisle)
+	}) {
+		return nil, 0, ErrPromoNotApplicable.WithDetail("promo code is only valid for %s seats", strings.Join(promo.SeatTypes, " or "))
+	}
+
+	discount := promoDiscount(promo, quote.Price, quote.Currency)
+	if discount <= 0 {
+		return nil, 0, ErrPromoNotApplicable.WithDetail("promo code is not valid for prices in %s", quote.Currency)
+	}
+
+	if _, err := tx.Promos().FindActiveRedemption(promo.ID, userID); err == nil {
+		return nil, 0, ErrPromoAlreadyUsed
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return nil, 0, err
+	}
+
+	redeemed, err := tx.Promos().Redeem(promo.ID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !redeemed {
+		return nil, 0, ErrPromoExhausted
+	}
+	return promo, discount, nil
+}
+
+// promoDiscount menghitung potongan untuk harga dalam currency; 0 jika promo tidak berlaku untuk mata uang tersebut
+func promoDiscount(promo *model.PromoCode, price float64, currency string) float64 {
+	var discount float64
+	switch promo.DiscountType {
+	case model.DiscountPercent:
+		discount = price * promo.Percent / 100
+		if limit, ok := promo.MaxDiscount[currency]; ok {
+			discount = math.Min(discount, limit)
+		}
+	case model.DiscountFixed:
+		discount = promo.Amounts[currency]
+	}
+	return roundPrice(math.Min(discount, price))
+}
+
+func recordRedemption(tx repository.Store, promo *model.PromoCode, booking *model.Booking) error {
+	err := tx.Promos().CreateRedemption(&model.PromoRedemption{
+		PromoCodeID:   promo.ID,
+		UserID:        booking.UserID,
+		BookingID:     booking.ID,
+		Currency:      booking.Currency,
+		OriginalPrice: roundPrice(booking.Price + booking.Discount),
+		Discount:      booking.Discount,
+		FinalPrice:    booking.Price,
+	})
+	if errors.Is(err, repository.ErrDuplicate) {
+		return ErrPromoAlreadyUsed
+	}
+	return err
+}
+
+// releasePromo melepas pemakaian promo pada booking yang dibatalkan atau kedaluwarsa
+func releasePromo(tx repository.Store, bookingID uint, now time.Time) error {
+	redemption, err := tx.Promos().FindActiveRedemptionByBooking(bookingID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if err := tx.Promos().ReleaseRedemption(redemption.ID, now); err != nil {
+		return err
+	}
+	return tx.Promos().Unredeem(redemption.PromoCodeID)
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+// promo membuat kode promo percent lewat PromoService seperti dari admin
+func (env *testEnv) promo(t *testing.T, input PromoCodeInput) *model.PromoCode {
+	t.Helper()
+	if input.DiscountType == "" {
+		input.DiscountType, input.Percent = model.DiscountPercent, 50
+	}
+	promo, err := NewPromoService(env.store).CreatePromoCode(0, input)
+	if err != nil {
+		t.Fatalf("CreatePromoCode: %v", err)
+	}
+	return promo
+}
+
+func TestInactivePromoIsRejected(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.user(t, "budi@example.com")
+	seat := env.freeSeats(t)[0]
+
+	promo := env.promo(t, PromoCodeInput{Code: "paused", Active: false})
+	stored, err := env.store.Promos().FindByID(promo.ID)
+	if err != nil {
+		t.Fatalf("find promo: %v", err)
+	}
+	if stored.Active {
+		t.Fatal("promo created as inactive was stored as active")
+	}
+
+	if _, err := env.bookings.CreateBooking(user.ID, seat.ID, "PAUSED"); !errors.Is(err, ErrPromoCodeInvalid) {
+		t.Errorf("booking with inactive promo err = %v, want ErrPromoCodeInvalid", err)
+	}
+	if !env.seat(t, seat.ID).Available {
+		t.Error("rejected promo still took the seat")
+	}
+	if stored, _ := env.store.Promos().FindByID(promo.ID); stored.Redemptions != 0 {
+		t.Errorf("redemptions = %d, want 0", stored.Redemptions)
+	}
+}
+
+func TestRedeemAndReleasePromo(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.user(t, "budi@example.com")
+	free := env.freeSeats(t)
+	promo := env.promo(t, PromoCodeInput{Code: " half-off ", Active: true})
+
+	booking, err := env.bookings.CreateBooking(user.ID, free[0].ID, "half-off")
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+	if booking.PromoCode != "HALF-OFF" || booking.Discount != roundPrice(free[0].Price/2) || booking.Price != roundPrice(free[0].Price-booking.Discount) {
+		t.Errorf("booking = %s discount %v price %v, want HALF-OFF at half of %v", booking.PromoCode, booking.Discount, booking.Price, free[0].Price)
+	}
+	if stored, _ := env.store.Promos().FindByID(promo.ID); stored.Redemptions != 1 {
+		t.Errorf("redemptions after booking = %d, want 1", stored.Redemptions)
+	}
+
+	// Satu user hanya boleh memakai kode yang sama sekali selama pemakaiannya masih berlaku
+	if _, err := env.bookings.CreateBooking(user.ID, free[1].ID, "HALF-OFF"); !errors.Is(err, ErrPromoAlreadyUsed) {
+		t.Errorf("second redemption err = %v, want ErrPromoAlreadyUsed", err)
+	}
+
+	if err := env.bookings.CancelBooking(user.ID, booking.ID); err != nil {
+		t.Fatalf("CancelBooking: %v", err)
+	}
+	if stored, _ := env.store.Promos().FindByID(promo.ID); stored.Redemptions != 0 {
+		t.Errorf("redemptions after cancel = %d, want 0", stored.Redemptions)
+	}
+	report, err := NewPromoService(env.store).RedemptionReport(promo.ID)
+	if err != nil {
+		t.Fatalf("RedemptionReport: %v", err)
+	}
+	if report.Redemptions != 0 || report.Released != 1 {
+		t.Errorf("report = %d active %d released, want 0 and 1", report.Redemptions, report.Released)
+	}
+
+	if _, err := env.bookings.CreateBooking(user.ID, free[1].ID, "HALF-OFF"); err != nil {
+		t.Errorf("redeem again after release: %v", err)
+	}
+}
+
+func TestPromoRedemptionLimit(t *testing.T) {
+	env := newTestEnv(t)
+	budi := env.user(t, "budi@example.com")
+	sari := env.user(t, "sari@example.com")
+	free := env.freeSeats(t)
+	env.promo(t, PromoCodeInput{Code: "FIRST1", MaxRedemptions: 1, Active: true})
+
+	if _, err := env.bookings.CreateBooking(budi.ID, free[0].ID, "FIRST1"); err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+	if _, err := env.bookings.CreateBooking(sari.ID, free[1].ID, "FIRST1"); !errors.Is(err, ErrPromoExhausted) {
+		t.Errorf("redemption over the limit err = %v, want ErrPromoExhausted", err)
+	}
+}
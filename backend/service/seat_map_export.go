@@ -0,0 +1,314 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/repository"
+)
+
+// ExportSeatMap menyusun ulang dokumen SeatMapResponse untuk satu penerbangan dari layout dan
+// inventory yang tersimpan. available dan prices mengikuti kondisi saat ini: kursi yang sudah
+// dibooking, di-hold, dipilih di sistem sumber atau diblokir tidak available, dan harga sudah
+// melewati rule harga yang aktif. Setiap penumpang mendapat seat map dengan offer miliknya.
+func (s *SeatService) ExportSeatMap(flightID uint) (*SeatMapResponse, error) {
+	flight, err := s.store.Flights().FindByID(flightID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrFlightNotFound
+		}
+		return nil, err
+	}
+
+	seats, err := s.store.Seats().FindByFlight(flightID)
+	if err != nil {
+		return nil, err
+	}
+	passengers, err := s.store.Passengers().FindByFlight(flightID)
+	if err != nil {
+		return nil, err
+	}
+	offers, err := s.store.SeatOffers().FindByFlight(flightID)
+	if err != nil {
+		return nil, err
+	}
+	assignments, err := s.store.SeatAssignments().FindByFlight(flightID)
+	if err != nil {
+		return nil, err
+	}
+	set, err := activeRuleSet(s.store)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	pc, err := flightPricingContext(s.store, flightID, now)
+	if err != nil {
+		return nil, err
+	}
+	var rules []model.PriceRule
+	if set != nil {
+		rules = set.Rules
+	}
+
+	type offerKey struct {
+		seatID         uint
+		passengerIndex int
+	}
+	offerFor := map[offerKey]model.SeatOffer{}
+	for _, offer := range offers {
+		offerFor[offerKey{offer.SeatID, offer.PassengerIndex}] = offer
+	}
+	assigned := map[uint]bool{}
+	for _, assignment := range assignments {
+		assigned[assignment.SeatID] = true
+	}
+
+	segment := exportSegment(flight, passengers)
+	// Tanpa data penumpang, seat map diekspor sekali dengan harga kursi tanpa offer
+	if len(passengers) == 0 {
+		passengers = []model.FlightPassenger{{}}
+	}
+	for i := range passengers {
+		passenger := &passengers[i]
+		var priced []exportedSeat
+		for _, seat := range seats {
+			available := seat.Available && !seat.IsBlocked(now) && !assigned[seat.ID]
+			if offer, ok := offerFor[offerKey{seat.ID, passenger.PassengerIndex}]; ok {
+				seat.Price = offer.Price
+				seat.Currency = offer.Currency
+				seat.SeatEntitlement = offer.SeatEntitlement
+				available = available && offer.Available
+			}
+
+			price, _ := applyPriceRules(rules, &seat, pc)
+			priced = append(priced, exportedSeat{seat: seat, available: available, price: price})
+		}
+
+		segment.PassengerSeatMaps = append(segment.PassengerSeatMaps, SeatMapPassengerSeatMap{
+			SeatSelectionEnabledForPax: true,
+			SeatMap:                    exportSeatLayout(flight, priced, now),
+			Passenger:                  exportPassenger(passenger),
+		})
+	}
+
+	response := &SeatMapResponse{
+		SeatsItineraryParts: []SeatMapItineraryPart{{SegmentSeatMaps: []SeatMapSegmentSeatMap{segment}}},
+		SelectedSeats:       []SelectedSeat{},
+	}
+	for _, assignment := range assignments {
+		response.SelectedSeats = append(response.SelectedSeats, SelectedSeat{
+			PassengerIndex: assignment.PassengerIndex,
+			SeatCode:       assignment.SeatCode,
+		})
+	}
+	return response, nil
+}
+
+// exportedSeat adalah kursi dengan harga dan entitlement dari offer satu penumpang, beserta
+// ketersediaan dan harga setelah rule harga untuk penumpang tersebut
+type exportedSeat struct {
+	seat      model.Seat
+	available bool
+	price     float64
+}
+
+func exportSegment(flight *model.Flight, passengers []model.FlightPassenger) SeatMapSegmentSeatMap {
+	segment := SeatMapSegment{
+		Equipment:   flight.Equipment,
+		Origin:      flight.Origin,
+		Destination: flight.Destination,
+		Departure:   flight.LocalDeparture().Format(segmentTimeLayout),
+		Arrival:     flight.LocalArrival().Format(segmentTimeLayout),
+	}
+	segment.Flight.FlightNumber = flight.FlightNumber
+	segment.Flight.AirlineCode = flight.AirlineCode
+	segment.Flight.DepartureTerminal = flight.DepartureTerminal
+	segment.Flight.ArrivalTerminal = flight.ArrivalTerminal
+	// Kelas tarif pada dokumen berlaku per segmen; dipakai milik penumpang pertama
+	if len(passengers) > 0 {
+		segment.BookingClass = passengers[0].BookingClass
+		segment.FareBasis = passengers[0].FareBasis
+	}
+	return SeatMapSegmentSeatMap{Segment: segment}
+}
+
+func exportPassenger(passenger *model.FlightPassenger) SeatMapPassenger {
+	pax := SeatMapPassenger{
+		PassengerIndex:      passenger.PassengerIndex,
+		PassengerNameNumber: passenger.NameNumber,
+	}
+	pax.PassengerDetails.FirstName = passenger.FirstName
+	pax.PassengerDetails.LastName = passenger.LastName
+	pax.PassengerInfo.Type = passenger.Type
+	pax.PassengerInfo.Emails = []string{}
+	if passenger.Email != "" {
+		pax.PassengerInfo.Emails = append(pax.PassengerInfo.Emails, passenger.Email)
+	}
+	if passenger.DateOfBirth != nil {
+		pax.PassengerInfo.DateOfBirth = passenger.DateOfBirth.Format(dateOfBirthLayout)
+	}
+	pax.Preferences.SpecialPreferences.SpecialRequests = []SpecialRequest{}
+	for _, code := range passenger.SpecialRequests {
+		pax.Preferences.SpecialPreferences.SpecialRequests = append(pax.Preferences.SpecialPreferences.SpecialRequests, SpecialRequest{Code: code})
+	}
+	pax.Preferences.FrequentFlyer = []SeatMapFrequentFlyer{}
+	if passenger.FrequentFlyerNumber != "" {
+		pax.Preferences.FrequentFlyer = append(pax.Preferences.FrequentFlyer, SeatMapFrequentFlyer{
+			Airline:    passenger.FrequentFlyerAirline,
+			Number:     passenger.FrequentFlyerNumber,
+			TierNumber: passenger.FrequentFlyerTier,
+		})
+	}
+	return pax
+}
+
+// exportSeatLayout menyusun kabin dari kursi yang tersimpan. Kabin baru dimulai setiap kali kelas
+// kabin berganti; kolom dan lorong dibentuk ulang dari huruf kolom dan ColumnGroup kursi.
+func exportSeatLayout(flight *model.Flight, seats []exportedSeat, now time.Time) SeatMap {
+	seatMap := SeatMap{Aircraft: flight.Equipment, RowsDisabledCauses: []RowDisabledCause{}, Cabins: []SeatMapCabin{}}
+	if len(seats) > 0 && seats[0].seat.Aircraft != "" {
+		seatMap.Aircraft = seats[0].seat.Aircraft
+	}
+
+	rows := map[int][]exportedSeat{}
+	var rowNumbers []int
+	for _, seat := range seats {
+		if _, ok := rows[seat.seat.RowNumber]; !ok {
+			rowNumbers = append(rowNumbers, seat.seat.RowNumber)
+		}
+		rows[seat.seat.RowNumber] = append(rows[seat.seat.RowNumber], seat)
+	}
+	sort.Ints(rowNumbers)
+
+	var cabinRows [][]int
+	cabinClass := ""
+	for _, number := range rowNumbers {
+		class := rows[number][0].seat.Segment
+		if len(cabinRows) == 0 || class != cabinClass {
+			cabinRows = append(cabinRows, nil)
+			cabinClass = class
+		}
+		cabinRows[len(cabinRows)-1] = append(cabinRows[len(cabinRows)-1], number)
+	}
+
+	for _, numbers := range cabinRows {
+		columns := exportColumns(numbers, rows)
+		cabin := SeatMapCabin{
+			Deck:        "MAIN",
+			SeatColumns: columns,
+			FirstRow:    numbers[0],
+			LastRow:     numbers[len(numbers)-1],
+		}
+		for _, number := range numbers {
+			cabin.SeatRows = append(cabin.SeatRows, exportRow(number, columns, rows[number]))
+			if cause := rowBlockCause(rows[number], now); cause != "" {
+				seatMap.RowsDisabledCauses = append(seatMap.RowsDisabledCauses, RowDisabledCause{RowNumber: number, Cause: cause})
+			}
+		}
+		seatMap.Cabins = append(seatMap.Cabins, cabin)
+	}
+	return seatMap
+}
+
+// exportColumns membentuk seatColumns kabin, misalnya LEFT_SIDE, A, B, C, AISLE, D, E, F, RIGHT_SIDE
+func exportColumns(numbers []int, rows map[int][]exportedSeat) []string {
+	groups := map[int]map[string]bool{}
+	for _, number := range numbers {
+		for _, seat := range rows[number] {
+			if groups[seat.seat.ColumnGroup] == nil {
+				groups[seat.seat.ColumnGroup] = map[string]bool{}
+			}
+			groups[seat.seat.ColumnGroup][seatColumn(seat.seat.SeatCode)] = true
+		}
+	}
+
+	var groupIDs []int
+	for id := range groups {
+		groupIDs = append(groupIDs, id)
+	}
+	sort.Ints(groupIDs)
+
+	columns := []string{"LEFT_SIDE"}
+	for i, id := range groupIDs {
+		if i > 0 {
+			columns = append(columns, "AISLE")
+		}
+		var letters []string
+		for letter := range groups[id] {
+			letters = append(letters, letter)
+		}
+		sort.Strings(letters)
+		columns = append(columns, letters...)
+	}
+	return append(columns, "RIGHT_SIDE")
+}
+
+func exportRow(number int, columns []string, seats []exportedSeat) SeatMapRow {
+	byColumn := map[string]exportedSeat{}
+	for _, seat := range seats {
+		byColumn[seatColumn(seat.seat.SeatCode)] = seat
+	}
+
+	row := SeatMapRow{RowNumber: number}
+	slotCodes := map[string]bool{}
+	for _, column := range columns {
+		var slot SeatMapSeat
+		switch column {
+		case "LEFT_SIDE", "RIGHT_SIDE":
+			slot = SeatMapSeat{SlotCharacteristics: []string{column}, StorefrontSlotCode: "BLANK"}
+		case "AISLE":
+			slot = SeatMapSeat{StorefrontSlotCode: "AISLE"}
+		default:
+			seat, ok := byColumn[column]
+			if !ok {
+				slot = SeatMapSeat{StorefrontSlotCode: "BLANK"}
+				break
+			}
+			slot = exportSeat(seat)
+		}
+		slotCodes[slot.StorefrontSlotCode] = true
+		row.Seats = append(row.Seats, slot)
+	}
+
+	for code := range slotCodes {
+		row.SeatCodes = append(row.SeatCodes, code)
+	}
+	sort.Strings(row.SeatCodes)
+	return row
+}
+
+func exportSeat(seat exportedSeat) SeatMapSeat {
+	return SeatMapSeat{
+		StorefrontSlotCode:  "SEAT",
+		Available:           seat.available,
+		Code:                seat.seat.SeatCode,
+		Designations:        seat.seat.Designations,
+		Entitled:            seat.seat.Entitled,
+		FeeWaived:           seat.seat.FeeWaived,
+		EntitledRuleID:      seat.seat.EntitledRuleID,
+		FeeWaivedRuleID:     seat.seat.FeeWaivedRuleID,
+		SeatCharacteristics: seat.seat.Characteristics,
+		Limitations:         seat.seat.Limitations,
+		FreeOfCharge:        seat.seat.FreeOfCharge,
+		Prices: &SeatMapPrices{Alternatives: [][]SeatMapAmount{{
+			{Amount: seat.price, Currency: seat.seat.Currency},
+		}}},
+	}
+}
+
+// rowBlockCause mengembalikan alasan block jika semua kursi pada baris diblokir dengan alasan yang sama
+func rowBlockCause(seats []exportedSeat, now time.Time) string {
+	cause := ""
+	for _, seat := range seats {
+		if !seat.seat.IsBlocked(now) {
+			return ""
+		}
+		if cause != "" && seat.seat.BlockReason != cause {
+			return ""
+		}
+		cause = seat.seat.BlockReason
+	}
+	return cause
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+// exportJSON mengekspor seat map penerbangan sebagai dokumen JSON
+func exportJSON(t *testing.T, env *testEnv, flightID uint) []byte {
+	t.Helper()
+	doc, err := env.seats.ExportSeatMap(flightID)
+	if err != nil {
+		t.Fatalf("ExportSeatMap: %v", err)
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("marshal export: %v", err)
+	}
+	return data
+}
+
+// reimport mengimpor dokumen ke store baru dan mengembalikan penerbangannya
+func reimport(t *testing.T, data []byte) (*testEnv, uint) {
+	t.Helper()
+	env := newEmptyTestEnv(t)
+	imported, err := env.seats.ImportSeatMapDocument(data, "")
+	if err != nil {
+		t.Fatalf("import exported seat map: %v", err)
+	}
+	if len(imported.Flights) != 1 {
+		t.Fatalf("exported document imported %d flights, want 1", len(imported.Flights))
+	}
+	return env, imported.Flights[0]
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	env := newTestEnv(t)
+	exported := exportJSON(t, env, 1)
+
+	// Tanpa booking dan rule harga, dokumen ekspor sama dengan dokumen sumber setelah di-parse
+	want := renderSeatMap(parseFixture(t, JSONSeatMapSource{}, "../data/SeatMapResponse.json"))
+	parsed, err := JSONSeatMapSource{}.Parse(exported)
+	if err != nil {
+		t.Fatalf("parse export: %v", err)
+	}
+	if got := renderSeatMap(parsed); got != want {
+		t.Errorf("exported seat map differs from the source document:\n%s", got)
+	}
+
+	copied, flightID := reimport(t, exported)
+	original, _ := env.store.Seats().FindByFlight(1)
+	seats, err := copied.store.Seats().FindByFlight(flightID)
+	if err != nil {
+		t.Fatalf("imported seats: %v", err)
+	}
+	if len(seats) != len(original) {
+		t.Fatalf("reimported %d seats, want %d", len(seats), len(original))
+	}
+	for i := range seats {
+		a, b := original[i], seats[i]
+		if a.SeatCode != b.SeatCode || a.Available != b.Available || a.Price != b.Price || a.Currency != b.Currency ||
+			a.Segment != b.Segment || a.ColumnGroup != b.ColumnGroup || a.BlockReason != b.BlockReason || a.SeatEntitlement != b.SeatEntitlement {
+			t.Errorf("reimported seat %+v, want %+v", b, a)
+		}
+	}
+}
+
+func TestExportImportIsStable(t *testing.T) {
+	env := newEmptyTestEnv(t)
+	data, err := os.ReadFile("testdata/seatmap_two_pax.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported, err := env.seats.ImportSeatMapDocument(data, "")
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	flightID := imported.Flights[0]
+
+	user := env.user(t, "budi@example.com")
+	var booked model.Seat
+	for _, seat := range imported.Seats {
+		if seat.Available && seat.Price > 0 {
+			booked = seat
+			break
+		}
+	}
+	if _, err := env.bookings.CreateBooking(user.ID, booked.ID, ""); err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+
+	// Kursi yang dibooking dan kursi pilihan sistem sumber diekspor sebagai tidak available;
+	// setelah diimpor dan diekspor lagi dokumennya tidak berubah
+	first := exportJSON(t, env, flightID)
+	copied, copiedFlight := reimport(t, first)
+	second := exportJSON(t, copied, copiedFlight)
+	if string(first) != string(second) {
+		t.Error("export of the reimported seat map differs from the first export")
+	}
+
+	var doc SeatMapResponse
+	if err := json.Unmarshal(first, &doc); err != nil {
+		t.Fatal(err)
+	}
+	segment := doc.SeatsItineraryParts[0].SegmentSeatMaps[0]
+	if len(segment.PassengerSeatMaps) != 2 || len(doc.SelectedSeats) == 0 {
+		t.Fatalf("export has %d passenger seat maps and %d selected seats, want 2 and the source selection",
+			len(segment.PassengerSeatMaps), len(doc.SelectedSeats))
+	}
+	unavailable := map[string]bool{booked.SeatCode: true}
+	for _, selected := range doc.SelectedSeats {
+		unavailable[selected.SeatCode] = true
+	}
+	for _, pax := range segment.PassengerSeatMaps {
+		for _, cabin := range pax.SeatMap.Cabins {
+			for _, row := range cabin.SeatRows {
+				for _, seat := range row.Seats {
+					if unavailable[seat.Code] && seat.Available {
+						t.Errorf("passenger %d: seat %s is exported as available", pax.Passenger.PassengerIndex, seat.Code)
+					}
+				}
+			}
+		}
+	}
+}
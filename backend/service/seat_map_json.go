@@ -0,0 +1,382 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+// segmentTimeLayout adalah format waktu lokal tanpa offset pada dokumen seat map
+const segmentTimeLayout = "2006-01-02T15:04:05"
+
+// SeatMapResponse adalah dokumen seat map format JSON vendor (format "json"). Setiap segmen punya
+// seat map per penumpang; selectedSeats berisi kursi yang sudah dipilih penumpang di sistem sumber.
+// Struktur yang sama dipakai untuk export seat map.
+type SeatMapResponse struct {
+	SeatsItineraryParts []SeatMapItineraryPart `json:"seatsItineraryParts"`
+	SelectedSeats       []SelectedSeat         `json:"selectedSeats"`
+}
+
+type SeatMapItineraryPart struct {
+	SegmentSeatMaps []SeatMapSegmentSeatMap `json:"segmentSeatMaps"`
+}
+
+type SeatMapSegmentSeatMap struct {
+	PassengerSeatMaps []SeatMapPassengerSeatMap `json:"passengerSeatMaps"`
+	Segment           SeatMapSegment            `json:"segment"`
+}
+
+type SeatMapPassengerSeatMap struct {
+	SeatSelectionEnabledForPax bool             `json:"seatSelectionEnabledForPax"`
+	SeatMap                    SeatMap          `json:"seatMap"`
+	Passenger                  SeatMapPassenger `json:"passenger"`
+}
+
+type SeatMap struct {
+	RowsDisabledCauses []RowDisabledCause `json:"rowsDisabledCauses"`
+	Aircraft           string             `json:"aircraft"`
+	Cabins             []SeatMapCabin     `json:"cabins"`
+}
+
+type SeatMapCabin struct {
+	Deck        string       `json:"deck"`
+	SeatColumns []string     `json:"seatColumns"`
+	SeatRows    []SeatMapRow `json:"seatRows"`
+	FirstRow    int          `json:"firstRow"`
+	LastRow     int          `json:"lastRow"`
+}
+
+type SeatMapRow struct {
+	RowNumber int           `json:"rowNumber"`
+	SeatCodes []string      `json:"seatCodes"`
+	Seats     []SeatMapSeat `json:"seats"`
+}
+
+// SeatMapSeat adalah satu slot pada baris kursi. Hanya slot dengan storefrontSlotCode SEAT yang
+// merupakan kursi; slot lain (AISLE, BLANK, WING, ...) hanya menggambarkan layout.
+type SeatMapSeat struct {
+	SlotCharacteristics []string       `json:"slotCharacteristics,omitempty"`
+	StorefrontSlotCode  string         `json:"storefrontSlotCode"`
+	Available           bool           `json:"available"`
+	Code                string         `json:"code,omitempty"`
+	Designations        []string       `json:"designations,omitempty"`
+	Entitled            bool           `json:"entitled"`
+	FeeWaived           bool           `json:"feeWaived"`
+	EntitledRuleID      string         `json:"entitledRuleId,omitempty"`
+	FeeWaivedRuleID     string         `json:"feeWaivedRuleId,omitempty"`
+	SeatCharacteristics []string       `json:"seatCharacteristics,omitempty"`
+	Limitations         []string       `json:"limitations,omitempty"`
+	FreeOfCharge        bool           `json:"freeOfCharge"`
+	Prices              *SeatMapPrices `json:"prices,omitempty"`
+}
+
+// SeatMapPrices berisi alternatif harga; importer memakai harga pertama dari alternatif pertama
+type SeatMapPrices struct {
+	Alternatives [][]SeatMapAmount `json:"alternatives"`
+}
+
+type SeatMapAmount struct {
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+// SelectedSeat adalah kursi yang sudah dipilih penumpang di sistem sumber. ItineraryPartIndex dan
+// SegmentIndex adalah posisi segmen (dimulai dari 0) pada seatsItineraryParts dan segmentSeatMaps.
+type SelectedSeat struct {
+	ItineraryPartIndex int    `json:"itineraryPartIndex"`
+	SegmentIndex       int    `json:"segmentIndex"`
+	PassengerIndex     int    `json:"passengerIndex"`
+	SeatCode           string `json:"seatCode"`
+}
+
+// JSONSeatMapSource adalah adapter untuk dokumen SeatMapResponse
+type JSONSeatMapSource struct{}
+
+func (JSONSeatMapSource) Format() string {
+	return "json"
+}
+
+func (JSONSeatMapSource) Detect(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '{' && bytes.Contains(data, []byte(`"seatsItineraryParts"`))
+}
+
+func (JSONSeatMapSource) Parse(data []byte) (*CanonicalSeatMap, error) {
+	var response SeatMapResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, ErrSeatMapInvalid.WithDetail("seat map document is invalid: %v", err)
+	}
+
+	seatMap := &CanonicalSeatMap{}
+	positions := map[[2]int]int{}
+	for partIndex, part := range response.SeatsItineraryParts {
+		for segIndex, segMap := range part.SegmentSeatMaps {
+			flight, err := parseSegmentFlight(segMap.Segment)
+			if err != nil {
+				return nil, err
+			}
+
+			// Jam pada seat map vendor tidak membawa offset: jam lokal bandara
+			segment := CanonicalSegment{Flight: *flight, LocalDeparture: true, LocalArrival: true}
+			seenPassengers := map[int]bool{}
+			for _, paxMap := range segMap.PassengerSeatMaps {
+				if index := paxMap.Passenger.PassengerIndex; index != 0 && !seenPassengers[index] {
+					seenPassengers[index] = true
+					segment.Passengers = append(segment.Passengers, parsePassenger(paxMap.Passenger, segMap.Segment))
+				}
+				segment.SeatMaps = append(segment.SeatMaps, PassengerSeatMap{
+					PassengerIndex: paxMap.Passenger.PassengerIndex,
+					Seats:          parseSeats(paxMap.SeatMap),
+				})
+			}
+
+			positions[[2]int{partIndex, segIndex}] = len(seatMap.Segments)
+			seatMap.Segments = append(seatMap.Segments, segment)
+		}
+	}
+
+	for _, selected := range response.SelectedSeats {
+		segment, ok := positions[[2]int{selected.ItineraryPartIndex, selected.SegmentIndex}]
+		if !ok {
+			return nil, ErrSeatMapInvalid.WithDetail("selected seat %s refers to unknown segment %d/%d", selected.SeatCode, selected.ItineraryPartIndex, selected.SegmentIndex)
+		}
+		seatMap.SelectedSeats = append(seatMap.SelectedSeats, CanonicalSelectedSeat{
+			Segment:        segment,
+			PassengerIndex: selected.PassengerIndex,
+			SeatCode:       selected.SeatCode,
+		})
+	}
+	return seatMap, nil
+}
+
+// RowDisabledCause adalah alasan satu baris kursi tidak dijual pada dokumen sumber.
+// Entry berupa string biasa diperlakukan sebagai alasan untuk semua kursi yang tidak available.
+type RowDisabledCause struct {
+	RowNumber int    `json:"rowNumber"`
+	Cause     string `json:"cause"`
+}
+
+func (c *RowDisabledCause) UnmarshalJSON(data []byte) error {
+	var cause string
+	if err := json.Unmarshal(data, &cause); err == nil {
+		*c = RowDisabledCause{Cause: cause}
+		return nil
+	}
+
+	type plain RowDisabledCause
+	return json.Unmarshal(data, (*plain)(c))
+}
+
+// sourceUnavailableReason adalah alasan block untuk kursi yang tidak available di dokumen sumber
+// tanpa alasan yang lebih spesifik
+const sourceUnavailableReason = "UNAVAILABLE_IN_SOURCE"
+
+// SeatMapPassenger adalah penumpang yang menjadi dasar perhitungan seat map sumber
+type SeatMapPassenger struct {
+	PassengerIndex      int    `json:"passengerIndex"`
+	PassengerNameNumber string `json:"passengerNameNumber"`
+	PassengerDetails    struct {
+		FirstName string `json:"firstName"`
+		LastName  string `json:"lastName"`
+	} `json:"passengerDetails"`
+	PassengerInfo struct {
+		DateOfBirth string   `json:"dateOfBirth"`
+		Type        string   `json:"type"`
+		Emails      []string `json:"emails"`
+	} `json:"passengerInfo"`
+	Preferences struct {
+		SpecialPreferences struct {
+			SpecialRequests []SpecialRequest `json:"specialRequests"`
+		} `json:"specialPreferences"`
+		FrequentFlyer []SeatMapFrequentFlyer `json:"frequentFlyer"`
+	} `json:"preferences"`
+}
+
+type SeatMapFrequentFlyer struct {
+	Airline    string `json:"airline"`
+	Number     string `json:"number"`
+	TierNumber int    `json:"tierNumber"`
+}
+
+// SpecialRequest adalah SSR penumpang. Entry berupa string biasa dianggap sebagai kodenya.
+type SpecialRequest struct {
+	Code string `json:"code"`
+}
+
+func (r *SpecialRequest) UnmarshalJSON(data []byte) error {
+	var code string
+	if err := json.Unmarshal(data, &code); err == nil {
+		*r = SpecialRequest{Code: code}
+		return nil
+	}
+
+	type plain SpecialRequest
+	return json.Unmarshal(data, (*plain)(r))
+}
+
+// dateOfBirthLayout adalah format tanggal lahir penumpang pada dokumen seat map
+const dateOfBirthLayout = "2006-01-02"
+
+type SeatMapSegment struct {
+	Equipment string `json:"equipment"`
+	Flight    struct {
+		FlightNumber      int    `json:"flightNumber"`
+		AirlineCode       string `json:"airlineCode"`
+		DepartureTerminal string `json:"departureTerminal"`
+		ArrivalTerminal   string `json:"arrivalTerminal"`
+	} `json:"flight"`
+	Origin      string `json:"origin"`
+	Destination string `json:"destination"`
+	Departure   string `json:"departure"`
+	Arrival     string `json:"arrival"`
+	// BookingClass dan FareBasis adalah kelas tarif penumpang pada segmen ini
+	BookingClass string `json:"bookingClass"`
+	FareBasis    string `json:"fareBasis"`
+}
+
+// parseSeats mengambil kursi yang bisa dijual (storefrontSlotCode SEAT) dari seat map satu penumpang
+func parseSeats(seatMap SeatMap) []model.Seat {
+	var seats []model.Seat
+	disabledRows, generalCause := rowCauses(seatMap.RowsDisabledCauses)
+	for _, cabin := range seatMap.Cabins {
+		groups := columnGroups(cabin.SeatColumns)
+		for _, row := range cabin.SeatRows {
+			for _, seat := range row.Seats {
+				if seat.StorefrontSlotCode != "SEAT" {
+					continue
+				}
+
+				// Get price and currency from the first alternative
+				var price float64
+				var currency string
+				if seat.Prices != nil && len(seat.Prices.Alternatives) > 0 && len(seat.Prices.Alternatives[0]) > 0 {
+					price = seat.Prices.Alternatives[0][0].Amount
+					currency = seat.Prices.Alternatives[0][0].Currency
+				}
+
+				// Check for window or aisle seat
+				isWindow := false
+				isAisle := false
+				for _, char := range seat.SeatCharacteristics {
+					if char == "W" {
+						isWindow = true
+					} else if char == "A" {
+						isAisle = true
+					}
+				}
+
+				// Kursi yang tidak dijual di sumber diimpor sebagai kursi kosong yang diblokir
+				var block model.SeatBlock
+				reason := disabledRows[row.RowNumber]
+				if reason == "" && !seat.Available {
+					reason = generalCause
+				}
+				if reason != "" {
+					block = model.SeatBlock{BlockReason: reason, BlockSource: model.SeatBlockImport}
+				}
+
+				seats = append(seats, model.Seat{
+					SeatCode:        seat.Code,
+					Available:       block.BlockReason == "",
+					Price:           price,
+					Currency:        currency,
+					RowNumber:       row.RowNumber,
+					ColumnGroup:     groups[seatColumn(seat.Code)],
+					Segment:         rowCabin(row.RowNumber),
+					IsWindow:        isWindow,
+					IsAisle:         isAisle,
+					Aircraft:        seatMap.Aircraft,
+					Characteristics: model.StringArray(seat.SeatCharacteristics),
+					Designations:    model.StringArray(seat.Designations),
+					Limitations:     model.StringArray(seat.Limitations),
+					SeatBlock:       block,
+					SeatEntitlement: model.SeatEntitlement{
+						Entitled:        seat.Entitled,
+						EntitledRuleID:  seat.EntitledRuleID,
+						FeeWaived:       seat.FeeWaived,
+						FeeWaivedRuleID: seat.FeeWaivedRuleID,
+						FreeOfCharge:    seat.FreeOfCharge,
+					},
+				})
+			}
+		}
+	}
+	return seats
+}
+
+// parsePassenger mengambil data penumpang yang dipakai untuk evaluasi entitlement.
+// Jika ada beberapa keanggotaan frequent flyer, yang dipakai adalah maskapai penerbangan ini.
+func parsePassenger(pax SeatMapPassenger, segment SeatMapSegment) model.FlightPassenger {
+	passenger := model.FlightPassenger{
+		PassengerIndex: pax.PassengerIndex,
+		NameNumber:     pax.PassengerNameNumber,
+		FirstName:      pax.PassengerDetails.FirstName,
+		LastName:       pax.PassengerDetails.LastName,
+		Type:           pax.PassengerInfo.Type,
+		BookingClass:   segment.BookingClass,
+		FareBasis:      segment.FareBasis,
+	}
+	if dob, err := time.Parse(dateOfBirthLayout, pax.PassengerInfo.DateOfBirth); err == nil {
+		passenger.DateOfBirth = &dob
+	}
+	passenger.SpecialRequests = model.StringArray{}
+	for _, request := range pax.Preferences.SpecialPreferences.SpecialRequests {
+		if code := strings.ToUpper(strings.TrimSpace(request.Code)); code != "" {
+			passenger.SpecialRequests = append(passenger.SpecialRequests, code)
+		}
+	}
+	if len(pax.PassengerInfo.Emails) > 0 {
+		passenger.Email = strings.ToLower(strings.TrimSpace(pax.PassengerInfo.Emails[0]))
+	}
+	for i, ff := range pax.Preferences.FrequentFlyer {
+		if i == 0 || ff.Airline == segment.Flight.AirlineCode {
+			passenger.FrequentFlyerAirline = ff.Airline
+			passenger.FrequentFlyerNumber = ff.Number
+			passenger.FrequentFlyerTier = ff.TierNumber
+		}
+	}
+	return passenger
+}
+
+// rowCauses memisahkan rowsDisabledCauses menjadi alasan per nomor baris dan alasan umum
+func rowCauses(causes []RowDisabledCause) (map[int]string, string) {
+	rows := map[int]string{}
+	general := sourceUnavailableReason
+	for _, cause := range causes {
+		if cause.Cause == "" {
+			continue
+		}
+		if cause.RowNumber == 0 {
+			general = cause.Cause
+		} else if rows[cause.RowNumber] == "" {
+			rows[cause.RowNumber] = cause.Cause
+		}
+	}
+	return rows, general
+}
+
+func parseSegmentFlight(segment SeatMapSegment) (*model.Flight, error) {
+	departure, err := time.Parse(segmentTimeLayout, segment.Departure)
+	if err != nil {
+		return nil, ErrSeatMapInvalid.WithDetail("invalid segment departure %q", segment.Departure)
+	}
+	arrival, err := time.Parse(segmentTimeLayout, segment.Arrival)
+	if err != nil {
+		return nil, ErrSeatMapInvalid.WithDetail("invalid segment arrival %q", segment.Arrival)
+	}
+
+	return &model.Flight{
+		AirlineCode:       segment.Flight.AirlineCode,
+		FlightNumber:      segment.Flight.FlightNumber,
+		Origin:            segment.Origin,
+		Destination:       segment.Destination,
+		Departure:         departure,
+		Arrival:           arrival,
+		DepartureTerminal: segment.Flight.DepartureTerminal,
+		ArrivalTerminal:   segment.Flight.ArrivalTerminal,
+		Equipment:         segment.Equipment,
+	}, nil
+}
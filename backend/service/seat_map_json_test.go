@@ -0,0 +1,29 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestJSONSeatMapSourceGolden(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{"seatmap_response", "../data/SeatMapResponse.json"},
+		// Dua penumpang dengan ketersediaan dan fee waiver berbeda serta satu kursi terpilih
+		{"seatmap_two_pax", "testdata/seatmap_two_pax.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkGolden(t, "json_"+tt.name, parseFixture(t, JSONSeatMapSource{}, tt.path))
+		})
+	}
+}
+
+func TestJSONSeatMapSourceRejectsUnknownSelectedSegment(t *testing.T) {
+	data := []byte(`{"seatsItineraryParts":[],"selectedSeats":[{"itineraryPartIndex":0,"segmentIndex":1,"passengerIndex":1,"seatCode":"4A"}]}`)
+	if _, err := (JSONSeatMapSource{}).Parse(data); !errors.Is(err, ErrSeatMapInvalid) {
+		t.Errorf("Parse err = %v, want ErrSeatMapInvalid", err)
+	}
+}
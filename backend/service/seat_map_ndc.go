@@ -0,0 +1,360 @@
+package service
+
+import (
+	"bytes"
+	"encoding/xml"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+// ndcSeatAvailabilityRS adalah bagian IATA NDC SeatAvailabilityRS yang dipakai untuk import.
+// Tag tanpa namespace sehingga versi skema NDC yang berbeda tetap terbaca.
+type ndcSeatAvailabilityRS struct {
+	Errors []struct {
+		Code     string `xml:"Code"`
+		DescText string `xml:"DescText"`
+	} `xml:"Error"`
+	Response struct {
+		OfferItems []ndcOfferItem `xml:"ALaCarteOffer>ALaCarteOfferItem"`
+		DataLists  struct {
+			ContactInfos []struct {
+				ContactInfoID string `xml:"ContactInfoID"`
+				Email         string `xml:"EmailAddress>EmailAddressText"`
+			} `xml:"ContactInfoList>ContactInfo"`
+			Paxes        []ndcPax        `xml:"PaxList>Pax"`
+			PaxSegments  []ndcPaxSegment `xml:"PaxSegmentList>PaxSegment"`
+			SeatProfiles []struct {
+				SeatProfileID   string   `xml:"SeatProfileID"`
+				Characteristics []string `xml:"SeatCharacteristicCode"`
+			} `xml:"SeatProfileList>SeatProfile"`
+		} `xml:"DataLists"`
+		SeatMaps []ndcSeatMap `xml:"SeatMap"`
+	} `xml:"Response"`
+}
+
+// ndcOfferItem adalah harga kursi untuk penumpang dan segmen pada Eligibility-nya.
+// Eligibility yang kosong berlaku untuk semua penumpang atau semua segmen.
+type ndcOfferItem struct {
+	OfferItemID   string   `xml:"OfferItemID"`
+	PaxRefIDs     []string `xml:"Eligibility>PaxRefID"`
+	SegmentRefIDs []string `xml:"Eligibility>FlightAssociations>PaxSegmentRefID"`
+	TotalAmount   struct {
+		Amount  string `xml:",chardata"`
+		CurCode string `xml:"CurCode,attr"`
+	} `xml:"UnitPriceDetail>TotalAmount"`
+}
+
+type ndcPax struct {
+	PaxID            string `xml:"PaxID"`
+	PTC              string `xml:"PTC"`
+	Birthdate        string `xml:"Birthdate"`
+	GivenName        string `xml:"Individual>GivenName"`
+	Surname          string `xml:"Individual>Surname"`
+	ContactInfoRefID string `xml:"ContactInfoRefID"`
+	Loyalty          []struct {
+		AccountNumber string `xml:"AccountNumber"`
+		Airline       string `xml:"LoyaltyProgram>Carrier>AirlineDesigCode"`
+	} `xml:"LoyaltyProgramAccount"`
+}
+
+type ndcPaxSegment struct {
+	PaxSegmentID string `xml:"PaxSegmentID"`
+	Origin       string `xml:"Dep>IATA_LocationCode"`
+	Departure    string `xml:"Dep>AircraftScheduledDateTime"`
+	DepTerminal  string `xml:"Dep>TerminalName"`
+	Destination  string `xml:"Arrival>IATA_LocationCode"`
+	Arrival      string `xml:"Arrival>AircraftScheduledDateTime"`
+	ArrTerminal  string `xml:"Arrival>TerminalName"`
+	AirlineCode  string `xml:"MarketingCarrierInfo>CarrierDesigCode"`
+	FlightNumber string `xml:"MarketingCarrierInfo>MarketingCarrierFlightNumberText"`
+	BookingClass string `xml:"MarketingCarrierInfo>MarketingCarrierRBD_Code"`
+	AircraftType string `xml:"DatedOperatingLeg>CarrierAircraftType>CarrierAircraftTypeCode"`
+	IATAAircraft string `xml:"DatedOperatingLeg>IATA_AircraftType>IATA_AircraftTypeCode"`
+}
+
+type ndcSeatMap struct {
+	PaxSegmentRefID string `xml:"PaxSegmentRefID"`
+	Cabins          []struct {
+		CabinTypeCode string `xml:"CabinType>CabinTypeCode"`
+		CabinTypeName string `xml:"CabinType>CabinTypeName"`
+		Columns       []struct {
+			Position string `xml:"Position,attr"`
+			Type     string `xml:",chardata"`
+		} `xml:"CabinLayout>Columns"`
+		Rows []struct {
+			RowNumber int       `xml:"RowNumber"`
+			Seats     []ndcSeat `xml:"Seat"`
+		} `xml:"SeatRow"`
+	} `xml:"CabinCompartment"`
+}
+
+type ndcSeat struct {
+	ColumnID             string   `xml:"ColumnID"`
+	OccupationStatusCode string   `xml:"OccupationStatusCode"`
+	Characteristics      []string `xml:"SeatCharacteristicCode"`
+	OfferItemRefIDs      []string `xml:"OfferItemRefs>OfferItemRefID"`
+	SeatProfileRefIDs    []string `xml:"SeatProfileRefID"`
+}
+
+// ndcSeatFree adalah OccupationStatusCode untuk kursi yang masih kosong
+const ndcSeatFree = "F"
+
+// NDCSeatMapSource adalah adapter untuk IATA NDC SeatAvailabilityRS (format "ndc"). Setiap Pax
+// mendapat PassengerIndex sesuai urutan PaxList; harga per penumpang diambil dari ALaCarteOfferItem
+// yang dirujuk kursi dan berlaku untuk penumpang serta segmen tersebut.
+type NDCSeatMapSource struct{}
+
+func (NDCSeatMapSource) Format() string {
+	return "ndc"
+}
+
+func (NDCSeatMapSource) Detect(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '<' && bytes.Contains(data, []byte("SeatAvailabilityRS"))
+}
+
+func (NDCSeatMapSource) Parse(data []byte) (*CanonicalSeatMap, error) {
+	var rs ndcSeatAvailabilityRS
+	if err := xml.Unmarshal(data, &rs); err != nil {
+		return nil, ErrSeatMapInvalid.WithDetail("NDC seat availability document is invalid: %v", err)
+	}
+	if len(rs.Response.SeatMaps) == 0 && len(rs.Errors) > 0 {
+		return nil, ErrSeatMapInvalid.WithDetail("NDC seat availability response contains error %s: %s", rs.Errors[0].Code, rs.Errors[0].DescText)
+	}
+
+	lists := rs.Response.DataLists
+	emails := map[string]string{}
+	for _, contact := range lists.ContactInfos {
+		emails[contact.ContactInfoID] = strings.ToLower(strings.TrimSpace(contact.Email))
+	}
+	paxIndexes := map[string]int{}
+	for i, pax := range lists.Paxes {
+		paxIndexes[pax.PaxID] = i + 1
+	}
+	segments := map[string]ndcPaxSegment{}
+	for _, segment := range lists.PaxSegments {
+		segments[segment.PaxSegmentID] = segment
+	}
+	profiles := map[string][]string{}
+	for _, profile := range lists.SeatProfiles {
+		profiles[profile.SeatProfileID] = profile.Characteristics
+	}
+	items := map[string]ndcOfferItem{}
+	for _, item := range rs.Response.OfferItems {
+		items[item.OfferItemID] = item
+	}
+
+	seatMap := &CanonicalSeatMap{}
+	for _, ndcMap := range rs.Response.SeatMaps {
+		paxSegment, ok := segments[ndcMap.PaxSegmentRefID]
+		if !ok {
+			return nil, ErrSeatMapInvalid.WithDetail("seat map refers to unknown pax segment %q", ndcMap.PaxSegmentRefID)
+		}
+		segment, err := parseNDCSegment(paxSegment)
+		if err != nil {
+			return nil, err
+		}
+		for _, pax := range lists.Paxes {
+			segment.Passengers = append(segment.Passengers, parseNDCPax(pax, paxIndexes[pax.PaxID], emails, paxSegment))
+		}
+
+		// Tanpa PaxList, seat map dibaca sekali dengan harga yang tidak terikat penumpang
+		paxIDs := []string{""}
+		if len(lists.Paxes) > 0 {
+			paxIDs = paxIDs[:0]
+			for _, pax := range lists.Paxes {
+				paxIDs = append(paxIDs, pax.PaxID)
+			}
+		}
+		for _, paxID := range paxIDs {
+			segment.SeatMaps = append(segment.SeatMaps, PassengerSeatMap{
+				PassengerIndex: paxIndexes[paxID],
+				Seats:          parseNDCSeats(ndcMap, paxID, segment.Flight.Equipment, items, profiles),
+			})
+		}
+		seatMap.Segments = append(seatMap.Segments, segment)
+	}
+	return seatMap, nil
+}
+
+// parseNDCSeats mengambil kursi satu SeatMap dengan harga dan ketersediaan untuk paxID.
+// Kursi yang merujuk offer item tetapi tidak ada yang berlaku untuk paxID tidak dijual ke penumpang itu.
+func parseNDCSeats(ndcMap ndcSeatMap, paxID, aircraft string, items map[string]ndcOfferItem, profiles map[string][]string) []model.Seat {
+	var seats []model.Seat
+	for _, cabin := range ndcMap.Cabins {
+		var columns []string
+		aisles := map[string]bool{}
+		for _, column := range cabin.Columns {
+			position := strings.TrimSpace(column.Position)
+			// Dua kolom aisle yang berurutan mengapit lorong
+			if len(columns) > 0 && aisles[columns[len(columns)-1]] && isNDCAisle(column.Type) {
+				columns = append(columns, "AISLE")
+			}
+			columns = append(columns, position)
+			aisles[position] = isNDCAisle(column.Type)
+		}
+		groups := columnGroups(columns)
+
+		for _, row := range cabin.Rows {
+			for _, ndcSeat := range row.Seats {
+				column := strings.TrimSpace(ndcSeat.ColumnID)
+				characteristics := append([]string{}, ndcSeat.Characteristics...)
+				for _, ref := range ndcSeat.SeatProfileRefIDs {
+					characteristics = append(characteristics, profiles[ref]...)
+				}
+
+				price, currency, offered := ndcSeatPrice(ndcSeat.OfferItemRefIDs, paxID, ndcMap.PaxSegmentRefID, items)
+				available := strings.EqualFold(strings.TrimSpace(ndcSeat.OccupationStatusCode), ndcSeatFree) && offered
+
+				var block model.SeatBlock
+				if !available {
+					block = model.SeatBlock{BlockReason: sourceUnavailableReason, BlockSource: model.SeatBlockImport}
+				}
+
+				seats = append(seats, model.Seat{
+					SeatCode:        strconv.Itoa(row.RowNumber) + column,
+					Available:       available,
+					Price:           price,
+					Currency:        currency,
+					RowNumber:       row.RowNumber,
+					ColumnGroup:     groups[column],
+					Segment:         ndcCabin(cabin.CabinTypeCode, cabin.CabinTypeName, row.RowNumber),
+					IsWindow:        slices.Contains(characteristics, "W"),
+					IsAisle:         slices.Contains(characteristics, "A"),
+					Aircraft:        aircraft,
+					Characteristics: model.StringArray(characteristics),
+					Designations:    model.StringArray{},
+					Limitations:     model.StringArray{},
+					SeatBlock:       block,
+				})
+			}
+		}
+	}
+	return seats
+}
+
+// ndcSeatPrice memilih offer item pertama yang dirujuk kursi dan berlaku untuk paxID dan segmen.
+// Kursi tanpa rujukan offer item dianggap gratis; offered false berarti tidak ada offer yang berlaku.
+func ndcSeatPrice(refs []string, paxID, segmentID string, items map[string]ndcOfferItem) (float64, string, bool) {
+	if len(refs) == 0 {
+		return 0, "", true
+	}
+	for _, ref := range refs {
+		item, ok := items[ref]
+		if !ok {
+			continue
+		}
+		if paxID != "" && len(item.PaxRefIDs) > 0 && !slices.Contains(item.PaxRefIDs, paxID) {
+			continue
+		}
+		if len(item.SegmentRefIDs) > 0 && !slices.Contains(item.SegmentRefIDs, segmentID) {
+			continue
+		}
+		amount, err := strconv.ParseFloat(strings.TrimSpace(item.TotalAmount.Amount), 64)
+		if err != nil {
+			continue
+		}
+		return amount, item.TotalAmount.CurCode, true
+	}
+	return 0, "", false
+}
+
+func parseNDCSegment(segment ndcPaxSegment) (CanonicalSegment, error) {
+	departure, localDeparture, err := parseNDCTime(segment.Departure)
+	if err != nil {
+		return CanonicalSegment{}, ErrSeatMapInvalid.WithDetail("invalid departure %q on pax segment %s", segment.Departure, segment.PaxSegmentID)
+	}
+	arrival, localArrival, err := parseNDCTime(segment.Arrival)
+	if err != nil {
+		return CanonicalSegment{}, ErrSeatMapInvalid.WithDetail("invalid arrival %q on pax segment %s", segment.Arrival, segment.PaxSegmentID)
+	}
+	flightNumber, err := strconv.Atoi(strings.TrimSpace(segment.FlightNumber))
+	if err != nil {
+		return CanonicalSegment{}, ErrSeatMapInvalid.WithDetail("invalid flight number %q on pax segment %s", segment.FlightNumber, segment.PaxSegmentID)
+	}
+
+	equipment := segment.AircraftType
+	if equipment == "" {
+		equipment = segment.IATAAircraft
+	}
+	flight := model.Flight{
+		AirlineCode:       strings.TrimSpace(segment.AirlineCode),
+		FlightNumber:      flightNumber,
+		Origin:            segment.Origin,
+		Destination:       segment.Destination,
+		Departure:         departure,
+		Arrival:           arrival,
+		DepartureTerminal: segment.DepTerminal,
+		ArrivalTerminal:   segment.ArrTerminal,
+		Equipment:         equipment,
+	}
+	return CanonicalSegment{Flight: flight, LocalDeparture: localDeparture, LocalArrival: localArrival}, nil
+}
+
+// parseNDCTime membaca xs:dateTime, dengan atau tanpa offset zona waktu.
+// local bernilai true jika tanpa offset, artinya jam lokal bandara.
+func parseNDCTime(value string) (t time.Time, local bool, err error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(segmentTimeLayout, value); err == nil {
+		return t, true, nil
+	}
+	t, err = time.Parse(time.RFC3339, value)
+	return t, false, err
+}
+
+// parseNDCPax mengambil data penumpang untuk entitlement dan kelayakan kursi.
+// Keanggotaan frequent flyer maskapai segmen didahulukan.
+func parseNDCPax(pax ndcPax, index int, emails map[string]string, segment ndcPaxSegment) model.FlightPassenger {
+	passenger := model.FlightPassenger{
+		PassengerIndex:  index,
+		NameNumber:      pax.PaxID,
+		FirstName:       pax.GivenName,
+		LastName:        pax.Surname,
+		Email:           emails[pax.ContactInfoRefID],
+		Type:            pax.PTC,
+		SpecialRequests: model.StringArray{},
+		BookingClass:    segment.BookingClass,
+	}
+	if dob, err := time.Parse(dateOfBirthLayout, strings.TrimSpace(pax.Birthdate)); err == nil {
+		passenger.DateOfBirth = &dob
+	}
+	for i, account := range pax.Loyalty {
+		if i == 0 || account.Airline == segment.AirlineCode {
+			passenger.FrequentFlyerAirline = account.Airline
+			passenger.FrequentFlyerNumber = account.AccountNumber
+		}
+	}
+	return passenger
+}
+
+// isNDCAisle melaporkan apakah tipe kolom CabinLayout adalah kolom di sebelah lorong
+func isNDCAisle(columnType string) bool {
+	return strings.EqualFold(strings.TrimSpace(columnType), "A")
+}
+
+// ndcCabin memetakan CabinType NDC ke kabin; tanpa CabinType, kabin ditentukan dari nomor baris
+func ndcCabin(code, name string, rowNumber int) string {
+	switch strings.ToUpper(strings.TrimSpace(code)) {
+	case "F", "P":
+		return "FIRST"
+	case "C", "J":
+		return "BUSINESS"
+	case "W", "Y", "M":
+		return "ECONOMY"
+	}
+
+	name = strings.ToUpper(name)
+	switch {
+	case strings.Contains(name, "FIRST"):
+		return "FIRST"
+	case strings.Contains(name, "BUSINESS"):
+		return "BUSINESS"
+	case strings.Contains(name, "ECONOMY"):
+		return "ECONOMY"
+	}
+	return rowCabin(rowNumber)
+}
@@ -0,0 +1,17 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNDCSeatMapSourceGolden(t *testing.T) {
+	checkGolden(t, "ndc_seat_availability", parseFixture(t, NDCSeatMapSource{}, "../data/SeatAvailabilityRS.xml"))
+}
+
+func TestNDCSeatMapSourceRejectsMalformedXML(t *testing.T) {
+	data := []byte(`<IATA_SeatAvailabilityRS><Response>`)
+	if _, err := (NDCSeatMapSource{}).Parse(data); !errors.Is(err, ErrSeatMapInvalid) {
+		t.Errorf("Parse err = %v, want ErrSeatMapInvalid", err)
+	}
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+// SeatMapSource menerjemahkan satu format dokumen seat map dari partner ke CanonicalSeatMap
+type SeatMapSource interface {
+	// Format adalah nama format untuk memilih adapter secara eksplisit, misalnya "ndc"
+	Format() string
+	// Detect melaporkan apakah data berformat ini; dipakai jika format tidak disebutkan
+	Detect(data []byte) bool
+	Parse(data []byte) (*CanonicalSeatMap, error)
+}
+
+// DefaultSeatMapSources mengembalikan adapter bawaan: JSON vendor lama dan NDC SeatAvailabilityRS
+func DefaultSeatMapSources() []SeatMapSource {
+	return []SeatMapSource{JSONSeatMapSource{}, NDCSeatMapSource{}}
+}
+
+// CanonicalSeatMap adalah seat map sumber setelah diterjemahkan adapter, apa pun formatnya
+type CanonicalSeatMap struct {
+	Segments      []CanonicalSegment
+	SelectedSeats []CanonicalSelectedSeat
+}
+
+// CanonicalSegment adalah satu segmen penerbangan beserta penumpang dan seat map per penumpang
+type CanonicalSegment struct {
+	Flight model.Flight
+	// LocalDeparture/LocalArrival berarti jam pada Flight ditulis tanpa offset dan merupakan
+	// jam lokal bandara asal/tujuan; zonanya dilengkapi dari tabel bandara saat import
+	LocalDeparture bool
+	LocalArrival   bool
+	Passengers     []model.FlightPassenger
+	SeatMaps       []PassengerSeatMap
+}
+
+// PassengerSeatMap adalah kursi yang ditawarkan ke satu penumpang dengan harga dan ketersediaan
+// untuk penumpang tersebut. PassengerIndex 0 berarti seat map yang tidak terkait penumpang.
+type PassengerSeatMap struct {
+	PassengerIndex int
+	Seats          []model.Seat
+}
+
+// CanonicalSelectedSeat adalah kursi yang sudah dipilih penumpang di sistem sumber.
+// Segment adalah posisi segmen pada CanonicalSeatMap.Segments.
+type CanonicalSelectedSeat struct {
+	Segment        int
+	PassengerIndex int
+	SeatCode       string
+}
+
+// ImportedSegment adalah hasil parse satu segmen dokumen seat map: penerbangan beserta inventory
+// kursinya, penumpang, offer per penumpang dan kursi yang sudah dipilih. Offer dan assignment
+// dihubungkan ke kursi lewat SeatCode; SeatID diisi setelah kursi disimpan.
+type ImportedSegment struct {
+	Flight         *model.Flight
+	LocalDeparture bool
+	LocalArrival   bool
+	Seats          []model.Seat
+	Passengers     []model.FlightPassenger
+	Offers         []model.SeatOffer
+	Assignments    []model.SeatAssignment
+}
+
+// ParseSeatMap menerjemahkan dokumen dengan source lalu membentuk satu ImportedSegment per segmen.
+// Kursi dengan kode yang sama pada seat map beberapa penumpang menjadi satu kursi inventory;
+// kursi tersebut hanya diblokir jika tidak dijual untuk semua penumpang.
+func ParseSeatMap(source SeatMapSource, data []byte) ([]*ImportedSegment, error) {
+	seatMap, err := source.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(seatMap.Segments) == 0 {
+		return nil, ErrSeatMapInvalid.WithDetail("seat map does not contain any segment")
+	}
+
+	segments := make([]*ImportedSegment, 0, len(seatMap.Segments))
+	designators := map[string]bool{}
+	for _, canonical := range seatMap.Segments {
+		flight := canonical.Flight
+		designator := fmt.Sprintf("%s%d %s", flight.AirlineCode, flight.FlightNumber, flight.Departure.Format(segmentTimeLayout))
+		if designators[designator] {
+			return nil, ErrSeatMapInvalid.WithDetail("segment %s appears more than once", designator)
+		}
+		designators[designator] = true
+
+		segment := &ImportedSegment{
+			Flight:         &flight,
+			LocalDeparture: canonical.LocalDeparture,
+			LocalArrival:   canonical.LocalArrival,
+			Passengers:     canonical.Passengers,
+		}
+		inventory := map[string]int{}
+		offered := map[int]bool{}
+		for _, paxMap := range canonical.SeatMaps {
+			// Seat map kedua untuk penumpang yang sama hanya menambah inventory, bukan offer
+			index := paxMap.PassengerIndex
+			withOffers := index != 0 && !offered[index]
+			offered[index] = true
+
+			codes := map[string]bool{}
+			for _, seat := range paxMap.Seats {
+				if codes[seat.SeatCode] {
+					return nil, ErrSeatMapInvalid.WithDetail("seat map for segment %s contains duplicate seat code %s", designator, seat.SeatCode)
+				}
+				codes[seat.SeatCode] = true
+
+				if withOffers {
+					segment.Offers = append(segment.Offers, model.SeatOffer{
+						PassengerIndex:  index,
+						SeatCode:        seat.SeatCode,
+						Available:       seat.Available,
+						Price:           seat.Price,
+						Currency:        seat.Currency,
+						SeatEntitlement: seat.SeatEntitlement,
+					})
+				}
+
+				i, ok := inventory[seat.SeatCode]
+				if !ok {
+					inventory[seat.SeatCode] = len(segment.Seats)
+					segment.Seats = append(segment.Seats, seat)
+				} else if seat.Available && !segment.Seats[i].Available {
+					segment.Seats[i].Available = true
+					segment.Seats[i].SeatBlock = model.SeatBlock{}
+				}
+			}
+		}
+		segments = append(segments, segment)
+	}
+
+	for _, selected := range seatMap.SelectedSeats {
+		if selected.Segment < 0 || selected.Segment >= len(segments) {
+			return nil, ErrSeatMapInvalid.WithDetail("selected seat %s refers to an unknown segment", selected.SeatCode)
+		}
+		if err := assignSelectedSeat(segments[selected.Segment], selected); err != nil {
+			return nil, err
+		}
+	}
+
+	return segments, nil
+}
+
+// assignSelectedSeat mencatat kursi pilihan penumpang pada segmen dan mengeluarkannya dari penjualan
+func assignSelectedSeat(segment *ImportedSegment, selected CanonicalSelectedSeat) error {
+	code := strings.ToUpper(strings.TrimSpace(selected.SeatCode))
+	seat := -1
+	for i := range segment.Seats {
+		if segment.Seats[i].SeatCode == code {
+			seat = i
+			break
+		}
+	}
+	if seat < 0 {
+		return ErrSeatMapInvalid.WithDetail("selected seat %s is not on the seat map of its segment", code)
+	}
+
+	for _, assignment := range segment.Assignments {
+		if assignment.SeatCode == code {
+			return ErrSeatMapInvalid.WithDetail("seat %s is selected by more than one passenger", code)
+		}
+		if assignment.PassengerIndex == selected.PassengerIndex {
+			return ErrSeatMapInvalid.WithDetail("passenger %d has more than one selected seat on a segment", selected.PassengerIndex)
+		}
+	}
+
+	segment.Assignments = append(segment.Assignments, model.SeatAssignment{
+		PassengerIndex: selected.PassengerIndex,
+		SeatCode:       code,
+	})
+	segment.Seats[seat].Available = false
+	return nil
+}
+
+// columnGroups memetakan huruf kolom ke kelompok kursi di antara lorong, berdasarkan urutan
+// seatColumns kabin (misalnya LEFT_SIDE, A, B, C, AISLE, D, E, F, RIGHT_SIDE)
+func columnGroups(columns []string) map[string]int {
+	groups := map[string]int{}
+	group, seen := 0, false
+	for _, column := range columns {
+		switch column {
+		case "LEFT_SIDE", "RIGHT_SIDE":
+		case "AISLE":
+			if seen {
+				group++
+				seen = false
+			}
+		default:
+			groups[column] = group
+			seen = true
+		}
+	}
+	return groups
+}
+
+// seatColumn mengambil huruf kolom dari kode kursi, misalnya "12A" menjadi "A"
+func seatColumn(code string) string {
+	return strings.TrimLeft(code, "0123456789")
+}
+
+// rowCabin adalah kabin berdasarkan nomor baris, untuk dokumen yang tidak menyebutkan kabin
+func rowCabin(rowNumber int) string {
+	if rowNumber <= 2 {
+		return "FIRST"
+	} else if rowNumber <= 7 {
+		return "BUSINESS"
+	}
+	return "ECONOMY"
+}
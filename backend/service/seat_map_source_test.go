@@ -0,0 +1,123 @@
+package service
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var updateGolden = flag.Bool("update", false, "tulis ulang file golden di testdata")
+
+// renderSeatMap menulis CanonicalSeatMap sebagai teks satu baris per penumpang dan per kursi
+// supaya selisih hasil adapter mudah dibaca di diff
+func renderSeatMap(seatMap *CanonicalSeatMap) string {
+	var b strings.Builder
+	for i, segment := range seatMap.Segments {
+		f := segment.Flight
+		fmt.Fprintf(&b, "segment %d %s%d %s-%s %s (local %v) - %s (local %v) equipment %s terminal %q-%q\n",
+			i, f.AirlineCode, f.FlightNumber, f.Origin, f.Destination,
+			f.Departure.Format(time.RFC3339), segment.LocalDeparture, f.Arrival.Format(time.RFC3339), segment.LocalArrival,
+			f.Equipment, f.DepartureTerminal, f.ArrivalTerminal)
+		for _, pax := range segment.Passengers {
+			dob := ""
+			if pax.DateOfBirth != nil {
+				dob = pax.DateOfBirth.Format("2006-01-02")
+			}
+			fmt.Fprintf(&b, "  passenger %d %s %s/%s %s dob=%s email=%s ssr=%v ff=%s%s tier=%d class=%s fare=%s\n",
+				pax.PassengerIndex, pax.NameNumber, pax.LastName, pax.FirstName, pax.Type, dob, pax.Email,
+				[]string(pax.SpecialRequests), pax.FrequentFlyerAirline, pax.FrequentFlyerNumber, pax.FrequentFlyerTier,
+				pax.BookingClass, pax.FareBasis)
+		}
+		for _, seats := range segment.SeatMaps {
+			fmt.Fprintf(&b, "  seatmap passenger %d: %d seats\n", seats.PassengerIndex, len(seats.Seats))
+			for _, seat := range seats.Seats {
+				fmt.Fprintf(&b, "    %-4s row %d group %d %s available=%v price=%.2f %s window=%v aisle=%v aircraft=%s",
+					seat.SeatCode, seat.RowNumber, seat.ColumnGroup, seat.Segment, seat.Available, seat.Price, seat.Currency,
+					seat.IsWindow, seat.IsAisle, seat.Aircraft)
+				fmt.Fprintf(&b, " entitled=%v/%s waived=%v/%s free=%v",
+					seat.Entitled, seat.EntitledRuleID, seat.FeeWaived, seat.FeeWaivedRuleID, seat.FreeOfCharge)
+				if seat.BlockReason != "" {
+					fmt.Fprintf(&b, " block=%s/%s", seat.BlockSource, seat.BlockReason)
+				}
+				fmt.Fprintf(&b, " chars=%v designations=%v limitations=%v\n",
+					[]string(seat.Characteristics), []string(seat.Designations), []string(seat.Limitations))
+			}
+		}
+	}
+	for _, selected := range seatMap.SelectedSeats {
+		fmt.Fprintf(&b, "selected segment %d passenger %d %s\n", selected.Segment, selected.PassengerIndex, selected.SeatCode)
+	}
+	return b.String()
+}
+
+// checkGolden membandingkan hasil parse dengan testdata/<name>.golden; jalankan
+// go test ./service -update untuk menulis ulang file golden setelah perubahan yang disengaja
+func checkGolden(t *testing.T, name string, seatMap *CanonicalSeatMap) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	got := renderSeatMap(seatMap)
+	if *updateGolden {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file: %v", err)
+	}
+	if got != string(want) {
+		gotLines, wantLines := strings.Split(got, "\n"), strings.Split(string(want), "\n")
+		for i := 0; i < len(gotLines) || i < len(wantLines); i++ {
+			var g, w string
+			if i < len(gotLines) {
+				g = gotLines[i]
+			}
+			if i < len(wantLines) {
+				w = wantLines[i]
+			}
+			if g != w {
+				t.Fatalf("%s differs at line %d:\n got: %s\nwant: %s", path, i+1, g, w)
+			}
+		}
+	}
+}
+
+// parseFixture membaca dan mem-parse satu dokumen contoh dengan adapter yang diberikan
+func parseFixture(t *testing.T, source SeatMapSource, path string) *CanonicalSeatMap {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !source.Detect(data) {
+		t.Errorf("%s adapter does not detect %s", source.Format(), path)
+	}
+	seatMap, err := source.Parse(data)
+	if err != nil {
+		t.Fatalf("parse %s: %v", path, err)
+	}
+	return seatMap
+}
+
+func TestSeatMapSourcesDetectOnlyTheirFormat(t *testing.T) {
+	fixtures := map[string]string{
+		"json": "../data/SeatMapResponse.json",
+		"ndc":  "../data/SeatAvailabilityRS.xml",
+	}
+	for _, source := range DefaultSeatMapSources() {
+		for format, path := range fixtures {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := source.Detect(data), format == source.Format(); got != want {
+				t.Errorf("%s.Detect(%s) = %v, want %v", source.Format(), path, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/repository"
+)
+
+// SeatMapSyncService menarik seat map dari upstream provider secara berkala dan mengimpornya
+// tanpa menghapus kursi yang sudah dibooking. Setiap sync dicatat sebagai SeatMapSync.
+type SeatMapSyncService struct {
+	store    repository.Store
+	seats    *SeatService
+	upstream SeatMapUpstream
+	// running mencegah dua sync berjalan bersamaan di proses yang sama
+	running sync.Mutex
+}
+
+// NewSeatMapSyncService membuat service sync; upstream nil berarti sync dinonaktifkan
+func NewSeatMapSyncService(store repository.Store, seats *SeatService, upstream SeatMapUpstream) *SeatMapSyncService {
+	return &SeatMapSyncService{store: store, seats: seats, upstream: upstream}
+}
+
+// Enabled melaporkan apakah upstream seat map sudah dikonfigurasi
+func (s *SeatMapSyncService) Enabled() bool {
+	return s.upstream != nil
+}
+
+// Sync mengambil semua dokumen dari upstream lalu mengimpor setiap dokumen dalam transaksinya
+// sendiri. Dokumen yang gagal tidak membatalkan dokumen lain; kegagalannya dicatat pada sync run.
+// actorID diisi untuk sync manual oleh admin.
+func (s *SeatMapSyncService) Sync(ctx context.Context, trigger string, actorID *uint) (*model.SeatMapSync, error) {
+	if s.upstream == nil {
+		return nil, ErrSeatMapSyncDisabled
+	}
+	if !s.running.TryLock() {
+		return nil, ErrSeatMapSyncRunning
+	}
+	defer s.running.Unlock()
+
+	run := &model.SeatMapSync{
+		StartedAt:   time.Now(),
+		Trigger:     trigger,
+		TriggeredBy: actorID,
+		Source:      s.upstream.Name(),
+		Status:      model.SyncRunning,
+	}
+	if err := s.store.SeatMapSyncs().Create(run); err != nil {
+		return nil, err
+	}
+
+	var failures []string
+	documents, fetchErr := s.upstream.Fetch(ctx)
+	if fetchErr != nil {
+		failures = append(failures, fmt.Sprintf("fetch: %v", fetchErr))
+	}
+
+	flights := map[uint]bool{}
+	for _, document := range documents {
+		result, err := s.seats.ImportSeatMapDocument(document.Data, document.Format)
+		if err != nil {
+			run.Failed++
+			failures = append(failures, fmt.Sprintf("%s: %v", document.Name, err))
+			continue
+		}
+		run.SeatMapDiff.Add(result.Diff)
+		for _, flightID := range result.Flights {
+			flights[flightID] = true
+		}
+	}
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Documents = len(documents)
+	run.Flights = len(flights)
+	run.Error = strings.Join(failures, "\n")
+	switch {
+	case len(failures) == 0:
+		run.Status = model.SyncSucceeded
+	case fetchErr == nil && run.Failed < run.Documents:
+		run.Status = model.SyncPartial
+	default:
+		run.Status = model.SyncFailed
+	}
+	if err := s.store.SeatMapSyncs().Update(run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// RunScheduler menjalankan sync sekali saat start lalu setiap interval sampai ctx selesai
+func (s *SeatMapSyncService) RunScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		run, err := s.Sync(ctx, model.SyncTriggerScheduled, nil)
+		switch {
+		case err != nil:
+			log.Printf("failed to sync seat maps: %v", err)
+		case run.Status != model.SyncSucceeded:
+			log.Printf("seat map sync %d %s: %s", run.ID, run.Status, run.Error)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncRuns mengembalikan riwayat sync, terbaru dulu
+func (s *SeatMapSyncService) SyncRuns(pagination Pagination) (*Page[model.SeatMapSync], error) {
+	pagination = pagination.normalize()
+
+	runs, total, err := s.store.SeatMapSyncs().Search(pagination.offset(), pagination.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	if runs == nil {
+		runs = []model.SeatMapSync{}
+	}
+	return &Page[model.SeatMapSync]{Items: runs, Total: total, Page: pagination.Page, PageSize: pagination.PageSize}, nil
+}
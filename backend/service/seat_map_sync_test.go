@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+// fakeUpstream adalah upstream seat map lokal. failures adalah jumlah request pertama per path
+// yang dijawab 503 sebelum dokumennya dikirim.
+type fakeUpstream struct {
+	*httptest.Server
+	documents map[string][]byte
+	failures  atomic.Int32
+	hits      atomic.Int32
+}
+
+func newFakeUpstream(t *testing.T, documents map[string][]byte, failures int) *fakeUpstream {
+	t.Helper()
+	upstream := &fakeUpstream{documents: documents}
+	upstream.failures.Store(int32(failures))
+	var lock sync.Mutex
+	seen := map[string]int{}
+	upstream.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstream.hits.Add(1)
+		if r.Header.Get("Authorization") != "Bearer sync-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		lock.Lock()
+		seen[r.URL.Path]++
+		attempt := seen[r.URL.Path]
+		lock.Unlock()
+		document, ok := upstream.documents[r.URL.Path]
+		switch {
+		case !ok:
+			http.NotFound(w, r)
+		case attempt <= int(upstream.failures.Load()):
+			http.Error(w, "try again later", http.StatusServiceUnavailable)
+		default:
+			w.Write(document)
+		}
+	}))
+	t.Cleanup(upstream.Close)
+	return upstream
+}
+
+func (u *fakeUpstream) source(paths ...string) *HTTPSeatMapUpstream {
+	urls := make([]string, len(paths))
+	for i, path := range paths {
+		urls[i] = u.URL + path
+	}
+	return &HTTPSeatMapUpstream{URLs: urls, Token: "sync-token", MaxAttempts: 3, RetryBase: time.Millisecond}
+}
+
+func sampleSeatMap(t *testing.T) []byte {
+	t.Helper()
+	data, err := os.ReadFile("../data/SeatMapResponse.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestSeatMapSyncImportsUpstreamDocument(t *testing.T) {
+	env := newTestEnv(t)
+	admin := env.user(t, "admin@example.com")
+	user := env.user(t, "budi@example.com")
+	seat := env.freeSeats(t)[0]
+	if _, err := env.bookings.CreateBooking(user.ID, seat.ID, ""); err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+
+	upstream := newFakeUpstream(t, map[string][]byte{"/seatmap.json": sampleSeatMap(t)}, 0)
+	syncer := NewSeatMapSyncService(env.store, env.seats, upstream.source("/seatmap.json"))
+	run, err := syncer.Sync(context.Background(), model.SyncTriggerManual, &admin.ID)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	if run.Status != model.SyncSucceeded || run.Error != "" {
+		t.Fatalf("run = %s %q, want succeeded", run.Status, run.Error)
+	}
+	if run.Documents != 1 || run.Failed != 0 || run.Flights != 1 {
+		t.Errorf("run counts = %d documents %d failed %d flights, want 1/0/1", run.Documents, run.Failed, run.Flights)
+	}
+	if want := (model.SeatMapDiff{Unchanged: 150}); run.SeatMapDiff != want {
+		t.Errorf("diff = %+v, want %+v", run.SeatMapDiff, want)
+	}
+	if run.Trigger != model.SyncTriggerManual || run.TriggeredBy == nil || *run.TriggeredBy != admin.ID {
+		t.Errorf("run trigger = %s by %v, want manual by %d", run.Trigger, run.TriggeredBy, admin.ID)
+	}
+	if run.Source != upstream.URL+"/seatmap.json" || run.FinishedAt == nil {
+		t.Errorf("run source = %q finished %v, want upstream URL and finish time", run.Source, run.FinishedAt)
+	}
+
+	// Sync ulang tidak melepas kursi yang sudah dibooking
+	if env.seat(t, seat.ID).Available {
+		t.Error("booked seat became available after sync")
+	}
+}
+
+func TestSeatMapSyncRetriesUpstreamServerError(t *testing.T) {
+	env := newTestEnv(t)
+	upstream := newFakeUpstream(t, map[string][]byte{"/seatmap.json": sampleSeatMap(t)}, 2)
+	syncer := NewSeatMapSyncService(env.store, env.seats, upstream.source("/seatmap.json"))
+
+	run, err := syncer.Sync(context.Background(), model.SyncTriggerScheduled, nil)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if run.Status != model.SyncSucceeded {
+		t.Errorf("run = %s %q, want succeeded after retries", run.Status, run.Error)
+	}
+	if hits := upstream.hits.Load(); hits != 3 {
+		t.Errorf("upstream received %d requests, want 3 (two 503 then 200)", hits)
+	}
+
+	// Percobaan habis: sync gagal dengan pesan dari upstream
+	upstream.failures.Store(10)
+	run, err = syncer.Sync(context.Background(), model.SyncTriggerScheduled, nil)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if run.Status != model.SyncFailed || !strings.Contains(run.Error, "503") {
+		t.Errorf("run = %s %q, want failed with 503", run.Status, run.Error)
+	}
+}
+
+func TestSeatMapSyncDoesNotRetryClientError(t *testing.T) {
+	env := newTestEnv(t)
+	upstream := newFakeUpstream(t, map[string][]byte{}, 0)
+	syncer := NewSeatMapSyncService(env.store, env.seats, upstream.source("/missing.json"))
+
+	run, err := syncer.Sync(context.Background(), model.SyncTriggerScheduled, nil)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if run.Status != model.SyncFailed || run.Documents != 0 {
+		t.Errorf("run = %s with %d documents, want failed with none", run.Status, run.Documents)
+	}
+	if hits := upstream.hits.Load(); hits != 1 {
+		t.Errorf("upstream received %d requests for a 404, want 1", hits)
+	}
+}
+
+func TestSeatMapSyncRunBookkeeping(t *testing.T) {
+	env := newTestEnv(t)
+	upstream := newFakeUpstream(t, map[string][]byte{
+		"/seatmap.json": sampleSeatMap(t),
+		"/broken.json":  []byte(`{"hello":"world"}`),
+	}, 0)
+
+	// Satu dokumen yang tidak dikenali tidak membatalkan dokumen lain
+	partial := NewSeatMapSyncService(env.store, env.seats, upstream.source("/seatmap.json", "/broken.json"))
+	run, err := partial.Sync(context.Background(), model.SyncTriggerScheduled, nil)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if run.Status != model.SyncPartial || run.Documents != 2 || run.Failed != 1 || run.Flights != 1 {
+		t.Errorf("run = %s %d documents %d failed %d flights, want partial 2/1/1", run.Status, run.Documents, run.Failed, run.Flights)
+	}
+	if !strings.Contains(run.Error, "/broken.json") {
+		t.Errorf("run error = %q, want the failing document name", run.Error)
+	}
+
+	succeeded, err := NewSeatMapSyncService(env.store, env.seats, upstream.source("/seatmap.json")).
+		Sync(context.Background(), model.SyncTriggerScheduled, nil)
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	page, err := partial.SyncRuns(Pagination{Page: 1, PageSize: 10})
+	if err != nil {
+		t.Fatalf("SyncRuns: %v", err)
+	}
+	if page.Total != 2 || len(page.Items) != 2 {
+		t.Fatalf("sync runs = %d of %d, want 2", len(page.Items), page.Total)
+	}
+	if page.Items[0].ID != succeeded.ID || page.Items[0].Status != model.SyncSucceeded || page.Items[1].Status != model.SyncPartial {
+		t.Errorf("sync runs = %d %s, %d %s; want newest succeeded run first", page.Items[0].ID, page.Items[0].Status, page.Items[1].ID, page.Items[1].Status)
+	}
+
+	disabled := NewSeatMapSyncService(env.store, env.seats, nil)
+	if _, err := disabled.Sync(context.Background(), model.SyncTriggerManual, nil); !errors.Is(err, ErrSeatMapSyncDisabled) {
+		t.Errorf("sync without upstream err = %v, want ErrSeatMapSyncDisabled", err)
+	}
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxUpstreamDocumentSize membatasi ukuran satu dokumen seat map dari upstream
+const maxUpstreamDocumentSize = 20 << 20
+
+const (
+	defaultUpstreamAttempts  = 3
+	defaultUpstreamRetryBase = 2 * time.Second
+	maxUpstreamRetryDelay    = 30 * time.Second
+)
+
+// SeatMapDocument adalah satu dokumen seat map yang diambil dari upstream.
+// Format kosong berarti format dideteksi dari isi dokumen.
+type SeatMapDocument struct {
+	Name   string
+	Format string
+	Data   []byte
+}
+
+// SeatMapUpstream adalah provider seat map yang ditarik secara berkala oleh SeatMapSyncService
+type SeatMapUpstream interface {
+	// Name menjelaskan sumber untuk dicatat pada sync run, misalnya URL atau direktori
+	Name() string
+	Fetch(ctx context.Context) ([]SeatMapDocument, error)
+}
+
+// HTTPSeatMapUpstream mengambil satu dokumen seat map dari setiap URL dengan GET. Respons 5xx
+// dan kegagalan koneksi dicoba ulang dengan jeda yang berlipat dua.
+type HTTPSeatMapUpstream struct {
+	URLs   []string
+	Format string
+	// Token dikirim sebagai Authorization: Bearer jika diisi
+	Token  string
+	Client *http.Client
+	// MaxAttempts adalah jumlah percobaan per URL (default 3); RetryBase adalah jeda setelah
+	// percobaan pertama gagal (default 2s)
+	MaxAttempts int
+	RetryBase   time.Duration
+}
+
+// upstreamError adalah respons upstream yang bukan 200; hanya 5xx yang dicoba ulang
+type upstreamError struct {
+	url    string
+	status int
+	text   string
+}
+
+func (e *upstreamError) Error() string {
+	return fmt.Sprintf("upstream %s returned %s", e.url, e.text)
+}
+
+func (u *HTTPSeatMapUpstream) Name() string {
+	return strings.Join(u.URLs, ", ")
+}
+
+func (u *HTTPSeatMapUpstream) Fetch(ctx context.Context) ([]SeatMapDocument, error) {
+	client := u.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	documents := make([]SeatMapDocument, 0, len(u.URLs))
+	for _, url := range u.URLs {
+		data, err := u.fetchWithRetry(ctx, client, url)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, SeatMapDocument{Name: url, Format: u.Format, Data: data})
+	}
+	return documents, nil
+}
+
+// fetchWithRetry menjalankan get sampai berhasil, mendapat error yang tidak layak dicoba ulang,
+// atau percobaan habis
+func (u *HTTPSeatMapUpstream) fetchWithRetry(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	attempts := u.MaxAttempts
+	if attempts <= 0 {
+		attempts = defaultUpstreamAttempts
+	}
+	base := u.RetryBase
+	if base <= 0 {
+		base = defaultUpstreamRetryBase
+	}
+
+	for attempt := 1; ; attempt++ {
+		data, err := u.get(ctx, client, url)
+		if err == nil || attempt >= attempts || !retryableUpstreamError(err) {
+			return data, err
+		}
+
+		timer := time.NewTimer(retryDelay(base, maxUpstreamRetryDelay, attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryableUpstreamError melaporkan apakah err layak dicoba ulang: respons 5xx atau kegagalan
+// jaringan, bukan 4xx, dokumen terlalu besar atau ctx yang dibatalkan
+func retryableUpstreamError(err error) bool {
+	var status *upstreamError
+	if errors.As(err, &status) {
+		return status.status >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && !errors.Is(err, context.Canceled)
+}
+
+func (u *HTTPSeatMapUpstream) get(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if u.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+u.Token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, &upstreamError{url: url, status: resp.StatusCode, text: resp.Status}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxUpstreamDocumentSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxUpstreamDocumentSize {
+		return nil, fmt.Errorf("upstream %s returned a document larger than %d bytes", url, maxUpstreamDocumentSize)
+	}
+	return data, nil
+}
+
+// DirSeatMapUpstream membaca setiap file .json dan .xml di Dir, terurut berdasarkan nama file.
+// Formatnya dideteksi dari isi file.
+type DirSeatMapUpstream struct {
+	Dir string
+}
+
+func (u *DirSeatMapUpstream) Name() string {
+	return u.Dir
+}
+
+func (u *DirSeatMapUpstream) Fetch(ctx context.Context) ([]SeatMapDocument, error) {
+	entries, err := os.ReadDir(u.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.Type().IsRegular() && (ext == ".json" || ext == ".xml") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	documents := make([]SeatMapDocument, 0, len(names))
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		path := filepath.Join(u.Dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		documents = append(documents, SeatMapDocument{Name: path, Data: data})
+	}
+	return documents, nil
+}
@@ -1,148 +1,387 @@
 package service
 
 import (
-	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
-	"gorm.io/gorm"
-
+	"github.com/tiananugerah/go-BookCabin/event"
 	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/repository"
 )
 
 type SeatService struct {
-	db *gorm.DB
-}
-
-func NewSeatService(db *gorm.DB) *SeatService {
-	return &SeatService{db: db}
-}
-
-type SeatMapResponse struct {
-	SeatsItineraryParts []struct {
-		SegmentSeatMaps []struct {
-			PassengerSeatMaps []struct {
-				SeatMap struct {
-					Aircraft string `json:"aircraft"`
-					Cabins []struct {
-						Deck string `json:"deck"`
-						SeatColumns []string `json:"seatColumns"`
-						SeatRows []struct {
-							RowNumber int `json:"rowNumber"`
-							SeatCodes []string `json:"seatCodes"`
-							Seats []struct {
-								Code string `json:"code"`
-								Available bool `json:"available"`
-								StorefrontSlotCode string `json:"storefrontSlotCode"`
-								SeatCharacteristics []string `json:"seatCharacteristics"`
-								Designations []string `json:"designations"`
-								Prices struct {
-									Alternatives [][]struct {
-										Amount float64 `json:"amount"`
-										Currency string `json:"currency"`
-									} `json:"alternatives"`
-								} `json:"prices"`
-							} `json:"seats"`
-						} `json:"seatRows"`
-					} `json:"cabins"`
-				} `json:"seatMap"`
-			} `json:"passengerSeatMaps"`
-		} `json:"segmentSeatMaps"`
-	} `json:"seatsItineraryParts"`
-}
-
-func (s *SeatService) ImportSeatMapFromFile(path string) ([]model.Seat, error) {
+	store   repository.Store
+	bus     event.Bus
+	sources []SeatMapSource
+}
+
+func NewSeatService(store repository.Store, bus event.Bus) *SeatService {
+	return &SeatService{store: store, bus: bus, sources: DefaultSeatMapSources()}
+}
+
+// RegisterSource menambahkan adapter seat map. Adapter yang didaftarkan belakangan dicoba
+// lebih dulu saat deteksi format, sehingga format custom bisa mendahului format bawaan.
+func (s *SeatService) RegisterSource(source SeatMapSource) {
+	s.sources = append([]SeatMapSource{source}, s.sources...)
+}
+
+// ImportSeatMapFromFile membaca dokumen seat map dari file lalu mengimpornya seperti ImportSeatMap
+func (s *SeatService) ImportSeatMapFromFile(actorID uint, path, reason string) ([]model.Seat, error) {
 	file, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
+	return s.ImportSeatMap(actorID, file, "", reason)
+}
+
+// SeatMapImport adalah hasil import satu dokumen seat map
+type SeatMapImport struct {
+	Seats   []model.Seat
+	Flights []uint
+	Diff    model.SeatMapDiff
+}
+
+// ImportSeatMap memperbarui inventory kursi setiap segmen pada dokumen seat map atas nama admin
+// dan mengembalikan kursi dari semua segmen. Setiap penerbangan yang disentuh dicatat di audit
+// log dalam transaksi yang sama. format memilih adapter secara eksplisit; kosong berarti dideteksi.
+func (s *SeatService) ImportSeatMap(actorID uint, data []byte, format, reason string) ([]model.Seat, error) {
+	source, err := s.source(data, format)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.importDocument(source, data, func(tx repository.Store, flight *model.Flight, diff model.SeatMapDiff) error {
+		return audit(tx, actorID, AuditSeatMapImport, auditTargetFlight, flight.ID, reason, model.JSONMap{
+			"flight":    fmt.Sprintf("%s%d", flight.AirlineCode, flight.FlightNumber),
+			"format":    source.Format(),
+			"added":     diff.Added,
+			"updated":   diff.Updated,
+			"removed":   diff.Removed,
+			"unchanged": diff.Unchanged,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.Seats, nil
+}
+
+// ImportSeatMapDocument mengimpor dokumen seat map dalam satu transaksi dan melaporkan
+// penerbangan yang disentuh beserta jumlah kursi yang ditambah, diubah dan dihapus. Dipakai
+// oleh sync terjadwal yang mencatat hasilnya sendiri sebagai SeatMapSync.
+func (s *SeatService) ImportSeatMapDocument(data []byte, format string) (*SeatMapImport, error) {
+	source, err := s.source(data, format)
+	if err != nil {
+		return nil, err
+	}
+	return s.importDocument(source, data, nil)
+}
+
+// importDocument mengimpor semua segmen dokumen dalam satu transaksi. fn (jika ada) dijalankan
+// per segmen dalam transaksi yang sama, misalnya untuk mencatat audit log admin.
+func (s *SeatService) importDocument(source SeatMapSource, data []byte, fn func(tx repository.Store, flight *model.Flight, diff model.SeatMapDiff) error) (*SeatMapImport, error) {
+	segments, err := ParseSeatMap(source, data)
+	if err != nil {
+		return nil, err
+	}
 
-	var response SeatMapResponse
-	if err := json.Unmarshal(file, &response); err != nil {
+	result := &SeatMapImport{}
+	err = s.store.Transaction(func(tx repository.Store) error {
+		if err := localizeSegments(tx, segments); err != nil {
+			return err
+		}
+		for _, segment := range segments {
+			diff, err := importSegment(tx, segment)
+			if err != nil {
+				return err
+			}
+			if fn != nil {
+				if err := fn(tx, segment.Flight, diff); err != nil {
+					return err
+				}
+			}
+			result.Seats = append(result.Seats, segment.Seats...)
+			result.Flights = append(result.Flights, segment.Flight.ID)
+			result.Diff.Add(diff)
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-    // Delete existing seats before importing new ones
-    if err := s.db.Exec("DELETE FROM seats").Error; err != nil {
-        return nil, err
-    }
-
-    var seats []model.Seat
-
-    for _, part := range response.SeatsItineraryParts {
-        for _, segMap := range part.SegmentSeatMaps {
-            for _, paxMap := range segMap.PassengerSeatMaps {
-                aircraft := paxMap.SeatMap.Aircraft
-                for _, cabin := range paxMap.SeatMap.Cabins {
-                    for _, row := range cabin.SeatRows {
-                        for _, seat := range row.Seats {
-                            if seat.StorefrontSlotCode == "SEAT" {
-                                // Determine segment based on row number
-                                segment := "ECONOMY"
-                                if row.RowNumber <= 2 {
-                                    segment = "FIRST"
-                                } else if row.RowNumber <= 7 {
-                                    segment = "BUSINESS"
-                                }
-
-                                // Get price and currency from the first alternative
-                                var price float64
-                                var currency string
-                                if len(seat.Prices.Alternatives) > 0 && len(seat.Prices.Alternatives[0]) > 0 {
-                                    price = seat.Prices.Alternatives[0][0].Amount
-                                    currency = seat.Prices.Alternatives[0][0].Currency
-                                }
-
-                                // Check for window or aisle seat
-                                isWindow := false
-                                isAisle := false
-                                for _, char := range seat.SeatCharacteristics {
-                                    if char == "W" {
-                                        isWindow = true
-                                    } else if char == "A" {
-                                        isAisle = true
-                                    }
-                                }
-
-                                newSeat := model.Seat{
-                                    SeatCode:        seat.Code,
-                                    Available:       seat.Available,
-                                    Price:          price,
-                                    Currency:       currency,
-                                    RowNumber:      row.RowNumber,
-                                    Segment:        segment,
-                                    IsWindow:       isWindow,
-                                    IsAisle:        isAisle,
-                                    Aircraft:       aircraft,
-                                    Characteristics: model.StringArray(seat.SeatCharacteristics),
-                                }
-                                seats = append(seats, newSeat)
-                            }
-                        }
-                    }
-                }
-            }
-        }
-    }
-
-    result := s.db.Create(&seats)
-    if result.Error != nil {
-        return nil, result.Error
-    }
-
-    return seats, nil
+	for _, flightID := range result.Flights {
+		s.publishImported(flightID)
+	}
+	return result, nil
+}
+
+// publishImported memberi tahu subscriber bahwa inventory kursi penerbangan baru saja diimpor
+func (s *SeatService) publishImported(flightID uint) {
+	if err := s.bus.Publish(event.Event{Type: event.SeatMapImported, FlightID: flightID}); err != nil {
+		log.Printf("failed to publish %s for flight %d: %v", event.SeatMapImported, flightID, err)
+	}
+}
+
+// removedFromSourceReason adalah alasan block untuk kursi yang hilang dari seat map sumber
+// tetapi masih dirujuk booking, sehingga tidak bisa dihapus
+const removedFromSourceReason = "REMOVED_FROM_SOURCE"
+
+// importSegment menyimpan penerbangan segmen lalu menggabungkan kursinya dengan inventory yang
+// ada berdasarkan SeatCode. Kursi yang sudah ada diperbarui tanpa mengganti ID-nya sehingga
+// booking tetap menunjuk ke kursi yang sama; kursi yang hilang dari dokumen dihapus, atau
+// diblokir jika pernah dibooking. Penumpang, offer dan assignment diganti dengan isi dokumen.
+func importSegment(tx repository.Store, segment *ImportedSegment) (model.SeatMapDiff, error) {
+	var diff model.SeatMapDiff
+	flight := segment.Flight
+	if err := saveFlight(tx, flight); err != nil {
+		return diff, err
+	}
+	existing, err := tx.Seats().FindByFlight(flight.ID)
+	if err != nil {
+		return diff, err
+	}
+	bookings, err := tx.Bookings().FindByFlight(flight.ID)
+	if err != nil {
+		return diff, err
+	}
+	booked, occupied := map[uint]bool{}, map[uint]bool{}
+	for _, booking := range bookings {
+		booked[booking.SeatID] = true
+		if slices.Contains(model.ActiveStatuses, booking.Status) {
+			occupied[booking.SeatID] = true
+		}
+	}
+
+	keepOpsBlocks(existing, segment.Seats)
+	if err := tx.Passengers().ReplaceForFlight(flight.ID, segment.Passengers); err != nil {
+		return diff, err
+	}
+	// Assignment dan offer lama bisa menunjuk ke kursi yang akan dihapus
+	if err := tx.SeatAssignments().ReplaceForFlight(flight.ID, nil); err != nil {
+		return diff, err
+	}
+	if err := tx.SeatOffers().ReplaceForFlight(flight.ID, nil); err != nil {
+		return diff, err
+	}
+
+	current := map[string]model.Seat{}
+	for _, seat := range existing {
+		current[seat.SeatCode] = seat
+	}
+	var added []int
+	for i := range segment.Seats {
+		seat := &segment.Seats[i]
+		seat.FlightID = flight.ID
+		old, ok := current[seat.SeatCode]
+		if !ok {
+			added = append(added, i)
+			continue
+		}
+		delete(current, seat.SeatCode)
+
+		seat.ID = old.ID
+		seat.CreatedAt = old.CreatedAt
+		// Kursi yang sedang dibooking tetap tidak dijual walaupun available di sumber
+		if occupied[old.ID] {
+			seat.Available = false
+		}
+		if sameBlock(old.SeatBlock, seat.SeatBlock) {
+			seat.SeatBlock = old.SeatBlock
+		}
+		if sameSeat(&old, seat) {
+			diff.Unchanged++
+			continue
+		}
+		if err := tx.Seats().Update(seat); err != nil {
+			return diff, err
+		}
+		diff.Updated++
+	}
+
+	newSeats := make([]model.Seat, 0, len(added))
+	for _, i := range added {
+		newSeats = append(newSeats, segment.Seats[i])
+	}
+	if err := tx.Seats().CreateBatch(newSeats); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return diff, ErrSeatMapInvalid.WithDetail("seat map for flight %s%d contains duplicate seat codes", flight.AirlineCode, flight.FlightNumber)
+		}
+		return diff, err
+	}
+	for j, i := range added {
+		segment.Seats[i] = newSeats[j]
+	}
+	diff.Added = len(added)
+
+	var removed []uint
+	for _, seat := range sortedSeats(current) {
+		if !booked[seat.ID] {
+			removed = append(removed, seat.ID)
+			continue
+		}
+		if seat.BlockReason == removedFromSourceReason {
+			continue
+		}
+		seat.Available = false
+		seat.SeatBlock = model.SeatBlock{BlockReason: removedFromSourceReason, BlockSource: model.SeatBlockImport}
+		if err := tx.Seats().Update(&seat); err != nil {
+			return diff, err
+		}
+		diff.Removed++
+	}
+	if err := tx.Seats().Delete(removed); err != nil {
+		return diff, err
+	}
+	diff.Removed += len(removed)
+
+	seatIDs := map[string]uint{}
+	for _, seat := range segment.Seats {
+		seatIDs[seat.SeatCode] = seat.ID
+	}
+	for i := range segment.Offers {
+		segment.Offers[i].SeatID = seatIDs[segment.Offers[i].SeatCode]
+	}
+	for i := range segment.Assignments {
+		segment.Assignments[i].SeatID = seatIDs[segment.Assignments[i].SeatCode]
+	}
+	if err := tx.SeatOffers().ReplaceForFlight(flight.ID, segment.Offers); err != nil {
+		return diff, err
+	}
+	return diff, tx.SeatAssignments().ReplaceForFlight(flight.ID, segment.Assignments)
+}
+
+// saveFlight memperbarui penerbangan yang sudah ada (berdasarkan designator) atau membuat yang baru
+func saveFlight(tx repository.Store, flight *model.Flight) error {
+	existing, err := tx.Flights().FindByDesignator(flight.AirlineCode, flight.FlightNumber, flight.Departure)
+	if errors.Is(err, repository.ErrNotFound) {
+		return tx.Flights().Create(flight)
+	} else if err != nil {
+		return err
+	}
+
+	flight.ID = existing.ID
+	flight.CreatedAt = existing.CreatedAt
+	return tx.Flights().Update(flight)
+}
+
+// keepOpsBlocks menyalin block dari admin yang masih berlaku ke kursi hasil import ulang
+// dengan kode yang sama, supaya import tidak membuka kembali kursi yang rusak
+func keepOpsBlocks(existing, seats []model.Seat) {
+	now := time.Now()
+	blocks := map[string]model.SeatBlock{}
+	for _, seat := range existing {
+		if seat.BlockSource == model.SeatBlockOps && seat.IsBlocked(now) {
+			blocks[seat.SeatCode] = seat.SeatBlock
+		}
+	}
+	for i := range seats {
+		if block, ok := blocks[seats[i].SeatCode]; ok {
+			seats[i].SeatBlock = block
+			seats[i].Available = false
+		}
+	}
+}
+
+// sameBlock membandingkan isi block tanpa memperhatikan kapan block dibuat
+func sameBlock(a, b model.SeatBlock) bool {
+	return a.BlockReason == b.BlockReason && a.BlockSource == b.BlockSource &&
+		equalTime(a.BlockedUntil, b.BlockedUntil) && equalID(a.BlockedBy, b.BlockedBy)
+}
+
+func equalTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func equalID(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// sameSeat melaporkan apakah import tidak mengubah kolom kursi yang tersimpan
+func sameSeat(old, seat *model.Seat) bool {
+	return old.Available == seat.Available &&
+		old.Price == seat.Price &&
+		old.Currency == seat.Currency &&
+		old.RowNumber == seat.RowNumber &&
+		old.ColumnGroup == seat.ColumnGroup &&
+		old.Segment == seat.Segment &&
+		old.IsWindow == seat.IsWindow &&
+		old.IsAisle == seat.IsAisle &&
+		old.Aircraft == seat.Aircraft &&
+		slices.Equal(old.Characteristics, seat.Characteristics) &&
+		slices.Equal(old.Designations, seat.Designations) &&
+		slices.Equal(old.Limitations, seat.Limitations) &&
+		sameBlock(old.SeatBlock, seat.SeatBlock) &&
+		old.SeatEntitlement == seat.SeatEntitlement
+}
+
+func sortedSeats(seats map[string]model.Seat) []model.Seat {
+	out := make([]model.Seat, 0, len(seats))
+	for _, seat := range seats {
+		out = append(out, seat)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// source memilih adapter berdasarkan nama format, atau adapter pertama yang mengenali data
+func (s *SeatService) source(data []byte, format string) (SeatMapSource, error) {
+	if format = strings.ToLower(strings.TrimSpace(format)); format != "" {
+		for _, source := range s.sources {
+			if source.Format() == format {
+				return source, nil
+			}
+		}
+		return nil, ErrSeatMapFormat.WithDetail("unknown seat map format %q, supported formats: %s", format, strings.Join(s.SeatMapFormats(), ", "))
+	}
+
+	for _, source := range s.sources {
+		if source.Detect(data) {
+			return source, nil
+		}
+	}
+	return nil, ErrSeatMapFormat.WithDetail("seat map format could not be detected")
+}
+
+// SeatMapFormats mengembalikan nama format dari semua adapter yang terdaftar
+func (s *SeatService) SeatMapFormats() []string {
+	formats := make([]string, 0, len(s.sources))
+	for _, source := range s.sources {
+		formats = append(formats, source.Format())
+	}
+	return formats
 }
 
 func (s *SeatService) GetAllSeats() ([]model.Seat, error) {
-	var seats []model.Seat
-	result := s.db.Find(&seats)
-	return seats, result.Error
+	return s.store.Seats().FindAll()
 }
 
 func (s *SeatService) GetSeatByID(id uint) (*model.Seat, error) {
-	var seat model.Seat
-	result := s.db.First(&seat, id)
-	if result.Error != nil {
-		return nil, result.Error
+	return s.store.Seats().FindByID(id)
+}
+
+func (s *SeatService) GetFlightSeats(flightID uint) ([]model.Seat, error) {
+	if _, err := s.store.Flights().FindByID(flightID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrFlightNotFound
+		}
+		return nil, err
 	}
-	return &seat, nil
+	return s.store.Seats().FindByFlight(flightID)
+}
+
+// SubscribeSeatChanges berlangganan perubahan ketersediaan kursi untuk satu penerbangan
+func (s *SeatService) SubscribeSeatChanges(flightID uint) (<-chan event.Event, func()) {
+	return s.bus.Subscribe(event.ForFlight(flightID))
 }
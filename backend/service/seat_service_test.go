@@ -0,0 +1,200 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/repository"
+)
+
+func TestParseSeatMap(t *testing.T) {
+	data, err := os.ReadFile("../data/SeatMapResponse.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	segments, err := ParseSeatMap(JSONSeatMapSource{}, data)
+	if err != nil {
+		t.Fatalf("ParseSeatMap: %v", err)
+	}
+	if len(segments) == 0 {
+		t.Fatal("no segments were parsed")
+	}
+	flight, seats, passengers := segments[0].Flight, segments[0].Seats, segments[0].Passengers
+	if flight.AirlineCode != "OD" || flight.FlightNumber != 312 || flight.Origin != "KUL" || flight.Destination != "CGK" {
+		t.Errorf("flight = %s%d %s-%s, want OD312 KUL-CGK", flight.AirlineCode, flight.FlightNumber, flight.Origin, flight.Destination)
+	}
+	if len(seats) != 150 {
+		t.Fatalf("parsed %d seats, want 150", len(seats))
+	}
+	if len(passengers) == 0 {
+		t.Error("no passengers were parsed")
+	}
+
+	byCode := map[string]int{}
+	for i, seat := range seats {
+		byCode[seat.SeatCode] = i
+	}
+	window, ok := byCode["4A"]
+	if !ok {
+		t.Fatal("seat 4A was not parsed")
+	}
+	if seat := seats[window]; !seat.IsWindow || seat.IsAisle || seat.RowNumber != 4 || seat.Segment != "BUSINESS" || seat.Aircraft != "738" {
+		t.Errorf("4A = %+v, want business window seat on 738", seat)
+	}
+	if seat := seats[byCode["4C"]]; !seat.IsAisle || seat.IsWindow {
+		t.Errorf("4C window=%v aisle=%v, want aisle", seat.IsWindow, seat.IsAisle)
+	}
+	if seat := seats[window]; seat.Price != 65 || seat.Currency != "MYR" {
+		t.Errorf("4A price = %v %s, want 65 MYR", seat.Price, seat.Currency)
+	}
+}
+
+func TestParseSeatMapRejectsInvalidDocument(t *testing.T) {
+	if _, err := ParseSeatMap(JSONSeatMapSource{}, []byte(`{"seatsItineraryParts":`)); !errors.Is(err, ErrSeatMapInvalid) {
+		t.Errorf("ParseSeatMap of a truncated document err = %v, want ErrSeatMapInvalid", err)
+	}
+}
+
+func TestImportSeatMapReplacesSeats(t *testing.T) {
+	env := newTestEnv(t)
+	admin := env.user(t, "admin@example.com")
+	seats, err := env.seats.ImportSeatMapFromFile(admin.ID, "../data/SeatMapResponse.json", "reimport")
+	if err != nil {
+		t.Fatalf("reimport: %v", err)
+	}
+	all, err := env.seats.GetAllSeats()
+	if err != nil {
+		t.Fatalf("GetAllSeats: %v", err)
+	}
+	if len(all) != len(seats) {
+		t.Errorf("store has %d seats after reimport, want %d", len(all), len(seats))
+	}
+}
+
+func TestImportSeatMap(t *testing.T) {
+	env := newTestEnv(t)
+
+	flight, err := env.store.Flights().FindByID(1)
+	if err != nil {
+		t.Fatalf("imported flight: %v", err)
+	}
+	if flight.AirlineCode != "OD" || flight.FlightNumber != 312 || flight.Origin != "KUL" || flight.Destination != "CGK" {
+		t.Errorf("flight = %s%d %s-%s, want OD312 KUL-CGK", flight.AirlineCode, flight.FlightNumber, flight.Origin, flight.Destination)
+	}
+	seats, err := env.store.Seats().FindByFlight(flight.ID)
+	if err != nil {
+		t.Fatalf("imported seats: %v", err)
+	}
+	if len(seats) != 150 {
+		t.Errorf("imported %d seats, want 150", len(seats))
+	}
+}
+
+func TestImportSeatMapKeepsBlockedSeatsUnavailable(t *testing.T) {
+	env := newTestEnv(t)
+
+	seats, err := env.store.Seats().FindByFlight(1)
+	if err != nil {
+		t.Fatalf("imported seats: %v", err)
+	}
+	blocked := 0
+	for _, seat := range seats {
+		if seat.BlockReason == "" {
+			continue
+		}
+		blocked++
+		if seat.Available || seat.BlockSource != model.SeatBlockImport {
+			t.Errorf("seat %s blocked for %q = available %v source %q, want unavailable import block",
+				seat.SeatCode, seat.BlockReason, seat.Available, seat.BlockSource)
+		}
+	}
+	if blocked == 0 {
+		t.Fatal("fixture has no seats that are unavailable in the source")
+	}
+
+	available, err := env.bookings.GetAvailableSeats()
+	if err != nil {
+		t.Fatalf("GetAvailableSeats: %v", err)
+	}
+	for _, seat := range available {
+		if seat.BlockReason != "" {
+			t.Errorf("blocked seat %s is offered for sale", seat.SeatCode)
+		}
+	}
+}
+
+func TestImportSeatMapRejectsUnknownFormat(t *testing.T) {
+	env := newTestEnv(t)
+	if _, err := env.seats.ImportSeatMapDocument([]byte(`{"hello":"world"}`), ""); !errors.Is(err, ErrSeatMapFormat) {
+		t.Errorf("import of an unrecognised document err = %v, want ErrSeatMapFormat", err)
+	}
+	if _, err := env.seats.ImportSeatMapDocument([]byte(`{}`), "sabre"); !errors.Is(err, ErrSeatMapFormat) {
+		t.Errorf("import with an unknown format name err = %v, want ErrSeatMapFormat", err)
+	}
+}
+
+func TestReimportSeatMapKeepsBookings(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.user(t, "budi@example.com")
+	seat := env.freeSeats(t)[0]
+	booking, err := env.bookings.CreateBooking(user.ID, seat.ID, "")
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+
+	data, err := os.ReadFile("../data/SeatMapResponse.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := env.seats.ImportSeatMapDocument(data, "")
+	if err != nil {
+		t.Fatalf("reimport: %v", err)
+	}
+	if want := (model.SeatMapDiff{Unchanged: 150}); result.Diff != want {
+		t.Errorf("diff = %+v, want %+v", result.Diff, want)
+	}
+
+	// Kursi yang dibooking tetap kursi yang sama dan tetap tidak dijual
+	reimported := env.seat(t, seat.ID)
+	if reimported.SeatCode != seat.SeatCode || reimported.Available {
+		t.Errorf("booked seat after reimport = %s available=%v, want %s unavailable", reimported.SeatCode, reimported.Available, seat.SeatCode)
+	}
+	current, err := env.store.Bookings().FindByID(booking.ID)
+	if err != nil {
+		t.Fatalf("find booking: %v", err)
+	}
+	if current.Status != model.StatusConfirmed || current.SeatID != seat.ID {
+		t.Errorf("booking after reimport = %s seat %d, want confirmed on seat %d", current.Status, current.SeatID, seat.ID)
+	}
+}
+
+func TestAdminImportSeatMapIsAudited(t *testing.T) {
+	env := newTestEnv(t)
+	admin := env.user(t, "admin@example.com")
+
+	seats, err := env.seats.ImportSeatMapFromFile(admin.ID, "../data/SeatAvailabilityRS.xml", "new NDC feed")
+	if err != nil {
+		t.Fatalf("ImportSeatMapFromFile: %v", err)
+	}
+	if len(seats) == 0 {
+		t.Fatal("import returned no seats")
+	}
+
+	logs, total, err := env.store.Audit().Search(repository.AuditFilter{Action: AuditSeatMapImport})
+	if err != nil {
+		t.Fatalf("search audit log: %v", err)
+	}
+	if total == 0 {
+		t.Fatal("admin import wrote no audit entry")
+	}
+	for _, entry := range logs {
+		if entry.ActorID != admin.ID || entry.TargetType != auditTargetFlight || entry.Reason != "new NDC feed" {
+			t.Errorf("audit entry = actor %d %s %q, want actor %d flight %q", entry.ActorID, entry.TargetType, entry.Reason, admin.ID, "new NDC feed")
+		}
+		if entry.Details["format"] != "ndc" {
+			t.Errorf("audit format = %v, want ndc", entry.Details["format"])
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/repository"
+)
+
+// UserProfile adalah data user yang aman dikirim ke client (tanpa hash password)
+type UserProfile struct {
+	ID                 uint       `json:"id"`
+	Email              string     `json:"email"`
+	Name               string     `json:"name"`
+	Role               string     `json:"role"`
+	EmailVerifiedAt    *time.Time `json:"email_verified_at,omitempty"`
+	BookingLimitExempt bool       `json:"booking_limit_exempt"`
+	CreatedAt          time.Time  `json:"created_at"`
+	Locale             string     `json:"locale"`
+}
+
+func NewUserProfile(user *model.User) *UserProfile {
+	return &UserProfile{
+		ID:                 user.ID,
+		Email:              user.Email,
+		Name:               user.Name,
+		Role:               user.Role,
+		EmailVerifiedAt:    user.EmailVerifiedAt,
+		BookingLimitExempt: user.BookingLimitExempt,
+		CreatedAt:          user.CreatedAt,
+		Locale:             user.Locale,
+	}
+}
+
+// ProfileUpdate berisi field profil yang boleh diubah sendiri oleh user; nil berarti tidak diubah.
+// Email dan password punya alur sendiri di AuthService.
+type ProfileUpdate struct {
+	Name *string
+	// Locale adalah bahasa notifikasi; harus salah satu locale yang punya template
+	Locale *string
+}
+
+type UserService struct {
+	store          repository.Store
+	authService    *AuthService
+	bookingService *BookingService
+}
+
+func NewUserService(store repository.Store, authService *AuthService, bookingService *BookingService) *UserService {
+	return &UserService{store: store, authService: authService, bookingService: bookingService}
+}
+
+func (s *UserService) GetProfile(userID uint) (*UserProfile, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return NewUserProfile(user), nil
+}
+
+func (s *UserService) UpdateProfile(userID uint, update ProfileUpdate) (*UserProfile, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Name != nil {
+		name := strings.TrimSpace(*update.Name)
+		if name == "" {
+			return nil, ErrValidationFailed.WithDetail("name must not be empty")
+		}
+		user.Name = name
+	}
+	if update.Locale != nil {
+		locale := strings.ToLower(strings.TrimSpace(*update.Locale))
+		if !SupportedLocale(locale) {
+			return nil, ErrValidationFailed.WithDetail("locale %q is not supported", *update.Locale)
+		}
+		user.Locale = locale
+	}
+
+	if err := s.store.Users().Update(user); err != nil {
+		return nil, err
+	}
+	return NewUserProfile(user), nil
+}
+
+// DeleteAccount menghapus akun setelah password dikonfirmasi. Data pribadi dianonimkan
+// lalu user di-soft delete. Hold dan booking penerbangan yang belum berangkat dibatalkan;
+// booking yang sudah lewat tetap tersimpan untuk keperluan akuntansi.
+// Email lama bisa dipakai register lagi.
+func (s *UserService) DeleteAccount(userID uint, password string) error {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return err
+	}
+	if err := s.authService.checkPassword(user, password); err != nil {
+		return err
+	}
+
+	var released []releasedBooking
+	err = s.store.Transaction(func(tx repository.Store) error {
+		if err := anonymizeUser(tx, user); err != nil {
+			return err
+		}
+		released, err = s.bookingService.releaseUserBookings(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	s.bookingService.publishReleased(released)
+	return nil
+}
+
+// anonymizeUser menghapus data pribadi user, membatalkan antrean waitlist, token email dan
+// notifikasi yang belum terkirim, lalu soft delete. Isi dan alamat tujuan notifikasi serta
+// data penumpang manifest dengan email user ikut dikosongkan. Hold dan booking yang masih
+// berjalan dilepas terpisah lewat BookingService.releaseUserBookings di transaksi yang sama.
+func anonymizeUser(tx repository.Store, user *model.User) error {
+	entries, err := tx.Waitlist().FindByUser(user.ID)
+	if err != nil {
+		return err
+	}
+	for i := range entries {
+		if entries[i].Status == model.WaitlistWaiting || entries[i].Status == model.WaitlistOffered {
+			entries[i].Status = model.WaitlistCancelled
+			if err := tx.Waitlist().Update(&entries[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	now := time.Now()
+	for _, purpose := range []string{model.TokenEmailVerification, model.TokenPasswordReset, model.TokenEmailChange} {
+		if err := tx.Tokens().InvalidateForUser(user.ID, purpose, now); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Notifications().AnonymizeForUser(user.ID); err != nil {
+		return err
+	}
+	if err := tx.Passengers().AnonymizeByEmail(user.Email); err != nil {
+		return err
+	}
+
+	user.Email = fmt.Sprintf("deleted-%d@deleted.invalid", user.ID)
+	user.Name = "Deleted User"
+	user.Password = ""
+	user.EmailVerifiedAt = nil
+	user.BookingLimitExempt = false
+	if err := tx.Users().Update(user); err != nil {
+		return err
+	}
+	return tx.Users().Delete(user.ID)
+}
+
+func (s *UserService) findUser(userID uint) (*model.User, error) {
+	user, err := s.store.Users().FindByID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
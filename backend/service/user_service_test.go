@@ -0,0 +1,194 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/ratelimit"
+	"github.com/tiananugerah/go-BookCabin/repository"
+)
+
+func TestAnonymizeUserCancelsNotifications(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.user(t, "budi@example.com")
+	other := env.user(t, "sari@example.com")
+
+	now := time.Now()
+	pending := &model.Notification{UserID: user.ID, Event: "booking.created", Channel: model.ChannelEmail, DedupKey: "a",
+		Subject: "Booking confirmed", Body: "Hi Budi", Recipient: user.Email, NextAttemptAt: &now}
+	sent := &model.Notification{UserID: user.ID, Event: "booking.created", Channel: model.ChannelInApp, DedupKey: "a",
+		Subject: "Booking confirmed", Body: "Hi Budi", Status: model.NotificationSent, SentAt: &now}
+	untouched := &model.Notification{UserID: other.ID, Event: "booking.created", Channel: model.ChannelEmail, DedupKey: "b",
+		Subject: "Booking confirmed", Body: "Hi Sari", Recipient: other.Email, NextAttemptAt: &now}
+	for _, notification := range []*model.Notification{pending, sent, untouched} {
+		if err := env.store.Notifications().Create(notification); err != nil {
+			t.Fatalf("create notification: %v", err)
+		}
+	}
+
+	// Worker yang sudah mengklaim notifikasi sebelum akun dihapus
+	claimed := *pending
+	if ok, err := env.store.Notifications().Claim(&claimed, now.Add(time.Minute)); err != nil || !ok {
+		t.Fatalf("Claim = %v, %v", ok, err)
+	}
+
+	if err := env.store.Transaction(func(tx repository.Store) error { return anonymizeUser(tx, user) }); err != nil {
+		t.Fatalf("anonymizeUser: %v", err)
+	}
+
+	claimed.Status, claimed.NextAttemptAt = model.NotificationSent, nil
+	if err := env.store.Notifications().UpdateDelivery(&claimed); err != nil {
+		t.Fatalf("UpdateDelivery: %v", err)
+	}
+
+	notifications, _, err := env.store.Notifications().Search(repository.NotificationFilter{UserID: user.ID})
+	if err != nil {
+		t.Fatalf("search notifications: %v", err)
+	}
+	if len(notifications) != 2 {
+		t.Fatalf("found %d notifications, want 2", len(notifications))
+	}
+	for _, notification := range notifications {
+		if notification.Recipient != "" || notification.Subject != "" || notification.Body != "" {
+			t.Errorf("notification %d still has recipient %q subject %q body %q", notification.ID, notification.Recipient, notification.Subject, notification.Body)
+		}
+		want := model.NotificationSent
+		if notification.ID == pending.ID {
+			want = model.NotificationCancelled
+		}
+		if notification.Status != want {
+			t.Errorf("notification %d status = %s, want %s", notification.ID, notification.Status, want)
+		}
+	}
+	if due, _ := env.store.Notifications().FindDue(now.Add(time.Hour), 10); len(due) != 1 || due[0].ID != untouched.ID {
+		t.Errorf("due notifications = %+v, want only the other user's", due)
+	}
+}
+
+func TestDeleteUserScrubsPassengerRecords(t *testing.T) {
+	env := newTestEnv(t)
+	admin := env.user(t, "admin@example.com")
+	user := env.user(t, "budi@example.com")
+
+	dob := time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC)
+	passengers := []model.FlightPassenger{
+		{PassengerIndex: 1, FirstName: "Budi", LastName: "Santoso", Email: "BUDI@example.com", Type: "ADT", DateOfBirth: &dob,
+			SpecialRequests: model.StringArray{"WCHR"}, FrequentFlyerAirline: "OD", FrequentFlyerNumber: "OD123456", FrequentFlyerTier: 2, BookingClass: "Y"},
+		{PassengerIndex: 2, FirstName: "Sari", LastName: "Wijaya", Email: "sari@example.com", Type: "ADT", FrequentFlyerNumber: "OD654321"},
+	}
+	if err := env.store.Passengers().ReplaceForFlight(1, passengers); err != nil {
+		t.Fatalf("ReplaceForFlight: %v", err)
+	}
+
+	admins := NewAdminService(env.store, env.bus, env.bookings)
+	if err := admins.DeleteUser(admin.ID, user.ID, "GDPR request"); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+
+	if _, err := env.store.Passengers().FindByEmail(1, "budi@example.com"); err == nil {
+		t.Error("passenger record still matches the deleted user's email")
+	}
+	all, err := env.store.Passengers().FindByFlight(1)
+	if err != nil {
+		t.Fatalf("FindByFlight: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("flight has %d passengers, want 2", len(all))
+	}
+	for _, p := range all {
+		switch p.PassengerIndex {
+		case 1:
+			if p.FirstName != "" || p.LastName != "" || p.Email != "" || p.DateOfBirth != nil || len(p.SpecialRequests) != 0 ||
+				p.FrequentFlyerAirline != "" || p.FrequentFlyerNumber != "" || p.FrequentFlyerTier != 0 {
+				t.Errorf("deleted user's passenger record = %+v, want personal data cleared", p)
+			}
+			// Data manifest yang bukan data pribadi tetap ada
+			if p.Type != "ADT" || p.BookingClass != "Y" {
+				t.Errorf("passenger type %q class %q, want ADT Y", p.Type, p.BookingClass)
+			}
+		case 2:
+			if p.FirstName != "Sari" || p.Email != "sari@example.com" || p.FrequentFlyerNumber != "OD654321" {
+				t.Errorf("other passenger record = %+v, want it untouched", p)
+			}
+		}
+	}
+}
+
+func TestDeleteAccountReleasesOpenBookings(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.user(t, "budi@example.com")
+	free := env.freeSeats(t)
+	env.departIn(t, free[0].FlightID, 48*time.Hour)
+
+	hold, err := env.bookings.HoldSeat(user.ID, free[0].ID, "")
+	if err != nil {
+		t.Fatalf("HoldSeat: %v", err)
+	}
+	confirmed, err := env.bookings.CreateBooking(user.ID, free[1].ID, "")
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+
+	events, unsubscribe := env.bus.Subscribe(nil)
+	defer unsubscribe()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("Rahasia123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+	user.Password = string(hash)
+	if err := env.store.Users().Update(user); err != nil {
+		t.Fatalf("update user: %v", err)
+	}
+	lockout := ratelimit.NewLockout(ratelimit.NewMemoryStore(), ratelimit.DefaultLockoutPolicy())
+	auth := NewAuthService(env.store, []byte("test-key"), &recordingMailer{}, lockout, DefaultAuthConfig())
+	users := NewUserService(env.store, auth, env.bookings)
+	if err := users.DeleteAccount(user.ID, "Rahasia123"); err != nil {
+		t.Fatalf("DeleteAccount: %v", err)
+	}
+
+	for _, id := range []uint{hold.ID, confirmed.ID} {
+		booking, err := env.store.Bookings().FindByID(id)
+		if err != nil {
+			t.Fatalf("find booking: %v", err)
+		}
+		if booking.Status != model.StatusCancelled {
+			t.Errorf("booking %d status = %s, want cancelled", id, booking.Status)
+		}
+		if seat := env.seat(t, booking.SeatID); !seat.Available {
+			t.Errorf("seat %s of booking %d is still unavailable", seat.SeatCode, id)
+		}
+	}
+	if n := len(events); n != 2 {
+		t.Errorf("published %d events, want a booking.cancelled per released booking", n)
+	}
+}
+
+func TestDeleteAccountKeepsPastBookings(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.user(t, "budi@example.com")
+	seat := env.freeSeats(t)[0]
+	env.departIn(t, seat.FlightID, 48*time.Hour)
+
+	booking, err := env.bookings.CreateBooking(user.ID, seat.ID, "")
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+	env.departIn(t, seat.FlightID, -2*time.Hour)
+
+	admin := env.user(t, "admin@example.com")
+	if err := NewAdminService(env.store, env.bus, env.bookings).DeleteUser(admin.ID, user.ID, "closed account"); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+
+	kept, err := env.store.Bookings().FindByID(booking.ID)
+	if err != nil {
+		t.Fatalf("find booking: %v", err)
+	}
+	if kept.Status != model.StatusConfirmed {
+		t.Errorf("flown booking status = %s, want confirmed", kept.Status)
+	}
+}
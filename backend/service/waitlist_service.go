@@ -0,0 +1,148 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/repository"
+)
+
+type WaitlistService struct {
+	store repository.Store
+}
+
+func NewWaitlistService(store repository.Store) *WaitlistService {
+	return &WaitlistService{store: store}
+}
+
+// JoinWaitlist mendaftarkan user ke antrean untuk kursi, tipe kursi, atau kabin yang sedang penuh.
+// Kursi pertama yang kosong dan cocok akan di-hold otomatis untuk entry paling awal.
+func (s *WaitlistService) JoinWaitlist(userID uint, entry model.WaitlistEntry) (*model.WaitlistEntry, error) {
+	if entry.SeatID == nil && entry.SeatType == "" && entry.Cabin == "" {
+		return nil, ErrValidationFailed.WithDetail("one of seat_id, seat_type or cabin is required")
+	}
+	if entry.SeatType != "" && entry.SeatType != model.SeatTypeWindow && entry.SeatType != model.SeatTypeAisle {
+		return nil, ErrValidationFailed.WithDetail("seat_type must be %q or %q", model.SeatTypeWindow, model.SeatTypeAisle)
+	}
+
+	if _, err := s.store.Flights().FindByID(entry.FlightID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrFlightNotFound
+		}
+		return nil, err
+	}
+	if entry.SeatID != nil {
+		seat, err := s.store.Seats().FindByID(*entry.SeatID)
+		if errors.Is(err, repository.ErrNotFound) || (err == nil && seat.FlightID != entry.FlightID) {
+			return nil, ErrSeatNotFound
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	// Waitlist hanya untuk kursi yang sudah habis; kursi yang masih kosong bisa langsung dibooking
+	unbooked, err := s.store.Seats().FindUnbookedByFlight(entry.FlightID)
+	if err != nil {
+		return nil, err
+	}
+	unbooked = withoutBlocked(unbooked, time.Now())
+	for i := range unbooked {
+		if entry.Matches(&unbooked[i]) {
+			return nil, ErrMatchingSeatAvailable
+		}
+	}
+
+	existing, err := s.store.Waitlist().FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range existing {
+		open := existing[i].Status == model.WaitlistWaiting || existing[i].Status == model.WaitlistOffered
+		if open && existing[i].SameCriteria(&entry) {
+			return nil, ErrAlreadyWaitlisted
+		}
+	}
+
+	created := &model.WaitlistEntry{
+		UserID:   userID,
+		FlightID: entry.FlightID,
+		SeatID:   entry.SeatID,
+		SeatType: entry.SeatType,
+		Cabin:    entry.Cabin,
+		Status:   model.WaitlistWaiting,
+	}
+	if err := s.store.Waitlist().Create(created); err != nil {
+		return nil, err
+	}
+
+	if err := s.fillPositions([]*model.WaitlistEntry{created}); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// GetUserWaitlist mengembalikan semua entry user beserta posisi antrean untuk yang masih waiting
+func (s *WaitlistService) GetUserWaitlist(userID uint) ([]model.WaitlistEntry, error) {
+	entries, err := s.store.Waitlist().FindByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]*model.WaitlistEntry, len(entries))
+	for i := range entries {
+		refs[i] = &entries[i]
+	}
+	if err := s.fillPositions(refs); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (s *WaitlistService) LeaveWaitlist(userID, entryID uint) error {
+	entry, err := s.store.Waitlist().FindByID(entryID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrWaitlistEntryNotFound
+		}
+		return err
+	}
+	if entry.UserID != userID {
+		return ErrWaitlistEntryNotFound
+	}
+	if entry.Status != model.WaitlistWaiting {
+		return ErrWaitlistEntryClosed
+	}
+
+	entry.Status = model.WaitlistCancelled
+	return s.store.Waitlist().Update(entry)
+}
+
+// fillPositions menghitung posisi (mulai dari 1) setiap entry waiting di antara
+// entry lain dengan kriteria yang sama pada penerbangan yang sama.
+func (s *WaitlistService) fillPositions(entries []*model.WaitlistEntry) error {
+	queues := map[uint][]model.WaitlistEntry{}
+	for _, entry := range entries {
+		if entry.Status != model.WaitlistWaiting {
+			continue
+		}
+
+		queue, ok := queues[entry.FlightID]
+		if !ok {
+			var err error
+			queue, err = s.store.Waitlist().FindWaiting(entry.FlightID)
+			if err != nil {
+				return err
+			}
+			queues[entry.FlightID] = queue
+		}
+
+		entry.Position = 1
+		for i := range queue {
+			if queue[i].ID < entry.ID && queue[i].SameCriteria(entry) {
+				entry.Position++
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/tiananugerah/go-BookCabin/model"
+)
+
+func TestJoinWaitlistForBookedSeat(t *testing.T) {
+	env := newTestEnv(t)
+	waitlist := NewWaitlistService(env.store)
+	owner := env.user(t, "budi@example.com")
+	waiter := env.user(t, "sari@example.com")
+	free := env.freeSeats(t)
+
+	if _, err := waitlist.JoinWaitlist(waiter.ID, model.WaitlistEntry{FlightID: free[0].FlightID, SeatID: &free[0].ID}); !errors.Is(err, ErrMatchingSeatAvailable) {
+		t.Errorf("waitlist for a free seat err = %v, want ErrMatchingSeatAvailable", err)
+	}
+
+	booking, err := env.bookings.CreateBooking(owner.ID, free[0].ID, "")
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+	entry, err := waitlist.JoinWaitlist(waiter.ID, model.WaitlistEntry{FlightID: free[0].FlightID, SeatID: &free[0].ID})
+	if err != nil {
+		t.Fatalf("JoinWaitlist: %v", err)
+	}
+	if entry.Status != model.WaitlistWaiting || entry.Position != 1 {
+		t.Errorf("entry = %s position %d, want waiting at 1", entry.Status, entry.Position)
+	}
+
+	// Kursi yang dilepas langsung di-hold untuk entry pertama
+	if err := env.bookings.CancelBooking(owner.ID, booking.ID); err != nil {
+		t.Fatalf("CancelBooking: %v", err)
+	}
+	entries, err := waitlist.GetUserWaitlist(waiter.ID)
+	if err != nil {
+		t.Fatalf("GetUserWaitlist: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Status != model.WaitlistOffered || entries[0].HoldBookingID == nil {
+		t.Fatalf("entries = %+v, want one offered entry with a hold", entries)
+	}
+	hold, err := env.store.Bookings().FindByID(*entries[0].HoldBookingID)
+	if err != nil {
+		t.Fatalf("find hold: %v", err)
+	}
+	if hold.UserID != waiter.ID || hold.SeatID != free[0].ID || hold.Status != model.StatusPending {
+		t.Errorf("hold = user %d seat %d %s, want pending hold for user %d on seat %d", hold.UserID, hold.SeatID, hold.Status, waiter.ID, free[0].ID)
+	}
+}
@@ -0,0 +1,702 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/event"
+	"github.com/tiananugerah/go-BookCabin/model"
+	"github.com/tiananugerah/go-BookCabin/repository"
+)
+
+// WebhookEvents adalah tipe event yang bisa dilanggan endpoint partner
+var WebhookEvents = []event.Type{
+	event.BookingCreated,
+	event.BookingConfirmed,
+	event.BookingCancelled,
+	event.BookingSeatChanged,
+	event.HoldExpiring,
+	event.SeatBlocked,
+	event.SeatMapImported,
+}
+
+// Header pada setiap request webhook. Signature adalah "sha256=" + hex HMAC-SHA256 dari
+// "<timestamp>.<body>" dengan secret endpoint; lihat SignWebhook.
+const (
+	WebhookEventHeader     = "X-BookCabin-Event"
+	WebhookDeliveryHeader  = "X-BookCabin-Delivery"
+	WebhookTimestampHeader = "X-BookCabin-Timestamp"
+	WebhookSignatureHeader = "X-BookCabin-Signature"
+)
+
+// webhookBatchSize adalah jumlah delivery jatuh tempo yang diproses per putaran
+const webhookBatchSize = 100
+
+// maxWebhookErrorBody membatasi potongan body respons gagal yang disimpan pada delivery
+const maxWebhookErrorBody = 512
+
+type WebhookConfig struct {
+	// RetryBase adalah jeda setelah percobaan pertama gagal; jeda berikutnya berlipat dua
+	// sampai RetryMax
+	RetryBase time.Duration
+	RetryMax  time.Duration
+	// MaxAttempts adalah jumlah percobaan sebelum delivery masuk dead-letter queue
+	MaxAttempts int
+	// Timeout membatasi satu request ke endpoint partner
+	Timeout time.Duration
+}
+
+func DefaultWebhookConfig() WebhookConfig {
+	return WebhookConfig{
+		RetryBase:   30 * time.Second,
+		RetryMax:    time.Hour,
+		MaxAttempts: 10,
+		Timeout:     10 * time.Second,
+	}
+}
+
+// WebhookService mengirim event domain ke endpoint partner yang didaftarkan admin. Setiap event
+// disimpan dulu sebagai delivery per endpoint lalu dikirim dengan retry; delivery yang tetap
+// gagal masuk dead-letter queue dan bisa dikirim ulang lewat replay.
+type WebhookService struct {
+	store  repository.Store
+	config WebhookConfig
+	client *http.Client
+	// wake membangunkan RunDelivery saat ada delivery baru
+	wake chan struct{}
+}
+
+func NewWebhookService(store repository.Store, config WebhookConfig) *WebhookService {
+	return &WebhookService{
+		store:  store,
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// WebhookInput adalah data endpoint dari admin; Active nil berarti tidak diubah (aktif saat dibuat)
+type WebhookInput struct {
+	Name   string
+	URL    string
+	Events []string
+	Active *bool
+}
+
+// WebhookWithSecret adalah endpoint beserta secret-nya, hanya dikembalikan saat endpoint dibuat
+// atau secret diganti
+type WebhookWithSecret struct {
+	model.WebhookEndpoint
+	Secret string `json:"secret"`
+}
+
+// WebhookPayload adalah body JSON yang dikirim ke endpoint. ID sama untuk setiap percobaan dan
+// replay sehingga partner bisa membuang event yang sudah diproses.
+type WebhookPayload struct {
+	ID         string      `json:"id"`
+	Type       event.Type  `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+type webhookBooking struct {
+	Reference string              `json:"reference"`
+	Status    model.BookingStatus `json:"status"`
+	UserID    uint                `json:"user_id"`
+	Email     string              `json:"email"`
+	FlightID  uint                `json:"flight_id"`
+	Flight    string              `json:"flight,omitempty"`
+	Departure *time.Time          `json:"departure,omitempty"`
+	SeatCode  string              `json:"seat_code"`
+	Cabin     string              `json:"cabin"`
+	Price     float64             `json:"price"`
+	Currency  string              `json:"currency"`
+	PromoCode string              `json:"promo_code,omitempty"`
+}
+
+type webhookSeat struct {
+	FlightID     uint       `json:"flight_id"`
+	Flight       string     `json:"flight,omitempty"`
+	SeatCode     string     `json:"seat_code"`
+	Cabin        string     `json:"cabin"`
+	BlockReason  string     `json:"block_reason,omitempty"`
+	BlockedUntil *time.Time `json:"blocked_until,omitempty"`
+}
+
+type webhookSeatMap struct {
+	FlightID       uint      `json:"flight_id"`
+	Flight         string    `json:"flight"`
+	Origin         string    `json:"origin"`
+	Destination    string    `json:"destination"`
+	Departure      time.Time `json:"departure"`
+	Equipment      string    `json:"equipment"`
+	Seats          int       `json:"seats"`
+	SeatsAvailable int       `json:"seats_available"`
+}
+
+// SignWebhook menghitung nilai header signature untuk payload yang dikirim pada timestamp
+// (detik Unix). Partner menghitung ulang nilai ini dengan secret endpoint, membandingkannya
+// secara constant-time, dan menolak timestamp yang terlalu lama untuk mencegah replay.
+func SignWebhook(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *WebhookService) ListWebhooks() ([]model.WebhookEndpoint, error) {
+	return s.store.WebhookEndpoints().FindAll()
+}
+
+func (s *WebhookService) GetWebhook(id uint) (*model.WebhookEndpoint, error) {
+	return findWebhook(s.store, id)
+}
+
+// CreateWebhook mendaftarkan endpoint partner dengan secret baru
+func (s *WebhookService) CreateWebhook(actorID uint, input WebhookInput) (*WebhookWithSecret, error) {
+	endpoint := &model.WebhookEndpoint{Active: true, CreatedBy: actorID}
+	if err := applyWebhookInput(endpoint, input); err != nil {
+		return nil, err
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	endpoint.Secret = secret
+
+	err = s.store.Transaction(func(tx repository.Store) error {
+		if err := tx.WebhookEndpoints().Create(endpoint); err != nil {
+			return err
+		}
+		return audit(tx, actorID, AuditWebhookCreate, auditTargetWebhook, endpoint.ID, "", webhookDetails(endpoint))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookWithSecret{WebhookEndpoint: *endpoint, Secret: secret}, nil
+}
+
+// UpdateWebhook mengganti nama, URL, event dan status aktif endpoint. Delivery yang sudah
+// dibuat tetap dikirim ke URL terbaru.
+func (s *WebhookService) UpdateWebhook(actorID, id uint, input WebhookInput, reason string) (*model.WebhookEndpoint, error) {
+	endpoint, err := findWebhook(s.store, id)
+	if err != nil {
+		return nil, err
+	}
+	previous := webhookDetails(endpoint)
+	if err := applyWebhookInput(endpoint, input); err != nil {
+		return nil, err
+	}
+
+	err = s.store.Transaction(func(tx repository.Store) error {
+		if err := tx.WebhookEndpoints().Update(endpoint); err != nil {
+			return err
+		}
+		details := webhookDetails(endpoint)
+		details["previous"] = previous
+		return audit(tx, actorID, AuditWebhookUpdate, auditTargetWebhook, endpoint.ID, reason, details)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+// RotateWebhookSecret mengganti secret endpoint. Percobaan berikutnya, termasuk untuk delivery
+// yang sedang di-retry, ditandatangani dengan secret baru.
+func (s *WebhookService) RotateWebhookSecret(actorID, id uint, reason string) (*WebhookWithSecret, error) {
+	endpoint, err := findWebhook(s.store, id)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	endpoint.Secret = secret
+
+	err = s.store.Transaction(func(tx repository.Store) error {
+		if err := tx.WebhookEndpoints().Update(endpoint); err != nil {
+			return err
+		}
+		return audit(tx, actorID, AuditWebhookRotateSecret, auditTargetWebhook, endpoint.ID, reason, model.JSONMap{
+			"url": endpoint.URL,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookWithSecret{WebhookEndpoint: *endpoint, Secret: secret}, nil
+}
+
+// DeleteWebhook menghapus endpoint. Riwayat delivery tetap tersimpan; delivery yang belum
+// terkirim masuk dead-letter queue pada percobaan berikutnya.
+func (s *WebhookService) DeleteWebhook(actorID, id uint, reason string) error {
+	endpoint, err := findWebhook(s.store, id)
+	if err != nil {
+		return err
+	}
+
+	return s.store.Transaction(func(tx repository.Store) error {
+		if err := tx.WebhookEndpoints().Delete(endpoint.ID); err != nil {
+			return err
+		}
+		return audit(tx, actorID, AuditWebhookDelete, auditTargetWebhook, endpoint.ID, reason, webhookDetails(endpoint))
+	})
+}
+
+// ListDeliveries mengembalikan riwayat delivery, terbaru dulu
+func (s *WebhookService) ListDeliveries(filter repository.WebhookDeliveryFilter, pagination Pagination) (*Page[model.WebhookDelivery], error) {
+	pagination = pagination.normalize()
+	filter.Offset, filter.Limit = pagination.offset(), pagination.PageSize
+
+	deliveries, total, err := s.store.WebhookDeliveries().Search(filter)
+	if err != nil {
+		return nil, err
+	}
+	if deliveries == nil {
+		deliveries = []model.WebhookDelivery{}
+	}
+	return &Page[model.WebhookDelivery]{Items: deliveries, Total: total, Page: pagination.Page, PageSize: pagination.PageSize}, nil
+}
+
+func (s *WebhookService) GetDelivery(id uint) (*model.WebhookDelivery, error) {
+	return findDelivery(s.store, id)
+}
+
+// ReplayDelivery menjadwalkan ulang delivery yang sudah terkirim atau ada di dead-letter queue
+// dengan payload yang sama dan jumlah percobaan dari nol
+func (s *WebhookService) ReplayDelivery(actorID, id uint, reason string) (*model.WebhookDelivery, error) {
+	var delivery *model.WebhookDelivery
+	err := s.store.Transaction(func(tx repository.Store) error {
+		var err error
+		if delivery, err = findDelivery(tx, id); err != nil {
+			return err
+		}
+		if delivery.Status == model.WebhookPending {
+			return ErrWebhookDeliveryPending
+		}
+		if _, err := findWebhook(tx, delivery.EndpointID); err != nil {
+			return err
+		}
+		return replay(tx, actorID, delivery, reason)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.notifyWorker()
+	return delivery, nil
+}
+
+// ReplayDeadDeliveries menjadwalkan ulang semua delivery endpoint di dead-letter queue, misalnya
+// setelah gangguan di sisi partner selesai, dan mengembalikan jumlahnya
+func (s *WebhookService) ReplayDeadDeliveries(actorID, endpointID uint, reason string) (int, error) {
+	replayed := 0
+	err := s.store.Transaction(func(tx repository.Store) error {
+		if _, err := findWebhook(tx, endpointID); err != nil {
+			return err
+		}
+		for {
+			// Delivery yang di-replay keluar dari filter, sehingga halaman pertama selalu berisi sisanya
+			dead, _, err := tx.WebhookDeliveries().Search(repository.WebhookDeliveryFilter{
+				EndpointID: endpointID,
+				Status:     model.WebhookDead,
+				Limit:      webhookBatchSize,
+			})
+			if err != nil {
+				return err
+			}
+			if len(dead) == 0 {
+				return nil
+			}
+			for i := range dead {
+				if err := replay(tx, actorID, &dead[i], reason); err != nil {
+					return err
+				}
+				replayed++
+			}
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	if replayed > 0 {
+		s.notifyWorker()
+	}
+	return replayed, nil
+}
+
+func replay(tx repository.Store, actorID uint, delivery *model.WebhookDelivery, reason string) error {
+	previous := delivery.Status
+	now := time.Now()
+	delivery.Status = model.WebhookPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = &now
+	delivery.DeliveredAt = nil
+	delivery.DeadAt = nil
+	if err := tx.WebhookDeliveries().Update(delivery); err != nil {
+		return err
+	}
+	return audit(tx, actorID, AuditWebhookReplay, auditTargetDelivery, delivery.ID, reason, model.JSONMap{
+		"endpoint_id":     delivery.EndpointID,
+		"event":           delivery.Event,
+		"previous_status": previous,
+	})
+}
+
+// Run berlangganan event yang bisa dilanggan partner dan membuat delivery-nya sampai ctx
+// dibatalkan
+func (s *WebhookService) Run(ctx context.Context, bus event.Bus) {
+	events, unsubscribe := bus.Subscribe(func(e event.Event) bool {
+		return slices.Contains(WebhookEvents, e.Type)
+	})
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := s.Enqueue(e); err != nil {
+				log.Printf("failed to create webhook deliveries for %s: %v", e.Type, err)
+			}
+		}
+	}
+}
+
+// Enqueue membuat satu delivery untuk setiap endpoint aktif yang berlangganan tipe event.
+// Dengan PostgresBus setiap replika menerima event yang sama; EventKey memastikan hanya satu
+// delivery yang tersimpan per endpoint.
+func (s *WebhookService) Enqueue(e event.Event) error {
+	endpoints, err := s.store.WebhookEndpoints().FindAll()
+	if err != nil {
+		return err
+	}
+	endpoints = slices.DeleteFunc(endpoints, func(endpoint model.WebhookEndpoint) bool {
+		return !endpoint.Subscribes(string(e.Type))
+	})
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	key := fmt.Sprintf("%s:%d:%d:%d", e.Type, e.FlightID, e.SeatID, e.BookingID)
+	if e.Type == event.SeatMapImported || e.Type == event.SeatBlocked || e.Type == event.BookingSeatChanged {
+		// Kursi dan penerbangan bisa diimpor atau diblokir, dan booking bisa dipindah, berkali-kali;
+		// waktu event membedakannya
+		key = fmt.Sprintf("%s:%d", key, e.OccurredAt.UnixNano())
+	}
+	data, err := s.payloadData(e)
+	if err != nil || data == nil {
+		return err
+	}
+	payload, err := json.Marshal(WebhookPayload{ID: key, Type: e.Type, OccurredAt: e.OccurredAt.UTC(), Data: data})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	created := false
+	for _, endpoint := range endpoints {
+		delivery := &model.WebhookDelivery{
+			EndpointID:    endpoint.ID,
+			EventKey:      key,
+			Event:         string(e.Type),
+			Payload:       string(payload),
+			Status:        model.WebhookPending,
+			NextAttemptAt: &now,
+		}
+		if err := s.store.WebhookDeliveries().Create(delivery); err != nil {
+			if errors.Is(err, repository.ErrDuplicate) {
+				continue
+			}
+			return err
+		}
+		created = true
+	}
+	if created {
+		s.notifyWorker()
+	}
+	return nil
+}
+
+// payloadData menyusun isi "data" pada payload sesuai tipe event. nil berarti objek event
+// sudah tidak ada sehingga tidak ada yang dikirim.
+func (s *WebhookService) payloadData(e event.Event) (interface{}, error) {
+	switch e.Type {
+	case event.SeatBlocked:
+		seat, err := s.store.Seats().FindByID(e.SeatID)
+		if err != nil {
+			return nil, ignoreNotFound(err)
+		}
+		data := webhookSeat{
+			FlightID:     seat.FlightID,
+			SeatCode:     seat.SeatCode,
+			Cabin:        seat.Segment,
+			BlockReason:  seat.BlockReason,
+			BlockedUntil: seat.BlockedUntil,
+		}
+		if flight, err := s.store.Flights().FindByID(seat.FlightID); err == nil {
+			data.Flight = fmt.Sprintf("%s%d", flight.AirlineCode, flight.FlightNumber)
+		}
+		return data, nil
+
+	case event.SeatMapImported:
+		flight, err := s.store.Flights().FindByID(e.FlightID)
+		if err != nil {
+			return nil, ignoreNotFound(err)
+		}
+		seats, err := s.store.Seats().FindByFlight(flight.ID)
+		if err != nil {
+			return nil, err
+		}
+		data := webhookSeatMap{
+			FlightID:    flight.ID,
+			Flight:      fmt.Sprintf("%s%d", flight.AirlineCode, flight.FlightNumber),
+			Origin:      flight.Origin,
+			Destination: flight.Destination,
+			Departure:   flight.Departure,
+			Equipment:   flight.Equipment,
+			Seats:       len(seats),
+		}
+		for _, seat := range seats {
+			if seat.Available {
+				data.SeatsAvailable++
+			}
+		}
+		return data, nil
+
+	default:
+		booking, err := s.store.Bookings().FindByID(e.BookingID)
+		if err != nil {
+			return nil, ignoreNotFound(err)
+		}
+		data := webhookBooking{
+			Reference: booking.Reference,
+			Status:    booking.Status,
+			UserID:    booking.UserID,
+			FlightID:  booking.Seat.FlightID,
+			SeatCode:  e.SeatCode,
+			Cabin:     booking.Seat.Segment,
+			Price:     booking.Price,
+			Currency:  booking.Currency,
+			PromoCode: booking.PromoCode,
+		}
+		if user, err := s.store.Users().FindByID(booking.UserID); err == nil {
+			data.Email = user.Email
+		}
+		if flight, err := s.store.Flights().FindByID(booking.Seat.FlightID); err == nil {
+			data.Flight = fmt.Sprintf("%s%d", flight.AirlineCode, flight.FlightNumber)
+			data.Departure = &flight.Departure
+		}
+		return data, nil
+	}
+}
+
+func ignoreNotFound(err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// DeliverDue mengirim delivery yang jadwal percobaannya sudah tiba. Delivery diklaim dulu agar
+// replika lain tidak mengirimnya bersamaan.
+func (s *WebhookService) DeliverDue(ctx context.Context, now time.Time) error {
+	due, err := s.store.WebhookDeliveries().FindDue(now, webhookBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for i := range due {
+		delivery := &due[i]
+		// Klaim berlaku sedikit lebih lama dari timeout request; jika proses mati di tengah
+		// pengiriman, delivery dicoba lagi setelah klaim habis
+		claimed, err := s.store.WebhookDeliveries().Claim(delivery, time.Now().Add(2*s.config.Timeout))
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+		s.deliver(ctx, delivery)
+		if err := s.store.WebhookDeliveries().Update(delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliver menjalankan satu percobaan kirim dan mencatat hasilnya pada delivery
+func (s *WebhookService) deliver(ctx context.Context, delivery *model.WebhookDelivery) {
+	status, err := s.post(ctx, delivery)
+	now := time.Now()
+	delivery.ResponseStatus = status
+	if err == nil {
+		delivery.Status = model.WebhookDelivered
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		delivery.LastError = ""
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= s.config.MaxAttempts || errors.Is(err, ErrWebhookNotFound) {
+		delivery.Status = model.WebhookDead
+		delivery.DeadAt = &now
+		delivery.NextAttemptAt = nil
+		log.Printf("webhook delivery %d to endpoint %d moved to dead-letter queue after %d attempts: %v",
+			delivery.ID, delivery.EndpointID, delivery.Attempts, err)
+		return
+	}
+	next := now.Add(retryDelay(s.config.RetryBase, s.config.RetryMax, delivery.Attempts))
+	delivery.NextAttemptAt = &next
+}
+
+// post mengirim payload delivery ke endpoint dengan signature baru untuk percobaan ini.
+// Respons selain 2xx dianggap gagal.
+func (s *WebhookService) post(ctx context.Context, delivery *model.WebhookDelivery) (int, error) {
+	endpoint, err := findWebhook(s.store, delivery.EndpointID)
+	if err != nil {
+		return 0, err
+	}
+
+	payload := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(endpoint.Secret, timestamp, payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookErrorBody))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return resp.StatusCode, nil
+}
+
+// RunDelivery mengirim delivery jatuh tempo setiap interval, dan segera setelah ada delivery
+// baru, sampai ctx dibatalkan
+func (s *WebhookService) RunDelivery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+		if err := s.DeliverDue(ctx, time.Now()); err != nil {
+			log.Printf("failed to deliver webhooks: %v", err)
+		}
+	}
+}
+
+func (s *WebhookService) notifyWorker() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func findWebhook(tx repository.Store, id uint) (*model.WebhookEndpoint, error) {
+	endpoint, err := tx.WebhookEndpoints().FindByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return endpoint, nil
+}
+
+func findDelivery(tx repository.Store, id uint) (*model.WebhookDelivery, error) {
+	delivery, err := tx.WebhookDeliveries().FindByID(id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// applyWebhookInput memvalidasi input admin lalu menyalinnya ke endpoint
+func applyWebhookInput(endpoint *model.WebhookEndpoint, input WebhookInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return ErrValidationFailed.WithDetail("name is required")
+	}
+	target, err := url.Parse(strings.TrimSpace(input.URL))
+	if err != nil || (target.Scheme != "https" && target.Scheme != "http") || target.Host == "" {
+		return ErrValidationFailed.WithDetail("url must be an absolute http or https URL")
+	}
+	if len(input.Events) == 0 {
+		return ErrValidationFailed.WithDetail("events must list at least one event type")
+	}
+	events := model.StringArray{}
+	for _, eventType := range input.Events {
+		eventType = strings.TrimSpace(eventType)
+		if !slices.Contains(WebhookEvents, event.Type(eventType)) {
+			return ErrValidationFailed.WithDetail("unsupported event type %q", eventType)
+		}
+		if !slices.Contains(events, eventType) {
+			events = append(events, eventType)
+		}
+	}
+
+	endpoint.Name = name
+	endpoint.URL = target.String()
+	endpoint.Events = events
+	if input.Active != nil {
+		endpoint.Active = *input.Active
+	}
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(raw), nil
+}
+
+func webhookDetails(endpoint *model.WebhookEndpoint) model.JSONMap {
+	return model.JSONMap{
+		"name":   endpoint.Name,
+		"url":    endpoint.URL,
+		"events": []string(endpoint.Events),
+		"active": endpoint.Active,
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tiananugerah/go-BookCabin/event"
+	"github.com/tiananugerah/go-BookCabin/repository"
+)
+
+func TestWebhookDeliversSignedSeatChanges(t *testing.T) {
+	env := newTestEnv(t)
+	webhooks := NewWebhookService(env.store, DefaultWebhookConfig())
+	admin := env.user(t, "admin@example.com")
+	user := env.user(t, "budi@example.com")
+	seat := env.freeSeats(t)[0]
+	booking, err := env.bookings.CreateBooking(user.ID, seat.ID, "")
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+
+	var lock sync.Mutex
+	var received []WebhookPayload
+	var secret string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(WebhookTimestampHeader), 10, 64)
+		if r.Header.Get(WebhookSignatureHeader) != SignWebhook(secret, timestamp, body) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		var payload WebhookPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		lock.Lock()
+		received = append(received, payload)
+		lock.Unlock()
+	}))
+	defer server.Close()
+
+	endpoint, err := webhooks.CreateWebhook(admin.ID, WebhookInput{
+		Name:   "ops",
+		URL:    server.URL,
+		Events: []string{string(event.BookingSeatChanged), string(event.HoldExpiring)},
+	})
+	if err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+	secret = endpoint.Secret
+
+	// Booking yang dipindah bolak-balik ke kursi yang sama tetap menghasilkan dua delivery
+	changed := event.Event{Type: event.BookingSeatChanged, FlightID: seat.FlightID, SeatID: seat.ID, SeatCode: seat.SeatCode,
+		BookingID: booking.ID, UserID: user.ID, OccurredAt: time.Now()}
+	again := changed
+	again.OccurredAt = changed.OccurredAt.Add(time.Minute)
+	for _, e := range []event.Event{changed, again} {
+		if err := webhooks.Enqueue(e); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+	if err := webhooks.DeliverDue(context.Background(), time.Now()); err != nil {
+		t.Fatalf("DeliverDue: %v", err)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(received) != 2 {
+		t.Fatalf("endpoint received %d signed deliveries, want 2", len(received))
+	}
+	if received[0].Type != event.BookingSeatChanged || received[0].ID == received[1].ID {
+		t.Errorf("payloads = %+v, want two distinct booking.seat_changed deliveries", received)
+	}
+}
+
+func TestInactiveWebhookGetsNoDeliveries(t *testing.T) {
+	env := newTestEnv(t)
+	webhooks := NewWebhookService(env.store, DefaultWebhookConfig())
+	admin := env.user(t, "admin@example.com")
+	seat := env.freeSeats(t)[0]
+
+	inactive := false
+	endpoint, err := webhooks.CreateWebhook(admin.ID, WebhookInput{
+		Name:   "paused",
+		URL:    "https://partner.example.com/hook",
+		Events: []string{string(event.SeatBlocked)},
+		Active: &inactive,
+	})
+	if err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+	stored, err := webhooks.GetWebhook(endpoint.ID)
+	if err != nil {
+		t.Fatalf("GetWebhook: %v", err)
+	}
+	if stored.Active {
+		t.Fatal("endpoint registered as inactive was stored as active")
+	}
+
+	if err := webhooks.Enqueue(event.Event{Type: event.SeatBlocked, FlightID: seat.FlightID, SeatID: seat.ID, SeatCode: seat.SeatCode, OccurredAt: time.Now()}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	deliveries, err := webhooks.ListDeliveries(repository.WebhookDeliveryFilter{EndpointID: endpoint.ID}, Pagination{})
+	if err != nil {
+		t.Fatalf("ListDeliveries: %v", err)
+	}
+	if deliveries.Total != 0 {
+		t.Errorf("inactive endpoint got %d deliveries, want 0", deliveries.Total)
+	}
+}
@@ -38,8 +38,9 @@ class SeatService {
     return response.data;
   }
 
-  async importSeats(): Promise<void> {
-    await apiClient.post('/api/seats/import', {});
+  // Hanya untuk admin: tanpa body, backend mengimpor ulang file seat map bawaan dan mencatat reason di audit log
+  async importSeats(reason: string): Promise<void> {
+    await apiClient.post(`/admin/seats/import?reason=${encodeURIComponent(reason)}`, null);
   }
 }
 